@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"kasir-api/internal/config"
 	"kasir-api/internal/database"
+	"kasir-api/internal/domain"
 	"kasir-api/internal/handler"
+	"kasir-api/internal/receipt"
 	"kasir-api/internal/repository"
 	"kasir-api/internal/router"
 	"kasir-api/internal/service"
+	"kasir-api/internal/storage"
 
 	_ "kasir-api/docs"
 )
@@ -47,21 +53,135 @@ func main() {
 	}
 	defer db.Close()
 
+	// Bring the schema up to date
+	if err := database.Migrate(db); err != nil {
+		log.Fatal("Gagal migrasi database:", err)
+	}
+
 	// Initialize repositories
 	productRepo := repository.NewProductRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	taxRateRepo := repository.NewTaxRateRepository(db)
+	shiftRepo := repository.NewShiftRepository(db)
+	cartRepo := repository.NewCartRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
+	stockMovementRepo := repository.NewStockMovementRepository(db)
+	outletRepo := repository.NewOutletRepository(db)
+	stockTransferRepo := repository.NewStockTransferRepository(db)
+	unitRepo := repository.NewUnitRepository(db)
+	productUnitRepo := repository.NewProductUnitRepository(db)
+	productComponentRepo := repository.NewProductComponentRepository(db)
+	productImageRepo := repository.NewProductImageRepository(db)
+	stockBatchRepo := repository.NewStockBatchRepository(db)
+	stockCountRepo := repository.NewStockCountRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Initialize file storage
+	imageStore := storage.NewLocal(cfg.UploadDir, cfg.UploadBaseURL)
+
+	// Initialize receipt rendering, from a template file if one is configured
+	receiptOptions := receipt.Options{Header: cfg.ReceiptHeader, Footer: cfg.ReceiptFooter}
+	if cfg.ReceiptTemplate != "" {
+		source, err := os.ReadFile(cfg.ReceiptTemplate)
+		if err != nil {
+			log.Fatal("Error reading receipt template:", err)
+		}
+		receiptOptions.Template = string(source)
+	}
+	receipts, err := receipt.New(receiptOptions)
+	if err != nil {
+		log.Fatal("Error loading receipt template:", err)
+	}
+	if _, ok := receipt.Columns(cfg.ReceiptPaper); !ok {
+		log.Fatal("RECEIPT_PAPER must be 58 or 80")
+	}
 
 	// Initialize services
-	productService := service.NewProductService(productRepo, categoryRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
+	productService := service.NewProductService(productRepo, categoryRepo, priceHistoryRepo, taxRateRepo,
+		reservationRepo, stockMovementRepo, outletRepo, unitRepo, productComponentRepo, productImageRepo, imageStore,
+		transactor)
+	categoryService := service.NewCategoryService(categoryRepo, taxRateRepo, transactor)
+	auditService := service.NewAuditService(auditRepo)
+	promotionService := service.NewPromotionService(promotionRepo, productRepo, categoryRepo, transactor)
+	quoteService := service.NewQuoteService(productRepo, productUnitRepo, categoryRepo, promotionRepo, taxRateRepo)
+	taxRateService := service.NewTaxRateService(taxRateRepo, transactor)
+	shiftService := service.NewShiftService(shiftRepo, transactor)
+	cartService := service.NewCartService(cartRepo, productRepo, productUnitRepo, customerRepo, outletRepo, transactor,
+		cfg.ReservationTTL, domain.LoyaltyProgram{SpendPerPoint: cfg.LoyaltySpendPerPoint, PointValue: cfg.LoyaltyPointValue})
+	customerService := service.NewCustomerService(customerRepo, cartRepo, transactor)
+	supplierService := service.NewSupplierService(supplierRepo, transactor)
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, outletRepo, transactor)
+	outletService := service.NewOutletService(outletRepo, transactor)
+	stockTransferService := service.NewStockTransferService(stockTransferRepo, outletRepo, transactor)
+	unitService := service.NewUnitService(unitRepo, productRepo, productUnitRepo, transactor)
+	productImageService := service.NewProductImageService(productRepo, productImageRepo, imageStore, transactor,
+		cfg.ImageMaxBytes)
+	batchService := service.NewBatchService(stockBatchRepo, productRepo, outletRepo, transactor)
+	stockCountService := service.NewStockCountService(stockCountRepo, outletRepo, transactor)
+	transactionService := service.NewTransactionService(cartRepo, refundRepo, outletRepo, transactor, cfg.VoidWindow,
+		receipts, cfg.ReceiptPaper)
+	reportService := service.NewReportService(cartRepo, refundRepo, outletRepo)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyTTL)
 
 	// Initialize handlers
 	productHandler := handler.NewProductHandler(productService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	healthHandler := handler.NewHealthHandler(db)
+	auditHandler := handler.NewAuditHandler(auditService)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+	quoteHandler := handler.NewQuoteHandler(quoteService)
+	taxRateHandler := handler.NewTaxRateHandler(taxRateService)
+	shiftHandler := handler.NewShiftHandler(shiftService)
+	cartHandler := handler.NewCartHandler(cartService)
+	customerHandler := handler.NewCustomerHandler(customerService)
+	supplierHandler := handler.NewSupplierHandler(supplierService)
+	purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderService)
+	outletHandler := handler.NewOutletHandler(outletService)
+	stockTransferHandler := handler.NewStockTransferHandler(stockTransferService)
+	unitHandler := handler.NewUnitHandler(unitService)
+	productImageHandler := handler.NewProductImageHandler(productImageService)
+	batchHandler := handler.NewBatchHandler(batchService)
+	stockCountHandler := handler.NewStockCountHandler(stockCountService)
+	transactionHandler := handler.NewTransactionHandler(transactionService)
+	reportHandler := handler.NewReportHandler(reportService)
 
 	// Setup router
-	r := router.New(productHandler, categoryHandler, healthHandler)
+	r := router.New(router.Handlers{
+		Product:       productHandler,
+		Category:      categoryHandler,
+		Health:        healthHandler,
+		Audit:         auditHandler,
+		Promotion:     promotionHandler,
+		Quote:         quoteHandler,
+		TaxRate:       taxRateHandler,
+		Shift:         shiftHandler,
+		Cart:          cartHandler,
+		Customer:      customerHandler,
+		Supplier:      supplierHandler,
+		PurchaseOrder: purchaseOrderHandler,
+		Outlet:        outletHandler,
+		Unit:          unitHandler,
+		StockTransfer: stockTransferHandler,
+		ProductImage:  productImageHandler,
+		Batch:         batchHandler,
+		StockCount:    stockCountHandler,
+		Report:        reportHandler,
+		Transaction:   transactionHandler,
+		Uploads:       imageStore.Handler(),
+	}, cfg.AdminToken, cfg.SupervisorToken, idempotencyService, handler.IdempotentBodyLimit(cfg.ImageMaxBytes))
+
+	// Release stock held by carts whose reservations have expired, and drop
+	// stored idempotent responses past their TTL
+	go cartService.RunReservationReaper(context.Background(), time.Minute)
+	go idempotencyService.RunReaper(context.Background(), time.Hour)
 
 	// Start server
 	addr := "0.0.0.0:" + cfg.Port
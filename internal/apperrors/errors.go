@@ -18,4 +18,57 @@ var (
 
 	// ErrCategoryNotFound is returned when the specified category does not exist
 	ErrCategoryNotFound = errors.New("category not found")
+
+	// ErrProductNotFound is returned when a referenced product does not exist
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrTaxRateNotFound is returned when a referenced tax rate does not exist
+	ErrTaxRateNotFound = errors.New("tax rate not found")
+
+	// ErrCustomerNotFound is returned when a referenced customer does not exist
+	ErrCustomerNotFound = errors.New("customer not found")
+
+	// ErrSupplierNotFound is returned when a referenced supplier does not exist
+	ErrSupplierNotFound = errors.New("supplier not found")
+
+	// ErrOutletNotFound is returned when a referenced outlet does not exist
+	ErrOutletNotFound = errors.New("outlet not found")
+
+	// ErrUnitNotFound is returned when a referenced unit of measure does not exist
+	ErrUnitNotFound = errors.New("unit not found")
+
+	// ErrInsufficientStock is returned when a sale needs more stock than is on hand
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrNoOpenShift is returned when cash is taken without an open shift to
+	// reconcile it in
+	ErrNoOpenShift = errors.New("cash payments need an open shift")
+
+	// ErrForbidden is returned when the caller may not act on a resource
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrInsufficientPoints is returned when a customer redeems more loyalty
+	// points than they have
+	ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+	// ErrVoidNotAllowed is returned when a sale can no longer be voided
+	ErrVoidNotAllowed = errors.New("sale can no longer be voided")
+
+	// ErrImageNotFound is returned when a product has no image with the given ID
+	ErrImageNotFound = errors.New("image not found")
+
+	// ErrImageTooLarge is returned when an uploaded image exceeds the size limit
+	ErrImageTooLarge = errors.New("image too large")
+
+	// ErrUnsupportedImageType is returned when an upload is not an image type
+	// the store accepts
+	ErrUnsupportedImageType = errors.New("unsupported image type")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is sent again
+	// with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+	// ErrIdempotencyInProgress is returned when a retry arrives while the first
+	// request with the same idempotency key is still being handled
+	ErrIdempotencyInProgress = errors.New("idempotent request still in progress")
 )
@@ -3,20 +3,45 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
+	Port                 string        `mapstructure:"PORT"`
+	DBConn               string        `mapstructure:"DB_CONN"`
+	AdminToken           string        `mapstructure:"ADMIN_TOKEN"`
+	SupervisorToken      string        `mapstructure:"SUPERVISOR_TOKEN"`
+	VoidWindow           time.Duration `mapstructure:"VOID_WINDOW"`
+	ReservationTTL       time.Duration `mapstructure:"RESERVATION_TTL"`
+	IdempotencyTTL       time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	LoyaltySpendPerPoint int           `mapstructure:"LOYALTY_SPEND_PER_POINT"`
+	LoyaltyPointValue    int           `mapstructure:"LOYALTY_POINT_VALUE"`
+	UploadDir            string        `mapstructure:"UPLOAD_DIR"`
+	UploadBaseURL        string        `mapstructure:"UPLOAD_BASE_URL"`
+	ImageMaxBytes        int           `mapstructure:"IMAGE_MAX_BYTES"`
+	ReceiptPaper         int           `mapstructure:"RECEIPT_PAPER"`
+	ReceiptHeader        []string      `mapstructure:"RECEIPT_HEADER"`
+	ReceiptFooter        []string      `mapstructure:"RECEIPT_FOOTER"`
+	ReceiptTemplate      string        `mapstructure:"RECEIPT_TEMPLATE"`
 }
 
 // Load reads configuration from environment variables and .env file
 func Load() (*Config, error) {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.SetDefault("RESERVATION_TTL", "15m")
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("VOID_WINDOW", "15m")
+	viper.SetDefault("LOYALTY_SPEND_PER_POINT", 10000)
+	viper.SetDefault("LOYALTY_POINT_VALUE", 100)
+	viper.SetDefault("UPLOAD_DIR", "uploads")
+	viper.SetDefault("UPLOAD_BASE_URL", "/uploads")
+	viper.SetDefault("IMAGE_MAX_BYTES", 5<<20)
+	viper.SetDefault("RECEIPT_PAPER", 58)
+	viper.SetDefault("RECEIPT_FOOTER", "Thank you")
 
 	// Load from .env file if exists
 	if _, err := os.Stat(".env"); err == nil {
@@ -27,9 +52,31 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:                 viper.GetString("PORT"),
+		DBConn:               viper.GetString("DB_CONN"),
+		AdminToken:           viper.GetString("ADMIN_TOKEN"),
+		SupervisorToken:      viper.GetString("SUPERVISOR_TOKEN"),
+		VoidWindow:           viper.GetDuration("VOID_WINDOW"),
+		ReservationTTL:       viper.GetDuration("RESERVATION_TTL"),
+		IdempotencyTTL:       viper.GetDuration("IDEMPOTENCY_TTL"),
+		LoyaltySpendPerPoint: viper.GetInt("LOYALTY_SPEND_PER_POINT"),
+		LoyaltyPointValue:    viper.GetInt("LOYALTY_POINT_VALUE"),
+		UploadDir:            viper.GetString("UPLOAD_DIR"),
+		UploadBaseURL:        viper.GetString("UPLOAD_BASE_URL"),
+		ImageMaxBytes:        viper.GetInt("IMAGE_MAX_BYTES"),
+		ReceiptPaper:         viper.GetInt("RECEIPT_PAPER"),
+		ReceiptHeader:        lines(viper.GetString("RECEIPT_HEADER")),
+		ReceiptFooter:        lines(viper.GetString("RECEIPT_FOOTER")),
+		ReceiptTemplate:      viper.GetString("RECEIPT_TEMPLATE"),
 	}
 
 	return cfg, nil
 }
+
+// lines splits a setting holding several lines separated by "|"
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "|")
+}
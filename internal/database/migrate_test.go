@@ -0,0 +1,21 @@
+package database
+
+import "testing"
+
+func TestMigrations_NumberedInOrder(t *testing.T) {
+	list, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations: %v", err)
+	}
+	if len(list) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range list {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+		if m.Name == "" || m.SQL == "" {
+			t.Errorf("migration %04d is missing its name or SQL", m.Version)
+		}
+	}
+}
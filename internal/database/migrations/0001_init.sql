@@ -0,0 +1,22 @@
+-- Baseline schema: categories and products with a single stock column.
+-- IF NOT EXISTS lets databases set up by hand from the old README adopt
+-- the migrations.
+
+-- Create categories table
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT
+);
+
+-- Create products table
+CREATE TABLE IF NOT EXISTS products (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL,
+    stock INTEGER NOT NULL DEFAULT 0,
+    category_id INTEGER NOT NULL REFERENCES categories(id)
+);
+
+-- Create index for faster product lookups by category
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
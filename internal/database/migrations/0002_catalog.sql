@@ -0,0 +1,117 @@
+-- Tax rates, units, the category tree, variants, bundles, images and price
+-- history.
+
+-- Create tax rates table
+CREATE TABLE tax_rates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rate NUMERIC(5, 2) NOT NULL,
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Categories with a parent_id are subcategories; a category with
+-- subcategories or products cannot be deleted.
+ALTER TABLE categories
+    ADD COLUMN tax_rate_id INTEGER REFERENCES tax_rates(id),
+    ADD COLUMN parent_id INTEGER REFERENCES categories(id);
+
+-- Create index for walking down the category tree
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+-- Create units of measure table and the piece unit products are counted in
+-- unless they name another
+CREATE TABLE units (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL
+);
+
+INSERT INTO units (code, name) VALUES ('pcs', 'Piece');
+
+-- Variants are products with a parent_id; a product with variants cannot be
+-- deleted. Barcodes are NULL when a product has none. Bundles hold no stock
+-- of their own and are made up of product_components. Existing products
+-- are counted in pieces.
+ALTER TABLE products
+    ADD COLUMN type VARCHAR(16) NOT NULL DEFAULT 'standard' CHECK (type IN ('standard', 'bundle')),
+    ADD COLUMN cost_price INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN tax_rate_id INTEGER REFERENCES tax_rates(id),
+    ADD COLUMN unit_id INTEGER NOT NULL DEFAULT 1 REFERENCES units(id),
+    ADD COLUMN parent_id INTEGER REFERENCES products(id),
+    ADD COLUMN barcode VARCHAR(64) UNIQUE,
+    ADD COLUMN options JSONB NOT NULL DEFAULT '{}';
+
+-- Create index for listing a product's variants
+CREATE INDEX idx_products_parent_id ON products(parent_id);
+
+-- Create product units table, the other units a product is bought or sold
+-- in and how many base units each holds. Units in use cannot be deleted.
+CREATE TABLE product_units (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    unit_id INTEGER NOT NULL REFERENCES units(id),
+    factor INTEGER NOT NULL CHECK (factor > 0),
+    price INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, unit_id)
+);
+
+-- Create product components table, the quantity of each product, in its
+-- base unit, that one unit of a bundle consumes. Products that are part of a
+-- bundle cannot be deleted.
+CREATE TABLE product_components (
+    bundle_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    component_id INTEGER NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id)
+);
+
+-- Create index for finding the bundles a product is part of
+CREATE INDEX idx_product_components_component_id ON product_components(component_id);
+
+-- Create product images table. key and thumbnail_key locate the original
+-- and its thumbnail in image storage.
+CREATE TABLE product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(64) NOT NULL,
+    size INTEGER NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create index for listing a product's images
+CREATE INDEX idx_product_images_product_id ON product_images(product_id);
+
+-- Create product price history table. Deleting a product keeps its history
+-- for auditing with product_id cleared.
+CREATE TABLE product_price_history (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+    old_price INTEGER NOT NULL,
+    new_price INTEGER NOT NULL,
+    changed_by VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create index for listing a product's price history
+CREATE INDEX idx_product_price_history_product_id ON product_price_history(product_id);
+
+-- Create audit log table
+CREATE TABLE audit_log (
+    id SERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(20) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before JSONB,
+    after JSONB,
+    changes JSONB,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for the audit log filters
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
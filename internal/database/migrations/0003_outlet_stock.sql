@@ -0,0 +1,34 @@
+-- Outlets, and stock kept per outlet in product_stocks instead of
+-- products.stock.
+
+-- Create outlets table and the default outlet that requests without an
+-- outlet use
+CREATE TABLE outlets (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO outlets (code, name) VALUES ('MAIN', 'Main outlet');
+
+-- Create product stocks table, the quantity of each product on hand at each
+-- outlet, in the product's base unit. Outlets holding stock rows cannot be
+-- deleted.
+CREATE TABLE product_stocks (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    quantity INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, outlet_id)
+);
+
+-- Create index for listing an outlet's stock
+CREATE INDEX idx_product_stocks_outlet_id ON product_stocks(outlet_id);
+
+-- Move the stock on hand to the default outlet before dropping the column
+INSERT INTO product_stocks (product_id, outlet_id, quantity)
+SELECT p.id, o.id, p.stock
+FROM products p
+JOIN outlets o ON o.code = 'MAIN';
+
+ALTER TABLE products DROP COLUMN stock;
@@ -0,0 +1,222 @@
+-- Promotions, shifts, customers, carts with their payments, discounts, taxes
+-- and stock reservations, refunds, and idempotency keys.
+
+-- Create promotions table
+CREATE TABLE promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    scope VARCHAR(20) NOT NULL,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    value INTEGER NOT NULL DEFAULT 0,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    free_quantity INTEGER NOT NULL DEFAULT 0,
+    min_subtotal INTEGER NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ
+);
+
+-- Create cashier shifts table
+CREATE TABLE shifts (
+    id SERIAL PRIMARY KEY,
+    cashier VARCHAR(255) NOT NULL,
+    opening_cash INTEGER NOT NULL,
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ,
+    expected_cash INTEGER,
+    counted_cash INTEGER,
+    variance INTEGER
+);
+
+-- Allow at most one open shift per cashier
+CREATE UNIQUE INDEX idx_shifts_open_cashier ON shifts(cashier) WHERE closed_at IS NULL;
+
+-- Create customers table. Member codes are optional but unique when set.
+CREATE TABLE customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    member_code VARCHAR(50) UNIQUE,
+    points INTEGER NOT NULL DEFAULT 0 CHECK (points >= 0)
+);
+
+-- Create carts table. A checked out cart is the record of a sale; a voided
+-- one keeps its lines and payments, with who voided it, when and why, but no
+-- longer counts as a sale.
+CREATE TABLE carts (
+    id SERIAL PRIMARY KEY,
+    cashier VARCHAR(255) NOT NULL DEFAULT '',
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(20) NOT NULL,
+    reserve_stock BOOLEAN NOT NULL DEFAULT FALSE,
+    shift_id INTEGER REFERENCES shifts(id),
+    customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+    subtotal INTEGER NOT NULL DEFAULT 0,
+    discount INTEGER NOT NULL DEFAULT 0,
+    tax INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    paid INTEGER NOT NULL DEFAULT 0,
+    change_due INTEGER NOT NULL DEFAULT 0,
+    points_redeemed INTEGER NOT NULL DEFAULT 0,
+    points_discount INTEGER NOT NULL DEFAULT 0,
+    points_earned INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    checked_out_at TIMESTAMPTZ,
+    voided_at TIMESTAMPTZ,
+    voided_by VARCHAR(255) NOT NULL DEFAULT '',
+    void_reason TEXT NOT NULL DEFAULT ''
+);
+
+-- Create indexes for listing carts by status and outlet, summing a shift's
+-- sales and listing a customer's purchases
+CREATE INDEX idx_carts_status ON carts(status);
+CREATE INDEX idx_carts_outlet_id ON carts(outlet_id);
+CREATE INDEX idx_carts_shift_id ON carts(shift_id);
+CREATE INDEX idx_carts_customer_id ON carts(customer_id);
+
+-- Create cart items table. product_id has no foreign key so sold lines
+-- survive the product being deleted. cost_price is the cost of one unit
+-- snapshotted at checkout, so margins on past sales do not move with the
+-- product's cost price.
+CREATE TABLE cart_items (
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    unit_id INTEGER NOT NULL DEFAULT 1,
+    quantity INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    unit_price INTEGER NOT NULL DEFAULT 0,
+    cost_price INTEGER NOT NULL DEFAULT 0,
+    discount INTEGER NOT NULL DEFAULT 0,
+    tax INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (cart_id, position)
+);
+
+-- Create cart item stock table, the quantity of each product, in its base
+-- unit, a sold line took from stock: the product itself or a bundle's
+-- components. Refunds and voids put back what was taken, whatever has
+-- happened to the catalogue since.
+CREATE TABLE cart_item_stock (
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (cart_id, position, product_id)
+);
+
+-- Create payments table, one row per tender of a checked out cart
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    method VARCHAR(20) NOT NULL,
+    amount INTEGER NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- Create index for loading a cart's payments
+CREATE INDEX idx_payments_cart_id ON payments(cart_id);
+
+-- Create cart discounts table, every promotion applied to a checked out cart:
+-- on one of its lines when position is set, on the whole cart when it is
+-- NULL. promotion_id has no foreign key so the record survives the promotion
+-- being deleted.
+CREATE TABLE cart_discounts (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    position INTEGER,
+    promotion_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL
+);
+
+-- Create index for loading a cart's discounts
+CREATE INDEX idx_cart_discounts_cart_id ON cart_discounts(cart_id);
+
+-- Create cart taxes table, the tax charged under each rate on a checked out
+-- cart. The rate's name and percentage are copied so the record survives the
+-- rate changing or being deleted.
+CREATE TABLE cart_taxes (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    tax_rate_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    rate NUMERIC(5, 2) NOT NULL,
+    inclusive BOOLEAN NOT NULL,
+    taxable INTEGER NOT NULL,
+    tax INTEGER NOT NULL
+);
+
+-- Create index for loading a cart's taxes
+CREATE INDEX idx_cart_taxes_cart_id ON cart_taxes(cart_id);
+
+-- Create stock reservations table, one row per reserving cart line
+CREATE TABLE stock_reservations (
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (cart_id, product_id)
+);
+
+-- Create indexes for summing a product's reservations and reaping expired ones
+CREATE INDEX idx_stock_reservations_product_id ON stock_reservations(product_id);
+CREATE INDEX idx_stock_reservations_expires_at ON stock_reservations(expires_at);
+
+-- Create refunds table, refunds of checked out carts, paid back by method in
+-- the refunding cashier's shift
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL REFERENCES carts(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    shift_id INTEGER REFERENCES shifts(id),
+    restock BOOLEAN NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    method VARCHAR(20) NOT NULL DEFAULT 'cash',
+    amount INTEGER NOT NULL,
+    tax INTEGER NOT NULL,
+    refunded_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for listing a cart's refunds and the refunds in a period,
+-- and summing a shift's refunds
+CREATE INDEX idx_refunds_cart_id ON refunds(cart_id);
+CREATE INDEX idx_refunds_created_at ON refunds(created_at);
+CREATE INDEX idx_refunds_shift_id ON refunds(shift_id);
+
+-- Create refund lines table, the lines a refund gives back. Like cart_items
+-- they do not reference products, so refunded products can still be deleted.
+CREATE TABLE refund_lines (
+    refund_id INTEGER NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    unit_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    tax INTEGER NOT NULL,
+    cost_price INTEGER NOT NULL,
+    PRIMARY KEY (refund_id, position)
+);
+
+-- Create idempotency keys table, holding the first response to each keyed
+-- request until it expires; status_code is NULL while the request runs
+CREATE TABLE idempotency_keys (
+    key VARCHAR(255) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, route)
+);
+
+-- Create index for reaping expired idempotency keys
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
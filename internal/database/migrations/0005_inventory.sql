@@ -0,0 +1,145 @@
+-- Suppliers and purchase orders, the stock movement ledger, batches,
+-- transfers between outlets and stock counts.
+
+-- Create suppliers table
+CREATE TABLE suppliers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT ''
+);
+
+-- Create purchase orders table. Suppliers with orders cannot be deleted.
+CREATE TABLE purchase_orders (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id),
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(20) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create index for listing purchase orders by status
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(status);
+
+-- Create purchase order lines table. Like cart_items it does not reference
+-- products, so ordered products can still be deleted.
+CREATE TABLE purchase_order_lines (
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    unit_id INTEGER NOT NULL DEFAULT 1,
+    factor INTEGER NOT NULL DEFAULT 1,
+    quantity INTEGER NOT NULL,
+    unit_cost INTEGER NOT NULL,
+    received_quantity INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (purchase_order_id, position)
+);
+
+-- Create stock movements table, the ledger of every change to a product's
+-- stock. Deleting a product keeps its movements for auditing with product_id
+-- cleared.
+CREATE TABLE stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER REFERENCES products(id) ON DELETE SET NULL,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    quantity INTEGER NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create index for listing a product's stock movements
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, created_at DESC);
+
+-- Create stock batches table, lots of a product received at an outlet with
+-- their lot number and expiry date. remaining never exceeds the product's
+-- stock at the outlet; stock is taken from the batch expiring first.
+CREATE TABLE stock_batches (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    lot_number VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    remaining INTEGER NOT NULL CHECK (remaining >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for taking a product's batches first-expired-first-out and
+-- for finding batches about to expire
+CREATE INDEX idx_stock_batches_product_outlet ON stock_batches(product_id, outlet_id, expires_at);
+CREATE INDEX idx_stock_batches_expires_at ON stock_batches(expires_at) WHERE remaining > 0;
+
+-- Create stock transfers table, documents moving stock between outlets
+CREATE TABLE stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    to_outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    status VARCHAR(20) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_outlet_id <> to_outlet_id)
+);
+
+-- Create indexes for listing an outlet's transfers in either direction
+CREATE INDEX idx_stock_transfers_from_outlet_id ON stock_transfers(from_outlet_id);
+CREATE INDEX idx_stock_transfers_to_outlet_id ON stock_transfers(to_outlet_id);
+
+-- Create stock transfer lines table. Like purchase_order_lines it does not
+-- reference products.
+CREATE TABLE stock_transfer_lines (
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (transfer_id, position)
+);
+
+-- Create stock counts table, stock opname documents counting the products at
+-- an outlet, optionally only those in one category. The category is not
+-- referenced so deleting it later does not touch the count.
+CREATE TABLE stock_counts (
+    id SERIAL PRIMARY KEY,
+    outlet_id INTEGER NOT NULL REFERENCES outlets(id),
+    category_id INTEGER,
+    status VARCHAR(20) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    approved_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create index for listing an outlet's stock counts
+CREATE INDEX idx_stock_counts_outlet_id ON stock_counts(outlet_id);
+
+-- Create stock count lines table. Like stock_transfer_lines it does not
+-- reference products, and it keeps the product name so the count outlives them.
+CREATE TABLE stock_count_lines (
+    count_id INTEGER NOT NULL REFERENCES stock_counts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    system_quantity INTEGER NOT NULL,
+    counted_quantity INTEGER CHECK (counted_quantity >= 0),
+    PRIMARY KEY (count_id, position)
+);
+
+-- Create stock count entries table, every quantity recorded by a counter
+CREATE TABLE stock_count_entries (
+    id SERIAL PRIMARY KEY,
+    count_id INTEGER NOT NULL REFERENCES stock_counts(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    counted_by VARCHAR(255) NOT NULL DEFAULT '',
+    counted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create index for loading a count's entries
+CREATE INDEX idx_stock_count_entries_count_id ON stock_count_entries(count_id, id);
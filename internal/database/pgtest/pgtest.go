@@ -0,0 +1,132 @@
+// Package pgtest starts a throwaway PostgreSQL server from locally installed
+// binaries so repository tests can run against real SQL without Docker or
+// network access.
+package pgtest
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"kasir-api/internal/database"
+
+	_ "github.com/lib/pq"
+)
+
+// ErrNotInstalled is returned by Start when no PostgreSQL binaries can be found
+var ErrNotInstalled = errors.New("postgres binaries not found (set KASIR_PG_BIN or add initdb to PATH)")
+
+// Cluster is a PostgreSQL server running from a temporary data directory
+type Cluster struct {
+	binDir  string
+	dataDir string
+	port    int
+	dbSeq   atomic.Int64
+}
+
+// Start initialises a new data directory and starts a server listening on a
+// unix socket inside it. Binaries are looked up in $KASIR_PG_BIN, then PATH,
+// then the usual Debian/Ubuntu install location.
+func Start() (*Cluster, error) {
+	binDir, err := findBinDir()
+	if err != nil {
+		return nil, err
+	}
+	if os.Geteuid() == 0 {
+		return nil, fmt.Errorf("%w: postgres refuses to run as root", ErrNotInstalled)
+	}
+
+	// Unix socket paths are limited to ~100 bytes, so avoid deep test temp dirs
+	dataDir, err := os.MkdirTemp("", "kasir-pg-")
+	if err != nil {
+		return nil, err
+	}
+
+	// The socket lives in its own directory, so the default port never clashes
+	c := &Cluster{binDir: binDir, dataDir: dataDir, port: 5432}
+	if err := c.run("initdb", "-D", c.pgData(), "-U", "postgres", "-A", "trust", "--no-sync"); err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses='' -c fsync=off", c.port, dataDir)
+	logFile := filepath.Join(dataDir, "postgres.log")
+	if err := c.run("pg_ctl", "-D", c.pgData(), "-l", logFile, "-o", opts, "-w", "start"); err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Stop shuts the server down and removes its data directory
+func (c *Cluster) Stop() error {
+	err := c.run("pg_ctl", "-D", c.pgData(), "-m", "immediate", "-w", "stop")
+	if rmErr := os.RemoveAll(c.dataDir); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// NewDB creates an empty database with the application schema applied and
+// returns a connection to it. The connection is closed when t finishes.
+func (c *Cluster) NewDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	admin, err := sql.Open("postgres", c.dsn("postgres"))
+	if err != nil {
+		t.Fatalf("pgtest: connect: %v", err)
+	}
+	defer admin.Close()
+
+	name := fmt.Sprintf("kasir_test_%d", c.dbSeq.Add(1))
+	if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("pgtest: create database: %v", err)
+	}
+
+	db, err := sql.Open("postgres", c.dsn(name))
+	if err != nil {
+		t.Fatalf("pgtest: connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("pgtest: migrate: %v", err)
+	}
+	return db
+}
+
+func (c *Cluster) pgData() string {
+	return filepath.Join(c.dataDir, "data")
+}
+
+func (c *Cluster) dsn(dbName string) string {
+	return fmt.Sprintf("host=%s port=%d user=postgres dbname=%s sslmode=disable", c.dataDir, c.port, dbName)
+}
+
+func (c *Cluster) run(name string, args ...string) error {
+	out, err := exec.Command(filepath.Join(c.binDir, name), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w\n%s", name, err, out)
+	}
+	return nil
+}
+
+func findBinDir() (string, error) {
+	if dir := os.Getenv("KASIR_PG_BIN"); dir != "" {
+		return dir, nil
+	}
+	if path, err := exec.LookPath("initdb"); err == nil {
+		return filepath.Dir(path), nil
+	}
+	matches, _ := filepath.Glob("/usr/lib/postgresql/*/bin/initdb")
+	if len(matches) > 0 {
+		return filepath.Dir(matches[len(matches)-1]), nil
+	}
+	return "", ErrNotInstalled
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Audited entity types
+const (
+	AuditEntityProduct       = "product"
+	AuditEntityCategory      = "category"
+	AuditEntityPromotion     = "promotion"
+	AuditEntityTaxRate       = "tax_rate"
+	AuditEntityShift         = "shift"
+	AuditEntityCustomer      = "customer"
+	AuditEntitySupplier      = "supplier"
+	AuditEntityPurchaseOrder = "purchase_order"
+	AuditEntityOutlet        = "outlet"
+	AuditEntityStockTransfer = "stock_transfer"
+	AuditEntityUnit          = "unit"
+	AuditEntityStockCount    = "stock_count"
+	AuditEntityCart          = "cart"
+	AuditEntityStockBatch    = "stock_batch"
+	AuditEntityRefund        = "refund"
+)
+
+// ChangeMeta identifies who made a change and which request it came from
+type ChangeMeta struct {
+	Actor     string
+	RequestID string
+}
+
+// AuditEntry records one mutating operation on an entity
+// @Description Audit log entry
+type AuditEntry struct {
+	ID         int             `json:"id" example:"1"`
+	Actor      string          `json:"actor,omitempty" example:"budi"`
+	Action     string          `json:"action" example:"update"`
+	EntityType string          `json:"entity_type" example:"product"`
+	EntityID   int             `json:"entity_id" example:"1"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	Changes    json.RawMessage `json:"changes,omitempty" swaggertype:"object"`
+	RequestID  string          `json:"request_id,omitempty" example:"9f2c4e1a7b3d5f60"`
+	CreatedAt  time.Time       `json:"created_at" example:"2026-01-15T08:30:00Z"`
+}
+
+// AuditFilter narrows an audit log query; zero values are ignored
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   int
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// StockBatch is a lot of a product received at an outlet, with the lot
+// number and expiry date printed on it. Remaining is how much of it is still
+// in stock. Batches account for part or all of a product's stock at the
+// outlet; stock received without a lot or expiry is not batched.
+// @Description Stock batch
+type StockBatch struct {
+	ID          int        `json:"id" example:"1"`
+	ProductID   int        `json:"product_id" example:"1"`
+	ProductName string     `json:"product_name" example:"Susu UHT 1L"`
+	OutletID    int        `json:"outlet_id" example:"1"`
+	LotNumber   string     `json:"lot_number" example:"L2403A"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2026-03-20T00:00:00Z"`
+	Quantity    int        `json:"quantity" example:"24"`
+	Remaining   int        `json:"remaining" example:"18"`
+	CreatedAt   time.Time  `json:"created_at" example:"2026-03-02T10:00:00Z"`
+}
+
+// Expired reports whether the batch has expired by at
+func (b StockBatch) Expired(at time.Time) bool {
+	return b.ExpiresAt != nil && !b.ExpiresAt.After(at)
+}
+
+// CompareExpiry orders batches first-expired-first-out: by expiry date, with
+// batches that never expire last, then by ID
+func CompareExpiry(a, b StockBatch) int {
+	switch {
+	case a.ExpiresAt == nil && b.ExpiresAt != nil:
+		return 1
+	case a.ExpiresAt != nil && b.ExpiresAt == nil:
+		return -1
+	case a.ExpiresAt != nil && !a.ExpiresAt.Equal(*b.ExpiresAt):
+		return a.ExpiresAt.Compare(*b.ExpiresAt)
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// BatchTake is a quantity taken out of a batch
+type BatchTake struct {
+	BatchID  int
+	Quantity int
+}
+
+// TakeFEFO plans taking quantity out of batches, first expired first out.
+// It takes no more than the batches have remaining; the rest of the quantity
+// comes from stock that is not batched.
+func TakeFEFO(batches []StockBatch, quantity int) []BatchTake {
+	ordered := slices.Clone(batches)
+	slices.SortFunc(ordered, CompareExpiry)
+
+	var takes []BatchTake
+	for _, b := range ordered {
+		if quantity <= 0 {
+			break
+		}
+		if b.Remaining <= 0 {
+			continue
+		}
+		take := min(b.Remaining, quantity)
+		takes = append(takes, BatchTake{BatchID: b.ID, Quantity: take})
+		quantity -= take
+	}
+	return takes
+}
+
+// WriteOffInput is how much of a batch to write off as waste. Zero writes
+// off everything that remains.
+// @Description Batch write-off
+type WriteOffInput struct {
+	Quantity int `json:"quantity,omitempty" example:"6"`
+}
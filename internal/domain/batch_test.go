@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStockBatch_Expired(t *testing.T) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	expiresAt := at
+	tests := []struct {
+		name  string
+		batch StockBatch
+		at    time.Time
+		want  bool
+	}{
+		{name: "before expiry", batch: StockBatch{ExpiresAt: &expiresAt}, at: at.Add(-time.Second), want: false},
+		{name: "at expiry", batch: StockBatch{ExpiresAt: &expiresAt}, at: at, want: true},
+		{name: "after expiry", batch: StockBatch{ExpiresAt: &expiresAt}, at: at.AddDate(0, 0, 1), want: true},
+		{name: "never expires", batch: StockBatch{}, at: at.AddDate(10, 0, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.batch.Expired(tt.at); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakeFEFO(t *testing.T) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	soon, later := at.AddDate(0, 0, 3), at.AddDate(0, 0, 30)
+	batches := []StockBatch{
+		{ID: 1, ExpiresAt: nil, Remaining: 10},
+		{ID: 2, ExpiresAt: &later, Remaining: 10},
+		{ID: 3, ExpiresAt: &soon, Remaining: 4},
+		{ID: 4, ExpiresAt: &soon, Remaining: 0},
+		{ID: 5, ExpiresAt: &soon, Remaining: 2},
+	}
+
+	tests := []struct {
+		name     string
+		quantity int
+		want     []BatchTake
+	}{
+		{name: "nothing", quantity: 0, want: nil},
+		{name: "within the first batch", quantity: 3, want: []BatchTake{{BatchID: 3, Quantity: 3}}},
+		{name: "same expiry by ID", quantity: 5, want: []BatchTake{{BatchID: 3, Quantity: 4}, {BatchID: 5, Quantity: 1}}},
+		{name: "undated batches last", quantity: 20, want: []BatchTake{{BatchID: 3, Quantity: 4}, {BatchID: 5, Quantity: 2},
+			{BatchID: 2, Quantity: 10}, {BatchID: 1, Quantity: 4}}},
+		{name: "more than is batched", quantity: 40, want: []BatchTake{{BatchID: 3, Quantity: 4}, {BatchID: 5, Quantity: 2},
+			{BatchID: 2, Quantity: 10}, {BatchID: 1, Quantity: 10}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TakeFEFO(batches, tt.quantity); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TakeFEFO(%d) = %+v, want %+v", tt.quantity, got, tt.want)
+			}
+		})
+	}
+	if batches[0].ID != 1 {
+		t.Errorf("TakeFEFO reordered its input")
+	}
+}
@@ -0,0 +1,38 @@
+package domain
+
+// Product types. A standard product holds stock of its own; a bundle, such as
+// a parcel of several products, holds none and is made up of components.
+const (
+	ProductTypeStandard = "standard"
+	ProductTypeBundle   = "bundle"
+)
+
+// BundleComponent is a quantity of a standard product, in its base unit, that
+// one unit of a bundle consumes
+// @Description Bundle component
+type BundleComponent struct {
+	ProductID int `json:"product_id" example:"1"`
+	Quantity  int `json:"quantity" example:"5"`
+}
+
+// BundleComponentsInput replaces every component of a bundle
+// @Description Bundle components
+type BundleComponentsInput struct {
+	Components []BundleComponent `json:"components"`
+}
+
+// BundleStock returns how many whole bundles the given stock of each
+// component makes up. A bundle without components makes up none.
+func BundleStock(components []BundleComponent, stock map[int]int) int {
+	if len(components) == 0 {
+		return 0
+	}
+	bundles := -1
+	for _, c := range components {
+		n := max(stock[c.ProductID], 0) / c.Quantity
+		if bundles < 0 || n < bundles {
+			bundles = n
+		}
+	}
+	return bundles
+}
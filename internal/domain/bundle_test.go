@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestBundleStock(t *testing.T) {
+	components := []BundleComponent{{ProductID: 1, Quantity: 10}, {ProductID: 2, Quantity: 2}}
+	tests := []struct {
+		name       string
+		components []BundleComponent
+		stock      map[int]int
+		want       int
+	}{
+		{name: "scarcest component limits", components: components, stock: map[int]int{1: 100, 2: 7}, want: 3},
+		{name: "component out of stock", components: components, stock: map[int]int{1: 100}, want: 0},
+		{name: "negative stock counts as none", components: components, stock: map[int]int{1: -5, 2: 7}, want: 0},
+		{name: "no components", stock: map[int]int{1: 100}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BundleStock(tt.components, tt.stock); got != tt.want {
+				t.Errorf("BundleStock() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
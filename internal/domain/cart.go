@@ -0,0 +1,124 @@
+package domain
+
+import "time"
+
+// Cart statuses
+const (
+	// CartStatusOpen carts can be edited and checked out
+	CartStatusOpen = "open"
+	// CartStatusHeld carts are parked while the cashier serves someone else
+	CartStatusHeld = "held"
+	// CartStatusCheckedOut carts are completed sales and can no longer change
+	CartStatusCheckedOut = "checked_out"
+	// CartStatusVoided carts are sales undone by a supervisor; they keep their
+	// lines but no longer count as sales
+	CartStatusVoided = "voided"
+)
+
+// CartItem is a quantity of a product in one of its units in a cart. The
+// name and amounts are filled in at checkout from the prices, promotions and
+// taxes in effect then, with each promotion that took something off the line
+// in Discounts; CostPrice is the cost of one item in its unit at that time,
+// a bundle costing what its components cost. Stock is what the line took
+// from stock at checkout, so refunds and voids put back the same.
+// @Description Cart item
+type CartItem struct {
+	ProductID int               `json:"product_id" example:"1"`
+	UnitID    int               `json:"unit_id" example:"1"`
+	Quantity  int               `json:"quantity" example:"3"`
+	Name      string            `json:"name,omitempty" example:"Indomie Goreng"`
+	UnitPrice int               `json:"unit_price,omitempty" example:"3500"`
+	Discounts []AppliedDiscount `json:"discounts,omitempty"`
+	Discount  int               `json:"discount,omitempty" example:"1050"`
+	Tax       int               `json:"tax,omitempty" example:"936"`
+	Total     int               `json:"total,omitempty" example:"9450"`
+	CostPrice int               `json:"cost_price,omitempty" example:"2800"`
+	Stock     []StockTake       `json:"stock,omitempty"`
+}
+
+// StockTake is a quantity, in the product's base unit, a sold cart line took
+// from stock: of the product itself or, for a bundle, of each component
+// @Description Stock taken by a sold line
+type StockTake struct {
+	ProductID int `json:"product_id" example:"1"`
+	Quantity  int `json:"quantity" example:"3"`
+}
+
+// Cart is a server-side basket at an outlet. Prices and the outlet's stock
+// are checked at checkout, when the cart becomes the record of the sale.
+// Carts created with ReserveStock, such as online orders, also hold their
+// items' stock from the moment they are added until the reservation expires.
+// CartDiscounts are the promotions taken off the whole cart at checkout, on
+// top of those on its items, and Taxes the tax charged under each rate. Total is the amount due after any loyalty
+// PointsDiscount. A voided sale records who voided it, when and why.
+// @Description Cart
+type Cart struct {
+	ID             int               `json:"id" example:"1"`
+	Cashier        string            `json:"cashier" example:"budi"`
+	OutletID       int               `json:"outlet_id" example:"1"`
+	Status         string            `json:"status" example:"open" enums:"open,held,checked_out,voided"`
+	ReserveStock   bool              `json:"reserve_stock" example:"false"`
+	Items          []CartItem        `json:"items"`
+	ShiftID        *int              `json:"shift_id,omitempty" example:"1"`
+	CustomerID     *int              `json:"customer_id,omitempty" example:"1"`
+	Subtotal       int               `json:"subtotal" example:"10500"`
+	CartDiscounts  []AppliedDiscount `json:"cart_discounts,omitempty"`
+	Discount       int               `json:"discount" example:"1050"`
+	Tax            int               `json:"tax" example:"936"`
+	Taxes          []TaxSummary      `json:"taxes,omitempty"`
+	Total          int               `json:"total" example:"9450"`
+	Payments       []Payment         `json:"payments"`
+	Paid           int               `json:"paid" example:"10000"`
+	Change         int               `json:"change" example:"550"`
+	PointsRedeemed int               `json:"points_redeemed" example:"0"`
+	PointsDiscount int               `json:"points_discount" example:"0"`
+	PointsEarned   int               `json:"points_earned" example:"0"`
+	CreatedAt      time.Time         `json:"created_at" example:"2026-03-01T08:00:00Z"`
+	UpdatedAt      time.Time         `json:"updated_at" example:"2026-03-01T08:05:00Z"`
+	CheckedOutAt   *time.Time        `json:"checked_out_at,omitempty" example:"2026-03-01T08:05:00Z"`
+	VoidedAt       *time.Time        `json:"voided_at,omitempty" example:"2026-03-01T08:10:00Z"`
+	VoidedBy       string            `json:"voided_by,omitempty" example:"siti"`
+	VoidReason     string            `json:"void_reason,omitempty" example:"Salah input"`
+}
+
+// CartInput is used to create a cart, optionally with items and a customer.
+// Carts without an outlet sell from the default outlet.
+// @Description Cart input
+type CartInput struct {
+	Items        []QuoteItem `json:"items"`
+	ReserveStock bool        `json:"reserve_stock" example:"false"`
+	CustomerID   *int        `json:"customer_id,omitempty" example:"1"`
+	OutletID     int         `json:"outlet_id,omitempty" example:"1"`
+}
+
+// CheckoutInput holds the payments tendered at checkout. CustomerID attaches
+// a customer if the cart has none yet, and RedeemPoints spends that
+// customer's points as a discount.
+// @Description Checkout payments
+type CheckoutInput struct {
+	Payments     []Payment `json:"payments"`
+	CustomerID   *int      `json:"customer_id,omitempty" example:"1"`
+	RedeemPoints int       `json:"redeem_points" example:"0"`
+}
+
+// CheckoutResult is the completed sale and the quote it was priced with
+// @Description Completed sale
+type CheckoutResult struct {
+	Cart  Cart  `json:"cart"`
+	Quote Quote `json:"quote"`
+}
+
+// VoidInput gives the reason a sale is voided
+// @Description Void input
+type VoidInput struct {
+	Reason string `json:"reason" example:"Salah input"`
+}
+
+// Reservation is stock held at an outlet for a cart line until ExpiresAt
+type Reservation struct {
+	CartID    int
+	OutletID  int
+	ProductID int
+	Quantity  int
+	ExpiresAt time.Time
+}
@@ -1,11 +1,16 @@
 package domain
 
-// Category represents a product category
+// Category represents a product category. Categories form a tree: a category
+// with a ParentID is a subcategory of it. Children is only filled in when
+// listing the tree.
 // @Description Category information
 type Category struct {
-	ID          int    `json:"id" example:"1"`
-	Name        string `json:"name" example:"Makanan Ringan"`
-	Description string `json:"description,omitempty" example:"Kategori untuk makanan ringan seperti keripik, biskuit, dll."`
+	ID          int        `json:"id" example:"1"`
+	Name        string     `json:"name" example:"Makanan Ringan"`
+	Description string     `json:"description,omitempty" example:"Kategori untuk makanan ringan seperti keripik, biskuit, dll."`
+	TaxRateID   *int       `json:"tax_rate_id,omitempty" example:"1"`
+	ParentID    *int       `json:"parent_id,omitempty" example:"1"`
+	Children    []Category `json:"children,omitempty"`
 }
 
 // CategoryInput is used for create/update requests
@@ -13,4 +18,43 @@ type Category struct {
 type CategoryInput struct {
 	Name        string `json:"name" example:"Makanan Ringan"`
 	Description string `json:"description,omitempty" example:"Kategori untuk makanan ringan seperti keripik, biskuit, dll."`
+	TaxRateID   *int   `json:"tax_rate_id,omitempty" example:"1"`
+	ParentID    *int   `json:"parent_id,omitempty" example:"1"`
+}
+
+// CategoryTree nests each category under its parent in Children and returns
+// the top-level categories, keeping the original order at every level. A
+// category whose parent is not in the list stays at the top level.
+func CategoryTree(categories []Category) []Category {
+	known := make(map[int]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+
+	children := make(map[int][]Category)
+	var roots []Category
+	for _, c := range categories {
+		if c.ParentID != nil && known[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+			continue
+		}
+		roots = append(roots, c)
+	}
+
+	var nest func(level []Category) []Category
+	nest = func(level []Category) []Category {
+		if len(level) == 0 {
+			return nil
+		}
+		nested := make([]Category, len(level))
+		for i, c := range level {
+			c.Children = nest(children[c.ID])
+			nested[i] = c
+		}
+		return nested
+	}
+	if len(roots) == 0 {
+		return []Category{}
+	}
+	return nest(roots)
 }
@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestCategoryTree(t *testing.T) {
+	one, two, missing := 1, 2, 99
+	tree := CategoryTree([]Category{
+		{ID: 1, Name: "Makanan"},
+		{ID: 2, Name: "Mi Instan", ParentID: &one},
+		{ID: 3, Name: "Mi Cup", ParentID: &two},
+		{ID: 4, Name: "Roti", ParentID: &one},
+		{ID: 5, Name: "Minuman"},
+		{ID: 6, Name: "Yatim", ParentID: &missing},
+	})
+
+	if len(tree) != 3 || tree[0].ID != 1 || tree[1].ID != 5 || tree[2].ID != 6 {
+		t.Fatalf("roots = %+v, want 1, 5 and the orphan 6", tree)
+	}
+	food := tree[0].Children
+	if len(food) != 2 || food[0].ID != 2 || food[1].ID != 4 {
+		t.Fatalf("children of 1 = %+v, want 2 and 4", food)
+	}
+	if len(food[0].Children) != 1 || food[0].Children[0].ID != 3 || food[1].Children != nil {
+		t.Errorf("grandchildren = %+v, %+v, want only 3 under 2", food[0].Children, food[1].Children)
+	}
+	if tree[1].Children != nil {
+		t.Errorf("children of 5 = %+v, want none", tree[1].Children)
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+// Customer is a shopper who can be attached to carts to collect loyalty
+// points. Points only change through checkout.
+// @Description Customer
+type Customer struct {
+	ID         int    `json:"id" example:"1"`
+	Name       string `json:"name" example:"Siti Aminah"`
+	Phone      string `json:"phone" example:"081234567890"`
+	Email      string `json:"email" example:"siti@example.com"`
+	MemberCode string `json:"member_code" example:"MBR-0001"`
+	Points     int    `json:"points" example:"120"`
+}
+
+// CustomerInput is used for create/update requests
+// @Description Customer input for create/update
+type CustomerInput struct {
+	Name       string `json:"name" example:"Siti Aminah"`
+	Phone      string `json:"phone" example:"081234567890"`
+	Email      string `json:"email" example:"siti@example.com"`
+	MemberCode string `json:"member_code" example:"MBR-0001"`
+}
+
+// LoyaltyProgram sets how customers earn and redeem points. A point is
+// earned for every SpendPerPoint Rupiah paid and is worth PointValue Rupiah
+// off a later purchase.
+type LoyaltyProgram struct {
+	SpendPerPoint int
+	PointValue    int
+}
+
+// PointsEarned returns the whole points earned by paying amount
+func (l LoyaltyProgram) PointsEarned(amount int) int {
+	if l.SpendPerPoint <= 0 || amount <= 0 {
+		return 0
+	}
+	return amount / l.SpendPerPoint
+}
+
+// RedemptionValue returns the discount in Rupiah for redeeming points
+func (l LoyaltyProgram) RedemptionValue(points int) int {
+	return points * l.PointValue
+}
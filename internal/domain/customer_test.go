@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestLoyaltyProgram_PointsEarned(t *testing.T) {
+	tests := []struct {
+		name    string
+		program LoyaltyProgram
+		amount  int
+		want    int
+	}{
+		{name: "whole points only", program: LoyaltyProgram{SpendPerPoint: 10000}, amount: 29999, want: 2},
+		{name: "below one point", program: LoyaltyProgram{SpendPerPoint: 10000}, amount: 9999, want: 0},
+		{name: "nothing paid", program: LoyaltyProgram{SpendPerPoint: 10000}, amount: 0, want: 0},
+		{name: "earning disabled", program: LoyaltyProgram{}, amount: 50000, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.program.PointsEarned(tt.amount); got != tt.want {
+				t.Errorf("PointsEarned(%d) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is the first response to a request sent with an
+// Idempotency-Key, kept so retries of the same request can be replayed.
+// StatusCode is zero while the first request is still being handled.
+type IdempotencyRecord struct {
+	Key         string
+	Route       string
+	RequestHash string
+	StatusCode  int
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the first request has finished and its response
+// can be replayed
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// ThumbnailSize is the longest side, in pixels, of the thumbnail generated
+// for every product image
+const ThumbnailSize = 300
+
+// ProductImage is a photo of a product. Key and ThumbnailKey locate the
+// original and its thumbnail in storage; URL and ThumbnailURL are where
+// clients fetch them from.
+// @Description Product image
+type ProductImage struct {
+	ID           int       `json:"id" example:"1"`
+	ProductID    int       `json:"product_id" example:"1"`
+	URL          string    `json:"url" example:"/uploads/products/1/3f2a9c1e7b5d4a60.jpg"`
+	ThumbnailURL string    `json:"thumbnail_url" example:"/uploads/products/1/3f2a9c1e7b5d4a60_thumb.jpg"`
+	ContentType  string    `json:"content_type" example:"image/jpeg"`
+	Size         int       `json:"size" example:"245760"`
+	Width        int       `json:"width" example:"1200"`
+	Height       int       `json:"height" example:"900"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at" example:"2026-01-15T08:30:00Z"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+// DefaultOutletID is the outlet seeded with the schema. Requests that do not
+// select an outlet read and change its stock, and sales and orders without
+// one belong to it.
+const DefaultOutletID = 1
+
+// Outlet is a store location with its own stock of the shared catalogue
+// @Description Outlet
+type Outlet struct {
+	ID      int    `json:"id" example:"1"`
+	Code    string `json:"code" example:"MAIN"`
+	Name    string `json:"name" example:"Toko Pusat"`
+	Address string `json:"address" example:"Jl. Merdeka No. 1, Bandung"`
+}
+
+// OutletInput is used for create/update requests
+// @Description Outlet input for create/update
+type OutletInput struct {
+	Code    string `json:"code" example:"MAIN"`
+	Name    string `json:"name" example:"Toko Pusat"`
+	Address string `json:"address" example:"Jl. Merdeka No. 1, Bandung"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+// Payment methods
+const (
+	PaymentCash      = "cash"
+	PaymentQRIS      = "qris"
+	PaymentDebitCard = "debit_card"
+	PaymentEWallet   = "e_wallet"
+)
+
+// Payment is one tender towards a sale. A sale can be split across several
+// payments, for example part cash and part QRIS.
+// @Description Payment tender
+type Payment struct {
+	Method    string `json:"method" example:"cash" enums:"cash,qris,debit_card,e_wallet"`
+	Amount    int    `json:"amount" example:"20000"`
+	Reference string `json:"reference,omitempty" example:"QR-20260301-0001"`
+}
+
+// Settlement is the result of applying payments to a total. Change is only
+// ever given back in cash.
+// @Description Payment settlement
+type Settlement struct {
+	Payments []Payment `json:"payments"`
+	Total    int       `json:"total" example:"18500"`
+	Paid     int       `json:"paid" example:"20000"`
+	Change   int       `json:"change" example:"1500"`
+}
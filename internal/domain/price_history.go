@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// PriceChange records a single change to a product's selling price
+// @Description Product price change
+type PriceChange struct {
+	ID        int       `json:"id" example:"1"`
+	ProductID int       `json:"product_id" example:"1"`
+	OldPrice  int       `json:"old_price" example:"3500"`
+	NewPrice  int       `json:"new_price" example:"4000"`
+	ChangedBy string    `json:"changed_by,omitempty" example:"budi"`
+	ChangedAt time.Time `json:"changed_at" example:"2026-01-15T08:30:00Z"`
+}
@@ -1,21 +1,137 @@
 package domain
 
-// Product represents a product in the store
+import "math"
+
+// Product represents a product in the store. The catalogue is shared by all
+// outlets; Stock, Reserved and Available are the figures at one outlet.
+// A variant, such as one size or flavour of a product, is a product of its
+// own with ParentID set and Options naming what sets it apart; it has its
+// own barcode, price and stock and is sold by its own ID. Price, CostPrice
+// and Stock are per UnitID, the base unit; other units convert into it
+// through ProductUnit. A bundle holds no stock of its own: its Stock and
+// Available are the whole bundles its components make up. Images are the
+// product's photos in upload order.
 // @Description Product information
 type Product struct {
-	ID         int       `json:"id" example:"1"`
-	Name       string    `json:"name" example:"Indomie Goreng"`
-	Price      int       `json:"price" example:"3500"`
-	Stock      int       `json:"stock" example:"100"`
-	CategoryID int       `json:"category_id" example:"1"`
-	Category   *Category `json:"category,omitempty"`
+	ID            int               `json:"id" example:"1"`
+	Name          string            `json:"name" example:"Indomie Goreng"`
+	Type          string            `json:"type" example:"standard"`
+	Price         int               `json:"price" example:"3500"`
+	CostPrice     int               `json:"cost_price" example:"2800"`
+	GrossProfit   int               `json:"gross_profit" example:"700"`
+	MarginPercent float64           `json:"margin_percent" example:"20"`
+	Stock         int               `json:"stock" example:"100"`
+	Reserved      int               `json:"reserved" example:"5"`
+	Available     int               `json:"available" example:"95"`
+	CategoryID    int               `json:"category_id" example:"1"`
+	Category      *Category         `json:"category,omitempty"`
+	TaxRateID     *int              `json:"tax_rate_id,omitempty" example:"1"`
+	UnitID        int               `json:"unit_id" example:"1"`
+	ParentID      *int              `json:"parent_id,omitempty" example:"1"`
+	Barcode       string            `json:"barcode" example:"089686010947"`
+	Options       map[string]string `json:"options,omitempty"`
+	Variants      []Product         `json:"variants,omitempty"`
+	Images        []ProductImage    `json:"images,omitempty"`
 }
 
-// ProductInput is used for create/update requests
+// ProductInput is used for create/update requests. Stock is set at the
+// outlet the request selects and ignored for bundles. Products without a
+// type are standard products, and products without a unit are counted in
+// pieces.
 // @Description Product input for create/update
 type ProductInput struct {
-	Name       string `json:"name" example:"Indomie Goreng"`
-	Price      int    `json:"price" example:"3500"`
-	Stock      int    `json:"stock" example:"100"`
-	CategoryID int    `json:"category_id" example:"1"`
+	Name       string            `json:"name" example:"Indomie Goreng"`
+	Type       string            `json:"type,omitempty" example:"standard"`
+	Price      int               `json:"price" example:"3500"`
+	CostPrice  int               `json:"cost_price" example:"2800"`
+	Stock      int               `json:"stock" example:"100"`
+	CategoryID int               `json:"category_id" example:"1"`
+	TaxRateID  *int              `json:"tax_rate_id,omitempty" example:"1"`
+	UnitID     int               `json:"unit_id,omitempty" example:"1"`
+	ParentID   *int              `json:"parent_id,omitempty" example:"1"`
+	Barcode    string            `json:"barcode" example:"089686010947"`
+	Options    map[string]string `json:"options,omitempty"`
+}
+
+// CalculateProfit fills GrossProfit and MarginPercent from Price and
+// CostPrice. The margin is a percentage of the selling price rounded to two
+// decimals, and zero for products without a price.
+func (p *Product) CalculateProfit() {
+	p.GrossProfit = p.Price - p.CostPrice
+	p.MarginPercent = 0
+	if p.Price != 0 {
+		p.MarginPercent = math.Round(float64(p.GrossProfit)*10000/float64(p.Price)) / 100
+	}
+}
+
+// EffectiveTaxRateID returns the product's own tax rate, falling back to the
+// rate of its category or else of the nearest ancestor category that has one,
+// looked up by ID in categories. The walk stops at a category it has already
+// seen. It is nil when the product is not taxed.
+func (p *Product) EffectiveTaxRateID(categories map[int]Category) *int {
+	if p.TaxRateID != nil {
+		return p.TaxRateID
+	}
+	seen := make(map[int]bool)
+	for category := p.Category; category != nil && !seen[category.ID]; {
+		if category.TaxRateID != nil {
+			return category.TaxRateID
+		}
+		seen[category.ID] = true
+		if category.ParentID == nil {
+			break
+		}
+		parent, ok := categories[*category.ParentID]
+		if !ok {
+			break
+		}
+		category = &parent
+	}
+	return nil
+}
+
+// SetReserved records how much stock open carts are holding and derives
+// Available from it. Available never goes below zero, even if stock was
+// lowered after the reservations were made.
+func (p *Product) SetReserved(reserved int) {
+	p.Reserved = reserved
+	p.Available = max(p.Stock-reserved, 0)
+}
+
+// ReceivedCost returns the product's cost price after quantity more units
+// arrive at unitCost: the average of the stock on hand and the delivery,
+// weighted by quantity and rounded. Stock at or below zero has no cost to
+// average with, so the delivery's unit cost is used as is.
+func (p *Product) ReceivedCost(quantity, unitCost int) int {
+	if p.Stock <= 0 {
+		return unitCost
+	}
+	total := float64(p.Stock*p.CostPrice + quantity*unitCost)
+	return int(math.Round(total / float64(p.Stock+quantity)))
+}
+
+// GroupVariants nests each variant under its parent in Variants and returns
+// the top-level products in their original order. A variant whose parent is
+// not in the list stays at the top level.
+func GroupVariants(products []Product) []Product {
+	parents := make(map[int]bool)
+	for _, p := range products {
+		if p.ParentID == nil {
+			parents[p.ID] = true
+		}
+	}
+
+	variants := make(map[int][]Product)
+	grouped := make([]Product, 0, len(products))
+	for _, p := range products {
+		if p.ParentID != nil && parents[*p.ParentID] {
+			variants[*p.ParentID] = append(variants[*p.ParentID], p)
+			continue
+		}
+		grouped = append(grouped, p)
+	}
+	for i := range grouped {
+		grouped[i].Variants = variants[grouped[i].ID]
+	}
+	return grouped
 }
@@ -0,0 +1,119 @@
+package domain
+
+import "testing"
+
+func TestProduct_CalculateProfit(t *testing.T) {
+	tests := []struct {
+		name       string
+		price      int
+		costPrice  int
+		wantProfit int
+		wantMargin float64
+	}{
+		{name: "typical margin", price: 3500, costPrice: 2800, wantProfit: 700, wantMargin: 20},
+		{name: "rounded to two decimals", price: 3000, costPrice: 2000, wantProfit: 1000, wantMargin: 33.33},
+		{name: "no cost recorded", price: 5000, costPrice: 0, wantProfit: 5000, wantMargin: 100},
+		{name: "sold at a loss", price: 2000, costPrice: 2500, wantProfit: -500, wantMargin: -25},
+		{name: "free item", price: 0, costPrice: 1000, wantProfit: -1000, wantMargin: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Product{Price: tt.price, CostPrice: tt.costPrice}
+			p.CalculateProfit()
+			if p.GrossProfit != tt.wantProfit || p.MarginPercent != tt.wantMargin {
+				t.Errorf("profit, margin = %d, %v, want %d, %v", p.GrossProfit, p.MarginPercent, tt.wantProfit, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestProduct_ReceivedCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		stock     int
+		costPrice int
+		quantity  int
+		unitCost  int
+		want      int
+	}{
+		{name: "same cost", stock: 100, costPrice: 2800, quantity: 48, unitCost: 2800, want: 2800},
+		{name: "weighted by quantity", stock: 100, costPrice: 2800, quantity: 100, unitCost: 3000, want: 2900},
+		{name: "rounded", stock: 2, costPrice: 1000, quantity: 1, unitCost: 1001, want: 1000},
+		{name: "empty shelf takes delivery cost", stock: 0, costPrice: 2800, quantity: 24, unitCost: 3100, want: 3100},
+		{name: "oversold shelf takes delivery cost", stock: -3, costPrice: 2800, quantity: 24, unitCost: 3100, want: 3100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Product{Stock: tt.stock, CostPrice: tt.costPrice}
+			if got := p.ReceivedCost(tt.quantity, tt.unitCost); got != tt.want {
+				t.Errorf("ReceivedCost(%d, %d) = %d, want %d", tt.quantity, tt.unitCost, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProduct_EffectiveTaxRateID(t *testing.T) {
+	ppn, reduced := 1, 2
+	one, two, five, six := 1, 2, 5, 6
+	categories := map[int]Category{
+		1: {ID: 1, Name: "Makanan", TaxRateID: &ppn},
+		2: {ID: 2, Name: "Makanan Ringan", ParentID: &one},
+		3: {ID: 3, Name: "Keripik", ParentID: &two},
+		4: {ID: 4, Name: "Minuman"},
+		5: {ID: 5, Name: "Loop A", ParentID: &six},
+		6: {ID: 6, Name: "Loop B", ParentID: &five},
+	}
+	tests := []struct {
+		name       string
+		taxRateID  *int
+		categoryID int
+		want       *int
+	}{
+		{name: "own rate wins", taxRateID: &reduced, categoryID: 3, want: &reduced},
+		{name: "category rate", categoryID: 1, want: &ppn},
+		{name: "grandparent rate", categoryID: 3, want: &ppn},
+		{name: "untaxed", categoryID: 4},
+		{name: "cycle", categoryID: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category := categories[tt.categoryID]
+			p := Product{TaxRateID: tt.taxRateID, CategoryID: tt.categoryID, Category: &category}
+			got := p.EffectiveTaxRateID(categories)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("EffectiveTaxRateID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupVariants(t *testing.T) {
+	parentID, missingID := 1, 9
+	products := []Product{
+		{ID: 1, Name: "Indomie"},
+		{ID: 2, Name: "Indomie Goreng", ParentID: &parentID},
+		{ID: 3, Name: "Aqua"},
+		{ID: 4, Name: "Indomie Soto", ParentID: &parentID},
+		{ID: 5, Name: "Orphan", ParentID: &missingID},
+	}
+
+	grouped := GroupVariants(products)
+
+	var ids []int
+	for _, p := range grouped {
+		ids = append(ids, p.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 3 || ids[2] != 5 {
+		t.Fatalf("top-level IDs = %v, want [1 3 5]", ids)
+	}
+	variants := grouped[0].Variants
+	if len(variants) != 2 || variants[0].ID != 2 || variants[1].ID != 4 {
+		t.Errorf("variants of 1 = %+v, want IDs 2 and 4", variants)
+	}
+	if grouped[1].Variants != nil || grouped[2].Variants != nil {
+		t.Errorf("products without variants got some: %+v, %+v", grouped[1].Variants, grouped[2].Variants)
+	}
+}
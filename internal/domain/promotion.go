@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// Promotion types
+const (
+	// PromotionPercentage takes Value percent off the matching amount
+	PromotionPercentage = "percentage"
+	// PromotionFixedAmount takes Value Rupiah off each matching unit, or off
+	// the whole cart for cart-scoped promotions
+	PromotionFixedAmount = "fixed_amount"
+	// PromotionBuyXGetY makes FreeQuantity units free for every
+	// BuyQuantity + FreeQuantity units on a line
+	PromotionBuyXGetY = "buy_x_get_y"
+)
+
+// Promotion scopes
+const (
+	PromotionScopeProduct  = "product"
+	PromotionScopeCategory = "category"
+	PromotionScopeCart     = "cart"
+)
+
+// Promotion is a discount rule evaluated when a basket is priced.
+// Stackable promotions combine with each other; a non-stackable promotion
+// never combines with another promotion at the same level (line or cart).
+// @Description Promotion rule
+type Promotion struct {
+	ID           int        `json:"id" example:"1"`
+	Name         string     `json:"name" example:"Diskon 10% Makanan Ringan"`
+	Type         string     `json:"type" example:"percentage" enums:"percentage,fixed_amount,buy_x_get_y"`
+	Scope        string     `json:"scope" example:"category" enums:"product,category,cart"`
+	ProductID    *int       `json:"product_id,omitempty" example:"1"`
+	CategoryID   *int       `json:"category_id,omitempty" example:"1"`
+	Value        int        `json:"value" example:"10"`
+	BuyQuantity  int        `json:"buy_quantity,omitempty" example:"2"`
+	FreeQuantity int        `json:"free_quantity,omitempty" example:"1"`
+	MinSubtotal  int        `json:"min_subtotal,omitempty" example:"50000"`
+	Stackable    bool       `json:"stackable" example:"false"`
+	Active       bool       `json:"active" example:"true"`
+	StartsAt     *time.Time `json:"starts_at,omitempty" example:"2026-03-01T00:00:00Z"`
+	EndsAt       *time.Time `json:"ends_at,omitempty" example:"2026-04-01T00:00:00Z"`
+}
+
+// PromotionInput is used for create/update requests
+// @Description Promotion input for create/update
+type PromotionInput struct {
+	Name         string     `json:"name" example:"Diskon 10% Makanan Ringan"`
+	Type         string     `json:"type" example:"percentage" enums:"percentage,fixed_amount,buy_x_get_y"`
+	Scope        string     `json:"scope" example:"category" enums:"product,category,cart"`
+	ProductID    *int       `json:"product_id,omitempty" example:"1"`
+	CategoryID   *int       `json:"category_id,omitempty" example:"1"`
+	Value        int        `json:"value" example:"10"`
+	BuyQuantity  int        `json:"buy_quantity,omitempty" example:"2"`
+	FreeQuantity int        `json:"free_quantity,omitempty" example:"1"`
+	MinSubtotal  int        `json:"min_subtotal,omitempty" example:"50000"`
+	Stackable    bool       `json:"stackable" example:"false"`
+	Active       bool       `json:"active" example:"true"`
+	StartsAt     *time.Time `json:"starts_at,omitempty" example:"2026-03-01T00:00:00Z"`
+	EndsAt       *time.Time `json:"ends_at,omitempty" example:"2026-04-01T00:00:00Z"`
+}
+
+// IsActiveAt reports whether the promotion is enabled and t falls inside
+// its validity window. StartsAt is inclusive and EndsAt exclusive.
+func (p Promotion) IsActiveAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Purchase order statuses
+const (
+	// PurchaseOrderStatusOrdered orders have been sent but nothing has arrived
+	PurchaseOrderStatusOrdered = "ordered"
+	// PurchaseOrderStatusPartiallyReceived orders have had some, but not all,
+	// of their lines delivered
+	PurchaseOrderStatusPartiallyReceived = "partially_received"
+	// PurchaseOrderStatusReceived orders have been delivered in full
+	PurchaseOrderStatusReceived = "received"
+	// PurchaseOrderStatusCancelled orders will not receive anything more
+	PurchaseOrderStatusCancelled = "cancelled"
+)
+
+// PurchaseOrderLine is a product ordered from a supplier at a unit cost, and
+// how much of it has been received so far. Quantities and the cost are in
+// UnitID, which holds Factor of the product's base unit, as it was when the
+// order was placed.
+// @Description Purchase order line
+type PurchaseOrderLine struct {
+	ProductID        int `json:"product_id" example:"1"`
+	UnitID           int `json:"unit_id" example:"2"`
+	Factor           int `json:"factor" example:"40"`
+	Quantity         int `json:"quantity" example:"2"`
+	UnitCost         int `json:"unit_cost" example:"112000"`
+	ReceivedQuantity int `json:"received_quantity" example:"1"`
+}
+
+// Outstanding returns how much of the line is still to be received
+func (l PurchaseOrderLine) Outstanding() int {
+	return l.Quantity - l.ReceivedQuantity
+}
+
+// BaseQuantity converts a quantity of the line's unit into the product's base
+// unit
+func (l PurchaseOrderLine) BaseQuantity(quantity int) int {
+	return quantity * max(l.Factor, 1)
+}
+
+// BaseCost returns the line's unit cost per base unit, rounded
+func (l PurchaseOrderLine) BaseCost() int {
+	if l.Factor <= 1 {
+		return l.UnitCost
+	}
+	return int(math.Round(float64(l.UnitCost) / float64(l.Factor)))
+}
+
+// PurchaseOrder is an order for stock from a supplier, delivered to an outlet
+// @Description Purchase order
+type PurchaseOrder struct {
+	ID         int                 `json:"id" example:"1"`
+	SupplierID int                 `json:"supplier_id" example:"1"`
+	OutletID   int                 `json:"outlet_id" example:"1"`
+	Status     string              `json:"status" example:"ordered" enums:"ordered,partially_received,received,cancelled"`
+	Notes      string              `json:"notes" example:"Deliver before Lebaran"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+	CreatedAt  time.Time           `json:"created_at" example:"2026-03-01T08:00:00Z"`
+	UpdatedAt  time.Time           `json:"updated_at" example:"2026-03-02T10:00:00Z"`
+}
+
+// UpdateStatus sets the status of an order that is not cancelled from how
+// much of its lines has been received
+func (po *PurchaseOrder) UpdateStatus() {
+	if po.Status == PurchaseOrderStatusCancelled {
+		return
+	}
+	received, complete := false, true
+	for _, line := range po.Lines {
+		if line.ReceivedQuantity > 0 {
+			received = true
+		}
+		if line.Outstanding() > 0 {
+			complete = false
+		}
+	}
+	switch {
+	case complete:
+		po.Status = PurchaseOrderStatusReceived
+	case received:
+		po.Status = PurchaseOrderStatusPartiallyReceived
+	default:
+		po.Status = PurchaseOrderStatusOrdered
+	}
+}
+
+// PurchaseOrderLineInput is a product, quantity and unit cost to order, in
+// UnitID or the product's base unit when it is zero
+// @Description Purchase order line input
+type PurchaseOrderLineInput struct {
+	ProductID int `json:"product_id" example:"1"`
+	UnitID    int `json:"unit_id,omitempty" example:"2"`
+	Quantity  int `json:"quantity" example:"2"`
+	UnitCost  int `json:"unit_cost" example:"112000"`
+}
+
+// PurchaseOrderInput is used to create a purchase order. Orders without an
+// outlet are delivered to the default outlet.
+// @Description Purchase order input
+type PurchaseOrderInput struct {
+	SupplierID int                      `json:"supplier_id" example:"1"`
+	OutletID   int                      `json:"outlet_id,omitempty" example:"1"`
+	Notes      string                   `json:"notes" example:"Deliver before Lebaran"`
+	Lines      []PurchaseOrderLineInput `json:"lines"`
+}
+
+// ReceiveLine is a quantity of a product delivered against a purchase order,
+// in the unit the product was ordered in. A delivery with a lot number or
+// expiry date is kept as a StockBatch.
+// @Description Received quantity of a product
+type ReceiveLine struct {
+	ProductID int        `json:"product_id" example:"1"`
+	Quantity  int        `json:"quantity" example:"24"`
+	LotNumber string     `json:"lot_number,omitempty" example:"L2403A"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-03-20T00:00:00Z"`
+}
+
+// ReceiveInput lists what arrived in one delivery
+// @Description Delivery against a purchase order
+type ReceiveInput struct {
+	Lines []ReceiveLine `json:"lines"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+// QuoteItem is one product and quantity in a basket to be priced. The
+// quantity is in UnitID, or in the product's base unit when it is zero.
+// @Description Basket item
+type QuoteItem struct {
+	ProductID int `json:"product_id" example:"1"`
+	UnitID    int `json:"unit_id,omitempty" example:"1"`
+	Quantity  int `json:"quantity" example:"3"`
+}
+
+// QuoteRequest is a basket to be priced without selling it
+// @Description Basket to price
+type QuoteRequest struct {
+	Items []QuoteItem `json:"items"`
+}
+
+// AppliedDiscount is the amount one promotion took off a line or the cart
+// @Description Applied promotion
+type AppliedDiscount struct {
+	PromotionID int    `json:"promotion_id" example:"1"`
+	Name        string `json:"name" example:"Diskon 10% Makanan Ringan"`
+	Amount      int    `json:"amount" example:"1050"`
+}
+
+// QuoteLine is a priced basket line. Quantity and UnitPrice are in UnitID;
+// BaseQuantity is what the line takes from stock in the product's base unit.
+// @Description Priced basket line
+type QuoteLine struct {
+	ProductID    int               `json:"product_id" example:"1"`
+	Name         string            `json:"name" example:"Indomie Goreng"`
+	CategoryID   int               `json:"category_id" example:"1"`
+	UnitID       int               `json:"unit_id" example:"1"`
+	Quantity     int               `json:"quantity" example:"3"`
+	BaseQuantity int               `json:"base_quantity" example:"3"`
+	UnitPrice    int               `json:"unit_price" example:"3500"`
+	Subtotal     int               `json:"subtotal" example:"10500"`
+	Discounts    []AppliedDiscount `json:"discounts"`
+	Discount     int               `json:"discount" example:"1050"`
+	Total        int               `json:"total" example:"9450"`
+	TaxRateID    *int              `json:"tax_rate_id,omitempty" example:"1"`
+	Tax          int               `json:"tax" example:"936"`
+}
+
+// Quote is a priced basket with every promotion and tax that applied.
+// Total is what the customer pays: the discounted subtotal plus any
+// tax-exclusive amounts. Tax-inclusive amounts are already in the prices.
+// @Description Priced basket
+type Quote struct {
+	Lines         []QuoteLine       `json:"lines"`
+	Subtotal      int               `json:"subtotal" example:"10500"`
+	CartDiscounts []AppliedDiscount `json:"cart_discounts"`
+	Discount      int               `json:"discount" example:"1050"`
+	Taxes         []TaxSummary      `json:"taxes"`
+	Tax           int               `json:"tax" example:"936"`
+	Total         int               `json:"total" example:"9450"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// RefundLine is a quantity of a sold cart line given back. Amount is what is
+// paid back for it, tax included, and Tax the part of it that was tax;
+// CostPrice is the cost of one item snapshotted on the sold line.
+// @Description Refund line
+type RefundLine struct {
+	ProductID int `json:"product_id" example:"1"`
+	UnitID    int `json:"unit_id" example:"1"`
+	Quantity  int `json:"quantity" example:"1"`
+	Amount    int `json:"amount" example:"3150"`
+	Tax       int `json:"tax" example:"312"`
+	CostPrice int `json:"cost_price" example:"2800"`
+}
+
+// Refund gives back some or all of what a checked out cart sold. Restock
+// records whether the items went back into the outlet's stock. Amount and Tax
+// total the lines. Method is how the amount was paid back, from the drawer of
+// the refunding cashier's open shift, ShiftID, when it was cash.
+// @Description Refund
+type Refund struct {
+	ID         int          `json:"id" example:"1"`
+	CartID     int          `json:"cart_id" example:"1"`
+	OutletID   int          `json:"outlet_id" example:"1"`
+	ShiftID    *int         `json:"shift_id,omitempty" example:"1"`
+	Lines      []RefundLine `json:"lines"`
+	Restock    bool         `json:"restock" example:"true"`
+	Reason     string       `json:"reason" example:"Kemasan rusak"`
+	Method     string       `json:"method" example:"cash" enums:"cash,qris,debit_card,e_wallet"`
+	Amount     int          `json:"amount" example:"3150"`
+	Tax        int          `json:"tax" example:"312"`
+	RefundedBy string       `json:"refunded_by" example:"budi"`
+	CreatedAt  time.Time    `json:"created_at" example:"2026-03-01T09:00:00Z"`
+}
+
+// RefundLineInput is a quantity of a sold product to give back. UnitID picks
+// the line when the product was sold in several units.
+// @Description Refund line input
+type RefundLineInput struct {
+	ProductID int `json:"product_id" example:"1"`
+	UnitID    int `json:"unit_id,omitempty" example:"1"`
+	Quantity  int `json:"quantity" example:"1"`
+}
+
+// RefundInput is used to refund a sale. Without lines everything not yet
+// refunded is given back. Without a method the refund is paid back the way
+// the sale was paid: in cash when it took any cash, otherwise in its first
+// tender.
+// @Description Refund input
+type RefundInput struct {
+	Lines   []RefundLineInput `json:"lines"`
+	Restock bool              `json:"restock" example:"true"`
+	Reason  string            `json:"reason" example:"Kemasan rusak"`
+	Method  string            `json:"method,omitempty" example:"cash" enums:"cash,qris,debit_card,e_wallet"`
+}
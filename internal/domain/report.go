@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// SalesFilter narrows a sales report; zero values are ignored
+type SalesFilter struct {
+	OutletID int
+	From     time.Time
+	To       time.Time
+}
+
+// SalesReport totals the sales checked out in a period, less the refunds
+// made in it. Revenue is what the sales took net of tax, less what was paid
+// back net of tax, and Cost what their items cost when sold, less the cost of
+// refunded items put back in stock. GrossProfit is the difference and
+// MarginPercent that as a percentage of revenue, rounded to two decimals.
+// @Description Sales and profit report
+type SalesReport struct {
+	Sales         int     `json:"sales" example:"2"`
+	Refunds       int     `json:"refunds" example:"0"`
+	Revenue       int     `json:"revenue" example:"17028"`
+	Tax           int     `json:"tax" example:"1872"`
+	Cost          int     `json:"cost" example:"14000"`
+	GrossProfit   int     `json:"gross_profit" example:"3028"`
+	MarginPercent float64 `json:"margin_percent" example:"17.78"`
+}
+
+// AddSale adds a checked out cart to the report
+func (r *SalesReport) AddSale(cart Cart) {
+	r.Sales++
+	r.Revenue += cart.Total - cart.Tax
+	r.Tax += cart.Tax
+	for _, item := range cart.Items {
+		r.Cost += item.CostPrice * item.Quantity
+	}
+	r.calculateProfit()
+}
+
+// AddRefund takes a refund off the report. Refunded items not put back in
+// stock are lost, so their cost stays in.
+func (r *SalesReport) AddRefund(refund Refund) {
+	r.Refunds++
+	r.Revenue -= refund.Amount - refund.Tax
+	r.Tax -= refund.Tax
+	if refund.Restock {
+		for _, line := range refund.Lines {
+			r.Cost -= line.CostPrice * line.Quantity
+		}
+	}
+	r.calculateProfit()
+}
+
+func (r *SalesReport) calculateProfit() {
+	r.GrossProfit = r.Revenue - r.Cost
+	r.MarginPercent = 0
+	if r.Revenue != 0 {
+		r.MarginPercent = math.Round(float64(r.GrossProfit)*10000/float64(r.Revenue)) / 100
+	}
+}
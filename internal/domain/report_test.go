@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestSalesReport_AddSale(t *testing.T) {
+	var report SalesReport
+	report.AddSale(Cart{
+		Total: 9450, Tax: 936,
+		Items: []CartItem{{Quantity: 3, CostPrice: 2800}},
+	})
+	report.AddSale(Cart{
+		Total: 7000, Tax: 0,
+		Items: []CartItem{{Quantity: 1, CostPrice: 4000}, {Quantity: 2, CostPrice: 0}},
+	})
+
+	want := SalesReport{Sales: 2, Revenue: 15514, Tax: 936, Cost: 12400, GrossProfit: 3114, MarginPercent: 20.07}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+
+	// Refunded items not put back in stock keep their cost
+	report.AddRefund(Refund{Amount: 3150, Tax: 312, Lines: []RefundLine{{Quantity: 1, CostPrice: 2800}}})
+	report.AddRefund(Refund{Amount: 3500, Restock: true, Lines: []RefundLine{{Quantity: 1, CostPrice: 4000}}})
+	want = SalesReport{Sales: 2, Refunds: 2, Revenue: 9176, Tax: 624, Cost: 8400, GrossProfit: 776, MarginPercent: 8.46}
+	if report != want {
+		t.Errorf("report after refunds = %+v, want %+v", report, want)
+	}
+
+	var empty SalesReport
+	empty.calculateProfit()
+	if empty != (SalesReport{}) {
+		t.Errorf("empty report = %+v, want zero", empty)
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Shift is a cashier session from opening the till with a cash float to
+// counting the drawer at close. The close fields are nil while it is open.
+// @Description Cashier shift
+type Shift struct {
+	ID           int        `json:"id" example:"1"`
+	Cashier      string     `json:"cashier" example:"budi"`
+	OpeningCash  int        `json:"opening_cash" example:"200000"`
+	OpenedAt     time.Time  `json:"opened_at" example:"2026-03-01T08:00:00Z"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty" example:"2026-03-01T16:00:00Z"`
+	ExpectedCash *int       `json:"expected_cash,omitempty" example:"200000"`
+	CountedCash  *int       `json:"counted_cash,omitempty" example:"199500"`
+	Variance     *int       `json:"variance,omitempty" example:"-500"`
+}
+
+// IsOpen reports whether the shift has not been closed yet
+func (s *Shift) IsOpen() bool {
+	return s.ClosedAt == nil
+}
+
+// OpenShiftInput is used to open a shift
+// @Description Opening cash float
+type OpenShiftInput struct {
+	OpeningCash int `json:"opening_cash" example:"200000"`
+}
+
+// CloseShiftInput is used to close a shift
+// @Description Cash counted in the drawer at close
+type CloseShiftInput struct {
+	CountedCash int `json:"counted_cash" example:"199500"`
+}
+
+// ShiftReport is the end-of-shift cash reconciliation ("tutup kasir").
+// Expected cash is the opening float plus cash taken during the shift, net
+// of change, less cash paid out for refunds; Variance is counted minus
+// expected. PaymentTotals sums the shift's sales per payment method net of
+// the refunds paid back in it, with cash also net of change, and
+// RefundTotals the refunds alone.
+// @Description End-of-shift reconciliation
+type ShiftReport struct {
+	Shift         Shift          `json:"shift"`
+	CashSales     int            `json:"cash_sales" example:"0"`
+	CashRefunds   int            `json:"cash_refunds" example:"0"`
+	ExpectedCash  int            `json:"expected_cash" example:"200000"`
+	CountedCash   int            `json:"counted_cash" example:"199500"`
+	Variance      int            `json:"variance" example:"-500"`
+	PaymentTotals map[string]int `json:"payment_totals"`
+	RefundTotals  map[string]int `json:"refund_totals"`
+}
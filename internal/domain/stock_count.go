@@ -0,0 +1,105 @@
+package domain
+
+import "time"
+
+// Stock count statuses
+const (
+	// StockCountStatusCounting counts are open for counters to record what
+	// they find
+	StockCountStatusCounting = "counting"
+	// StockCountStatusApproved counts have brought the outlet's stock in line
+	// with what was counted
+	StockCountStatusApproved = "approved"
+	// StockCountStatusCancelled counts were abandoned without changing stock
+	StockCountStatusCancelled = "cancelled"
+)
+
+// StockCountLine is a product to count: its stock on the system, as it was
+// when the count started and, once approved, when it was approved, and the
+// total the counters found. CountedQuantity and Variance are nil until the
+// product has been counted.
+// @Description Stock count line
+type StockCountLine struct {
+	ProductID       int    `json:"product_id" example:"1"`
+	ProductName     string `json:"product_name" example:"Indomie Goreng"`
+	SystemQuantity  int    `json:"system_quantity" example:"100"`
+	CountedQuantity *int   `json:"counted_quantity" example:"97"`
+	Variance        *int   `json:"variance" example:"-3"`
+}
+
+// StockCountEntry is a quantity of a product one counter found. Several
+// counters can count the same product, for example on the shelf and in the
+// storeroom; a negative quantity corrects an earlier entry.
+// @Description Stock count entry
+type StockCountEntry struct {
+	ID        int       `json:"id" example:"1"`
+	ProductID int       `json:"product_id" example:"1"`
+	Quantity  int       `json:"quantity" example:"60"`
+	CountedBy string    `json:"counted_by" example:"siti"`
+	CountedAt time.Time `json:"counted_at" example:"2026-03-31T20:15:00Z"`
+}
+
+// StockCount is a stock opname: a physical count of the products at an
+// outlet, optionally only those in one category, kept with every entry the
+// counters recorded
+// @Description Stock count
+type StockCount struct {
+	ID         int               `json:"id" example:"1"`
+	OutletID   int               `json:"outlet_id" example:"1"`
+	CategoryID *int              `json:"category_id" example:"1"`
+	Status     string            `json:"status" example:"counting" enums:"counting,approved,cancelled"`
+	Notes      string            `json:"notes" example:"Month-end count"`
+	Lines      []StockCountLine  `json:"lines"`
+	Entries    []StockCountEntry `json:"entries"`
+	CreatedBy  string            `json:"created_by" example:"budi"`
+	ApprovedBy string            `json:"approved_by" example:"budi"`
+	CreatedAt  time.Time         `json:"created_at" example:"2026-03-31T20:00:00Z"`
+	UpdatedAt  time.Time         `json:"updated_at" example:"2026-03-31T22:00:00Z"`
+}
+
+// CalculateVariance sets the variance of every counted line: what was counted
+// less the system stock
+func (c *StockCount) CalculateVariance() {
+	for i := range c.Lines {
+		line := &c.Lines[i]
+		line.Variance = nil
+		if line.CountedQuantity != nil {
+			variance := *line.CountedQuantity - line.SystemQuantity
+			line.Variance = &variance
+		}
+	}
+}
+
+// LineIndex returns the index of the product's line, or -1 when it is not
+// being counted
+func (c *StockCount) LineIndex(productID int) int {
+	for i, line := range c.Lines {
+		if line.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+// StockCountInput is used to start a stock count. Counts without an outlet
+// are of the default outlet, and counts without a category are of every
+// product.
+// @Description Stock count input
+type StockCountInput struct {
+	OutletID   int    `json:"outlet_id,omitempty" example:"1"`
+	CategoryID *int   `json:"category_id,omitempty" example:"1"`
+	Notes      string `json:"notes" example:"Month-end count"`
+}
+
+// CountedLine is a quantity of a product found by a counter
+// @Description Counted quantity of a product
+type CountedLine struct {
+	ProductID int `json:"product_id" example:"1"`
+	Quantity  int `json:"quantity" example:"60"`
+}
+
+// StockCountEntryInput lists what a counter found
+// @Description Counted quantities
+type StockCountEntryInput struct {
+	Lines []CountedLine `json:"lines"`
+}
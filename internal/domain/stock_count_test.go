@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestStockCount_CalculateVariance(t *testing.T) {
+	counted, short := 105, 97
+	count := StockCount{Lines: []StockCountLine{
+		{ProductID: 1, SystemQuantity: 100, CountedQuantity: &counted},
+		{ProductID: 2, SystemQuantity: 100, CountedQuantity: &short},
+		{ProductID: 3, SystemQuantity: 12},
+	}}
+
+	count.CalculateVariance()
+
+	for i, want := range []int{5, -3} {
+		if v := count.Lines[i].Variance; v == nil || *v != want {
+			t.Errorf("line %d variance = %v, want %d", i, v, want)
+		}
+	}
+	if v := count.Lines[2].Variance; v != nil {
+		t.Errorf("uncounted line variance = %d, want nil", *v)
+	}
+	if got := count.LineIndex(2); got != 1 {
+		t.Errorf("LineIndex(2) = %d, want 1", got)
+	}
+	if got := count.LineIndex(9); got != -1 {
+		t.Errorf("LineIndex(9) = %d, want -1", got)
+	}
+}
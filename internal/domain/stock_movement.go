@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Stock movement reasons
+const (
+	// StockMovementPurchase is stock received against a purchase order
+	StockMovementPurchase = "purchase"
+	// StockMovementSale is stock sold at checkout, including the components
+	// taken by a bundle
+	StockMovementSale = "sale"
+	// StockMovementTransferOut is stock sent to another outlet
+	StockMovementTransferOut = "transfer_out"
+	// StockMovementTransferIn is stock arriving from another outlet, or
+	// returning to its source when a transfer is cancelled
+	StockMovementTransferIn = "transfer_in"
+	// StockMovementWaste is stock written off, such as an expired batch
+	StockMovementWaste = "waste"
+	// StockMovementAdjustment is stock corrected by hand, either to what a
+	// stock count found or by editing the product
+	StockMovementAdjustment = "adjustment"
+	// StockMovementRefund is refunded stock put back on the shelf, including
+	// the components of a refunded bundle
+	StockMovementRefund = "refund"
+	// StockMovementVoid is the stock of a voided sale put back, including the
+	// components of a voided bundle
+	StockMovementVoid = "void"
+)
+
+// StockMovement is an entry in the stock ledger: a signed change to a
+// product's stock at an outlet, why it happened and what it refers to, such
+// as "PO-3"
+// @Description Stock ledger entry
+type StockMovement struct {
+	ID        int       `json:"id" example:"1"`
+	ProductID int       `json:"product_id" example:"1"`
+	OutletID  int       `json:"outlet_id" example:"1"`
+	Quantity  int       `json:"quantity" example:"24"`
+	Reason    string    `json:"reason" example:"purchase"`
+	Reference string    `json:"reference" example:"PO-3"`
+	Actor     string    `json:"actor" example:"budi"`
+	CreatedAt time.Time `json:"created_at" example:"2026-03-02T10:00:00Z"`
+}
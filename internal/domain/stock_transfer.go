@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Stock transfer statuses
+const (
+	// StockTransferStatusInTransit transfers have left the source outlet but
+	// not yet arrived
+	StockTransferStatusInTransit = "in_transit"
+	// StockTransferStatusReceived transfers have been added to the
+	// destination outlet's stock
+	StockTransferStatusReceived = "received"
+	// StockTransferStatusCancelled transfers were returned to the source
+	// outlet's stock
+	StockTransferStatusCancelled = "cancelled"
+)
+
+// StockTransferLine is a quantity of a product moved between outlets
+// @Description Stock transfer line
+type StockTransferLine struct {
+	ProductID int `json:"product_id" example:"1"`
+	Quantity  int `json:"quantity" example:"24"`
+}
+
+// StockTransfer is a document moving stock from one outlet to another. The
+// stock leaves the source outlet when the transfer is created and arrives at
+// the destination when it is received.
+// @Description Stock transfer
+type StockTransfer struct {
+	ID           int                 `json:"id" example:"1"`
+	FromOutletID int                 `json:"from_outlet_id" example:"1"`
+	ToOutletID   int                 `json:"to_outlet_id" example:"2"`
+	Status       string              `json:"status" example:"in_transit" enums:"in_transit,received,cancelled"`
+	Notes        string              `json:"notes" example:"Weekly restock"`
+	Lines        []StockTransferLine `json:"lines"`
+	CreatedBy    string              `json:"created_by" example:"budi"`
+	CreatedAt    time.Time           `json:"created_at" example:"2026-03-01T08:00:00Z"`
+	UpdatedAt    time.Time           `json:"updated_at" example:"2026-03-01T14:00:00Z"`
+}
+
+// StockTransferInput is used to create a stock transfer
+// @Description Stock transfer input
+type StockTransferInput struct {
+	FromOutletID int                 `json:"from_outlet_id" example:"1"`
+	ToOutletID   int                 `json:"to_outlet_id" example:"2"`
+	Notes        string              `json:"notes" example:"Weekly restock"`
+	Lines        []StockTransferLine `json:"lines"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// Supplier is a business the store buys stock from
+// @Description Supplier
+type Supplier struct {
+	ID      int    `json:"id" example:"1"`
+	Name    string `json:"name" example:"PT Indofood Sukses Makmur"`
+	Phone   string `json:"phone" example:"0215795822"`
+	Email   string `json:"email" example:"sales@indofood.example"`
+	Address string `json:"address" example:"Jl. Jend. Sudirman Kav. 76-78, Jakarta"`
+}
+
+// SupplierInput is used for create/update requests
+// @Description Supplier input for create/update
+type SupplierInput struct {
+	Name    string `json:"name" example:"PT Indofood Sukses Makmur"`
+	Phone   string `json:"phone" example:"0215795822"`
+	Email   string `json:"email" example:"sales@indofood.example"`
+	Address string `json:"address" example:"Jl. Jend. Sudirman Kav. 76-78, Jakarta"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+// TaxRate is a tax such as PPN that can be assigned to categories and products.
+// Inclusive rates are already contained in the selling price; exclusive
+// rates are added on top of it.
+// @Description Tax rate
+type TaxRate struct {
+	ID        int     `json:"id" example:"1"`
+	Name      string  `json:"name" example:"PPN"`
+	Rate      float64 `json:"rate" example:"11"`
+	Inclusive bool    `json:"inclusive" example:"true"`
+}
+
+// TaxRateInput is used for create/update requests
+// @Description Tax rate input for create/update
+type TaxRateInput struct {
+	Name      string  `json:"name" example:"PPN"`
+	Rate      float64 `json:"rate" example:"11"`
+	Inclusive bool    `json:"inclusive" example:"true"`
+}
+
+// TaxSummary totals the tax charged under one rate
+// @Description Tax breakdown per rate
+type TaxSummary struct {
+	TaxRateID int     `json:"tax_rate_id" example:"1"`
+	Name      string  `json:"name" example:"PPN"`
+	Rate      float64 `json:"rate" example:"11"`
+	Inclusive bool    `json:"inclusive" example:"true"`
+	Taxable   int     `json:"taxable" example:"8514"`
+	Tax       int     `json:"tax" example:"936"`
+}
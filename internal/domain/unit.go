@@ -0,0 +1,46 @@
+package domain
+
+// DefaultUnitID is the piece unit seeded with the schema. Products that do
+// not name a base unit hold their stock in it.
+const DefaultUnitID = 1
+
+// Unit is a unit of measure products are counted, bought or sold in, such as
+// a piece or a carton
+// @Description Unit of measure
+type Unit struct {
+	ID   int    `json:"id" example:"2"`
+	Code string `json:"code" example:"dus"`
+	Name string `json:"name" example:"Karton"`
+}
+
+// UnitInput is used for create/update requests
+// @Description Unit input for create/update
+type UnitInput struct {
+	Code string `json:"code" example:"dus"`
+	Name string `json:"name" example:"Karton"`
+}
+
+// ProductUnit converts another unit of a product into its base unit: one
+// UnitID holds Factor base units. Price is what one UnitID sells for; zero
+// means Factor times the product's price.
+// @Description Product unit conversion
+type ProductUnit struct {
+	UnitID int `json:"unit_id" example:"2"`
+	Factor int `json:"factor" example:"40"`
+	Price  int `json:"price" example:"130000"`
+}
+
+// SellingPrice returns what one unit sells for given the product's price per
+// base unit
+func (u ProductUnit) SellingPrice(basePrice int) int {
+	if u.Price > 0 {
+		return u.Price
+	}
+	return u.Factor * basePrice
+}
+
+// ProductUnitsInput replaces every unit conversion of a product
+// @Description Product unit conversions
+type ProductUnitsInput struct {
+	Units []ProductUnit `json:"units"`
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "testing"
+
+func TestProductUnit_SellingPrice(t *testing.T) {
+	tests := []struct {
+		name string
+		unit ProductUnit
+		want int
+	}{
+		{name: "own price", unit: ProductUnit{Factor: 40, Price: 130000}, want: 130000},
+		{name: "factor times base price", unit: ProductUnit{Factor: 40}, want: 140000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.unit.SellingPrice(3500); got != tt.want {
+				t.Errorf("SellingPrice(3500) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurchaseOrderLine_BaseUnits(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         PurchaseOrderLine
+		wantQuantity int
+		wantCost     int
+	}{
+		{name: "ordered in a pack", line: PurchaseOrderLine{Factor: 40, UnitCost: 112010}, wantQuantity: 80, wantCost: 2800},
+		{name: "ordered in the base unit", line: PurchaseOrderLine{Factor: 1, UnitCost: 2800}, wantQuantity: 2, wantCost: 2800},
+		{name: "no factor recorded", line: PurchaseOrderLine{UnitCost: 2800}, wantQuantity: 2, wantCost: 2800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.line.BaseQuantity(2); got != tt.wantQuantity {
+				t.Errorf("BaseQuantity(2) = %d, want %d", got, tt.wantQuantity)
+			}
+			if got := tt.line.BaseCost(); got != tt.wantCost {
+				t.Errorf("BaseCost() = %d, want %d", got, tt.wantCost)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// HandleAudit handles GET requests for /api/audit
+func (h *AuditHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// List godoc
+// @Summary      List audit log entries
+// @Description  Retrieve create, update and delete operations, newest first. Requires the admin token.
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token  header    string  true   "Admin token"
+// @Param        actor          query     string  false  "Filter by actor"
+// @Param        action         query     string  false  "Filter by action (create, update, delete)"
+// @Param        entity_type    query     string  false  "Filter by entity type (product, category)"
+// @Param        entity_id      query     int     false  "Filter by entity ID"
+// @Param        from           query     string  false  "Only entries at or after this RFC 3339 time"
+// @Param        to             query     string  false  "Only entries before this RFC 3339 time"
+// @Param        limit          query     int     false  "Maximum entries to return (default 100, max 500)"
+// @Param        offset         query     int     false  "Entries to skip"
+// @Success      200  {array}   domain.AuditEntry
+// @Failure      400  {string}  string  "Invalid query parameter"
+// @Failure      401  {string}  string  "Invalid admin token"
+// @Failure      500  {string}  string  "Failed to fetch audit log"
+// @Router       /audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid query parameter")
+		return
+	}
+
+	entries, err := h.service.List(filter)
+	if err != nil {
+		log.Println("Error fetching audit log:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch audit log")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, entries)
+}
+
+func parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := domain.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+	}
+
+	ints := map[string]*int{"entity_id": &filter.EntityID, "limit": &filter.Limit, "offset": &filter.Offset}
+	for name, dst := range ints {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return filter, err
+			}
+			*dst = n
+		}
+	}
+
+	times := map[string]*time.Time{"from": &filter.From, "to": &filter.To}
+	for name, dst := range times {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, err
+			}
+			*dst = t
+		}
+	}
+
+	return filter, nil
+}
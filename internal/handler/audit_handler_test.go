@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+const testAdminToken = "s3cret"
+
+// newAuditMux wires product, category and audit routes over shared
+// in-memory repositories, the same way router.New does
+func newAuditMux(t *testing.T) http.Handler {
+	t.Helper()
+	repos := newRepos(t)
+	tx := memory.NewTransactor(repos)
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories, repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), tx))
+	categories := handler.NewCategoryHandler(service.NewCategoryService(repos.Categories, repos.TaxRates, tx))
+	audit := handler.NewAuditHandler(service.NewAuditService(repos.Audit))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products/", products.HandleProductByID)
+	mux.HandleFunc("/api/categories", categories.HandleCategories)
+	mux.HandleFunc("/api/audit", handler.RequireAdminToken(testAdminToken, audit.HandleAudit))
+	return handler.RequestID(mux)
+}
+
+func serve(mux http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuditHandler_RecordsMutations(t *testing.T) {
+	mux := newAuditMux(t)
+	user := map[string]string{"X-User": "siti", "X-Request-ID": "req-42"}
+
+	if rec := serve(mux, http.MethodPost, "/api/categories", `{"name":"Minuman"}`, user); rec.Code != http.StatusCreated {
+		t.Fatalf("create category status = %d", rec.Code)
+	}
+	body := `{"name":"Indomie Goreng","price":4000,"stock":100,"category_id":1}`
+	rec := serve(mux, http.MethodPut, "/api/products/1", body, user)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update product status = %d", rec.Code)
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "req-42" {
+		t.Errorf("X-Request-ID = %q, want echoed req-42", got)
+	}
+	if rec := serve(mux, http.MethodDelete, "/api/products/1", "", user); rec.Code != http.StatusOK {
+		t.Fatalf("delete product status = %d", rec.Code)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/audit?entity_type=product", "", map[string]string{"X-Admin-Token": testAdminToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("audit status = %d", rec.Code)
+	}
+	var entries []domain.AuditEntry
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &entries); err != nil {
+		t.Fatalf("decode entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	del, upd := entries[0], entries[1]
+	if del.Action != domain.AuditActionDelete || del.After != nil || del.Before == nil {
+		t.Errorf("delete entry = %+v", del)
+	}
+	if upd.Action != domain.AuditActionUpdate || upd.Actor != "siti" || upd.RequestID != "req-42" || upd.EntityID != 1 {
+		t.Errorf("update entry = %+v", upd)
+	}
+	var changes map[string]struct{ Old, New any }
+	if err := json.Unmarshal(upd.Changes, &changes); err != nil {
+		t.Fatalf("decode changes: %v", err)
+	}
+	if changes["price"].Old != 3500.0 || changes["price"].New != 4000.0 {
+		t.Errorf("changes = %+v, want price 3500 -> 4000", changes)
+	}
+	if _, ok := changes["name"]; ok {
+		t.Errorf("changes = %+v, unchanged name must not be in the diff", changes)
+	}
+}
+
+func TestAuditHandler_HandleAudit(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		token      string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, token: testAdminToken, wantStatus: http.StatusOK},
+		{name: "filtered", method: http.MethodGet, query: "?actor=siti&entity_id=1&from=2026-01-01T00:00:00Z&limit=5", token: testAdminToken, wantStatus: http.StatusOK},
+		{name: "invalid entity id", method: http.MethodGet, query: "?entity_id=abc", token: testAdminToken, wantStatus: http.StatusBadRequest, wantError: "Invalid query parameter"},
+		{name: "invalid time", method: http.MethodGet, query: "?from=yesterday", token: testAdminToken, wantStatus: http.StatusBadRequest, wantError: "Invalid query parameter"},
+		{name: "missing token", method: http.MethodGet, wantStatus: http.StatusUnauthorized, wantError: "Invalid admin token"},
+		{name: "wrong token", method: http.MethodGet, token: "guess", wantStatus: http.StatusUnauthorized, wantError: "Invalid admin token"},
+		{name: "method not allowed", method: http.MethodPost, token: testAdminToken, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newAuditMux(t)
+			rec := serve(mux, tt.method, "/api/audit"+tt.query, "", map[string]string{"X-Admin-Token": tt.token})
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRequireAdminToken_Unconfigured(t *testing.T) {
+	h := handler.RequireAdminToken("", func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run without a configured token")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/audit", nil)
+	req.Header.Set("X-Admin-Token", "")
+	rec := httptest.NewRecorder()
+
+	h(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	var seen string
+	h := handler.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = w.Header().Get("X-Request-ID")
+	}))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if seen == "" || rec.Header().Get("X-Request-ID") != seen {
+		t.Errorf("X-Request-ID = %q, want generated id", rec.Header().Get("X-Request-ID"))
+	}
+}
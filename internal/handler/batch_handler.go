@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// BatchHandler handles HTTP requests for stock batches
+type BatchHandler struct {
+	service *service.BatchService
+}
+
+// NewBatchHandler creates a new stock batch handler
+func NewBatchHandler(service *service.BatchService) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+// HandleExpiring handles GET requests for /api/products/expiring
+func (h *BatchHandler) HandleExpiring(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetExpiring(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetExpiring godoc
+// @Summary      Get expiring stock
+// @Description  Retrieve the batches with stock remaining that expire within the next days, soonest first, including those already expired
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        days       query     int  false  "Days ahead to look, 7 by default"
+// @Param        outlet_id  query     int  false  "Only batches at this outlet"
+// @Success      200        {array}   domain.StockBatch
+// @Failure      400        {string}  string  "Invalid days"
+// @Failure      400        {string}  string  "Invalid outlet ID"
+// @Failure      400        {string}  string  "Outlet not found"
+// @Failure      500        {string}  string  "Failed to fetch expiring stock"
+// @Router       /products/expiring [get]
+func (h *BatchHandler) GetExpiring(w http.ResponseWriter, r *http.Request) {
+	days := service.DefaultExpiringDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		var err error
+		if days, err = strconv.Atoi(raw); err != nil || days < 0 {
+			WriteError(w, http.StatusBadRequest, "Invalid days")
+			return
+		}
+	}
+
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	batches, err := h.service.GetExpiring(days, outletID)
+	if err != nil {
+		log.Println("Error fetching expiring stock:", err)
+		writeBatchError(w, err, "Failed to fetch expiring stock")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, batches)
+}
+
+// HandleProductBatches handles GET requests for /api/products/{id}/batches
+func (h *BatchHandler) HandleProductBatches(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByProduct(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByProduct godoc
+// @Summary      Get product batches
+// @Description  Retrieve a product's batches with stock remaining at an outlet, in the first-expired-first-out order checkout takes them
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int  true   "Product ID"
+// @Param        outlet_id  query     int  false  "Outlet ID, the default outlet when omitted"
+// @Success      200        {array}   domain.StockBatch
+// @Failure      400        {string}  string  "Invalid product ID"
+// @Failure      400        {string}  string  "Invalid outlet ID"
+// @Failure      400        {string}  string  "Outlet not found"
+// @Failure      404        {string}  string  "Product not found"
+// @Failure      500        {string}  string  "Failed to fetch batches"
+// @Router       /products/{id}/batches [get]
+func (h *BatchHandler) GetByProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	batches, err := h.service.GetByProduct(id, outletID)
+	if err != nil {
+		log.Println("Error fetching batches:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		writeBatchError(w, err, "Failed to fetch batches")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, batches)
+}
+
+// HandleWriteOff handles POST requests for /api/stock-batches/{id}/write-off
+func (h *BatchHandler) HandleWriteOff(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.WriteOff(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// WriteOff godoc
+// @Summary      Write off a batch
+// @Description  Take stock out of a batch and its outlet's stock as waste, recorded in the stock ledger. A quantity of zero or none writes off everything that remains.
+// @Tags         stock-batches
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                   true   "Stock batch ID"
+// @Param        write_off body      domain.WriteOffInput  false  "Quantity to write off"
+// @Param        X-User    header    string                false  "User writing the stock off, recorded in the stock ledger"
+// @Success      200       {object}  domain.StockBatch
+// @Failure      400       {string}  string  "Invalid stock batch ID"
+// @Failure      400       {string}  string  "Invalid request body"
+// @Failure      404       {string}  string  "Stock batch not found"
+// @Failure      409       {string}  string  "Insufficient stock"
+// @Router       /stock-batches/{id}/write-off [post]
+func (h *BatchHandler) WriteOff(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid stock batch ID")
+		return
+	}
+
+	var input domain.WriteOffInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	batch, err := h.service.WriteOff(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error writing off stock batch:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Stock batch not found")
+			return
+		}
+		writeBatchError(w, err, "Failed to write off stock batch")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, batch)
+}
+
+func writeBatchError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrOutletNotFound):
+		WriteError(w, http.StatusBadRequest, "Outlet not found")
+	case errors.Is(err, apperrors.ErrInsufficientStock):
+		WriteError(w, http.StatusConflict, "Insufficient stock")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,213 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newBatchMux wires the stock batch, cart checkout and product stock ledger
+// routes the same way router.New does. Of product 1's 100 in stock, batch 1
+// holds 10 expiring in 3 days, batch 2 holds 20 expiring in 30 days and
+// batch 3 holds 5 that expired yesterday. budi has shift 1 open.
+func newBatchMux(t *testing.T) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := newRepos(t)
+	now := time.Now().UTC()
+	for _, seed := range []struct {
+		lot      string
+		days     int
+		quantity int
+	}{{"L1", 3, 10}, {"L2", 30, 20}, {"L0", -1, 5}} {
+		expiresAt := now.AddDate(0, 0, seed.days)
+		batch := domain.StockBatch{ProductID: 1, OutletID: domain.DefaultOutletID, LotNumber: seed.lot, ExpiresAt: &expiresAt,
+			Quantity: seed.quantity, Remaining: seed.quantity, CreatedAt: now}
+		if err := repos.StockBatches.Create(&batch); err != nil {
+			t.Fatalf("seed batch: %v", err)
+		}
+	}
+
+	if err := repos.Shifts.Create(&domain.Shift{Cashier: "budi", OpenedAt: now}); err != nil {
+		t.Fatalf("seed shift: %v", err)
+	}
+
+	tx := memory.NewTransactor(repos)
+	batches := handler.NewBatchHandler(service.NewBatchService(repos.StockBatches, repos.Products, repos.Outlets, tx))
+	carts := handler.NewCartHandler(service.NewCartService(repos.Carts, repos.Products, repos.ProductUnits, repos.Customers, repos.Outlets, tx,
+		15*time.Minute, domain.LoyaltyProgram{SpendPerPoint: 1000, PointValue: 100}))
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), tx))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products/expiring", batches.HandleExpiring)
+	mux.HandleFunc("/api/products/", products.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/batches", batches.HandleProductBatches)
+	mux.HandleFunc("/api/products/{id}/stock-movements", products.HandleStockMovements)
+	mux.HandleFunc("/api/stock-batches/{id}/write-off", batches.HandleWriteOff)
+	mux.HandleFunc("/api/carts", carts.HandleCarts)
+	mux.HandleFunc("/api/carts/{id}/checkout", carts.HandleCheckout)
+	return mux, repos
+}
+
+func TestBatchHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "expiring", method: http.MethodGet, path: "/api/products/expiring", wantStatus: http.StatusOK},
+		{name: "expiring within days", method: http.MethodGet, path: "/api/products/expiring?days=60", wantStatus: http.StatusOK},
+		{name: "expiring at outlet", method: http.MethodGet, path: "/api/products/expiring?outlet_id=1", wantStatus: http.StatusOK},
+		{name: "expiring with negative days", method: http.MethodGet, path: "/api/products/expiring?days=-1", wantStatus: http.StatusBadRequest, wantError: "Invalid days"},
+		{name: "expiring with invalid days", method: http.MethodGet, path: "/api/products/expiring?days=week", wantStatus: http.StatusBadRequest, wantError: "Invalid days"},
+		{name: "expiring at missing outlet", method: http.MethodGet, path: "/api/products/expiring?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "expiring method not allowed", method: http.MethodPost, path: "/api/products/expiring", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "product batches", method: http.MethodGet, path: "/api/products/1/batches", wantStatus: http.StatusOK},
+		{name: "product batches at missing outlet", method: http.MethodGet, path: "/api/products/1/batches?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "batches of missing product", method: http.MethodGet, path: "/api/products/99/batches", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "batches with invalid product id", method: http.MethodGet, path: "/api/products/abc/batches", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "write off", method: http.MethodPost, path: "/api/stock-batches/3/write-off", body: `{"quantity":2}`, wantStatus: http.StatusOK},
+		{name: "write off everything remaining", method: http.MethodPost, path: "/api/stock-batches/3/write-off", wantStatus: http.StatusOK},
+		{name: "write off more than remaining", method: http.MethodPost, path: "/api/stock-batches/3/write-off", body: `{"quantity":6}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: batch has only 5 remaining"},
+		{name: "write off negative quantity", method: http.MethodPost, path: "/api/stock-batches/3/write-off", body: `{"quantity":-1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must not be negative"},
+		{name: "write off malformed body", method: http.MethodPost, path: "/api/stock-batches/3/write-off", body: `{"quantity":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "write off missing batch", method: http.MethodPost, path: "/api/stock-batches/99/write-off", wantStatus: http.StatusNotFound, wantError: "Stock batch not found"},
+		{name: "write off invalid id", method: http.MethodPost, path: "/api/stock-batches/abc/write-off", wantStatus: http.StatusBadRequest, wantError: "Invalid stock batch ID"},
+		{name: "write off method not allowed", method: http.MethodGet, path: "/api/stock-batches/3/write-off", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newBatchMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestBatchHandler_Expiring(t *testing.T) {
+	mux, _ := newBatchMux(t)
+
+	expiring := func(path string) []domain.StockBatch {
+		t.Helper()
+		rec := serve(mux, http.MethodGet, path, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", path, rec.Code)
+		}
+		var batches []domain.StockBatch
+		if err := json.Unmarshal(decodeResponse(t, rec).Data, &batches); err != nil {
+			t.Fatalf("decode batches: %v", err)
+		}
+		return batches
+	}
+
+	week := expiring("/api/products/expiring")
+	if len(week) != 2 || week[0].LotNumber != "L0" || week[1].LotNumber != "L1" {
+		t.Fatalf("expiring in 7 days = %+v, want L0 then L1", week)
+	}
+	if week[1].ProductName != "Indomie Goreng" || week[1].Remaining != 10 {
+		t.Errorf("batch = %+v, want 10 Indomie Goreng remaining", week[1])
+	}
+	if today := expiring("/api/products/expiring?days=0"); len(today) != 1 || today[0].LotNumber != "L0" {
+		t.Errorf("expiring today = %+v, want the expired L0", today)
+	}
+	if all := expiring("/api/products/expiring?days=60"); len(all) != 3 {
+		t.Errorf("expiring in 60 days = %+v, want all three batches", all)
+	}
+}
+
+func TestBatchHandler_CheckoutTakesFirstExpiredFirst(t *testing.T) {
+	mux, repos := newBatchMux(t)
+	user := map[string]string{"X-User": "budi"}
+
+	if rec := serve(mux, http.MethodPost, "/api/carts", `{"items":[{"product_id":1,"quantity":12}]}`, user); rec.Code != http.StatusCreated {
+		t.Fatalf("create cart status = %d", rec.Code)
+	}
+	rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":100000}]}`, user)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// The expired L0 goes first, then L1 covers the rest
+	rec = serve(mux, http.MethodGet, "/api/products/1/batches", "", nil)
+	var batches []domain.StockBatch
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &batches); err != nil {
+		t.Fatalf("decode batches: %v", err)
+	}
+	if len(batches) != 2 || batches[0].LotNumber != "L1" || batches[0].Remaining != 3 || batches[1].Remaining != 20 {
+		t.Errorf("batches after checkout = %+v, want 3 left of L1 and 20 of L2", batches)
+	}
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if product.Stock != 88 {
+		t.Errorf("stock = %d, want 88", product.Stock)
+	}
+}
+
+func TestBatchHandler_WriteOffRecordsWaste(t *testing.T) {
+	mux, repos := newBatchMux(t)
+
+	rec := serve(mux, http.MethodPost, "/api/stock-batches/3/write-off", "", map[string]string{"X-User": "siti"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("write off status = %d, body %s", rec.Code, rec.Body)
+	}
+	var batch domain.StockBatch
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &batch); err != nil {
+		t.Fatalf("decode batch: %v", err)
+	}
+	if batch.Remaining != 0 || batch.Quantity != 5 {
+		t.Errorf("batch = %+v, want all 5 written off", batch)
+	}
+
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if product.Stock != 95 {
+		t.Errorf("stock = %d, want 95", product.Stock)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/products/1/stock-movements", "", nil)
+	var movements []domain.StockMovement
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &movements); err != nil {
+		t.Fatalf("decode stock movements: %v", err)
+	}
+	if len(movements) != 1 {
+		t.Fatalf("stock movements = %+v, want one", movements)
+	}
+	if m := movements[0]; m.Quantity != -5 || m.Reason != domain.StockMovementWaste || m.Reference != "BATCH-3" || m.Actor != "siti" {
+		t.Errorf("movement = %+v, want -5 waste for BATCH-3 by siti", m)
+	}
+	entries, err := repos.Audit.List(domain.AuditFilter{EntityType: domain.AuditEntityStockBatch, EntityID: 3, Limit: 10})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Actor != "siti" || entries[0].Changes == nil {
+		t.Errorf("audit entries = %+v, want the write-off by siti", entries)
+	}
+
+	rec = serve(mux, http.MethodPost, "/api/stock-batches/3/write-off", "", nil)
+	if got := decodeResponse(t, rec).Error; rec.Code != http.StatusBadRequest || got != "invalid input: batch has no stock remaining" {
+		t.Errorf("second write off = %d %q, want 400 for an empty batch", rec.Code, got)
+	}
+}
@@ -0,0 +1,402 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// CartHandler handles HTTP requests for carts
+type CartHandler struct {
+	service *service.CartService
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(service *service.CartService) *CartHandler {
+	return &CartHandler{service: service}
+}
+
+// HandleCarts handles GET and POST requests for /api/carts
+func (h *CartHandler) HandleCarts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all carts
+// @Description  Retrieve carts, optionally only those with a status such as held or at one outlet
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        status     query     string  false  "Cart status"  Enums(open, held, checked_out, voided)
+// @Param        outlet_id  query     int     false  "Only carts at this outlet"
+// @Success      200     {array}   domain.Cart
+// @Failure      400     {string}  string  "Invalid status"
+// @Failure      400     {string}  string  "Invalid outlet ID"
+// @Failure      400     {string}  string  "Outlet not found"
+// @Failure      500     {string}  string  "Failed to fetch carts"
+// @Router       /carts [get]
+func (h *CartHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	carts, err := h.service.GetAll(r.URL.Query().Get("status"), outletID)
+	if err != nil {
+		log.Println("Error fetching carts:", err)
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch carts")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, carts)
+}
+
+// Create godoc
+// @Summary      Create a cart
+// @Description  Open a cart for the cashier in the X-User header at an outlet (the default outlet if none is given), optionally with items and a customer. With reserve_stock the items are reserved until RESERVATION_TTL passes.
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        cart    body      domain.CartInput  false  "Initial items and customer"
+// @Param        X-User  header    string            false  "Cashier"
+// @Success      201     {object}  domain.Cart
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      400     {string}  string  "Product not found"
+// @Failure      400     {string}  string  "Customer not found"
+// @Failure      400     {string}  string  "Outlet not found"
+// @Failure      409     {string}  string  "Insufficient stock"
+// @Router       /carts [post]
+func (h *CartHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.CartInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	cart, err := h.service.Create(input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error creating cart:", err)
+		writeCartError(w, err, "", "Failed to create cart")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, cart)
+}
+
+// HandleCartByID handles GET requests for /api/carts/{id}
+func (h *CartHandler) HandleCartByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get cart by ID
+// @Description  Retrieve a single cart with its items, and its totals and payments once checked out
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Cart ID"
+// @Success      200  {object}  domain.Cart
+// @Failure      400  {string}  string  "Invalid cart ID"
+// @Failure      404  {string}  string  "Cart not found"
+// @Router       /carts/{id} [get]
+func (h *CartHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := cartIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	cart, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching cart by ID:", err)
+		writeCartError(w, err, "", "Failed to fetch cart")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, cart)
+}
+
+// HandleItems handles POST requests for /api/carts/{id}/items
+func (h *CartHandler) HandleItems(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.AddItem(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// AddItem godoc
+// @Summary      Add an item to a cart
+// @Description  Add a quantity of a product to an open cart, merging it with any existing line for the product
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int               true  "Cart ID"
+// @Param        item  body      domain.QuoteItem  true  "Product and quantity"
+// @Success      200   {object}  domain.Cart
+// @Failure      400   {string}  string  "Invalid cart ID or request body"
+// @Failure      400   {string}  string  "Product not found"
+// @Failure      404   {string}  string  "Cart not found"
+// @Failure      409   {string}  string  "Cart is not open"
+// @Failure      409   {string}  string  "Insufficient stock"
+// @Router       /carts/{id}/items [post]
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	id, ok := cartIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var item domain.QuoteItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	cart, err := h.service.AddItem(id, item)
+	if err != nil {
+		log.Println("Error adding cart item:", err)
+		writeCartError(w, err, "Cart is not open", "Failed to add item")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, cart)
+}
+
+// HandleItem handles DELETE requests for /api/carts/{id}/items/{product_id}
+func (h *CartHandler) HandleItem(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		h.RemoveItem(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// RemoveItem godoc
+// @Summary      Remove an item from a cart
+// @Description  Remove a product's lines from an open cart, or only its line in one unit
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int  true   "Cart ID"
+// @Param        product_id  path      int  true   "Product ID"
+// @Param        unit_id     query     int  false  "Only remove the line in this unit"
+// @Success      200         {object}  domain.Cart
+// @Failure      400         {string}  string  "Invalid cart or product ID"
+// @Failure      400         {string}  string  "Invalid unit ID"
+// @Failure      404         {string}  string  "Cart not found"
+// @Failure      409         {string}  string  "Cart is not open"
+// @Router       /carts/{id}/items/{product_id} [delete]
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	id, ok := cartIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	productID, err := strconv.Atoi(r.PathValue("product_id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+	unitID, err := unitIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid unit ID")
+		return
+	}
+
+	cart, err := h.service.RemoveItem(id, productID, unitID)
+	if err != nil {
+		log.Println("Error removing cart item:", err)
+		writeCartError(w, err, "Cart is not open", "Failed to remove item")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, cart)
+}
+
+// HandleHold handles POST requests for /api/carts/{id}/hold
+func (h *CartHandler) HandleHold(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Hold(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Hold godoc
+// @Summary      Hold a cart
+// @Description  Park an open cart so the cashier can serve the next customer
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Cart ID"
+// @Success      200  {object}  domain.Cart
+// @Failure      400  {string}  string  "Invalid cart ID"
+// @Failure      404  {string}  string  "Cart not found"
+// @Failure      409  {string}  string  "Only open carts can be held"
+// @Router       /carts/{id}/hold [post]
+func (h *CartHandler) Hold(w http.ResponseWriter, r *http.Request) {
+	id, ok := cartIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	cart, err := h.service.Hold(id)
+	if err != nil {
+		log.Println("Error holding cart:", err)
+		writeCartError(w, err, "Only open carts can be held", "Failed to hold cart")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, cart)
+}
+
+// HandleResume handles POST requests for /api/carts/{id}/resume
+func (h *CartHandler) HandleResume(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Resume(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Resume godoc
+// @Summary      Resume a cart
+// @Description  Reopen a held cart
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Cart ID"
+// @Success      200  {object}  domain.Cart
+// @Failure      400  {string}  string  "Invalid cart ID"
+// @Failure      404  {string}  string  "Cart not found"
+// @Failure      409  {string}  string  "Only held carts can be resumed"
+// @Router       /carts/{id}/resume [post]
+func (h *CartHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	id, ok := cartIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	cart, err := h.service.Resume(id)
+	if err != nil {
+		log.Println("Error resuming cart:", err)
+		writeCartError(w, err, "Only held carts can be resumed", "Failed to resume cart")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, cart)
+}
+
+// HandleCheckout handles POST requests for /api/carts/{id}/checkout
+func (h *CartHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Checkout(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Checkout godoc
+// @Summary      Check out a cart
+// @Description  Re-price an open cart with current prices, promotions and taxes, redeem and earn the customer's loyalty points, settle the payments, take the stock and record the sale
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                   true   "Cart ID"
+// @Param        checkout  body      domain.CheckoutInput  true   "Payments, customer and points to redeem"
+// @Param        X-User    header    string                false  "Cashier taking the payment, whose open shift cash payments go into"
+// @Success      200       {object}  domain.CheckoutResult
+// @Failure      400       {string}  string  "Invalid cart ID or request body"
+// @Failure      400       {string}  string  "Product not found"
+// @Failure      400       {string}  string  "Customer not found"
+// @Failure      404       {string}  string  "Cart not found"
+// @Failure      409       {string}  string  "Cart is not open"
+// @Failure      409       {string}  string  "Insufficient stock"
+// @Failure      409       {string}  string  "Insufficient loyalty points"
+// @Failure      409       {string}  string  "Cash payments need an open shift"
+// @Router       /carts/{id}/checkout [post]
+func (h *CartHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	id, ok := cartIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var input domain.CheckoutInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Checkout(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error checking out cart:", err)
+		writeCartError(w, err, "Cart is not open", "Failed to check out cart")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, result)
+}
+
+func cartIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid cart ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeCartError maps cart errors to responses. conflict is the message for
+// a cart whose status does not allow the operation.
+func writeCartError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Cart not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrInsufficientStock), errors.Is(err, apperrors.ErrInsufficientPoints),
+		errors.Is(err, apperrors.ErrNoOpenShift):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, apperrors.ErrProductNotFound):
+		WriteError(w, http.StatusBadRequest, "Product not found")
+	case errors.Is(err, apperrors.ErrCustomerNotFound):
+		WriteError(w, http.StatusBadRequest, "Customer not found")
+	case errors.Is(err, apperrors.ErrOutletNotFound):
+		WriteError(w, http.StatusBadRequest, "Outlet not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
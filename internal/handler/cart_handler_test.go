@@ -0,0 +1,555 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newCartMux wires the cart and shift routes the same way router.New does.
+// Shift 1 is open for budi with a 100000 float, cart 1 is budi's and open
+// with two Indomie Goreng, cart 2 is held and empty.
+func newCartMux(t *testing.T) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := newRepos(t)
+	tx := memory.NewTransactor(repos)
+	carts := handler.NewCartHandler(service.NewCartService(repos.Carts, repos.Products, repos.ProductUnits, repos.Customers, repos.Outlets, tx,
+		15*time.Minute, domain.LoyaltyProgram{SpendPerPoint: 1000, PointValue: 100}))
+	shifts := handler.NewShiftHandler(service.NewShiftService(repos.Shifts, tx))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/carts", carts.HandleCarts)
+	mux.HandleFunc("/api/carts/{id}", carts.HandleCartByID)
+	mux.HandleFunc("/api/carts/{id}/items", carts.HandleItems)
+	mux.HandleFunc("/api/carts/{id}/items/{product_id}", carts.HandleItem)
+	mux.HandleFunc("/api/carts/{id}/hold", carts.HandleHold)
+	mux.HandleFunc("/api/carts/{id}/resume", carts.HandleResume)
+	mux.HandleFunc("/api/carts/{id}/checkout", carts.HandleCheckout)
+	mux.HandleFunc("/api/shifts/open", shifts.HandleOpen)
+	mux.HandleFunc("/api/shifts/{id}/close", shifts.HandleClose)
+
+	user := map[string]string{"X-User": "budi"}
+	if rec := serve(mux, http.MethodPost, "/api/shifts/open", `{"opening_cash":100000}`, user); rec.Code != http.StatusCreated {
+		t.Fatalf("seed shift status = %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts", `{"items":[{"product_id":1,"quantity":2}]}`, user); rec.Code != http.StatusCreated {
+		t.Fatalf("seed cart status = %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts", "", user); rec.Code != http.StatusCreated {
+		t.Fatalf("seed empty cart status = %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/2/hold", "", nil); rec.Code != http.StatusOK {
+		t.Fatalf("seed hold status = %d", rec.Code)
+	}
+	return mux, repos
+}
+
+func TestCartHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/carts", wantStatus: http.StatusOK},
+		{name: "list held", method: http.MethodGet, path: "/api/carts?status=held", wantStatus: http.StatusOK},
+		{name: "list with unknown status", method: http.MethodGet, path: "/api/carts?status=lost", wantStatus: http.StatusBadRequest, wantError: "invalid input: status must be open, held, checked_out or voided"},
+		{name: "list at outlet", method: http.MethodGet, path: "/api/carts?outlet_id=1", wantStatus: http.StatusOK},
+		{name: "list at missing outlet", method: http.MethodGet, path: "/api/carts?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "list with invalid outlet", method: http.MethodGet, path: "/api/carts?outlet_id=abc", wantStatus: http.StatusBadRequest, wantError: "Invalid outlet ID"},
+		{name: "create at missing outlet", method: http.MethodPost, path: "/api/carts", body: `{"outlet_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/carts", body: `{"items":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create with unknown product", method: http.MethodPost, path: "/api/carts", body: `{"items":[{"product_id":99,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Product not found"},
+		{name: "carts method not allowed", method: http.MethodDelete, path: "/api/carts", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/carts/1", wantStatus: http.StatusOK},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/carts/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid cart ID"},
+		{name: "get missing", method: http.MethodGet, path: "/api/carts/99", wantStatus: http.StatusNotFound, wantError: "Cart not found"},
+		{name: "add item", method: http.MethodPost, path: "/api/carts/1/items", body: `{"product_id":1,"quantity":1}`, wantStatus: http.StatusOK},
+		{name: "add zero quantity", method: http.MethodPost, path: "/api/carts/1/items", body: `{"product_id":1,"quantity":0}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must be positive"},
+		{name: "add unknown product", method: http.MethodPost, path: "/api/carts/1/items", body: `{"product_id":99,"quantity":1}`, wantStatus: http.StatusBadRequest, wantError: "Product not found"},
+		{name: "add to held cart", method: http.MethodPost, path: "/api/carts/2/items", body: `{"product_id":1,"quantity":1}`, wantStatus: http.StatusConflict, wantError: "Cart is not open"},
+		{name: "add to missing cart", method: http.MethodPost, path: "/api/carts/99/items", body: `{"product_id":1,"quantity":1}`, wantStatus: http.StatusNotFound, wantError: "Cart not found"},
+		{name: "remove item", method: http.MethodDelete, path: "/api/carts/1/items/1", wantStatus: http.StatusOK},
+		{name: "remove item not in cart", method: http.MethodDelete, path: "/api/carts/1/items/2", wantStatus: http.StatusBadRequest, wantError: "invalid input: product is not in the cart"},
+		{name: "remove invalid product id", method: http.MethodDelete, path: "/api/carts/1/items/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "hold", method: http.MethodPost, path: "/api/carts/1/hold", wantStatus: http.StatusOK},
+		{name: "hold held cart", method: http.MethodPost, path: "/api/carts/2/hold", wantStatus: http.StatusConflict, wantError: "Only open carts can be held"},
+		{name: "resume", method: http.MethodPost, path: "/api/carts/2/resume", wantStatus: http.StatusOK},
+		{name: "resume open cart", method: http.MethodPost, path: "/api/carts/1/resume", wantStatus: http.StatusConflict, wantError: "Only held carts can be resumed"},
+		{name: "checkout", method: http.MethodPost, path: "/api/carts/1/checkout", body: `{"payments":[{"method":"cash","amount":10000}]}`, wantStatus: http.StatusOK},
+		{name: "checkout held cart", method: http.MethodPost, path: "/api/carts/2/checkout", body: `{"payments":[{"method":"cash","amount":10000}]}`, wantStatus: http.StatusConflict, wantError: "Cart is not open"},
+		{name: "checkout underpaid", method: http.MethodPost, path: "/api/carts/1/checkout", body: `{"payments":[{"method":"cash","amount":5000}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: payments do not cover the total"},
+		{name: "checkout without payments", method: http.MethodPost, path: "/api/carts/1/checkout", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: at least one payment is required"},
+		{name: "checkout malformed body", method: http.MethodPost, path: "/api/carts/1/checkout", body: `{"payments":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "checkout method not allowed", method: http.MethodGet, path: "/api/carts/1/checkout", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newCartMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCartHandler_CheckoutRecordsSale(t *testing.T) {
+	mux, repos := newCartMux(t)
+	user := map[string]string{"X-User": "budi"}
+
+	// The price goes up while the cart is parked; checkout uses the new price
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	product.Price = 4000
+	if err := repos.Products.Update(product); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+
+	body := `{"payments":[{"method":"qris","amount":5000,"reference":"QR-1"},{"method":"cash","amount":5000}]}`
+	rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", body, user)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d", rec.Code)
+	}
+	var result domain.CheckoutResult
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &result); err != nil {
+		t.Fatalf("decode checkout: %v", err)
+	}
+	cart := result.Cart
+	if cart.Status != domain.CartStatusCheckedOut || cart.Total != 8000 || cart.Paid != 10000 || cart.Change != 2000 ||
+		cart.ShiftID == nil || cart.Items[0].UnitPrice != 4000 || cart.Items[0].Name != "Indomie Goreng" {
+		t.Errorf("cart = %+v, want 2 x 4000 paid 10000 with 2000 change in the open shift", cart)
+	}
+
+	product, err = repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if product.Stock != 98 {
+		t.Errorf("stock = %d, want 98", product.Stock)
+	}
+	entries, err := repos.Audit.List(domain.AuditFilter{EntityType: domain.AuditEntityCart, EntityID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != domain.AuditActionUpdate || entries[0].Actor != "budi" || entries[0].Changes == nil {
+		t.Errorf("audit entries = %+v, want the checkout by budi", entries)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", body, user); rec.Code != http.StatusConflict {
+		t.Errorf("second checkout status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	rec = serve(mux, http.MethodPost, "/api/shifts/1/close", `{"counted_cash":103000}`, user)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("close shift status = %d", rec.Code)
+	}
+	var report domain.ShiftReport
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.CashSales != 3000 || report.ExpectedCash != 103000 || report.Variance != 0 ||
+		report.PaymentTotals[domain.PaymentQRIS] != 5000 {
+		t.Errorf("report = %+v, want 3000 cash net of change and 5000 qris", report)
+	}
+}
+
+func TestCartHandler_CheckoutRecordsDiscounts(t *testing.T) {
+	mux, repos := newCartMux(t)
+	promotions := []domain.Promotion{
+		{Name: "Diskon Snack", Type: domain.PromotionPercentage, Scope: domain.PromotionScopeCategory, CategoryID: intPtr(1), Value: 10, Active: true},
+		{Name: "Potongan 1000", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeCart, Value: 1000, Active: true},
+	}
+	for i := range promotions {
+		if err := repos.Promotions.Create(&promotions[i]); err != nil {
+			t.Fatalf("seed promotion: %v", err)
+		}
+	}
+
+	// 2 x 3500 less 10% on the line and 1000 off the cart
+	rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":5300}]}`, map[string]string{"X-User": "budi"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/carts/1", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get status = %d", rec.Code)
+	}
+	var cart domain.Cart
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &cart); err != nil {
+		t.Fatalf("decode cart: %v", err)
+	}
+	lineDiscounts := []domain.AppliedDiscount{{PromotionID: promotions[0].ID, Name: "Diskon Snack", Amount: 700}}
+	cartDiscounts := []domain.AppliedDiscount{{PromotionID: promotions[1].ID, Name: "Potongan 1000", Amount: 1000}}
+	if cart.Total != 5300 || len(cart.Items) != 1 || !slices.Equal(cart.Items[0].Discounts, lineDiscounts) ||
+		!slices.Equal(cart.CartDiscounts, cartDiscounts) {
+		t.Errorf("cart = %+v, want the line and cart promotions recorded", cart)
+	}
+}
+
+func TestCartHandler_CheckoutRecordsTaxes(t *testing.T) {
+	mux, repos := newCartMux(t)
+	rate := domain.TaxRate{Name: "PPN", Rate: 11, Inclusive: true}
+	if err := repos.TaxRates.Create(&rate); err != nil {
+		t.Fatalf("seed tax rate: %v", err)
+	}
+	category, err := repos.Categories.GetByID(1)
+	if err != nil {
+		t.Fatalf("get category: %v", err)
+	}
+	category.TaxRateID = &rate.ID
+	if err := repos.Categories.Update(category); err != nil {
+		t.Fatalf("assign tax rate: %v", err)
+	}
+
+	rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":7000}]}`, map[string]string{"X-User": "budi"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	cart, err := repos.Carts.GetByID(1)
+	if err != nil {
+		t.Fatalf("get cart: %v", err)
+	}
+	// 7000 already includes 11% PPN: 7000 * 11 / 111 = 693.7, rounded up
+	want := []domain.TaxSummary{{TaxRateID: rate.ID, Name: "PPN", Rate: 11, Inclusive: true, Taxable: 6306, Tax: 694}}
+	if cart.Tax != 694 || !slices.Equal(cart.Taxes, want) {
+		t.Errorf("tax, taxes = %d, %+v, want 694 PPN summarised as %+v", cart.Tax, cart.Taxes, want)
+	}
+}
+
+func TestCartHandler_CheckoutShortStock(t *testing.T) {
+	mux, repos := newCartMux(t)
+	if err := repos.Products.SetStock(1, domain.DefaultOutletID, 1); err != nil {
+		t.Fatalf("set stock: %v", err)
+	}
+
+	rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":10000}]}`, nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if got := decodeResponse(t, rec).Error; got != "insufficient stock: Indomie Goreng" {
+		t.Errorf("error = %q", got)
+	}
+	cart, err := repos.Carts.GetByID(1)
+	if err != nil {
+		t.Fatalf("get cart: %v", err)
+	}
+	if cart.Status != domain.CartStatusOpen {
+		t.Errorf("status = %q, want cart left open", cart.Status)
+	}
+}
+
+func TestCartHandler_CheckoutInUnits(t *testing.T) {
+	mux, repos := newCartMux(t)
+	dus := domain.Unit{Code: "dus", Name: "Dus"}
+	if err := repos.Units.Create(&dus); err != nil {
+		t.Fatalf("create unit: %v", err)
+	}
+	if err := repos.ProductUnits.ReplaceForProduct(1, []domain.ProductUnit{{UnitID: dus.ID, Factor: 40, Price: 130000}}); err != nil {
+		t.Fatalf("set product units: %v", err)
+	}
+
+	// A dus sells at its own price; pieces keep the base price
+	body := `{"items":[{"product_id":1,"unit_id":2,"quantity":1},{"product_id":1,"quantity":3},{"product_id":1,"unit_id":2,"quantity":1}]}`
+	rec := serve(mux, http.MethodPost, "/api/carts", body, map[string]string{"X-User": "budi"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create cart status = %d, body %s", rec.Code, rec.Body)
+	}
+	rec = serve(mux, http.MethodPost, "/api/carts", `{"items":[{"product_id":1,"unit_id":99,"quantity":1}]}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown unit status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	product.CostPrice = 2800
+	if err := repos.Products.Update(product); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+
+	rec = serve(mux, http.MethodPost, "/api/carts/3/checkout", `{"payments":[{"method":"cash","amount":300000}]}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d, body %s", rec.Code, rec.Body)
+	}
+	var result domain.CheckoutResult
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &result); err != nil {
+		t.Fatalf("decode checkout: %v", err)
+	}
+	cart := result.Cart
+	if cart.Total != 270500 || len(cart.Items) != 2 {
+		t.Fatalf("cart = %+v, want 2 dus at 130000 and 3 pieces at 3500", cart)
+	}
+	if item := cart.Items[0]; item.UnitID != dus.ID || item.Quantity != 2 || item.UnitPrice != 130000 || item.CostPrice != 112000 {
+		t.Errorf("dus line = %+v, want 2 x 130000 costing 40 x 2800 each", item)
+	}
+	if item := cart.Items[1]; item.CostPrice != 2800 {
+		t.Errorf("piece line = %+v, want a cost of 2800", item)
+	}
+
+	product, err = repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if product.Stock != 17 {
+		t.Errorf("stock = %d, want 17 pieces left after selling 83", product.Stock)
+	}
+}
+
+func TestCartHandler_CheckoutBundle(t *testing.T) {
+	mux, repos := newCartMux(t)
+	syrup := domain.Product{Name: "Sirup Marjan", Type: domain.ProductTypeStandard, Price: 20000, CostPrice: 15000, CategoryID: 1, UnitID: domain.DefaultUnitID}
+	parcel := domain.Product{Name: "Paket Lebaran", Type: domain.ProductTypeBundle, Price: 90000, CostPrice: 1, CategoryID: 1, UnitID: domain.DefaultUnitID}
+	indomie, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	indomie.CostPrice = 2800
+	if err := repos.Products.Update(indomie); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+	for _, p := range []*domain.Product{&syrup, &parcel} {
+		if err := repos.Products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	if err := repos.Products.SetStock(syrup.ID, domain.DefaultOutletID, 7); err != nil {
+		t.Fatalf("set stock: %v", err)
+	}
+	components := []domain.BundleComponent{{ProductID: 1, Quantity: 10}, {ProductID: syrup.ID, Quantity: 2}}
+	if err := repos.ProductComponents.ReplaceForBundle(parcel.ID, components); err != nil {
+		t.Fatalf("set components: %v", err)
+	}
+
+	stock := func(id int) int {
+		t.Helper()
+		got, err := repos.Products.GetStock(id, domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("get stock: %v", err)
+		}
+		return got
+	}
+	checkout := func(quantity int) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"items":[{"product_id":` + strconv.Itoa(parcel.ID) + `,"quantity":` + strconv.Itoa(quantity) + `}]}`
+		rec := serve(mux, http.MethodPost, "/api/carts", body, map[string]string{"X-User": "budi"})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create cart status = %d, body %s", rec.Code, rec.Body)
+		}
+		var cart domain.Cart
+		if err := json.Unmarshal(decodeResponse(t, rec).Data, &cart); err != nil {
+			t.Fatalf("decode cart: %v", err)
+		}
+		return serve(mux, http.MethodPost, "/api/carts/"+strconv.Itoa(cart.ID)+"/checkout",
+			`{"payments":[{"method":"cash","amount":200000}]}`, nil)
+	}
+
+	// Two parcels take 20 of product 1 and 4 syrups, and none of the bundle
+	// itself, each costing what its components cost
+	rec := checkout(2)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d, body %s", rec.Code, rec.Body)
+	}
+	var result domain.CheckoutResult
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &result); err != nil {
+		t.Fatalf("decode checkout: %v", err)
+	}
+	if cost := result.Cart.Items[0].CostPrice; cost != 58000 {
+		t.Errorf("parcel cost = %d, want 10 x 2800 + 2 x 15000", cost)
+	}
+	if got1, got2 := stock(1), stock(syrup.ID); got1 != 80 || got2 != 3 {
+		t.Errorf("component stock = %d, %d, want 80, 3", got1, got2)
+	}
+	for _, want := range []domain.StockMovement{{ProductID: 1, Quantity: -20}, {ProductID: syrup.ID, Quantity: -4}} {
+		movements, err := repos.StockMovements.GetByProductID(want.ProductID, 0)
+		if err != nil {
+			t.Fatalf("get stock movements: %v", err)
+		}
+		if len(movements) != 1 || movements[0].Quantity != want.Quantity || movements[0].Reason != domain.StockMovementSale ||
+			movements[0].Reference != "CART-3" || movements[0].Actor != "budi" {
+			t.Errorf("product %d movements = %+v, want a %d sale for CART-3 by budi", want.ProductID, movements, want.Quantity)
+		}
+	}
+	if movements, _ := repos.StockMovements.GetByProductID(parcel.ID, 0); len(movements) != 0 {
+		t.Errorf("bundle movements = %+v, want none", movements)
+	}
+
+	// Two more need 4 syrups with only 3 left, so nothing is taken
+	rec = checkout(2)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("short checkout status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if got := decodeResponse(t, rec).Error; got != "insufficient stock: Sirup Marjan" {
+		t.Errorf("error = %q", got)
+	}
+	if got1, got2 := stock(1), stock(syrup.ID); got1 != 80 || got2 != 3 {
+		t.Errorf("component stock after short checkout = %d, %d, want 80, 3", got1, got2)
+	}
+}
+
+func TestCartHandler_ReserveStock(t *testing.T) {
+	mux, repos := newCartMux(t)
+	if err := repos.Products.SetStock(1, domain.DefaultOutletID, 10); err != nil {
+		t.Fatalf("set stock: %v", err)
+	}
+
+	rec := serve(mux, http.MethodPost, "/api/carts", `{"reserve_stock":true,"items":[{"product_id":1,"quantity":8}]}`, map[string]string{"X-User": "budi"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create reserving cart status = %d", rec.Code)
+	}
+	var cart domain.Cart
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &cart); err != nil {
+		t.Fatalf("decode cart: %v", err)
+	}
+
+	products := service.NewProductService(repos.Products, repos.Categories, repos.PriceHistory, repos.TaxRates,
+		repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), memory.NewTransactor(repos))
+	got, err := products.GetByID(1, 0)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if got.Reserved != 8 || got.Available != 2 {
+		t.Errorf("reserved, available = %d, %d, want 8, 2", got.Reserved, got.Available)
+	}
+
+	// Cart 1 does not reserve, but may still take what is left unreserved
+	rec = serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":10000}]}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout unreserved cart status = %d", rec.Code)
+	}
+
+	rec = serve(mux, http.MethodPost, "/api/carts", `{"reserve_stock":true,"items":[{"product_id":1,"quantity":1}]}`, nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second reserving cart status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if got := decodeResponse(t, rec).Error; got != "insufficient stock: Indomie Goreng" {
+		t.Errorf("error = %q", got)
+	}
+
+	// The reserving cart can check out its own reservation and releases it
+	rec = serve(mux, http.MethodPost, "/api/carts/3/checkout", `{"payments":[{"method":"cash","amount":30000}]}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout reserving cart status = %d", rec.Code)
+	}
+	reserved, err := repos.Reservations.ReservedTotals(time.Now(), domain.DefaultOutletID, 0)
+	if err != nil {
+		t.Fatalf("reserved totals: %v", err)
+	}
+	if len(reserved) != 0 {
+		t.Errorf("reserved after checkout = %v, want none", reserved)
+	}
+}
+
+func TestCartHandler_CheckoutLoyalty(t *testing.T) {
+	mux, repos := newCartMux(t)
+	customer := domain.Customer{Name: "Siti Aminah"}
+	if err := repos.Customers.Create(&customer); err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	if err := repos.Customers.AddPoints(customer.ID, 20); err != nil {
+		t.Fatalf("add points: %v", err)
+	}
+
+	failures := []struct {
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{`{"payments":[{"method":"cash","amount":7000}],"redeem_points":5}`, http.StatusBadRequest, "invalid input: redeeming points needs a customer"},
+		{`{"payments":[{"method":"cash","amount":7000}],"customer_id":99}`, http.StatusBadRequest, "Customer not found"},
+		{`{"payments":[{"method":"cash","amount":7000}],"customer_id":1,"redeem_points":21}`, http.StatusConflict, "insufficient loyalty points: Siti Aminah has 20"},
+		{`{"payments":[{"method":"cash","amount":7000}],"customer_id":1,"redeem_points":-1}`, http.StatusBadRequest, "invalid input: redeem_points cannot be negative"},
+	}
+	for _, f := range failures {
+		rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", f.body, nil)
+		if rec.Code != f.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", f.body, rec.Code, f.wantStatus)
+		}
+		if got := decodeResponse(t, rec).Error; got != f.wantError {
+			t.Errorf("%s: error = %q, want %q", f.body, got, f.wantError)
+		}
+	}
+
+	// 2 x 3500 less 10 points worth 100 each leaves 6000, earning 6 points
+	body := `{"payments":[{"method":"cash","amount":6000}],"customer_id":1,"redeem_points":10}`
+	rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result domain.CheckoutResult
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &result); err != nil {
+		t.Fatalf("decode checkout: %v", err)
+	}
+	cart := result.Cart
+	if cart.Total != 6000 || cart.PointsRedeemed != 10 || cart.PointsDiscount != 1000 || cart.PointsEarned != 6 ||
+		cart.CustomerID == nil || *cart.CustomerID != customer.ID {
+		t.Errorf("cart = %+v, want 6000 due after redeeming 10 points and 6 earned", cart)
+	}
+
+	got, err := repos.Customers.GetByID(customer.ID)
+	if err != nil {
+		t.Fatalf("get customer: %v", err)
+	}
+	if got.Points != 16 {
+		t.Errorf("points = %d, want 16", got.Points)
+	}
+	history, err := repos.Carts.GetByCustomer(customer.ID)
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != cart.ID {
+		t.Errorf("history = %+v, want the checked out cart", history)
+	}
+}
+
+func TestCartHandler_CashNeedsOpenShift(t *testing.T) {
+	mux, _ := newCartMux(t)
+
+	// Nobody owns cart 3 and siti has no shift, so cash has nowhere to go
+	if rec := serve(mux, http.MethodPost, "/api/carts", `{"items":[{"product_id":1,"quantity":1}]}`, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create cart status = %d", rec.Code)
+	}
+	cash := `{"payments":[{"method":"cash","amount":5000}]}`
+	for _, user := range []map[string]string{nil, {"X-User": "siti"}} {
+		rec := serve(mux, http.MethodPost, "/api/carts/3/checkout", cash, user)
+		if got := decodeResponse(t, rec).Error; rec.Code != http.StatusConflict || got != "cash payments need an open shift" {
+			t.Errorf("cash checkout as %v = %d %q, want 409", user, rec.Code, got)
+		}
+	}
+
+	rec := serve(mux, http.MethodPost, "/api/carts/3/checkout", `{"payments":[{"method":"qris","amount":3500}]}`, map[string]string{"X-User": "siti"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("qris checkout status = %d, body %s", rec.Code, rec.Body)
+	}
+	var result domain.CheckoutResult
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &result); err != nil {
+		t.Fatalf("decode checkout: %v", err)
+	}
+	if result.Cart.ShiftID != nil {
+		t.Errorf("shift = %v, want none for a cashier without a shift", *result.Cart.ShiftID)
+	}
+}
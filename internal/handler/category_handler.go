@@ -53,15 +53,44 @@ func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, categories)
 }
 
+// GetTree godoc
+// @Summary      Get the category tree
+// @Description  Retrieve the top-level categories with their subcategories nested under children
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.Category
+// @Failure      405  {string}  string  "Method not allowed"
+// @Failure      500  {string}  string  "Failed to fetch categories"
+// @Router       /categories/tree [get]
+func (h *CategoryHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	tree, err := h.service.GetTree()
+	if err != nil {
+		log.Println("Error fetching category tree:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch categories")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, tree)
+}
+
 // Create godoc
 // @Summary      Create a new category
-// @Description  Create a new category with the provided data
+// @Description  Create a new category with the provided data, optionally as a subcategory of parent_id
 // @Tags         categories
 // @Accept       json
 // @Produce      json
-// @Param        category  body      domain.CategoryInput  true  "Category data"
+// @Param        category  body      domain.CategoryInput  true   "Category data"
+// @Param        X-User    header    string                false  "User making the change, recorded in the audit log"
 // @Success      201       {object}  domain.Category
 // @Failure      400       {string}  string  "Invalid request body"
+// @Failure      400       {string}  string  "Tax rate not found"
+// @Failure      400       {string}  string  "Parent category not found"
 // @Router       /categories [post]
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var category domain.Category
@@ -70,9 +99,9 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Create(&category); err != nil {
+	if err := h.service.Create(&category, changeMetaFromRequest(r)); err != nil {
 		log.Println("Error creating category:", err)
-		WriteError(w, http.StatusInternalServerError, "Failed to create category")
+		writeCategoryError(w, err, "Failed to create category")
 		return
 	}
 
@@ -127,14 +156,18 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary      Update a category
-// @Description  Update an existing category by its ID
+// @Description  Update an existing category by its ID. A category cannot be moved under itself or one of its subcategories.
 // @Tags         categories
 // @Accept       json
 // @Produce      json
-// @Param        id        path      int                   true  "Category ID"
-// @Param        category  body      domain.CategoryInput  true  "Category data"
+// @Param        id        path      int                   true   "Category ID"
+// @Param        category  body      domain.CategoryInput  true   "Category data"
+// @Param        X-User    header    string                false  "User making the change, recorded in the audit log"
 // @Success      200       {object}  domain.Category
 // @Failure      400       {string}  string  "Invalid category ID or request body"
+// @Failure      400       {string}  string  "Tax rate not found"
+// @Failure      400       {string}  string  "Parent category not found"
+// @Failure      404       {string}  string  "Category not found"
 // @Router       /categories/{id} [put]
 func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := parseIDFromPath(r.URL.Path, "/api/categories/")
@@ -150,13 +183,9 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	category.ID = id
-	if err := h.service.Update(&category); err != nil {
+	if err := h.service.Update(&category, changeMetaFromRequest(r)); err != nil {
 		log.Println("Error updating category:", err)
-		if errors.Is(err, apperrors.ErrNotFound) {
-			WriteError(w, http.StatusNotFound, "Category not found")
-			return
-		}
-		WriteError(w, http.StatusInternalServerError, "Failed to update category")
+		writeCategoryError(w, err, "Failed to update category")
 		return
 	}
 
@@ -165,14 +194,16 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary      Delete a category
-// @Description  Delete a category by its ID
+// @Description  Delete a category by its ID. Categories with subcategories or products cannot be deleted.
 // @Tags         categories
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Category ID"
+// @Param        id      path      int     true   "Category ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
 // @Success      200  {object}  handler.APIResponse  "Category deleted successfully"
 // @Failure      400  {string}  string  "Invalid category ID"
 // @Failure      404  {string}  string  "Category not found"
+// @Failure      409  {string}  string  "Category is in use"
 // @Failure      500  {string}  string  "Failed to delete category"
 // @Router       /categories/{id} [delete]
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -182,15 +213,28 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
 		log.Println("Error deleting category:", err)
-		if errors.Is(err, apperrors.ErrNotFound) {
-			WriteError(w, http.StatusNotFound, "Category not found")
-			return
-		}
-		WriteError(w, http.StatusInternalServerError, "Failed to delete category")
+		writeCategoryError(w, err, "Failed to delete category")
 		return
 	}
 
 	WriteJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully"})
 }
+
+func writeCategoryError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Category not found")
+	case errors.Is(err, apperrors.ErrCategoryNotFound):
+		WriteError(w, http.StatusBadRequest, "Parent category not found")
+	case errors.Is(err, apperrors.ErrTaxRateNotFound):
+		WriteError(w, http.StatusBadRequest, "Tax rate not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, "Category is in use")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
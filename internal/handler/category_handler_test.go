@@ -0,0 +1,198 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+func newCategoryHandler(t *testing.T) *handler.CategoryHandler {
+	t.Helper()
+	repos := newRepos(t)
+	return handler.NewCategoryHandler(service.NewCategoryService(repos.Categories, repos.TaxRates, memory.NewTransactor(repos)))
+}
+
+func TestCategoryHandler_HandleCategories(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, body: `{"name":"Minuman","description":"Drinks"}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create with unknown tax rate", method: http.MethodPost, body: `{"name":"Minuman","tax_rate_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Tax rate not found"},
+		{name: "create subcategory", method: http.MethodPost, body: `{"name":"Keripik","parent_id":1}`, wantStatus: http.StatusCreated},
+		{name: "create with unknown parent", method: http.MethodPost, body: `{"name":"Keripik","parent_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Parent category not found"},
+		{name: "method not allowed", method: http.MethodPut, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newCategoryHandler(t)
+			req := httptest.NewRequest(tt.method, "/api/categories", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleCategories(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCategoryHandler_CreateAssignsID(t *testing.T) {
+	h := newCategoryHandler(t)
+	rec := httptest.NewRecorder()
+
+	h.HandleCategories(rec, httptest.NewRequest(http.MethodPost, "/api/categories", strings.NewReader(`{"name":"Minuman"}`)))
+
+	var category domain.Category
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &category); err != nil {
+		t.Fatalf("decode category: %v", err)
+	}
+	if category.ID != 2 || category.Name != "Minuman" {
+		t.Errorf("category = %+v, want id 2 named Minuman", category)
+	}
+}
+
+func TestCategoryHandler_HandleCategoryByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/categories/1", wantStatus: http.StatusOK},
+		{name: "get with invalid id", method: http.MethodGet, path: "/api/categories/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid category ID"},
+		{name: "get missing", method: http.MethodGet, path: "/api/categories/99", wantStatus: http.StatusNotFound, wantError: "Category not found"},
+		{name: "update", method: http.MethodPut, path: "/api/categories/1", body: `{"name":"Snack"}`, wantStatus: http.StatusOK},
+		{name: "update with invalid id", method: http.MethodPut, path: "/api/categories/abc", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "Invalid category ID"},
+		{name: "update with malformed body", method: http.MethodPut, path: "/api/categories/1", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "update with unknown tax rate", method: http.MethodPut, path: "/api/categories/1", body: `{"name":"Snack","tax_rate_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Tax rate not found"},
+		{name: "update missing", method: http.MethodPut, path: "/api/categories/99", body: `{"name":"Snack"}`, wantStatus: http.StatusNotFound, wantError: "Category not found"},
+		{name: "update with unknown parent", method: http.MethodPut, path: "/api/categories/1", body: `{"name":"Snack","parent_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Parent category not found"},
+		{name: "update to own parent", method: http.MethodPut, path: "/api/categories/1", body: `{"name":"Snack","parent_id":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: a category cannot be its own parent"},
+		{name: "delete", method: http.MethodDelete, path: "/api/categories/1", wantStatus: http.StatusOK},
+		{name: "delete with invalid id", method: http.MethodDelete, path: "/api/categories/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid category ID"},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/categories/99", wantStatus: http.StatusNotFound, wantError: "Category not found"},
+		{name: "method not allowed", method: http.MethodPost, path: "/api/categories/1", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newCategoryHandler(t)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleCategoryByID(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCategoryHandler_ParentCheckStopsAtACycle(t *testing.T) {
+	repos := newRepos(t)
+	h := handler.NewCategoryHandler(service.NewCategoryService(repos.Categories, repos.TaxRates, memory.NewTransactor(repos)))
+	rec := httptest.NewRecorder()
+	h.HandleCategories(rec, httptest.NewRequest(http.MethodPost, "/api/categories", strings.NewReader(`{"name":"Keripik","parent_id":1}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create subcategory: status = %d, want 201", rec.Code)
+	}
+
+	// A cycle already in the table must not hang the walk up the ancestors
+	root, err := repos.Categories.GetByID(1)
+	if err != nil {
+		t.Fatalf("get category: %v", err)
+	}
+	root.ParentID = new(int)
+	*root.ParentID = 2
+	if err := repos.Categories.Update(root); err != nil {
+		t.Fatalf("update category: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	h.HandleCategories(rec, httptest.NewRequest(http.MethodPost, "/api/categories", strings.NewReader(`{"name":"Keripik Kentang","parent_id":2}`)))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("create under a cycle: status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCategoryHandler_Tree(t *testing.T) {
+	h := newCategoryHandler(t)
+	for _, body := range []string{
+		`{"name":"Keripik","parent_id":1}`,
+		`{"name":"Keripik Kentang","parent_id":2}`,
+		`{"name":"Minuman"}`,
+	} {
+		rec := httptest.NewRecorder()
+		h.HandleCategories(rec, httptest.NewRequest(http.MethodPost, "/api/categories", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create %s: status = %d, want 201", body, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleCategoryByID(rec, httptest.NewRequest(http.MethodPut, "/api/categories/1",
+		strings.NewReader(`{"name":"Makanan Ringan","parent_id":3}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("move under own subcategory: status = %d, want 400", rec.Code)
+	}
+	if got := decodeResponse(t, rec).Error; got != "invalid input: a category cannot be moved under its own subcategory" {
+		t.Errorf("move under own subcategory: error = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleCategoryByID(rec, httptest.NewRequest(http.MethodDelete, "/api/categories/2", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("delete with subcategory: status = %d, want 409", rec.Code)
+	}
+	if got := decodeResponse(t, rec).Error; got != "Category is in use" {
+		t.Errorf("delete with subcategory: error = %q, want %q", got, "Category is in use")
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetTree(rec, httptest.NewRequest(http.MethodGet, "/api/categories/tree", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("tree: status = %d, want 200", rec.Code)
+	}
+	var tree []domain.Category
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &tree); err != nil {
+		t.Fatalf("decode tree: %v", err)
+	}
+	if len(tree) != 2 || tree[0].ID != 1 || tree[1].ID != 4 || len(tree[1].Children) != 0 {
+		t.Fatalf("tree = %+v, want categories 1 and 4 at the top", tree)
+	}
+	if len(tree[0].Children) != 1 || tree[0].Children[0].ID != 2 ||
+		len(tree[0].Children[0].Children) != 1 || tree[0].Children[0].Children[0].ID != 3 {
+		t.Errorf("children of 1 = %+v, want 2 with child 3", tree[0].Children)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetTree(rec, httptest.NewRequest(http.MethodPost, "/api/categories/tree", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("tree POST: status = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// CustomerHandler handles HTTP requests for customers
+type CustomerHandler struct {
+	service *service.CustomerService
+}
+
+// NewCustomerHandler creates a new customer handler
+func NewCustomerHandler(service *service.CustomerService) *CustomerHandler {
+	return &CustomerHandler{service: service}
+}
+
+// HandleCustomers handles GET and POST requests for /api/customers
+func (h *CustomerHandler) HandleCustomers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all customers
+// @Description  Retrieve a list of all customers with their loyalty points
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.Customer
+// @Failure      500  {string}  string  "Failed to fetch customers"
+// @Router       /customers [get]
+func (h *CustomerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	customers, err := h.service.GetAll()
+	if err != nil {
+		log.Println("Error fetching customers:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch customers")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, customers)
+}
+
+// Create godoc
+// @Summary      Create a new customer
+// @Description  Register a customer, optionally with a unique member code. New customers start with no points.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        customer  body      domain.CustomerInput  true   "Customer data"
+// @Param        X-User    header    string                false  "User making the change, recorded in the audit log"
+// @Success      201       {object}  domain.Customer
+// @Failure      400       {string}  string  "Invalid request body"
+// @Failure      409       {string}  string  "Member code already in use"
+// @Router       /customers [post]
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.CustomerInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	customer := customerFromInput(input)
+	if err := h.service.Create(&customer, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error creating customer:", err)
+		writeCustomerError(w, err, "Failed to create customer")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, customer)
+}
+
+// HandleCustomerByID handles GET, PUT, DELETE requests for /api/customers/{id}
+func (h *CustomerHandler) HandleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get customer by ID
+// @Description  Retrieve a single customer by its ID
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Customer ID"
+// @Success      200  {object}  domain.Customer
+// @Failure      400  {string}  string  "Invalid customer ID"
+// @Failure      404  {string}  string  "Customer not found"
+// @Router       /customers/{id} [get]
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/customers/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	customer, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching customer by ID:", err)
+		writeCustomerError(w, err, "Failed to fetch customer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, customer)
+}
+
+// Update godoc
+// @Summary      Update a customer
+// @Description  Update a customer's details by its ID. Points can only change through checkout.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                   true   "Customer ID"
+// @Param        customer  body      domain.CustomerInput  true   "Customer data"
+// @Param        X-User    header    string                false  "User making the change, recorded in the audit log"
+// @Success      200       {object}  domain.Customer
+// @Failure      400       {string}  string  "Invalid customer ID or request body"
+// @Failure      404       {string}  string  "Customer not found"
+// @Failure      409       {string}  string  "Member code already in use"
+// @Router       /customers/{id} [put]
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/customers/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	var input domain.CustomerInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	customer := customerFromInput(input)
+	customer.ID = id
+	if err := h.service.Update(&customer, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error updating customer:", err)
+		writeCustomerError(w, err, "Failed to update customer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, customer)
+}
+
+// Delete godoc
+// @Summary      Delete a customer
+// @Description  Delete a customer by its ID. Their past sales are kept without a customer.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Customer ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Customer deleted successfully"
+// @Failure      400  {string}  string  "Invalid customer ID"
+// @Failure      404  {string}  string  "Customer not found"
+// @Router       /customers/{id} [delete]
+func (h *CustomerHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/customers/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting customer:", err)
+		writeCustomerError(w, err, "Failed to delete customer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Customer deleted successfully"})
+}
+
+// HandleTransactions handles GET requests for /api/customers/{id}/transactions
+func (h *CustomerHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetTransactions(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetTransactions godoc
+// @Summary      Get customer purchase history
+// @Description  Retrieve the carts a customer checked out, newest first
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Customer ID"
+// @Success      200  {array}   domain.Cart
+// @Failure      400  {string}  string  "Invalid customer ID"
+// @Failure      404  {string}  string  "Customer not found"
+// @Failure      500  {string}  string  "Failed to fetch transactions"
+// @Router       /customers/{id}/transactions [get]
+func (h *CustomerHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	carts, err := h.service.GetTransactions(id)
+	if err != nil {
+		log.Println("Error fetching customer transactions:", err)
+		writeCustomerError(w, err, "Failed to fetch transactions")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, carts)
+}
+
+func customerFromInput(input domain.CustomerInput) domain.Customer {
+	return domain.Customer{
+		Name:       input.Name,
+		Phone:      input.Phone,
+		Email:      input.Email,
+		MemberCode: input.MemberCode,
+	}
+}
+
+func writeCustomerError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Customer not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, "Member code already in use")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newCustomerMux wires the customer routes the same way router.New does.
+// Customer 1 has member code MBR-0001 and one checked out cart.
+func newCustomerMux(t *testing.T) http.Handler {
+	t.Helper()
+	repos := newRepos(t)
+	customer := domain.Customer{Name: "Siti Aminah", Phone: "081234567890", MemberCode: "MBR-0001"}
+	if err := repos.Customers.Create(&customer); err != nil {
+		t.Fatalf("seed customer: %v", err)
+	}
+	checkedOut := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	cart := domain.Cart{Status: domain.CartStatusCheckedOut, CustomerID: &customer.ID, Total: 7000, CheckedOutAt: &checkedOut}
+	if err := repos.Carts.Create(&cart); err != nil {
+		t.Fatalf("seed cart: %v", err)
+	}
+
+	customers := handler.NewCustomerHandler(service.NewCustomerService(repos.Customers, repos.Carts,
+		memory.NewTransactor(repos)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/customers", customers.HandleCustomers)
+	mux.HandleFunc("/api/customers/", customers.HandleCustomerByID)
+	mux.HandleFunc("/api/customers/{id}/transactions", customers.HandleTransactions)
+	return mux
+}
+
+func TestCustomerHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/customers", wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/api/customers", body: `{"name":"Budi Santoso","phone":"0811","member_code":"MBR-0002"}`, wantStatus: http.StatusCreated},
+		{name: "create without member code", method: http.MethodPost, path: "/api/customers", body: `{"name":"Budi Santoso"}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/customers", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create without name", method: http.MethodPost, path: "/api/customers", body: `{"phone":"0811"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: name is required"},
+		{name: "create with invalid email", method: http.MethodPost, path: "/api/customers", body: `{"name":"Budi","email":"budi"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: email is not valid"},
+		{name: "create with taken member code", method: http.MethodPost, path: "/api/customers", body: `{"name":"Budi","member_code":"MBR-0001"}`, wantStatus: http.StatusConflict, wantError: "Member code already in use"},
+		{name: "customers method not allowed", method: http.MethodDelete, path: "/api/customers", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/customers/1", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/customers/99", wantStatus: http.StatusNotFound, wantError: "Customer not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/customers/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid customer ID"},
+		{name: "update", method: http.MethodPut, path: "/api/customers/1", body: `{"name":"Siti A.","member_code":"MBR-0001"}`, wantStatus: http.StatusOK},
+		{name: "update missing", method: http.MethodPut, path: "/api/customers/99", body: `{"name":"Ghost"}`, wantStatus: http.StatusNotFound, wantError: "Customer not found"},
+		{name: "delete", method: http.MethodDelete, path: "/api/customers/1", wantStatus: http.StatusOK},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/customers/99", wantStatus: http.StatusNotFound, wantError: "Customer not found"},
+		{name: "transactions", method: http.MethodGet, path: "/api/customers/1/transactions", wantStatus: http.StatusOK},
+		{name: "transactions of missing customer", method: http.MethodGet, path: "/api/customers/99/transactions", wantStatus: http.StatusNotFound, wantError: "Customer not found"},
+		{name: "transactions invalid id", method: http.MethodGet, path: "/api/customers/abc/transactions", wantStatus: http.StatusBadRequest, wantError: "Invalid customer ID"},
+		{name: "transactions method not allowed", method: http.MethodPost, path: "/api/customers/1/transactions", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newCustomerMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCustomerHandler_Transactions(t *testing.T) {
+	mux := newCustomerMux(t)
+
+	rec := serve(mux, http.MethodGet, "/api/customers/1/transactions", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var carts []domain.Cart
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &carts); err != nil {
+		t.Fatalf("decode carts: %v", err)
+	}
+	if len(carts) != 1 || carts[0].Total != 7000 {
+		t.Errorf("transactions = %+v, want the seeded sale", carts)
+	}
+}
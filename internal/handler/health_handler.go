@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"database/sql"
 	"net/http"
 )
 
@@ -11,13 +10,18 @@ type healthStatus struct {
 	Database string `json:"database" example:"connected"`
 }
 
+// Pinger reports database connectivity; *sql.DB satisfies it
+type Pinger interface {
+	Ping() error
+}
+
 // HealthHandler handles health check requests
 type HealthHandler struct {
-	db *sql.DB
+	db Pinger
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(db *sql.DB) *HealthHandler {
+func NewHealthHandler(db Pinger) *HealthHandler {
 	return &HealthHandler{db: db}
 }
 
@@ -0,0 +1,49 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kasir-api/internal/handler"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) Ping() error {
+	return p.err
+}
+
+func TestHealthHandler_Health(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		pingErr    error
+		wantStatus int
+		wantError  string
+	}{
+		{name: "healthy", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "database down", method: http.MethodGet, pingErr: errors.New("connection refused"), wantStatus: http.StatusServiceUnavailable, wantError: "Database connection failed"},
+		{name: "method not allowed", method: http.MethodPost, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewHealthHandler(fakePinger{err: tt.pingErr})
+			rec := httptest.NewRecorder()
+
+			h.Health(rec, httptest.NewRequest(tt.method, "/api/health", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"net/http"
 	"strconv"
 	"strings"
+
+	"kasir-api/internal/domain"
 )
 
 // parseIDFromPath extracts numeric ID from URL path
@@ -11,3 +14,40 @@ func parseIDFromPath(path, prefix string) (int, error) {
 	idStr := strings.TrimPrefix(path, prefix)
 	return strconv.Atoi(idStr)
 }
+
+// changeMetaFromRequest attributes a change to the user named in the X-User
+// header and the request ID set by the RequestID middleware.
+// The API has no authentication yet, so the header is trusted as-is.
+func changeMetaFromRequest(r *http.Request) domain.ChangeMeta {
+	return domain.ChangeMeta{
+		Actor:     strings.TrimSpace(r.Header.Get("X-User")),
+		RequestID: requestIDFromContext(r.Context()),
+	}
+}
+
+// outletIDFromQuery reads the outlet_id query parameter that selects which
+// outlet's stock a request reads or changes. It returns zero when the
+// parameter is absent.
+func outletIDFromQuery(r *http.Request) (int, error) {
+	return optionalIDFromQuery(r, "outlet_id")
+}
+
+// unitIDFromQuery reads the unit_id query parameter, returning zero when it
+// is absent
+func unitIDFromQuery(r *http.Request) (int, error) {
+	return optionalIDFromQuery(r, "unit_id")
+}
+
+// optionalIDFromQuery reads a positive ID from a query parameter, returning
+// zero when the parameter is absent
+func optionalIDFromQuery(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return id, nil
+}
@@ -0,0 +1,56 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/storage"
+)
+
+// testResponse mirrors handler.APIResponse with a raw payload so each test
+// can decode Data into the type it expects
+type testResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder) testResponse {
+	t.Helper()
+	var resp testResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+// newRepos returns in-memory repositories seeded with one category and one
+// product stocked at the default outlet so every test starts from the same
+// fixture
+func newRepos(t *testing.T) repository.Repositories {
+	t.Helper()
+	repos := memory.NewRepositories()
+
+	category := domain.Category{Name: "Makanan Ringan", Description: "Snacks"}
+	if err := repos.Categories.Create(&category); err != nil {
+		t.Fatalf("seed category: %v", err)
+	}
+	product := domain.Product{Name: "Indomie Goreng", Type: domain.ProductTypeStandard, Price: 3500, CategoryID: category.ID, UnitID: domain.DefaultUnitID}
+	if err := repos.Products.Create(&product); err != nil {
+		t.Fatalf("seed product: %v", err)
+	}
+	if err := repos.Products.SetStock(product.ID, domain.DefaultOutletID, 100); err != nil {
+		t.Fatalf("seed stock: %v", err)
+	}
+	return repos
+}
+
+// newImageStore returns image storage in a directory removed after the test
+func newImageStore(t *testing.T) *storage.Local {
+	t.Helper()
+	return storage.NewLocal(t.TempDir(), "/uploads")
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/service"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// maxRequestIDLength matches the audit_log.request_id column
+const maxRequestIDLength = 64
+
+// RequestID tags every request with an ID, reusing a client-supplied
+// X-Request-ID when present, and echoes it back in the response header
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+// maxIdempotencyKeyLength matches the idempotency_keys.key column
+const maxIdempotencyKeyLength = 255
+
+// idempotentBodyHeadroom is the room a keyed request's body gets above the
+// largest image upload, for the multipart framing around it
+const idempotentBodyHeadroom = 1 << 20
+
+// IdempotentBodyLimit caps the body of a keyed request, which is read into
+// memory to be hashed, at an image upload of imageMaxBytes plus its
+// multipart framing, so any upload the image handler accepts can be keyed
+func IdempotentBodyLimit(imageMaxBytes int) int64 {
+	return int64(imageMaxBytes) + idempotentBodyHeadroom
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry. The first response for a key and route is stored and replayed to
+// retries with the same body; reusing the key with a different body, or
+// retrying while the first request is still running, is a 409. Server errors
+// are not stored, and neither is anything when the handler panics, so the
+// request can be retried for real. Neither are 401 and 403, which depend on
+// who is asking rather than on the body, so a retry with the right
+// credentials runs. Keyed bodies over maxBodyBytes are a 413.
+func Idempotency(svc *service.IdempotencyService, maxBodyBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			WriteError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				WriteError(w, http.StatusRequestEntityTooLarge, "Request body is too large")
+				return
+			}
+			WriteError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+		route := r.Method + " " + r.URL.Path
+
+		record, err := svc.Begin(key, route, hex.EncodeToString(hash[:]))
+		if err != nil {
+			switch {
+			case errors.Is(err, apperrors.ErrIdempotencyKeyReused):
+				WriteError(w, http.StatusConflict, "Idempotency-Key was already used with a different request")
+			case errors.Is(err, apperrors.ErrIdempotencyInProgress):
+				WriteError(w, http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+			default:
+				log.Println("Error checking idempotency key:", err)
+				WriteError(w, http.StatusInternalServerError, "Failed to check idempotency key")
+			}
+			return
+		}
+		if record != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.Body)
+			return
+		}
+
+		// Release the key unless a response is stored, including when next
+		// panics, so retries are not refused as in progress until it expires
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+			if err := svc.Abort(key, route); err != nil {
+				log.Println("Error releasing idempotency key:", err)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		switch {
+		case rec.status == 0, rec.status >= http.StatusInternalServerError,
+			rec.status == http.StatusUnauthorized, rec.status == http.StatusForbidden:
+			return
+		}
+		if err := svc.Complete(key, route, rec.status, rec.body.Bytes()); err != nil {
+			log.Println("Error storing idempotent response:", err)
+			return
+		}
+		stored = true
+	})
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// RequireAdminToken only lets requests through whose X-Admin-Token header
+// matches token. With no token configured the wrapped routes are disabled.
+func RequireAdminToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return requireToken("X-Admin-Token", "Admin", token, next)
+}
+
+// RequireSupervisorToken only lets requests through whose X-Supervisor-Token
+// header matches token. With no token configured the wrapped routes are
+// disabled.
+func RequireSupervisorToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return requireToken("X-Supervisor-Token", "Supervisor", token, next)
+}
+
+// requireToken checks header against token for the role named in errors
+func requireToken(header, role, token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			WriteError(w, http.StatusForbidden, role+" access is not configured")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(header)), []byte(token)) != 1 {
+			WriteError(w, http.StatusUnauthorized, "Invalid "+strings.ToLower(role)+" token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
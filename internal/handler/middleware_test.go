@@ -0,0 +1,164 @@
+package handler_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	repos := newRepos(t)
+	mux := http.NewServeMux()
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), memory.NewTransactor(repos)))
+	mux.HandleFunc("/api/products", products.HandleProducts)
+	h := handler.Idempotency(service.NewIdempotencyService(repos.Idempotency, time.Hour), 1<<20, mux)
+
+	body := `{"name":"Chitato","price":10000,"stock":10,"category_id":1}`
+	key := map[string]string{"Idempotency-Key": "tablet-1-0001"}
+
+	first := serve(h, http.MethodPost, "/api/products", body, key)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want %d", first.Code, http.StatusCreated)
+	}
+	retry := serve(h, http.MethodPost, "/api/products", body, key)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want the first response replayed", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Idempotent-Replayed header missing on retry")
+	}
+
+	reused := serve(h, http.MethodPost, "/api/products", `{"name":"Taro","price":9000,"stock":10,"category_id":1}`, key)
+	if reused.Code != http.StatusConflict {
+		t.Fatalf("reused key status = %d, want %d", reused.Code, http.StatusConflict)
+	}
+	if got := decodeResponse(t, reused).Error; got != "Idempotency-Key was already used with a different request" {
+		t.Errorf("error = %q", got)
+	}
+
+	if rec := serve(h, http.MethodPost, "/api/products", body, map[string]string{"Idempotency-Key": "tablet-1-0002"}); rec.Code != http.StatusCreated {
+		t.Errorf("new key status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	all, err := repos.Products.GetAll()
+	if err != nil {
+		t.Fatalf("get products: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("products = %d, want the seeded one plus two created", len(all))
+	}
+}
+
+func TestIdempotency_SkipsServerErrors(t *testing.T) {
+	repos := newRepos(t)
+	calls := 0
+	h := handler.Idempotency(service.NewIdempotencyService(repos.Idempotency, time.Hour), 1<<20,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				handler.WriteError(w, http.StatusInternalServerError, "Failed to create product")
+				return
+			}
+			handler.WriteJSON(w, http.StatusCreated, nil)
+		}))
+	key := map[string]string{"Idempotency-Key": "k"}
+
+	if rec := serve(h, http.MethodPost, "/api/products", "{}", key); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d", rec.Code)
+	}
+	if rec := serve(h, http.MethodPost, "/api/products", "{}", key); rec.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := serve(h, http.MethodGet, "/api/products", "", key); rec.Header().Get("Idempotent-Replayed") != "" || calls != 3 {
+		t.Errorf("GET handled %d times, want it passed through", calls)
+	}
+}
+
+func TestIdempotency_SkipsAuthFailures(t *testing.T) {
+	repos := newRepos(t)
+	calls := 0
+	keyed := handler.Idempotency(service.NewIdempotencyService(repos.Idempotency, time.Hour), 1<<20,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.Header.Get("X-User") != "budi" {
+				handler.WriteError(w, http.StatusForbidden, "Only the shift's cashier can close it")
+				return
+			}
+			handler.WriteJSON(w, http.StatusOK, nil)
+		}))
+	h := handler.RequireSupervisorToken("s3cret", keyed.ServeHTTP)
+
+	// Refused for its token before the key is claimed
+	if rec := serve(h, http.MethodPost, "/api/shifts/1/close", "{}", map[string]string{"Idempotency-Key": "k"}); rec.Code != http.StatusUnauthorized || calls != 0 {
+		t.Fatalf("without token status = %d after %d calls, want 401 before the handler", rec.Code, calls)
+	}
+	// Refused by the handler, and not replayed to the right user
+	headers := map[string]string{"Idempotency-Key": "k", "X-Supervisor-Token": "s3cret", "X-User": "siti"}
+	if rec := serve(h, http.MethodPost, "/api/shifts/1/close", "{}", headers); rec.Code != http.StatusForbidden {
+		t.Fatalf("wrong user status = %d, want 403", rec.Code)
+	}
+	headers["X-User"] = "budi"
+	if rec := serve(h, http.MethodPost, "/api/shifts/1/close", "{}", headers); rec.Code != http.StatusOK || calls != 2 {
+		t.Errorf("right user status = %d after %d calls, want 200 from the handler", rec.Code, calls)
+	}
+}
+
+func TestIdempotency_ReleasesKeyOnPanic(t *testing.T) {
+	repos := newRepos(t)
+	calls := 0
+	h := handler.Idempotency(service.NewIdempotencyService(repos.Idempotency, time.Hour), 1<<20,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				panic("handler bug")
+			}
+			handler.WriteJSON(w, http.StatusCreated, nil)
+		}))
+	key := map[string]string{"Idempotency-Key": "k"}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic was swallowed, want it passed on to the server")
+			}
+		}()
+		serve(h, http.MethodPost, "/api/products", "{}", key)
+	}()
+	if rec := serve(h, http.MethodPost, "/api/products", "{}", key); rec.Code != http.StatusCreated {
+		t.Fatalf("retry after panic status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotency_LimitsBody(t *testing.T) {
+	repos := newRepos(t)
+	limit := handler.IdempotentBodyLimit(4096)
+	calls := 0
+	h := handler.Idempotency(service.NewIdempotencyService(repos.Idempotency, time.Hour), limit,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			handler.WriteJSON(w, http.StatusCreated, nil)
+		}))
+
+	// An image at IMAGE_MAX_BYTES fits with room for its multipart framing
+	if limit <= 4096 {
+		t.Fatalf("limit = %d, want room above the image size", limit)
+	}
+	rec := serve(h, http.MethodPost, "/api/products/1/images", strings.Repeat("x", int(limit)), map[string]string{"Idempotency-Key": "a"})
+	if rec.Code != http.StatusCreated || calls != 1 {
+		t.Errorf("body at the limit = %d after %d calls, want 201", rec.Code, calls)
+	}
+
+	rec = serve(h, http.MethodPost, "/api/products/1/images", strings.Repeat("x", int(limit)+1), map[string]string{"Idempotency-Key": "b"})
+	if got := decodeResponse(t, rec).Error; rec.Code != http.StatusRequestEntityTooLarge || got != "Request body is too large" {
+		t.Errorf("oversized body = %d %q, want 413", rec.Code, got)
+	}
+	if calls != 1 {
+		t.Errorf("oversized request reached the handler")
+	}
+}
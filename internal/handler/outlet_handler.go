@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// OutletHandler handles HTTP requests for outlets
+type OutletHandler struct {
+	service *service.OutletService
+}
+
+// NewOutletHandler creates a new outlet handler
+func NewOutletHandler(service *service.OutletService) *OutletHandler {
+	return &OutletHandler{service: service}
+}
+
+// HandleOutlets handles GET and POST requests for /api/outlets
+func (h *OutletHandler) HandleOutlets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all outlets
+// @Description  Retrieve a list of all outlets
+// @Tags         outlets
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.Outlet
+// @Failure      500  {string}  string  "Failed to fetch outlets"
+// @Router       /outlets [get]
+func (h *OutletHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outlets, err := h.service.GetAll()
+	if err != nil {
+		log.Println("Error fetching outlets:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch outlets")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, outlets)
+}
+
+// Create godoc
+// @Summary      Create a new outlet
+// @Description  Create a new outlet with its own stock of the shared catalogue
+// @Tags         outlets
+// @Accept       json
+// @Produce      json
+// @Param        outlet  body      domain.OutletInput  true   "Outlet data"
+// @Param        X-User  header    string              false  "User making the change, recorded in the audit log"
+// @Success      201     {object}  domain.Outlet
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      409     {string}  string  "Outlet code already in use"
+// @Router       /outlets [post]
+func (h *OutletHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.OutletInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	outlet := outletFromInput(input)
+	if err := h.service.Create(&outlet, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error creating outlet:", err)
+		writeOutletError(w, err, "Outlet code already in use", "Failed to create outlet")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, outlet)
+}
+
+// HandleOutletByID handles GET, PUT, DELETE requests for /api/outlets/{id}
+func (h *OutletHandler) HandleOutletByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get outlet by ID
+// @Description  Retrieve a single outlet by its ID
+// @Tags         outlets
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Outlet ID"
+// @Success      200  {object}  domain.Outlet
+// @Failure      400  {string}  string  "Invalid outlet ID"
+// @Failure      404  {string}  string  "Outlet not found"
+// @Router       /outlets/{id} [get]
+func (h *OutletHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/outlets/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	outlet, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching outlet by ID:", err)
+		writeOutletError(w, err, "", "Failed to fetch outlet")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, outlet)
+}
+
+// Update godoc
+// @Summary      Update an outlet
+// @Description  Update an existing outlet by its ID
+// @Tags         outlets
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                 true   "Outlet ID"
+// @Param        outlet  body      domain.OutletInput  true   "Outlet data"
+// @Param        X-User  header    string              false  "User making the change, recorded in the audit log"
+// @Success      200     {object}  domain.Outlet
+// @Failure      400     {string}  string  "Invalid outlet ID or request body"
+// @Failure      404     {string}  string  "Outlet not found"
+// @Failure      409     {string}  string  "Outlet code already in use"
+// @Router       /outlets/{id} [put]
+func (h *OutletHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/outlets/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	var input domain.OutletInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	outlet := outletFromInput(input)
+	outlet.ID = id
+	if err := h.service.Update(&outlet, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error updating outlet:", err)
+		writeOutletError(w, err, "Outlet code already in use", "Failed to update outlet")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, outlet)
+}
+
+// Delete godoc
+// @Summary      Delete an outlet
+// @Description  Delete an outlet by its ID. The default outlet and outlets with stock, carts, purchase orders or transfers cannot be deleted.
+// @Tags         outlets
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Outlet ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Outlet deleted successfully"
+// @Failure      400  {string}  string  "Invalid outlet ID"
+// @Failure      404  {string}  string  "Outlet not found"
+// @Failure      409  {string}  string  "Outlet is in use"
+// @Router       /outlets/{id} [delete]
+func (h *OutletHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/outlets/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting outlet:", err)
+		writeOutletError(w, err, "Outlet is in use", "Failed to delete outlet")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Outlet deleted successfully"})
+}
+
+func outletFromInput(input domain.OutletInput) domain.Outlet {
+	return domain.Outlet{
+		Code:    input.Code,
+		Name:    input.Name,
+		Address: input.Address,
+	}
+}
+
+func writeOutletError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Outlet not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newOutletMux wires the outlet routes the same way router.New does. Outlet
+// 2 has code BDG next to the default outlet.
+func newOutletMux(t *testing.T) http.Handler {
+	t.Helper()
+	repos := newRepos(t)
+	outlet := domain.Outlet{Code: "BDG", Name: "Bandung"}
+	if err := repos.Outlets.Create(&outlet); err != nil {
+		t.Fatalf("seed outlet: %v", err)
+	}
+
+	outlets := handler.NewOutletHandler(service.NewOutletService(repos.Outlets, memory.NewTransactor(repos)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/outlets", outlets.HandleOutlets)
+	mux.HandleFunc("/api/outlets/", outlets.HandleOutletByID)
+	return mux
+}
+
+func TestOutletHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/outlets", wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/api/outlets", body: `{"code":"jkt","name":"Jakarta"}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/outlets", body: `{"code":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create without code", method: http.MethodPost, path: "/api/outlets", body: `{"name":"Jakarta"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: code is required"},
+		{name: "create without name", method: http.MethodPost, path: "/api/outlets", body: `{"code":"JKT"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: name is required"},
+		{name: "create with taken code", method: http.MethodPost, path: "/api/outlets", body: `{"code":"bdg","name":"Bandung 2"}`, wantStatus: http.StatusConflict, wantError: "Outlet code already in use"},
+		{name: "outlets method not allowed", method: http.MethodDelete, path: "/api/outlets", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/outlets/2", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/outlets/99", wantStatus: http.StatusNotFound, wantError: "Outlet not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/outlets/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid outlet ID"},
+		{name: "update", method: http.MethodPut, path: "/api/outlets/2", body: `{"code":"BDG","name":"Bandung Dago"}`, wantStatus: http.StatusOK},
+		{name: "update to taken code", method: http.MethodPut, path: "/api/outlets/2", body: `{"code":"MAIN","name":"Bandung"}`, wantStatus: http.StatusConflict, wantError: "Outlet code already in use"},
+		{name: "update missing", method: http.MethodPut, path: "/api/outlets/99", body: `{"code":"X","name":"Ghost"}`, wantStatus: http.StatusNotFound, wantError: "Outlet not found"},
+		{name: "delete", method: http.MethodDelete, path: "/api/outlets/2", wantStatus: http.StatusOK},
+		{name: "delete default outlet", method: http.MethodDelete, path: "/api/outlets/1", wantStatus: http.StatusConflict, wantError: "Outlet is in use"},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/outlets/99", wantStatus: http.StatusNotFound, wantError: "Outlet not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newOutletMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"kasir-api/internal/apperrors"
 	"kasir-api/internal/domain"
@@ -35,17 +36,49 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 
 // GetAll godoc
 // @Summary      Get all products
-// @Description  Retrieve a list of all products
+// @Description  Retrieve a list of all products with their stock at an outlet
 // @Tags         products
 // @Accept       json
 // @Produce      json
+// @Param        outlet_id    query     int     false  "Outlet whose stock is reported (default outlet if omitted)"
+// @Param        category_id  query     int     false  "Only list products in this category or its subcategories"
+// @Param        group        query     string  false  "Set to variants to nest variants under their parent"  Enums(variants)
 // @Success      200  {array}   domain.Product
+// @Failure      400  {string}  string  "Invalid outlet ID"
+// @Failure      400  {string}  string  "Invalid category ID"
+// @Failure      400  {string}  string  "Invalid group"
+// @Failure      400  {string}  string  "Outlet not found"
+// @Failure      400  {string}  string  "Category not found"
 // @Failure      500  {string}  string  "Failed to fetch products"
 // @Router       /products [get]
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	products, err := h.service.GetAll()
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+	categoryID, err := optionalIDFromQuery(r, "category_id")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+	group := r.URL.Query().Get("group")
+	if group != "" && group != "variants" {
+		WriteError(w, http.StatusBadRequest, "Invalid group")
+		return
+	}
+
+	products, err := h.service.GetAll(outletID, categoryID, group == "variants")
 	if err != nil {
 		log.Println("Error fetching products:", err)
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrCategoryNotFound) {
+			WriteError(w, http.StatusBadRequest, "Category not found")
+			return
+		}
 		WriteError(w, http.StatusInternalServerError, "Failed to fetch products")
 		return
 	}
@@ -55,28 +88,66 @@ func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 
 // Create godoc
 // @Summary      Create a new product
-// @Description  Create a new product with the provided data
+// @Description  Create a new product with the provided data, stocked at an outlet
 // @Tags         products
 // @Accept       json
 // @Produce      json
-// @Param        product  body      domain.ProductInput  true  "Product data"
+// @Param        product    body      domain.ProductInput  true   "Product data"
+// @Param        outlet_id  query     int                  false  "Outlet the stock is set at (default outlet if omitted)"
+// @Param        X-User     header    string               false  "User making the change, recorded in the audit log"
 // @Success      201      {object}  domain.Product
 // @Failure      400      {string}  string  "Invalid request body"
+// @Failure      400      {string}  string  "Invalid outlet ID"
+// @Failure      400      {string}  string  "Outlet not found"
 // @Failure      400      {string}  string  "Category not found"
+// @Failure      400      {string}  string  "Tax rate not found"
+// @Failure      400      {string}  string  "Unit not found"
+// @Failure      400      {string}  string  "Parent product not found"
+// @Failure      409      {string}  string  "Barcode already in use"
 // @Router       /products [post]
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
 	var product domain.Product
 	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
 		WriteError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if err := h.service.Create(&product); err != nil {
+	if err := h.service.Create(&product, outletID, changeMetaFromRequest(r)); err != nil {
 		log.Println("Error creating product:", err)
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
 		if errors.Is(err, apperrors.ErrCategoryNotFound) {
 			WriteError(w, http.StatusBadRequest, "Category not found")
 			return
 		}
+		if errors.Is(err, apperrors.ErrTaxRateNotFound) {
+			WriteError(w, http.StatusBadRequest, "Tax rate not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrUnitNotFound) {
+			WriteError(w, http.StatusBadRequest, "Unit not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrProductNotFound) {
+			WriteError(w, http.StatusBadRequest, "Parent product not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrConflict) {
+			WriteError(w, http.StatusConflict, "Barcode already in use")
+			return
+		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		WriteError(w, http.StatusBadRequest, "Failed to create product")
 		return
 	}
@@ -100,13 +171,16 @@ func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Reques
 
 // GetByID godoc
 // @Summary      Get product by ID
-// @Description  Retrieve a single product by its ID
+// @Description  Retrieve a single product by its ID with its stock at an outlet
 // @Tags         products
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Product ID"
+// @Param        id         path      int  true   "Product ID"
+// @Param        outlet_id  query     int  false  "Outlet whose stock is reported (default outlet if omitted)"
 // @Success      200  {object}  domain.Product
 // @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      400  {string}  string  "Invalid outlet ID"
+// @Failure      400  {string}  string  "Outlet not found"
 // @Failure      404  {string}  string  "Product not found"
 // @Router       /products/{id} [get]
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -115,10 +189,19 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "Invalid product ID")
 		return
 	}
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
 
-	product, err := h.service.GetByID(id)
+	product, err := h.service.GetByID(id, outletID)
 	if err != nil {
 		log.Println("Error fetching product by ID:", err)
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
 		if errors.Is(err, apperrors.ErrNotFound) {
 			WriteError(w, http.StatusNotFound, "Product not found")
 			return
@@ -132,15 +215,23 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary      Update a product
-// @Description  Update an existing product by its ID
+// @Description  Update an existing product by its ID and set its stock at an outlet
 // @Tags         products
 // @Accept       json
 // @Produce      json
-// @Param        id       path      int                  true  "Product ID"
-// @Param        product  body      domain.ProductInput  true  "Product data"
+// @Param        id         path      int                  true   "Product ID"
+// @Param        product    body      domain.ProductInput  true   "Product data"
+// @Param        outlet_id  query     int                  false  "Outlet the stock is set at (default outlet if omitted)"
+// @Param        X-User     header    string               false  "User making the change, recorded in price history and audit log"
 // @Success      200      {object}  domain.Product
 // @Failure      400      {string}  string  "Invalid product ID or request body"
+// @Failure      400      {string}  string  "Invalid outlet ID"
+// @Failure      400      {string}  string  "Outlet not found"
 // @Failure      400      {string}  string  "Category not found"
+// @Failure      400      {string}  string  "Tax rate not found"
+// @Failure      400      {string}  string  "Unit not found"
+// @Failure      400      {string}  string  "Parent product not found"
+// @Failure      409      {string}  string  "Barcode already in use"
 // @Router       /products/{id} [put]
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := parseIDFromPath(r.URL.Path, "/api/products/")
@@ -148,6 +239,11 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "Invalid product ID")
 		return
 	}
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
 
 	var product domain.Product
 	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
@@ -156,16 +252,40 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	product.ID = id
-	if err := h.service.Update(&product); err != nil {
+	if err := h.service.Update(&product, outletID, changeMetaFromRequest(r)); err != nil {
 		log.Println("Error updating product:", err)
 		if errors.Is(err, apperrors.ErrNotFound) {
 			WriteError(w, http.StatusNotFound, "Product not found")
 			return
 		}
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
 		if errors.Is(err, apperrors.ErrCategoryNotFound) {
 			WriteError(w, http.StatusBadRequest, "Category not found")
 			return
 		}
+		if errors.Is(err, apperrors.ErrTaxRateNotFound) {
+			WriteError(w, http.StatusBadRequest, "Tax rate not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrUnitNotFound) {
+			WriteError(w, http.StatusBadRequest, "Unit not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrProductNotFound) {
+			WriteError(w, http.StatusBadRequest, "Parent product not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrConflict) {
+			WriteError(w, http.StatusConflict, "Barcode already in use")
+			return
+		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		WriteError(w, http.StatusBadRequest, "Failed to update product")
 		return
 	}
@@ -175,14 +295,16 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary      Delete a product
-// @Description  Delete a product by its ID
+// @Description  Delete a product by its ID. Products with variants and products that are part of a bundle cannot be deleted.
 // @Tags         products
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Product ID"
+// @Param        id      path      int     true   "Product ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
 // @Success      200  {object}  handler.APIResponse  "Product deleted successfully"
 // @Failure      400  {string}  string  "Invalid product ID"
 // @Failure      404  {string}  string  "Product not found"
+// @Failure      409  {string}  string  "Product has variants or is part of a bundle"
 // @Router       /products/{id} [delete]
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := parseIDFromPath(r.URL.Path, "/api/products/")
@@ -191,15 +313,269 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
 		log.Println("Error deleting product:", err)
 		if errors.Is(err, apperrors.ErrNotFound) {
 			WriteError(w, http.StatusNotFound, "Product not found")
 			return
 		}
+		if errors.Is(err, apperrors.ErrConflict) {
+			WriteError(w, http.StatusConflict, "Product has variants or is part of a bundle")
+			return
+		}
 		WriteError(w, http.StatusBadRequest, "Failed to delete product")
 		return
 	}
 
 	WriteJSON(w, http.StatusOK, map[string]string{"message": "Product deleted successfully"})
 }
+
+// HandlePriceHistory handles GET requests for /api/products/{id}/price-history
+func (h *ProductHandler) HandlePriceHistory(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetPriceHistory(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetPriceHistory godoc
+// @Summary      Get product price history
+// @Description  Retrieve every price change of a product, newest first. The history of a deleted product is kept in the database but is no longer served (404).
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {array}   domain.PriceChange
+// @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      500  {string}  string  "Failed to fetch price history"
+// @Router       /products/{id}/price-history [get]
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	history, err := h.service.GetPriceHistory(id)
+	if err != nil {
+		log.Println("Error fetching price history:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch price history")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, history)
+}
+
+// HandleVariants handles GET requests for /api/products/{id}/variants
+func (h *ProductHandler) HandleVariants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetVariants(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetVariants godoc
+// @Summary      Get product variants
+// @Description  Retrieve the variants of a product with their stock at an outlet
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int  true   "Product ID"
+// @Param        outlet_id  query     int  false  "Outlet whose stock is reported (default outlet if omitted)"
+// @Success      200  {array}   domain.Product
+// @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      400  {string}  string  "Invalid outlet ID"
+// @Failure      400  {string}  string  "Outlet not found"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      500  {string}  string  "Failed to fetch variants"
+// @Router       /products/{id}/variants [get]
+func (h *ProductHandler) GetVariants(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	variants, err := h.service.GetVariants(id, outletID)
+	if err != nil {
+		log.Println("Error fetching variants:", err)
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch variants")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, variants)
+}
+
+// HandleComponents handles GET and PUT requests for /api/products/{id}/components
+func (h *ProductHandler) HandleComponents(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetComponents(w, r)
+	case http.MethodPut:
+		h.SetComponents(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetComponents godoc
+// @Summary      Get bundle components
+// @Description  Retrieve the products, and quantity of each in its base unit, that one unit of a bundle consumes
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {array}   domain.BundleComponent
+// @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      500  {string}  string  "Failed to fetch components"
+// @Router       /products/{id}/components [get]
+func (h *ProductHandler) GetComponents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	components, err := h.service.GetComponents(id)
+	if err != nil {
+		log.Println("Error fetching components:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch components")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, components)
+}
+
+// SetComponents godoc
+// @Summary      Set bundle components
+// @Description  Replace the components of a bundle. Components are standard products, each with the quantity in its base unit that one bundle consumes at checkout.
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int                           true   "Product ID"
+// @Param        components  body      domain.BundleComponentsInput  true   "Bundle components"
+// @Param        X-User      header    string                        false  "User making the change, recorded in the audit log"
+// @Success      200         {array}   domain.BundleComponent
+// @Failure      400         {string}  string  "Invalid product ID or request body"
+// @Failure      400         {string}  string  "Component product not found"
+// @Failure      404         {string}  string  "Product not found"
+// @Failure      500         {string}  string  "Failed to update components"
+// @Router       /products/{id}/components [put]
+func (h *ProductHandler) SetComponents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var input domain.BundleComponentsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	components, err := h.service.SetComponents(id, input.Components, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error updating components:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrProductNotFound) {
+			WriteError(w, http.StatusBadRequest, "Component product not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to update components")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, components)
+}
+
+// HandleStockMovements handles GET requests for /api/products/{id}/stock-movements
+func (h *ProductHandler) HandleStockMovements(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetStockMovements(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetStockMovements godoc
+// @Summary      Get product stock ledger
+// @Description  Retrieve every recorded change to a product's stock, such as purchase order receipts and transfers, newest first
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int  true   "Product ID"
+// @Param        outlet_id  query     int  false  "Only movements at this outlet"
+// @Success      200  {array}   domain.StockMovement
+// @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      400  {string}  string  "Invalid outlet ID"
+// @Failure      400  {string}  string  "Outlet not found"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      500  {string}  string  "Failed to fetch stock movements"
+// @Router       /products/{id}/stock-movements [get]
+func (h *ProductHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	movements, err := h.service.GetStockMovements(id, outletID)
+	if err != nil {
+		log.Println("Error fetching stock movements:", err)
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch stock movements")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, movements)
+}
@@ -0,0 +1,631 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+func newProductHandler(t *testing.T) *handler.ProductHandler {
+	t.Helper()
+	repos := newRepos(t)
+	return handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), memory.NewTransactor(repos)))
+}
+
+func TestProductHandler_HandleProducts(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, body: `{"name":"Chitato","price":10000,"stock":5,"category_id":1}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create with unknown category", method: http.MethodPost, body: `{"name":"Chitato","price":10000,"stock":5,"category_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Category not found"},
+		{name: "create with unknown tax rate", method: http.MethodPost, body: `{"name":"Chitato","price":10000,"stock":5,"category_id":1,"tax_rate_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Tax rate not found"},
+		{name: "create with negative cost", method: http.MethodPost, body: `{"name":"Chitato","price":10000,"cost_price":-1,"stock":5,"category_id":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: cost_price cannot be negative"},
+		{name: "method not allowed", method: http.MethodDelete, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newProductHandler(t)
+			req := httptest.NewRequest(tt.method, "/api/products", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleProducts(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProductHandler_GetAllIncludesCategory(t *testing.T) {
+	h := newProductHandler(t)
+	rec := httptest.NewRecorder()
+
+	h.HandleProducts(rec, httptest.NewRequest(http.MethodGet, "/api/products", nil))
+
+	var products []domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &products); err != nil {
+		t.Fatalf("decode products: %v", err)
+	}
+	if len(products) != 1 {
+		t.Fatalf("len(products) = %d, want 1", len(products))
+	}
+	if products[0].Category == nil || products[0].Category.Name != "Makanan Ringan" {
+		t.Errorf("category = %+v, want Makanan Ringan", products[0].Category)
+	}
+}
+
+func TestProductHandler_HandleProductByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/products/1", wantStatus: http.StatusOK},
+		{name: "get with invalid id", method: http.MethodGet, path: "/api/products/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "get missing", method: http.MethodGet, path: "/api/products/99", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "update", method: http.MethodPut, path: "/api/products/1", body: `{"name":"Indomie Soto","price":3000,"stock":50,"category_id":1}`, wantStatus: http.StatusOK},
+		{name: "update with invalid id", method: http.MethodPut, path: "/api/products/abc", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "update with malformed body", method: http.MethodPut, path: "/api/products/1", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "update with unknown category", method: http.MethodPut, path: "/api/products/1", body: `{"name":"Indomie Soto","price":3000,"stock":50,"category_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Category not found"},
+		{name: "update with unknown tax rate", method: http.MethodPut, path: "/api/products/1", body: `{"name":"Indomie Soto","price":3000,"stock":50,"category_id":1,"tax_rate_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Tax rate not found"},
+		{name: "update with negative cost", method: http.MethodPut, path: "/api/products/1", body: `{"name":"Indomie Soto","price":3000,"cost_price":-500,"stock":50,"category_id":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: cost_price cannot be negative"},
+		{name: "update missing", method: http.MethodPut, path: "/api/products/99", body: `{"name":"Indomie Soto","price":3000,"stock":50,"category_id":1}`, wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "delete", method: http.MethodDelete, path: "/api/products/1", wantStatus: http.StatusOK},
+		{name: "delete with invalid id", method: http.MethodDelete, path: "/api/products/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/products/99", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "method not allowed", method: http.MethodPost, path: "/api/products/1", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newProductHandler(t)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleProductByID(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProductHandler_UpdatePersists(t *testing.T) {
+	h := newProductHandler(t)
+	body := `{"name":"Indomie Soto","price":3000,"stock":50,"category_id":1}`
+	h.HandleProductByID(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/api/products/1", strings.NewReader(body)))
+
+	rec := httptest.NewRecorder()
+	h.HandleProductByID(rec, httptest.NewRequest(http.MethodGet, "/api/products/1", nil))
+
+	var product domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &product); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	if product.Name != "Indomie Soto" || product.Price != 3000 || product.Stock != 50 {
+		t.Errorf("product = %+v, want updated fields", product)
+	}
+}
+
+func TestProductHandler_StockEditsAreLedgered(t *testing.T) {
+	h := newProductHandler(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products/", h.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/stock-movements", h.HandleStockMovements)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/products/1",
+		strings.NewReader(`{"name":"Indomie Goreng","price":3500,"stock":90,"category_id":1}`))
+	req.Header.Set("X-User", "supervisor")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/products/1/stock-movements", nil))
+	var movements []domain.StockMovement
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &movements); err != nil {
+		t.Fatalf("decode movements: %v", err)
+	}
+	if len(movements) != 1 || movements[0].Quantity != -10 || movements[0].Reason != domain.StockMovementAdjustment ||
+		movements[0].Reference != "PRODUCT-1" || movements[0].Actor != "supervisor" {
+		t.Errorf("movements = %+v, want one -10 adjustment PRODUCT-1 by supervisor", movements)
+	}
+}
+
+func TestProductHandler_ReportsProfit(t *testing.T) {
+	h := newProductHandler(t)
+	body := `{"name":"Chitato","price":10000,"cost_price":7500,"stock":5,"category_id":1}`
+	rec := httptest.NewRecorder()
+	h.HandleProducts(rec, httptest.NewRequest(http.MethodPost, "/api/products", strings.NewReader(body)))
+
+	var created domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &created); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	if created.GrossProfit != 2500 || created.MarginPercent != 25 {
+		t.Errorf("create response profit, margin = %d, %v, want 2500, 25", created.GrossProfit, created.MarginPercent)
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleProducts(rec, httptest.NewRequest(http.MethodGet, "/api/products", nil))
+	var products []domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &products); err != nil {
+		t.Fatalf("decode products: %v", err)
+	}
+	if len(products) != 2 || products[1].CostPrice != 7500 || products[1].MarginPercent != 25 {
+		t.Errorf("products = %+v, want Chitato with 25%% margin", products)
+	}
+}
+
+func TestProductHandler_PriceHistory(t *testing.T) {
+	h := newProductHandler(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products/", h.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/price-history", h.HandlePriceHistory)
+
+	updates := []string{
+		`{"name":"Indomie Goreng","price":4000,"stock":100,"category_id":1}`,
+		`{"name":"Indomie Goreng Jumbo","price":4000,"stock":90,"category_id":1}`,
+		`{"name":"Indomie Goreng Jumbo","price":4500,"stock":90,"category_id":1}`,
+	}
+	for _, body := range updates {
+		req := httptest.NewRequest(http.MethodPut, "/api/products/1", strings.NewReader(body))
+		req.Header.Set("X-User", "supervisor")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("update status = %d, want 200", rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/products/1/price-history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var history []domain.PriceChange
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("len(history) = %d, want 2 (unchanged price is not recorded)", len(history))
+	}
+	if history[0].OldPrice != 4000 || history[0].NewPrice != 4500 || history[0].ChangedBy != "supervisor" {
+		t.Errorf("latest change = %+v, want 4000 -> 4500 by supervisor", history[0])
+	}
+	if history[1].OldPrice != 3500 || history[1].NewPrice != 4000 {
+		t.Errorf("first change = %+v, want 3500 -> 4000", history[1])
+	}
+
+	// The history of a deleted product stays in the database but is not served
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/products/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/products/1/price-history", nil))
+	if got := decodeResponse(t, rec).Error; rec.Code != http.StatusNotFound || got != "Product not found" {
+		t.Errorf("deleted product history = %d %q, want 404", rec.Code, got)
+	}
+}
+
+func TestProductHandler_HandlePriceHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		id         string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "empty history", method: http.MethodGet, id: "1", wantStatus: http.StatusOK},
+		{name: "invalid id", method: http.MethodGet, id: "abc", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "missing product", method: http.MethodGet, id: "99", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "method not allowed", method: http.MethodPost, id: "1", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newProductHandler(t)
+			req := httptest.NewRequest(tt.method, "/api/products/"+tt.id+"/price-history", nil)
+			req.SetPathValue("id", tt.id)
+			rec := httptest.NewRecorder()
+
+			h.HandlePriceHistory(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func newVariantMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	h := newProductHandler(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products", h.HandleProducts)
+	mux.HandleFunc("/api/products/", h.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/variants", h.HandleVariants)
+
+	body := `{"name":"Indomie Goreng Jumbo","price":4500,"stock":20,"parent_id":1,"barcode":"089686010947","options":{"size":"jumbo"}}`
+	if rec := serve(mux, http.MethodPost, "/api/products", body, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create variant status = %d, want 201", rec.Code)
+	}
+	return mux
+}
+
+func TestProductHandler_CreateVariant(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "variant", body: `{"name":"Indomie Goreng Pedas","price":3700,"parent_id":1,"options":{"size":"regular","flavour":"pedas"}}`, wantStatus: http.StatusCreated},
+		{name: "unknown parent", body: `{"name":"Chitato BBQ","price":10000,"parent_id":99,"options":{"flavour":"bbq"}}`, wantStatus: http.StatusBadRequest, wantError: "Parent product not found"},
+		{name: "variant of a variant", body: `{"name":"Indomie Goreng Jumbo Pedas","price":4700,"parent_id":2,"options":{"flavour":"pedas"}}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: variants cannot have variants"},
+		{name: "variant without options", body: `{"name":"Indomie Goreng Mini","price":2500,"parent_id":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: variants need at least one option"},
+		{name: "same options as a sibling", body: `{"name":"Indomie Goreng Besar","price":4500,"parent_id":1,"options":{"size":"jumbo"}}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: another variant has the same options"},
+		{name: "empty option value", body: `{"name":"Indomie Goreng Mini","price":2500,"parent_id":1,"options":{"size":""}}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: option names and values must not be empty"},
+		{name: "options without parent", body: `{"name":"Chitato","price":10000,"category_id":1,"options":{"flavour":"bbq"}}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: options are only allowed on variants"},
+		{name: "barcode in use", body: `{"name":"Chitato","price":10000,"category_id":1,"barcode":"089686010947"}`, wantStatus: http.StatusConflict, wantError: "Barcode already in use"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newVariantMux(t)
+
+			rec := serve(mux, http.MethodPost, "/api/products", tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProductHandler_VariantInheritsCategoryAndKeepsOwnStock(t *testing.T) {
+	mux := newVariantMux(t)
+
+	rec := serve(mux, http.MethodGet, "/api/products/2", "", nil)
+	var variant domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &variant); err != nil {
+		t.Fatalf("decode variant: %v", err)
+	}
+	if variant.ParentID == nil || *variant.ParentID != 1 || variant.CategoryID != 1 || variant.Stock != 20 ||
+		variant.Price != 4500 || variant.Options["size"] != "jumbo" {
+		t.Errorf("variant = %+v, want parent 1, category 1, stock 20, price 4500 and size jumbo", variant)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/products/1/variants", "", nil)
+	var variants []domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &variants); err != nil {
+		t.Fatalf("decode variants: %v", err)
+	}
+	if len(variants) != 1 || variants[0].ID != 2 || variants[0].Available != 20 {
+		t.Errorf("variants = %+v, want only product 2 with 20 available", variants)
+	}
+}
+
+func TestProductHandler_GetAllGroupsVariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantIDs   []int
+		wantError string
+	}{
+		{name: "flat", query: "", wantIDs: []int{1, 2}},
+		{name: "grouped", query: "?group=variants", wantIDs: []int{1}},
+		{name: "unknown grouping", query: "?group=category", wantError: "Invalid group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newVariantMux(t)
+
+			rec := serve(mux, http.MethodGet, "/api/products"+tt.query, "", nil)
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Fatalf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if tt.wantError != "" {
+				return
+			}
+			var products []domain.Product
+			if err := json.Unmarshal(resp.Data, &products); err != nil {
+				t.Fatalf("decode products: %v", err)
+			}
+			var ids []int
+			for _, p := range products {
+				ids = append(ids, p.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.query != "" && (len(products[0].Variants) != 1 || products[0].Variants[0].Stock != 20) {
+				t.Errorf("variants = %+v, want product 2 with stock 20", products[0].Variants)
+			}
+		})
+	}
+}
+
+func TestProductHandler_GetAllByCategory(t *testing.T) {
+	repos := newRepos(t)
+	parentID := 1
+	for _, c := range []domain.Category{{Name: "Keripik", ParentID: &parentID}, {Name: "Minuman"}} {
+		if err := repos.Categories.Create(&c); err != nil {
+			t.Fatalf("seed category: %v", err)
+		}
+	}
+	for _, p := range []domain.Product{
+		{Name: "Chitato", Type: domain.ProductTypeStandard, Price: 10000, CategoryID: 2, UnitID: domain.DefaultUnitID},
+		{Name: "Teh Botol", Type: domain.ProductTypeStandard, Price: 5000, CategoryID: 3, UnitID: domain.DefaultUnitID},
+	} {
+		if err := repos.Products.Create(&p); err != nil {
+			t.Fatalf("seed product: %v", err)
+		}
+	}
+	h := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), memory.NewTransactor(repos)))
+
+	tests := []struct {
+		name      string
+		query     string
+		wantIDs   []int
+		wantError string
+	}{
+		{name: "all", query: "", wantIDs: []int{1, 2, 3}},
+		{name: "category with subcategory", query: "?category_id=1", wantIDs: []int{1, 2}},
+		{name: "subcategory", query: "?category_id=2", wantIDs: []int{2}},
+		{name: "other category", query: "?category_id=3", wantIDs: []int{3}},
+		{name: "invalid category id", query: "?category_id=abc", wantError: "Invalid category ID"},
+		{name: "unknown category", query: "?category_id=99", wantError: "Category not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.HandleProducts(rec, httptest.NewRequest(http.MethodGet, "/api/products"+tt.query, nil))
+
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Fatalf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if tt.wantError != "" {
+				if rec.Code != http.StatusBadRequest {
+					t.Errorf("status = %d, want 400", rec.Code)
+				}
+				return
+			}
+			var products []domain.Product
+			if err := json.Unmarshal(resp.Data, &products); err != nil {
+				t.Fatalf("decode products: %v", err)
+			}
+			var ids []int
+			for _, p := range products {
+				ids = append(ids, p.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestProductHandler_HandleVariants(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/products/1/variants", wantStatus: http.StatusOK},
+		{name: "invalid id", method: http.MethodGet, path: "/api/products/abc/variants", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "missing product", method: http.MethodGet, path: "/api/products/99/variants", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "unknown outlet", method: http.MethodGet, path: "/api/products/1/variants?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "method not allowed", method: http.MethodPost, path: "/api/products/1/variants", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newVariantMux(t)
+
+			rec := serve(mux, tt.method, tt.path, "", nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProductHandler_DeleteParentWithVariants(t *testing.T) {
+	mux := newVariantMux(t)
+
+	rec := serve(mux, http.MethodDelete, "/api/products/1", "", nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", rec.Code)
+	}
+	if resp := decodeResponse(t, rec); resp.Error != "Product has variants or is part of a bundle" {
+		t.Errorf("error = %q, want Product has variants or is part of a bundle", resp.Error)
+	}
+
+	if rec := serve(mux, http.MethodDelete, "/api/products/2", "", nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete variant status = %d, want 200", rec.Code)
+	}
+	if rec := serve(mux, http.MethodDelete, "/api/products/1", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("delete parent status = %d, want 200", rec.Code)
+	}
+}
+
+// newBundleMux wires the product routes with product 2, Sirup Marjan with 7 in
+// stock, and two bundles: product 3, Paket Lebaran, made of 10 of product 1
+// and 2 of product 2, and product 4, Paket Hemat, without components yet.
+func newBundleMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	h := newProductHandler(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products", h.HandleProducts)
+	mux.HandleFunc("/api/products/", h.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/components", h.HandleComponents)
+
+	for _, body := range []string{
+		`{"name":"Sirup Marjan","price":20000,"stock":7,"category_id":1}`,
+		`{"name":"Paket Lebaran","type":"bundle","price":90000,"category_id":1}`,
+		`{"name":"Paket Hemat","type":"bundle","price":30000,"category_id":1}`,
+	} {
+		if rec := serve(mux, http.MethodPost, "/api/products", body, nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create product status = %d, body %s", rec.Code, rec.Body)
+		}
+	}
+	body := `{"components":[{"product_id":1,"quantity":10},{"product_id":2,"quantity":2}]}`
+	if rec := serve(mux, http.MethodPut, "/api/products/3/components", body, nil); rec.Code != http.StatusOK {
+		t.Fatalf("set components status = %d, body %s", rec.Code, rec.Body)
+	}
+	return mux
+}
+
+func TestProductHandler_HandleComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/products/3/components", wantStatus: http.StatusOK},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/products/abc/components", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "get missing product", method: http.MethodGet, path: "/api/products/99/components", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "set", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":[{"product_id":1,"quantity":5}]}`, wantStatus: http.StatusOK},
+		{name: "clear", method: http.MethodPut, path: "/api/products/3/components", body: `{"components":[]}`, wantStatus: http.StatusOK},
+		{name: "set with malformed body", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "set on standard product", method: http.MethodPut, path: "/api/products/1/components", body: `{"components":[{"product_id":2,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: only bundles have components"},
+		{name: "set without quantity", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":[{"product_id":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must be greater than zero"},
+		{name: "set containing itself", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":[{"product_id":4,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: a bundle cannot contain itself"},
+		{name: "set duplicate components", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":[{"product_id":1,"quantity":1},{"product_id":1,"quantity":2}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product 1 is listed more than once"},
+		{name: "set another bundle", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":[{"product_id":3,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: bundles cannot contain other bundles"},
+		{name: "set unknown component", method: http.MethodPut, path: "/api/products/4/components", body: `{"components":[{"product_id":99,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Component product not found"},
+		{name: "set on missing product", method: http.MethodPut, path: "/api/products/99/components", body: `{"components":[]}`, wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "method not allowed", method: http.MethodPost, path: "/api/products/3/components", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newBundleMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProductHandler_BundleStockFromComponents(t *testing.T) {
+	mux := newBundleMux(t)
+
+	// 100 of product 1 make 10 parcels, but 7 of product 2 only make 3
+	rec := serve(mux, http.MethodGet, "/api/products/3", "", nil)
+	var bundle domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if bundle.Type != domain.ProductTypeBundle || bundle.Stock != 3 || bundle.Available != 3 {
+		t.Errorf("bundle = %+v, want a bundle with 3 in stock and available", bundle)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/products/4", "", nil)
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if bundle.Stock != 0 {
+		t.Errorf("bundle without components stock = %d, want 0", bundle.Stock)
+	}
+
+	// Stock sent for a bundle is ignored
+	body := `{"name":"Paket Lebaran","type":"bundle","price":95000,"stock":50,"category_id":1}`
+	rec = serve(mux, http.MethodPut, "/api/products/3", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update bundle status = %d, body %s", rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if bundle.Stock != 3 || bundle.Price != 95000 {
+		t.Errorf("updated bundle = %+v, want price 95000 and stock still 3", bundle)
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantError string
+	}{
+		{name: "component becomes a bundle", method: http.MethodPut, path: "/api/products/2", body: `{"name":"Sirup Marjan","type":"bundle","price":20000,"category_id":1}`, wantError: "invalid input: a product that is part of a bundle cannot become a bundle"},
+		{name: "bundle with components becomes standard", method: http.MethodPut, path: "/api/products/3", body: `{"name":"Paket Lebaran","price":90000,"category_id":1}`, wantError: "invalid input: a bundle with components cannot become a standard product"},
+		{name: "unknown type", method: http.MethodPost, path: "/api/products", body: `{"name":"Paket","type":"kit","price":90000,"category_id":1}`, wantError: "invalid input: type must be standard or bundle"},
+		{name: "delete component", method: http.MethodDelete, path: "/api/products/2", wantError: "Product has variants or is part of a bundle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+			if resp := decodeResponse(t, rec); resp.Error != tt.wantError {
+				t.Errorf("status = %d, error = %q, want %q", rec.Code, resp.Error, tt.wantError)
+			}
+		})
+	}
+}
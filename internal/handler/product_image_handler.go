@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/service"
+)
+
+// imageFormField is the multipart form field product images are uploaded in
+const imageFormField = "image"
+
+// ProductImageHandler handles HTTP requests for product images
+type ProductImageHandler struct {
+	service *service.ProductImageService
+}
+
+// NewProductImageHandler creates a new product image handler
+func NewProductImageHandler(service *service.ProductImageService) *ProductImageHandler {
+	return &ProductImageHandler{service: service}
+}
+
+// HandleImages handles GET and POST requests for /api/products/{id}/images
+func (h *ProductImageHandler) HandleImages(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetImages(w, r)
+	case http.MethodPost:
+		h.Upload(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetImages godoc
+// @Summary      Get product images
+// @Description  Retrieve a product's images in upload order, with the URLs of each original and its thumbnail
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {array}   domain.ProductImage
+// @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      500  {string}  string  "Failed to fetch product images"
+// @Router       /products/{id}/images [get]
+func (h *ProductImageHandler) GetImages(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	images, err := h.service.GetByProductID(id)
+	if err != nil {
+		log.Println("Error fetching product images:", err)
+		writeProductImageError(w, err, "Failed to fetch product images")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, images)
+}
+
+// Upload godoc
+// @Summary      Upload a product image
+// @Description  Upload a JPEG or PNG photo of a product as the image field of a multipart form. The type is detected from the file's content. A thumbnail is generated alongside the original.
+// @Tags         products
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id      path      int     true   "Product ID"
+// @Param        image   formData  file    true   "JPEG or PNG image"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      201     {object}  domain.ProductImage
+// @Failure      400     {string}  string  "Invalid product ID or multipart form"
+// @Failure      400     {string}  string  "Image file is required"
+// @Failure      404     {string}  string  "Product not found"
+// @Failure      413     {string}  string  "Image too large"
+// @Failure      415     {string}  string  "Image must be a JPEG or PNG"
+// @Failure      500     {string}  string  "Failed to upload product image"
+// @Router       /products/{id}/images [post]
+func (h *ProductImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	file, err := imagePart(r)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			WriteError(w, http.StatusBadRequest, "Image file is required")
+			return
+		}
+		WriteError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer file.Close()
+
+	image, err := h.service.Upload(id, file, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error uploading product image:", err)
+		writeProductImageError(w, err, "Failed to upload product image")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, image)
+}
+
+// HandleImage handles DELETE requests for /api/products/{id}/images/{image_id}
+func (h *ProductImageHandler) HandleImage(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Delete godoc
+// @Summary      Delete a product image
+// @Description  Delete one of a product's images along with its thumbnail
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int     true   "Product ID"
+// @Param        image_id  path      int     true   "Image ID"
+// @Param        X-User    header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Image deleted successfully"
+// @Failure      400  {string}  string  "Invalid product ID or image ID"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      404  {string}  string  "Image not found"
+// @Failure      500  {string}  string  "Failed to delete product image"
+// @Router       /products/{id}/images/{image_id} [delete]
+func (h *ProductImageHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+	imageID, err := strconv.Atoi(r.PathValue("image_id"))
+	if err != nil || imageID <= 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid image ID")
+		return
+	}
+
+	if err := h.service.Delete(id, imageID, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting product image:", err)
+		writeProductImageError(w, err, "Failed to delete product image")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Image deleted successfully"})
+}
+
+// imagePart streams the image field of a multipart upload without buffering
+// the whole request, so the service can stop reading at its size limit. It
+// returns http.ErrMissingFile when the form has no image field.
+func imagePart(r *http.Request) (io.ReadCloser, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, http.ErrMissingFile
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == imageFormField && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+func writeProductImageError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Product not found")
+	case errors.Is(err, apperrors.ErrImageNotFound):
+		WriteError(w, http.StatusNotFound, "Image not found")
+	case errors.Is(err, apperrors.ErrImageTooLarge):
+		WriteError(w, http.StatusRequestEntityTooLarge, "Image too large")
+	case errors.Is(err, apperrors.ErrUnsupportedImageType):
+		WriteError(w, http.StatusUnsupportedMediaType, "Image must be a JPEG or PNG")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+	"kasir-api/internal/storage"
+)
+
+// newImageMux wires the product and product image routes the same way
+// router.New does, storing files under the returned directory and accepting
+// uploads of up to maxSize bytes
+func newImageMux(t *testing.T, maxSize int) (http.Handler, repository.Repositories, string) {
+	t.Helper()
+	repos := newRepos(t)
+	dir := t.TempDir()
+	store := storage.NewLocal(dir, "/uploads")
+	tx := memory.NewTransactor(repos)
+
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, store, tx))
+	images := handler.NewProductImageHandler(service.NewProductImageService(repos.Products, repos.ProductImages, store, tx, maxSize))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products/", products.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/images", images.HandleImages)
+	mux.HandleFunc("/api/products/{id}/images/{image_id}", images.HandleImage)
+	mux.Handle("/uploads/", http.StripPrefix("/uploads", store.Handler()))
+	return mux, repos, dir
+}
+
+// pngImage encodes a blank PNG of the given size
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// multipartForm builds a multipart body holding content as a file in field
+func multipartForm(t *testing.T, field string, content []byte) (string, map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile(field, "photo.png")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(content)
+	form.Close()
+	return buf.String(), map[string]string{"Content-Type": form.FormDataContentType()}
+}
+
+func TestProductImageHandler(t *testing.T) {
+	photo := pngImage(t, 40, 20)
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		field      string
+		content    []byte
+		rawBody    string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "upload", method: http.MethodPost, path: "/api/products/1/images", field: "image", content: photo, wantStatus: http.StatusCreated},
+		{name: "upload too large", method: http.MethodPost, path: "/api/products/1/images", field: "image", content: append(pngImage(t, 40, 20), make([]byte, 4096)...), wantStatus: http.StatusRequestEntityTooLarge, wantError: "Image too large"},
+		{name: "upload text file", method: http.MethodPost, path: "/api/products/1/images", field: "image", content: []byte("not a picture"), wantStatus: http.StatusUnsupportedMediaType, wantError: "Image must be a JPEG or PNG"},
+		{name: "upload corrupt png", method: http.MethodPost, path: "/api/products/1/images", field: "image", content: photo[:60], wantStatus: http.StatusBadRequest, wantError: "invalid input: image could not be read"},
+		{name: "upload empty file", method: http.MethodPost, path: "/api/products/1/images", field: "image", content: []byte{}, wantStatus: http.StatusBadRequest, wantError: "invalid input: image is empty"},
+		{name: "upload in another field", method: http.MethodPost, path: "/api/products/1/images", field: "photo", content: photo, wantStatus: http.StatusBadRequest, wantError: "Image file is required"},
+		{name: "upload without multipart form", method: http.MethodPost, path: "/api/products/1/images", rawBody: `{"image":"x"}`, wantStatus: http.StatusBadRequest, wantError: "Invalid multipart form"},
+		{name: "upload to missing product", method: http.MethodPost, path: "/api/products/99/images", field: "image", content: photo, wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "upload invalid product id", method: http.MethodPost, path: "/api/products/abc/images", field: "image", content: photo, wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "list", method: http.MethodGet, path: "/api/products/1/images", wantStatus: http.StatusOK},
+		{name: "list missing product", method: http.MethodGet, path: "/api/products/99/images", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "images method not allowed", method: http.MethodPut, path: "/api/products/1/images", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "delete missing image", method: http.MethodDelete, path: "/api/products/1/images/99", wantStatus: http.StatusNotFound, wantError: "Image not found"},
+		{name: "delete image of missing product", method: http.MethodDelete, path: "/api/products/99/images/1", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "delete invalid image id", method: http.MethodDelete, path: "/api/products/1/images/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid image ID"},
+		{name: "image method not allowed", method: http.MethodGet, path: "/api/products/1/images/1", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _, _ := newImageMux(t, 4096)
+			body, headers := tt.rawBody, map[string]string(nil)
+			if tt.field != "" {
+				body, headers = multipartForm(t, tt.field, tt.content)
+			}
+			rec := serve(mux, tt.method, tt.path, body, headers)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestProductImageHandler_UploadServeDelete(t *testing.T) {
+	mux, repos, dir := newImageMux(t, 1<<20)
+	body, headers := multipartForm(t, "image", pngImage(t, 600, 300))
+	headers["X-User"] = "budi"
+	rec := serve(mux, http.MethodPost, "/api/products/1/images", body, headers)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d, want 201", rec.Code)
+	}
+	var uploaded domain.ProductImage
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &uploaded); err != nil {
+		t.Fatalf("decode image: %v", err)
+	}
+	if uploaded.ContentType != "image/png" || uploaded.Width != 600 || uploaded.Height != 300 || uploaded.ThumbnailURL == "" {
+		t.Fatalf("image = %+v, want a 600x300 PNG with a thumbnail", uploaded)
+	}
+
+	rec = serve(mux, http.MethodGet, uploaded.ThumbnailURL, "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("fetch thumbnail status = %d, want 200", rec.Code)
+	}
+	thumb, err := png.DecodeConfig(rec.Body)
+	if err != nil {
+		t.Fatalf("decode thumbnail: %v", err)
+	}
+	if thumb.Width != domain.ThumbnailSize || thumb.Height != domain.ThumbnailSize/2 {
+		t.Errorf("thumbnail = %dx%d, want %dx%d", thumb.Width, thumb.Height, domain.ThumbnailSize, domain.ThumbnailSize/2)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/products/1", "", nil)
+	var product domain.Product
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &product); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	if len(product.Images) != 1 || product.Images[0].URL != uploaded.URL {
+		t.Errorf("product images = %+v, want the uploaded image", product.Images)
+	}
+
+	entries, err := repos.Audit.List(domain.AuditFilter{EntityType: domain.AuditEntityProduct, EntityID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != domain.AuditActionUpdate || entries[0].Actor != "budi" {
+		t.Errorf("audit = %+v, want one product update by budi", entries)
+	}
+
+	rec = serve(mux, http.MethodDelete, "/api/products/1/images/1", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200", rec.Code)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "products", "1", "*"))
+	if err != nil || len(files) != 0 {
+		t.Errorf("files after delete = %v, %v, want none", files, err)
+	}
+	if rec := serve(mux, http.MethodGet, uploaded.URL, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("fetch deleted image status = %d, want 404", rec.Code)
+	}
+}
+
+func TestProductImageHandler_DeleteProductRemovesImages(t *testing.T) {
+	mux, repos, dir := newImageMux(t, 1<<20)
+	body, headers := multipartForm(t, "image", pngImage(t, 40, 40))
+	if rec := serve(mux, http.MethodPost, "/api/products/1/images", body, headers); rec.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d, want 201", rec.Code)
+	}
+
+	if rec := serve(mux, http.MethodDelete, "/api/products/1", "", nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete product status = %d, want 200", rec.Code)
+	}
+	if images, err := repos.ProductImages.GetByProductIDs([]int{1}); err != nil || len(images) != 0 {
+		t.Errorf("image records = %+v, %v, want none", images, err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "products", "1", "*"))
+	if err != nil || len(files) != 0 {
+		t.Errorf("files after product delete = %v, %v, want none", files, err)
+	}
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// PromotionHandler handles HTTP requests for promotions
+type PromotionHandler struct {
+	service *service.PromotionService
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(service *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// HandlePromotions handles GET and POST requests for /api/promotions
+func (h *PromotionHandler) HandlePromotions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all promotions
+// @Description  Retrieve a list of all promotions, including inactive ones
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.Promotion
+// @Failure      500  {string}  string  "Failed to fetch promotions"
+// @Router       /promotions [get]
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.service.GetAll()
+	if err != nil {
+		log.Println("Error fetching promotions:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch promotions")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, promotions)
+}
+
+// Create godoc
+// @Summary      Create a new promotion
+// @Description  Create a percentage, fixed amount or buy X get Y promotion scoped to a product, category or the whole cart
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        promotion  body      domain.PromotionInput  true   "Promotion data"
+// @Param        X-User     header    string                 false  "User making the change, recorded in the audit log"
+// @Success      201        {object}  domain.Promotion
+// @Failure      400        {string}  string  "Invalid request body"
+// @Failure      400        {string}  string  "Product not found"
+// @Failure      400        {string}  string  "Category not found"
+// @Router       /promotions [post]
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var promotion domain.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&promotion); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.Create(&promotion, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error creating promotion:", err)
+		writePromotionError(w, err, "Failed to create promotion")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, promotion)
+}
+
+// HandlePromotionByID handles GET, PUT, DELETE requests for /api/promotions/{id}
+func (h *PromotionHandler) HandlePromotionByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get promotion by ID
+// @Description  Retrieve a single promotion by its ID
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Promotion ID"
+// @Success      200  {object}  domain.Promotion
+// @Failure      400  {string}  string  "Invalid promotion ID"
+// @Failure      404  {string}  string  "Promotion not found"
+// @Router       /promotions/{id} [get]
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/promotions/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	promotion, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching promotion by ID:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Promotion not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch promotion")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, promotion)
+}
+
+// Update godoc
+// @Summary      Update a promotion
+// @Description  Update an existing promotion by its ID
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int                    true   "Promotion ID"
+// @Param        promotion  body      domain.PromotionInput  true   "Promotion data"
+// @Param        X-User     header    string                 false  "User making the change, recorded in the audit log"
+// @Success      200        {object}  domain.Promotion
+// @Failure      400        {string}  string  "Invalid promotion ID or request body"
+// @Failure      404        {string}  string  "Promotion not found"
+// @Router       /promotions/{id} [put]
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/promotions/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	var promotion domain.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&promotion); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	promotion.ID = id
+	if err := h.service.Update(&promotion, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error updating promotion:", err)
+		writePromotionError(w, err, "Failed to update promotion")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, promotion)
+}
+
+// Delete godoc
+// @Summary      Delete a promotion
+// @Description  Delete a promotion by its ID
+// @Tags         promotions
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Promotion ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Promotion deleted successfully"
+// @Failure      400  {string}  string  "Invalid promotion ID"
+// @Failure      404  {string}  string  "Promotion not found"
+// @Router       /promotions/{id} [delete]
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/promotions/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting promotion:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Promotion not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to delete promotion")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Promotion deleted successfully"})
+}
+
+func writePromotionError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Promotion not found")
+	case errors.Is(err, apperrors.ErrProductNotFound):
+		WriteError(w, http.StatusBadRequest, "Product not found")
+	case errors.Is(err, apperrors.ErrCategoryNotFound):
+		WriteError(w, http.StatusBadRequest, "Category not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+func newPromotionHandler(t *testing.T) *handler.PromotionHandler {
+	t.Helper()
+	repos := newRepos(t)
+	h := handler.NewPromotionHandler(service.NewPromotionService(repos.Promotions, repos.Products, repos.Categories,
+		memory.NewTransactor(repos)))
+
+	seed := `{"name":"Diskon Snack","type":"percentage","scope":"category","category_id":1,"value":10,"active":true}`
+	rec := httptest.NewRecorder()
+	h.HandlePromotions(rec, httptest.NewRequest(http.MethodPost, "/api/promotions", strings.NewReader(seed)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("seed promotion status = %d", rec.Code)
+	}
+	return h
+}
+
+func TestPromotionHandler_HandlePromotions(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "create buy 2 get 1", method: http.MethodPost, body: `{"name":"B2G1","type":"buy_x_get_y","scope":"product","product_id":1,"buy_quantity":2,"free_quantity":1,"active":true}`, wantStatus: http.StatusCreated},
+		{name: "create cart fixed with window", method: http.MethodPost, body: `{"name":"Potongan 5rb","type":"fixed_amount","scope":"cart","value":5000,"min_subtotal":50000,"starts_at":"2026-03-01T00:00:00Z","ends_at":"2026-04-01T00:00:00Z"}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create with unknown type", method: http.MethodPost, body: `{"name":"X","type":"bogus","scope":"cart","value":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: type must be percentage, fixed_amount or buy_x_get_y"},
+		{name: "create with percentage over 100", method: http.MethodPost, body: `{"name":"X","type":"percentage","scope":"cart","value":150}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: percentage value must be between 1 and 100"},
+		{name: "create buy x get y on cart", method: http.MethodPost, body: `{"name":"X","type":"buy_x_get_y","scope":"cart","buy_quantity":1,"free_quantity":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: buy_x_get_y cannot be scoped to the whole cart"},
+		{name: "create with inverted window", method: http.MethodPost, body: `{"name":"X","type":"fixed_amount","scope":"cart","value":1,"starts_at":"2026-04-01T00:00:00Z","ends_at":"2026-03-01T00:00:00Z"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: ends_at must be after starts_at"},
+		{name: "create without product", method: http.MethodPost, body: `{"name":"X","type":"fixed_amount","scope":"product","value":1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product_id is required for product scope"},
+		{name: "create with unknown product", method: http.MethodPost, body: `{"name":"X","type":"fixed_amount","scope":"product","product_id":99,"value":1}`, wantStatus: http.StatusBadRequest, wantError: "Product not found"},
+		{name: "create with unknown category", method: http.MethodPost, body: `{"name":"X","type":"fixed_amount","scope":"category","category_id":99,"value":1}`, wantStatus: http.StatusBadRequest, wantError: "Category not found"},
+		{name: "method not allowed", method: http.MethodDelete, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newPromotionHandler(t)
+			req := httptest.NewRequest(tt.method, "/api/promotions", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandlePromotions(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPromotionHandler_HandlePromotionByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/promotions/1", wantStatus: http.StatusOK},
+		{name: "get with invalid id", method: http.MethodGet, path: "/api/promotions/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid promotion ID"},
+		{name: "get missing", method: http.MethodGet, path: "/api/promotions/99", wantStatus: http.StatusNotFound, wantError: "Promotion not found"},
+		{name: "update", method: http.MethodPut, path: "/api/promotions/1", body: `{"name":"Diskon Snack","type":"percentage","scope":"category","category_id":1,"value":15,"active":true}`, wantStatus: http.StatusOK},
+		{name: "update with malformed body", method: http.MethodPut, path: "/api/promotions/1", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "update invalid", method: http.MethodPut, path: "/api/promotions/1", body: `{"name":"","type":"percentage","scope":"cart","value":15}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: name is required"},
+		{name: "update missing", method: http.MethodPut, path: "/api/promotions/99", body: `{"name":"X","type":"percentage","scope":"cart","value":15}`, wantStatus: http.StatusNotFound, wantError: "Promotion not found"},
+		{name: "delete", method: http.MethodDelete, path: "/api/promotions/1", wantStatus: http.StatusOK},
+		{name: "delete with invalid id", method: http.MethodDelete, path: "/api/promotions/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid promotion ID"},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/promotions/99", wantStatus: http.StatusNotFound, wantError: "Promotion not found"},
+		{name: "method not allowed", method: http.MethodPost, path: "/api/promotions/1", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newPromotionHandler(t)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandlePromotionByID(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// PurchaseOrderHandler handles HTTP requests for purchase orders
+type PurchaseOrderHandler struct {
+	service *service.PurchaseOrderService
+}
+
+// NewPurchaseOrderHandler creates a new purchase order handler
+func NewPurchaseOrderHandler(service *service.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+// HandlePurchaseOrders handles GET and POST requests for /api/purchase-orders
+func (h *PurchaseOrderHandler) HandlePurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all purchase orders
+// @Description  Retrieve purchase orders, optionally only those with a status such as partially_received or for one outlet
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        status     query     string  false  "Purchase order status"  Enums(ordered, partially_received, received, cancelled)
+// @Param        outlet_id  query     int     false  "Only orders delivered to this outlet"
+// @Success      200     {array}   domain.PurchaseOrder
+// @Failure      400     {string}  string  "Invalid status"
+// @Failure      400     {string}  string  "Invalid outlet ID"
+// @Failure      400     {string}  string  "Outlet not found"
+// @Failure      500     {string}  string  "Failed to fetch purchase orders"
+// @Router       /purchase-orders [get]
+func (h *PurchaseOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	orders, err := h.service.GetAll(r.URL.Query().Get("status"), outletID)
+	if err != nil {
+		log.Println("Error fetching purchase orders:", err)
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, apperrors.ErrOutletNotFound) {
+			WriteError(w, http.StatusBadRequest, "Outlet not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch purchase orders")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, orders)
+}
+
+// Create godoc
+// @Summary      Create a purchase order
+// @Description  Order products from a supplier at a unit cost for delivery to an outlet (the default outlet if none is given). Stock only changes when the order is received.
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        order   body      domain.PurchaseOrderInput  true   "Supplier and lines to order"
+// @Param        X-User  header    string                     false  "User making the change, recorded in the audit log"
+// @Success      201     {object}  domain.PurchaseOrder
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      400     {string}  string  "Supplier not found"
+// @Failure      400     {string}  string  "Outlet not found"
+// @Failure      400     {string}  string  "Product not found"
+// @Router       /purchase-orders [post]
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.PurchaseOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	order, err := h.service.Create(input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error creating purchase order:", err)
+		writePurchaseOrderError(w, err, "", "Failed to create purchase order")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, order)
+}
+
+// HandlePurchaseOrderByID handles GET requests for /api/purchase-orders/{id}
+func (h *PurchaseOrderHandler) HandlePurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get purchase order by ID
+// @Description  Retrieve a single purchase order with how much of each line has been received
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Purchase order ID"
+// @Success      200  {object}  domain.PurchaseOrder
+// @Failure      400  {string}  string  "Invalid purchase order ID"
+// @Failure      404  {string}  string  "Purchase order not found"
+// @Router       /purchase-orders/{id} [get]
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := purchaseOrderIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching purchase order by ID:", err)
+		writePurchaseOrderError(w, err, "", "Failed to fetch purchase order")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, order)
+}
+
+// HandleReceive handles POST requests for /api/purchase-orders/{id}/receive
+func (h *PurchaseOrderHandler) HandleReceive(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Receive(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Receive godoc
+// @Summary      Receive a delivery
+// @Description  Add delivered quantities to the stock of the order's outlet through the stock ledger and update each product's cost price to the weighted average of stock on hand and the delivery. Orders can be received in several deliveries.
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                  true   "Purchase order ID"
+// @Param        delivery  body      domain.ReceiveInput  true   "Quantities received per product"
+// @Param        X-User    header    string               false  "User receiving the delivery"
+// @Success      200       {object}  domain.PurchaseOrder
+// @Failure      400       {string}  string  "Invalid purchase order ID or request body"
+// @Failure      404       {string}  string  "Purchase order not found"
+// @Failure      409       {string}  string  "Purchase order is already received or cancelled"
+// @Router       /purchase-orders/{id}/receive [post]
+func (h *PurchaseOrderHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	id, ok := purchaseOrderIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var input domain.ReceiveInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	order, err := h.service.Receive(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error receiving purchase order:", err)
+		writePurchaseOrderError(w, err, "Purchase order is already received or cancelled",
+			"Failed to receive purchase order")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, order)
+}
+
+// HandleCancel handles POST requests for /api/purchase-orders/{id}/cancel
+func (h *PurchaseOrderHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Cancel(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Cancel godoc
+// @Summary      Cancel a purchase order
+// @Description  Stop an order from receiving anything more. Stock already received is kept.
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Purchase order ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200     {object}  domain.PurchaseOrder
+// @Failure      400     {string}  string  "Invalid purchase order ID"
+// @Failure      404     {string}  string  "Purchase order not found"
+// @Failure      409     {string}  string  "Purchase order is already received or cancelled"
+// @Router       /purchase-orders/{id}/cancel [post]
+func (h *PurchaseOrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := purchaseOrderIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.Cancel(id, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error cancelling purchase order:", err)
+		writePurchaseOrderError(w, err, "Purchase order is already received or cancelled",
+			"Failed to cancel purchase order")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, order)
+}
+
+func purchaseOrderIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid purchase order ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writePurchaseOrderError maps purchase order errors to responses. conflict
+// is the message for an order whose status does not allow the operation.
+func writePurchaseOrderError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Purchase order not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrSupplierNotFound):
+		WriteError(w, http.StatusBadRequest, "Supplier not found")
+	case errors.Is(err, apperrors.ErrOutletNotFound):
+		WriteError(w, http.StatusBadRequest, "Outlet not found")
+	case errors.Is(err, apperrors.ErrProductNotFound):
+		WriteError(w, http.StatusBadRequest, "Product not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
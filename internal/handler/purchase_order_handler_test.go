@@ -0,0 +1,244 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newPurchaseOrderMux wires the purchase order and product stock ledger
+// routes the same way router.New does. Product 1 costs 2800, and supplier 1
+// is seeded with purchase order 1 for 100 of it at 3000 and purchase order 2,
+// which is cancelled.
+func newPurchaseOrderMux(t *testing.T) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := newRepos(t)
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	product.CostPrice = 2800
+	if err := repos.Products.Update(product); err != nil {
+		t.Fatalf("set cost price: %v", err)
+	}
+	supplier := domain.Supplier{Name: "PT Indofood"}
+	if err := repos.Suppliers.Create(&supplier); err != nil {
+		t.Fatalf("seed supplier: %v", err)
+	}
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	for _, status := range []string{domain.PurchaseOrderStatusOrdered, domain.PurchaseOrderStatusCancelled} {
+		po := domain.PurchaseOrder{
+			SupplierID: supplier.ID, OutletID: domain.DefaultOutletID, Status: status, CreatedAt: createdAt, UpdatedAt: createdAt,
+			Lines: []domain.PurchaseOrderLine{{ProductID: 1, Quantity: 100, UnitCost: 3000}},
+		}
+		if err := repos.PurchaseOrders.Create(&po); err != nil {
+			t.Fatalf("seed purchase order: %v", err)
+		}
+	}
+
+	tx := memory.NewTransactor(repos)
+	orders := handler.NewPurchaseOrderHandler(service.NewPurchaseOrderService(repos.PurchaseOrders, repos.Outlets, tx))
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), tx))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/purchase-orders", orders.HandlePurchaseOrders)
+	mux.HandleFunc("/api/purchase-orders/{id}", orders.HandlePurchaseOrderByID)
+	mux.HandleFunc("/api/purchase-orders/{id}/receive", orders.HandleReceive)
+	mux.HandleFunc("/api/purchase-orders/{id}/cancel", orders.HandleCancel)
+	mux.HandleFunc("/api/products/{id}/stock-movements", products.HandleStockMovements)
+	return mux, repos
+}
+
+func TestPurchaseOrderHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/purchase-orders", wantStatus: http.StatusOK},
+		{name: "list by status", method: http.MethodGet, path: "/api/purchase-orders?status=cancelled", wantStatus: http.StatusOK},
+		{name: "list at outlet", method: http.MethodGet, path: "/api/purchase-orders?outlet_id=1", wantStatus: http.StatusOK},
+		{name: "list at missing outlet", method: http.MethodGet, path: "/api/purchase-orders?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "list with unknown status", method: http.MethodGet, path: "/api/purchase-orders?status=lost", wantStatus: http.StatusBadRequest, wantError: "invalid input: status must be ordered, partially_received, received or cancelled"},
+		{name: "create", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[{"product_id":1,"quantity":48,"unit_cost":2800}]}`, wantStatus: http.StatusCreated},
+		{name: "create in unit without conversion", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[{"product_id":1,"unit_id":99,"quantity":2,"unit_cost":112000}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: Indomie Goreng has no conversion for unit 99"},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create without lines", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: lines must not be empty"},
+		{name: "create with zero quantity", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[{"product_id":1,"quantity":0,"unit_cost":2800}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must be greater than zero"},
+		{name: "create with negative cost", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[{"product_id":1,"quantity":1,"unit_cost":-1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: unit_cost must not be negative"},
+		{name: "create with duplicate product", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[{"product_id":1,"quantity":1,"unit_cost":1},{"product_id":1,"quantity":2,"unit_cost":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product 1 is listed more than once"},
+		{name: "create for missing supplier", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":99,"lines":[{"product_id":1,"quantity":1,"unit_cost":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Supplier not found"},
+		{name: "create for missing outlet", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"outlet_id":99,"lines":[{"product_id":1,"quantity":1,"unit_cost":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "create for missing product", method: http.MethodPost, path: "/api/purchase-orders", body: `{"supplier_id":1,"lines":[{"product_id":99,"quantity":1,"unit_cost":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Product not found"},
+		{name: "purchase orders method not allowed", method: http.MethodDelete, path: "/api/purchase-orders", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/purchase-orders/1", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/purchase-orders/99", wantStatus: http.StatusNotFound, wantError: "Purchase order not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/purchase-orders/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid purchase order ID"},
+		{name: "receive", method: http.MethodPost, path: "/api/purchase-orders/1/receive", body: `{"lines":[{"product_id":1,"quantity":40}]}`, wantStatus: http.StatusOK},
+		{name: "receive more than ordered", method: http.MethodPost, path: "/api/purchase-orders/1/receive", body: `{"lines":[{"product_id":1,"quantity":101}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product 1 has only 100 left to receive"},
+		{name: "receive product not on order", method: http.MethodPost, path: "/api/purchase-orders/1/receive", body: `{"lines":[{"product_id":2,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product 2 is not on this purchase order"},
+		{name: "receive nothing", method: http.MethodPost, path: "/api/purchase-orders/1/receive", body: `{"lines":[]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: lines must not be empty"},
+		{name: "receive cancelled order", method: http.MethodPost, path: "/api/purchase-orders/2/receive", body: `{"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusConflict, wantError: "Purchase order is already received or cancelled"},
+		{name: "receive missing order", method: http.MethodPost, path: "/api/purchase-orders/99/receive", body: `{"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusNotFound, wantError: "Purchase order not found"},
+		{name: "receive method not allowed", method: http.MethodGet, path: "/api/purchase-orders/1/receive", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "cancel", method: http.MethodPost, path: "/api/purchase-orders/1/cancel", wantStatus: http.StatusOK},
+		{name: "cancel cancelled order", method: http.MethodPost, path: "/api/purchase-orders/2/cancel", wantStatus: http.StatusConflict, wantError: "Purchase order is already received or cancelled"},
+		{name: "stock movements", method: http.MethodGet, path: "/api/products/1/stock-movements", wantStatus: http.StatusOK},
+		{name: "stock movements at outlet", method: http.MethodGet, path: "/api/products/1/stock-movements?outlet_id=1", wantStatus: http.StatusOK},
+		{name: "stock movements with invalid outlet", method: http.MethodGet, path: "/api/products/1/stock-movements?outlet_id=0", wantStatus: http.StatusBadRequest, wantError: "Invalid outlet ID"},
+		{name: "stock movements of missing product", method: http.MethodGet, path: "/api/products/99/stock-movements", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newPurchaseOrderMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPurchaseOrderHandler_PartialReceipts(t *testing.T) {
+	mux, repos := newPurchaseOrderMux(t)
+	headers := map[string]string{"X-User": "budi"}
+
+	receive := func(quantity int, wantStatus string) {
+		t.Helper()
+		body := `{"lines":[{"product_id":1,"quantity":` + strconv.Itoa(quantity) + `}]}`
+		rec := serve(mux, http.MethodPost, "/api/purchase-orders/1/receive", body, headers)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("receive %d: status = %d, body %s", quantity, rec.Code, rec.Body)
+		}
+		var po domain.PurchaseOrder
+		if err := json.Unmarshal(decodeResponse(t, rec).Data, &po); err != nil {
+			t.Fatalf("decode purchase order: %v", err)
+		}
+		if po.Status != wantStatus {
+			t.Errorf("after receiving %d: status = %q, want %q", quantity, po.Status, wantStatus)
+		}
+	}
+
+	// 100 on hand at 2800 plus 100 at 3000 averages to 2900
+	receive(40, domain.PurchaseOrderStatusPartiallyReceived)
+	receive(60, domain.PurchaseOrderStatusReceived)
+
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if product.Stock != 200 || product.CostPrice != 2900 {
+		t.Errorf("product stock, cost = %d, %d, want 200, 2900", product.Stock, product.CostPrice)
+	}
+
+	rec := serve(mux, http.MethodPost, "/api/purchase-orders/1/receive", `{"lines":[{"product_id":1,"quantity":1}]}`, headers)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("receive after complete: status = %d, want 409", rec.Code)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/products/1/stock-movements", "", nil)
+	var movements []domain.StockMovement
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &movements); err != nil {
+		t.Fatalf("decode stock movements: %v", err)
+	}
+	if len(movements) != 2 || movements[0].Quantity != 60 || movements[1].Quantity != 40 {
+		t.Fatalf("stock movements = %+v, want 60 then 40", movements)
+	}
+	if m := movements[0]; m.Reason != domain.StockMovementPurchase || m.Reference != "PO-1" || m.Actor != "budi" {
+		t.Errorf("movement = %+v, want a purchase for PO-1 by budi", m)
+	}
+}
+
+func TestPurchaseOrderHandler_ReceiveInUnits(t *testing.T) {
+	mux, repos := newPurchaseOrderMux(t)
+	dus := domain.Unit{Code: "dus", Name: "Dus"}
+	if err := repos.Units.Create(&dus); err != nil {
+		t.Fatalf("create unit: %v", err)
+	}
+	if err := repos.ProductUnits.ReplaceForProduct(1, []domain.ProductUnit{{UnitID: dus.ID, Factor: 40}}); err != nil {
+		t.Fatalf("set product units: %v", err)
+	}
+
+	body := `{"supplier_id":1,"lines":[{"product_id":1,"unit_id":2,"quantity":2,"unit_cost":124000}]}`
+	rec := serve(mux, http.MethodPost, "/api/purchase-orders", body, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body)
+	}
+	var po domain.PurchaseOrder
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &po); err != nil {
+		t.Fatalf("decode purchase order: %v", err)
+	}
+	if line := po.Lines[0]; line.UnitID != dus.ID || line.Factor != 40 {
+		t.Errorf("line = %+v, want ordered by the dus of 40", line)
+	}
+
+	// Two dus of 40 at 124000 each are 80 pieces at 3100
+	path := "/api/purchase-orders/" + strconv.Itoa(po.ID) + "/receive"
+	rec = serve(mux, http.MethodPost, path, `{"lines":[{"product_id":1,"quantity":2}]}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("receive status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// 100 on hand at 2800 plus 80 at 3100 averages to 2933
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if product.Stock != 180 || product.CostPrice != 2933 {
+		t.Errorf("product stock, cost = %d, %d, want 180, 2933", product.Stock, product.CostPrice)
+	}
+
+	rec = serve(mux, http.MethodGet, "/api/products/1/stock-movements", "", nil)
+	var movements []domain.StockMovement
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &movements); err != nil {
+		t.Fatalf("decode stock movements: %v", err)
+	}
+	if len(movements) != 1 || movements[0].Quantity != 80 {
+		t.Errorf("stock movements = %+v, want 80 pieces received", movements)
+	}
+}
+
+func TestPurchaseOrderHandler_ReceiveBatches(t *testing.T) {
+	mux, repos := newPurchaseOrderMux(t)
+
+	body := `{"lines":[{"product_id":1,"quantity":40,"lot_number":" L2403A ","expires_at":"2026-03-20T00:00:00Z"}]}`
+	if rec := serve(mux, http.MethodPost, "/api/purchase-orders/1/receive", body, nil); rec.Code != http.StatusOK {
+		t.Fatalf("receive with lot status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/purchase-orders/1/receive", `{"lines":[{"product_id":1,"quantity":10}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("receive without lot status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// Only the delivery with a lot and expiry is batched
+	batches, err := repos.StockBatches.GetInStock(1, domain.DefaultOutletID)
+	if err != nil {
+		t.Fatalf("GetInStock: %v", err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("batches = %+v, want one", batches)
+	}
+	b := batches[0]
+	if b.LotNumber != "L2403A" || b.ExpiresAt == nil || !b.ExpiresAt.Equal(time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)) ||
+		b.Quantity != 40 || b.Remaining != 40 {
+		t.Errorf("batch = %+v, want 40 of lot L2403A expiring 2026-03-20", b)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// QuoteHandler handles HTTP requests for basket quotes
+type QuoteHandler struct {
+	service *service.QuoteService
+}
+
+// NewQuoteHandler creates a new quote handler
+func NewQuoteHandler(service *service.QuoteService) *QuoteHandler {
+	return &QuoteHandler{service: service}
+}
+
+// HandleQuotes handles POST requests for /api/quotes
+func (h *QuoteHandler) HandleQuotes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Create godoc
+// @Summary      Price a basket
+// @Description  Price the given items with current prices and active promotions, without selling them
+// @Tags         quotes
+// @Accept       json
+// @Produce      json
+// @Param        basket  body      domain.QuoteRequest  true  "Items to price"
+// @Success      200     {object}  domain.Quote
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      400     {string}  string  "Product not found"
+// @Failure      500     {string}  string  "Failed to price basket"
+// @Router       /quotes [post]
+func (h *QuoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	quote, err := h.service.Quote(req)
+	if err != nil {
+		log.Println("Error pricing basket:", err)
+		switch {
+		case errors.Is(err, apperrors.ErrProductNotFound):
+			WriteError(w, http.StatusBadRequest, "Product not found")
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			WriteError(w, http.StatusInternalServerError, "Failed to price basket")
+		}
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, quote)
+}
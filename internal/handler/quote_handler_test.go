@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/service"
+)
+
+func newQuoteHandler(t *testing.T) *handler.QuoteHandler {
+	t.Helper()
+	repos := newRepos(t)
+	promotions := []domain.Promotion{
+		{Name: "Diskon Snack", Type: domain.PromotionPercentage, Scope: domain.PromotionScopeCategory, CategoryID: intPtr(1), Value: 10, Active: true},
+		{Name: "Nonaktif", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeCart, Value: 1000, Active: false},
+	}
+	for i := range promotions {
+		if err := repos.Promotions.Create(&promotions[i]); err != nil {
+			t.Fatalf("seed promotion: %v", err)
+		}
+	}
+	return handler.NewQuoteHandler(service.NewQuoteService(repos.Products, repos.ProductUnits, repos.Categories, repos.Promotions, repos.TaxRates))
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestQuoteHandler_HandleQuotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "quote", method: http.MethodPost, body: `{"items":[{"product_id":1,"quantity":2}]}`, wantStatus: http.StatusOK},
+		{name: "malformed body", method: http.MethodPost, body: `{"items":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "empty basket", method: http.MethodPost, body: `{"items":[]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: items must not be empty"},
+		{name: "zero quantity", method: http.MethodPost, body: `{"items":[{"product_id":1,"quantity":0}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must be positive"},
+		{name: "unknown product", method: http.MethodPost, body: `{"items":[{"product_id":99,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Product not found"},
+		{name: "method not allowed", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newQuoteHandler(t)
+			req := httptest.NewRequest(tt.method, "/api/quotes", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleQuotes(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestQuoteHandler_AppliesActivePromotions(t *testing.T) {
+	h := newQuoteHandler(t)
+	body := `{"items":[{"product_id":1,"quantity":2},{"product_id":1,"quantity":1}]}`
+	rec := httptest.NewRecorder()
+
+	h.HandleQuotes(rec, httptest.NewRequest(http.MethodPost, "/api/quotes", strings.NewReader(body)))
+
+	var quote domain.Quote
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &quote); err != nil {
+		t.Fatalf("decode quote: %v", err)
+	}
+	if len(quote.Lines) != 1 || quote.Lines[0].Quantity != 3 {
+		t.Fatalf("lines = %+v, want one merged line of 3", quote.Lines)
+	}
+	if quote.Subtotal != 10500 || quote.Discount != 1050 || quote.Total != 9450 || len(quote.CartDiscounts) != 0 {
+		t.Errorf("quote = %+v, want 10500 - 1050 = 9450 without the inactive cart promotion", quote)
+	}
+}
+
+func TestQuoteHandler_AppliesCategoryTax(t *testing.T) {
+	repos := newRepos(t)
+	rate := domain.TaxRate{Name: "PPN", Rate: 11, Inclusive: true}
+	if err := repos.TaxRates.Create(&rate); err != nil {
+		t.Fatalf("seed tax rate: %v", err)
+	}
+	category, err := repos.Categories.GetByID(1)
+	if err != nil {
+		t.Fatalf("get category: %v", err)
+	}
+	category.TaxRateID = &rate.ID
+	if err := repos.Categories.Update(category); err != nil {
+		t.Fatalf("assign tax rate: %v", err)
+	}
+	h := handler.NewQuoteHandler(service.NewQuoteService(repos.Products, repos.ProductUnits, repos.Categories, repos.Promotions, repos.TaxRates))
+	rec := httptest.NewRecorder()
+
+	h.HandleQuotes(rec, httptest.NewRequest(http.MethodPost, "/api/quotes", strings.NewReader(`{"items":[{"product_id":1,"quantity":3}]}`)))
+
+	var quote domain.Quote
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &quote); err != nil {
+		t.Fatalf("decode quote: %v", err)
+	}
+	// 10500 already includes 11% PPN: 10500 * 11 / 111 = 1040.5, rounded up
+	if quote.Tax != 1041 || quote.Total != 10500 || len(quote.Taxes) != 1 || quote.Taxes[0].Taxable != 9459 {
+		t.Errorf("quote = %+v, want 1041 PPN included in a 10500 total", quote)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// ReportHandler handles HTTP requests for reports
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(service *service.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// HandleSales handles GET requests for /api/reports/sales
+func (h *ReportHandler) HandleSales(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.Sales(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Sales godoc
+// @Summary      Get sales report
+// @Description  Total the revenue net of tax, tax, cost and gross profit with margin of the sales checked out in a period, using the cost snapshotted on each sold line
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Param        from       query     string  false  "Only sales checked out at or after this RFC 3339 time"
+// @Param        to         query     string  false  "Only sales checked out before this RFC 3339 time"
+// @Param        outlet_id  query     int     false  "Only sales at this outlet"
+// @Success      200        {object}  domain.SalesReport
+// @Failure      400        {string}  string  "Invalid query parameter"
+// @Failure      400        {string}  string  "Outlet not found"
+// @Failure      500        {string}  string  "Failed to build sales report"
+// @Router       /reports/sales [get]
+func (h *ReportHandler) Sales(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSalesFilter(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid query parameter")
+		return
+	}
+
+	report, err := h.service.Sales(filter)
+	if err != nil {
+		log.Println("Error building sales report:", err)
+		writeReportError(w, err, "Failed to build sales report")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, report)
+}
+
+func parseSalesFilter(r *http.Request) (domain.SalesFilter, error) {
+	var filter domain.SalesFilter
+	var err error
+	if filter.OutletID, err = outletIDFromQuery(r); err != nil {
+		return filter, err
+	}
+
+	times := map[string]*time.Time{"from": &filter.From, "to": &filter.To}
+	for name, dst := range times {
+		if v := r.URL.Query().Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, err
+			}
+			*dst = t
+		}
+	}
+
+	return filter, nil
+}
+
+func writeReportError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrOutletNotFound):
+		WriteError(w, http.StatusBadRequest, "Outlet not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
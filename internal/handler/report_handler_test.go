@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/service"
+)
+
+func TestReportHandler_Sales(t *testing.T) {
+	mux, repos := newCartMux(t)
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	product.CostPrice = 2800
+	if err := repos.Products.Update(product); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":7000}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+	// A later cost change leaves the sale's cost alone
+	product.CostPrice = 3000
+	if err := repos.Products.Update(product); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+
+	reports := handler.NewReportHandler(service.NewReportService(repos.Carts, repos.Refunds, repos.Outlets))
+	reportMux := http.NewServeMux()
+	reportMux.HandleFunc("/api/reports/sales", reports.HandleSales)
+
+	rec := serve(reportMux, http.MethodGet, "/api/reports/sales", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var report domain.SalesReport
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	want := domain.SalesReport{Sales: 1, Revenue: 7000, Cost: 5600, GrossProfit: 1400, MarginPercent: 20}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "before the sale", query: "?to=2000-01-01T00:00:00Z", wantStatus: http.StatusOK},
+		{name: "bad time", query: "?from=yesterday", wantStatus: http.StatusBadRequest, wantError: "Invalid query parameter"},
+		{name: "empty period", query: "?from=2026-03-02T00:00:00Z&to=2026-03-01T00:00:00Z", wantStatus: http.StatusBadRequest, wantError: "invalid input: from must be before to"},
+		{name: "unknown outlet", query: "?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(reportMux, http.MethodGet, "/api/reports/sales"+tt.query, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if tt.wantError == "" && string(resp.Data) != `{"sales":0,"refunds":0,"revenue":0,"tax":0,"cost":0,"gross_profit":0,"margin_percent":0}` {
+				t.Errorf("data = %s, want an empty report", resp.Data)
+			}
+		})
+	}
+}
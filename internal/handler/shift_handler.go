@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// ShiftHandler handles HTTP requests for cashier shifts
+type ShiftHandler struct {
+	service *service.ShiftService
+}
+
+// NewShiftHandler creates a new shift handler
+func NewShiftHandler(service *service.ShiftService) *ShiftHandler {
+	return &ShiftHandler{service: service}
+}
+
+// HandleOpen handles POST requests for /api/shifts/open
+func (h *ShiftHandler) HandleOpen(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Open(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Open godoc
+// @Summary      Open a shift
+// @Description  Open a shift for the cashier in the X-User header with an opening cash float
+// @Tags         shifts
+// @Accept       json
+// @Produce      json
+// @Param        shift   body      domain.OpenShiftInput  true  "Opening cash"
+// @Param        X-User  header    string                 true  "Cashier opening the shift"
+// @Success      201     {object}  domain.Shift
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      400     {string}  string  "X-User header is required"
+// @Failure      409     {string}  string  "Cashier already has an open shift"
+// @Router       /shifts/open [post]
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	meta := changeMetaFromRequest(r)
+	if meta.Actor == "" {
+		WriteError(w, http.StatusBadRequest, "X-User header is required")
+		return
+	}
+
+	var input domain.OpenShiftInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	shift, err := h.service.Open(input, meta)
+	if err != nil {
+		log.Println("Error opening shift:", err)
+		switch {
+		case errors.Is(err, apperrors.ErrConflict):
+			WriteError(w, http.StatusConflict, "Cashier already has an open shift")
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			WriteError(w, http.StatusInternalServerError, "Failed to open shift")
+		}
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, shift)
+}
+
+// HandleCurrent handles GET requests for /api/shifts/current
+func (h *ShiftHandler) HandleCurrent(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetCurrent(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetCurrent godoc
+// @Summary      Get current shift
+// @Description  Retrieve the open shift of the cashier in the X-User header
+// @Tags         shifts
+// @Accept       json
+// @Produce      json
+// @Param        X-User  header    string  true  "Cashier"
+// @Success      200     {object}  domain.Shift
+// @Failure      400     {string}  string  "X-User header is required"
+// @Failure      404     {string}  string  "No open shift"
+// @Router       /shifts/current [get]
+func (h *ShiftHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
+	cashier := changeMetaFromRequest(r).Actor
+	if cashier == "" {
+		WriteError(w, http.StatusBadRequest, "X-User header is required")
+		return
+	}
+
+	shift, err := h.service.GetCurrent(cashier)
+	if err != nil {
+		log.Println("Error fetching current shift:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "No open shift")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch shift")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, shift)
+}
+
+// HandleShiftByID handles GET requests for /api/shifts/{id}
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get shift by ID
+// @Description  Retrieve a single shift, including its reconciliation once closed
+// @Tags         shifts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Shift ID"
+// @Success      200  {object}  domain.Shift
+// @Failure      400  {string}  string  "Invalid shift ID"
+// @Failure      404  {string}  string  "Shift not found"
+// @Router       /shifts/{id} [get]
+func (h *ShiftHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid shift ID")
+		return
+	}
+
+	shift, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching shift by ID:", err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "Shift not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch shift")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, shift)
+}
+
+// HandleClose handles POST requests for /api/shifts/{id}/close
+func (h *ShiftHandler) HandleClose(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Close(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Close godoc
+// @Summary      Close a shift
+// @Description  Close a shift with the counted drawer cash and return expected vs counted cash, variance and payment-method totals
+// @Tags         shifts
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                     true   "Shift ID"
+// @Param        shift   body      domain.CloseShiftInput  true   "Counted cash"
+// @Param        X-User  header    string                  true   "Cashier closing the shift, who must be the one who opened it"
+// @Success      200     {object}  domain.ShiftReport
+// @Failure      400     {string}  string  "Invalid shift ID or request body"
+// @Failure      403     {string}  string  "Only the shift's cashier can close it"
+// @Failure      404     {string}  string  "Shift not found"
+// @Failure      409     {string}  string  "Shift is already closed"
+// @Router       /shifts/{id}/close [post]
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid shift ID")
+		return
+	}
+
+	var input domain.CloseShiftInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.service.Close(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error closing shift:", err)
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			WriteError(w, http.StatusNotFound, "Shift not found")
+		case errors.Is(err, apperrors.ErrForbidden):
+			WriteError(w, http.StatusForbidden, "Only the shift's cashier can close it")
+		case errors.Is(err, apperrors.ErrConflict):
+			WriteError(w, http.StatusConflict, "Shift is already closed")
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			WriteError(w, http.StatusInternalServerError, "Failed to close shift")
+		}
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, report)
+}
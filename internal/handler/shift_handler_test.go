@@ -0,0 +1,108 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newShiftMux wires the shift routes the same way router.New does and opens
+// shift 1 for budi
+func newShiftMux(t *testing.T) http.Handler {
+	t.Helper()
+	repos := newRepos(t)
+	h := handler.NewShiftHandler(service.NewShiftService(repos.Shifts, memory.NewTransactor(repos)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/shifts/open", h.HandleOpen)
+	mux.HandleFunc("/api/shifts/current", h.HandleCurrent)
+	mux.HandleFunc("/api/shifts/{id}", h.HandleShiftByID)
+	mux.HandleFunc("/api/shifts/{id}/close", h.HandleClose)
+
+	if rec := serve(mux, http.MethodPost, "/api/shifts/open", `{"opening_cash":200000}`, map[string]string{"X-User": "budi"}); rec.Code != http.StatusCreated {
+		t.Fatalf("seed shift status = %d", rec.Code)
+	}
+	return mux
+}
+
+func TestShiftHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		user       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "open", method: http.MethodPost, path: "/api/shifts/open", body: `{"opening_cash":150000}`, user: "siti", wantStatus: http.StatusCreated},
+		{name: "open without cashier", method: http.MethodPost, path: "/api/shifts/open", body: `{"opening_cash":150000}`, wantStatus: http.StatusBadRequest, wantError: "X-User header is required"},
+		{name: "open with malformed body", method: http.MethodPost, path: "/api/shifts/open", body: `{"opening_cash":`, user: "siti", wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "open with negative float", method: http.MethodPost, path: "/api/shifts/open", body: `{"opening_cash":-1}`, user: "siti", wantStatus: http.StatusBadRequest, wantError: "invalid input: opening_cash cannot be negative"},
+		{name: "open twice", method: http.MethodPost, path: "/api/shifts/open", body: `{"opening_cash":150000}`, user: "budi", wantStatus: http.StatusConflict, wantError: "Cashier already has an open shift"},
+		{name: "open method not allowed", method: http.MethodGet, path: "/api/shifts/open", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "current", method: http.MethodGet, path: "/api/shifts/current", user: "budi", wantStatus: http.StatusOK},
+		{name: "current without cashier", method: http.MethodGet, path: "/api/shifts/current", wantStatus: http.StatusBadRequest, wantError: "X-User header is required"},
+		{name: "current none open", method: http.MethodGet, path: "/api/shifts/current", user: "siti", wantStatus: http.StatusNotFound, wantError: "No open shift"},
+		{name: "get", method: http.MethodGet, path: "/api/shifts/1", wantStatus: http.StatusOK},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/shifts/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid shift ID"},
+		{name: "get missing", method: http.MethodGet, path: "/api/shifts/99", wantStatus: http.StatusNotFound, wantError: "Shift not found"},
+		{name: "close", method: http.MethodPost, path: "/api/shifts/1/close", body: `{"counted_cash":200000}`, user: "budi", wantStatus: http.StatusOK},
+		{name: "close by another cashier", method: http.MethodPost, path: "/api/shifts/1/close", body: `{"counted_cash":200000}`, user: "siti", wantStatus: http.StatusForbidden, wantError: "Only the shift's cashier can close it"},
+		{name: "close without cashier", method: http.MethodPost, path: "/api/shifts/1/close", body: `{"counted_cash":200000}`, wantStatus: http.StatusForbidden, wantError: "Only the shift's cashier can close it"},
+		{name: "close invalid id", method: http.MethodPost, path: "/api/shifts/abc/close", body: `{"counted_cash":0}`, wantStatus: http.StatusBadRequest, wantError: "Invalid shift ID"},
+		{name: "close missing", method: http.MethodPost, path: "/api/shifts/99/close", body: `{"counted_cash":0}`, wantStatus: http.StatusNotFound, wantError: "Shift not found"},
+		{name: "close with negative count", method: http.MethodPost, path: "/api/shifts/1/close", body: `{"counted_cash":-5}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: counted_cash cannot be negative"},
+		{name: "close method not allowed", method: http.MethodGet, path: "/api/shifts/1/close", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newShiftMux(t)
+			headers := map[string]string{}
+			if tt.user != "" {
+				headers["X-User"] = tt.user
+			}
+
+			rec := serve(mux, tt.method, tt.path, tt.body, headers)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestShiftHandler_CloseReconcilesCash(t *testing.T) {
+	mux := newShiftMux(t)
+	user := map[string]string{"X-User": "budi"}
+
+	rec := serve(mux, http.MethodPost, "/api/shifts/1/close", `{"counted_cash":199500}`, user)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("close status = %d", rec.Code)
+	}
+	var report domain.ShiftReport
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.ExpectedCash != 200000 || report.CountedCash != 199500 || report.Variance != -500 || report.Shift.IsOpen() {
+		t.Errorf("report = %+v, want 500 short against the 200000 float", report)
+	}
+
+	rec = serve(mux, http.MethodPost, "/api/shifts/1/close", `{"counted_cash":199500}`, user)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second close status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/shifts/open", `{"opening_cash":100000}`, user); rec.Code != http.StatusCreated {
+		t.Errorf("reopen after close status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// StockCountHandler handles HTTP requests for stock counts (stock opname)
+type StockCountHandler struct {
+	service *service.StockCountService
+}
+
+// NewStockCountHandler creates a new stock count handler
+func NewStockCountHandler(service *service.StockCountService) *StockCountHandler {
+	return &StockCountHandler{service: service}
+}
+
+// HandleStockCounts handles GET and POST requests for /api/stock-counts
+func (h *StockCountHandler) HandleStockCounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all stock counts
+// @Description  Retrieve stock counts with their lines and entries, optionally only those at one outlet or with a status
+// @Tags         stock-counts
+// @Accept       json
+// @Produce      json
+// @Param        outlet_id  query     int     false  "Only counts at this outlet"
+// @Param        status     query     string  false  "Stock count status"  Enums(counting, approved, cancelled)
+// @Success      200        {array}   domain.StockCount
+// @Failure      400        {string}  string  "Invalid outlet ID"
+// @Failure      400        {string}  string  "Invalid status"
+// @Failure      400        {string}  string  "Outlet not found"
+// @Failure      500        {string}  string  "Failed to fetch stock counts"
+// @Router       /stock-counts [get]
+func (h *StockCountHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	counts, err := h.service.GetAll(outletID, r.URL.Query().Get("status"))
+	if err != nil {
+		log.Println("Error fetching stock counts:", err)
+		writeStockCountError(w, err, "", "Failed to fetch stock counts")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, counts)
+}
+
+// Create godoc
+// @Summary      Start a stock count
+// @Description  Start counting the stocked products at an outlet, or only those in a category and its subcategories. Each line keeps the product's system stock as the count starts.
+// @Tags         stock-counts
+// @Accept       json
+// @Produce      json
+// @Param        count   body      domain.StockCountInput  true   "Outlet and category to count"
+// @Param        X-User  header    string                  false  "User starting the count, recorded in the audit log"
+// @Success      201     {object}  domain.StockCount
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      400     {string}  string  "Outlet not found"
+// @Failure      400     {string}  string  "Category not found"
+// @Router       /stock-counts [post]
+func (h *StockCountHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.StockCountInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	count, err := h.service.Create(input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error creating stock count:", err)
+		writeStockCountError(w, err, "", "Failed to create stock count")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, count)
+}
+
+// HandleStockCountByID handles GET requests for /api/stock-counts/{id}
+func (h *StockCountHandler) HandleStockCountByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get stock count by ID
+// @Description  Retrieve a stock count with the variance of each counted line against system stock and every entry the counters recorded
+// @Tags         stock-counts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Stock count ID"
+// @Success      200  {object}  domain.StockCount
+// @Failure      400  {string}  string  "Invalid stock count ID"
+// @Failure      404  {string}  string  "Stock count not found"
+// @Router       /stock-counts/{id} [get]
+func (h *StockCountHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockCountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching stock count by ID:", err)
+		writeStockCountError(w, err, "", "Failed to fetch stock count")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, count)
+}
+
+// HandleEntries handles POST requests for /api/stock-counts/{id}/entries
+func (h *StockCountHandler) HandleEntries(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Count(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Count godoc
+// @Summary      Record counted quantities
+// @Description  Record what a counter found of each product. Entries from several counters add up; a negative quantity corrects an earlier entry.
+// @Tags         stock-counts
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                          true   "Stock count ID"
+// @Param        entries  body      domain.StockCountEntryInput  true   "Counted quantities"
+// @Param        X-User   header    string                       false  "User who counted, recorded on each entry"
+// @Success      200      {object}  domain.StockCount
+// @Failure      400      {string}  string  "Invalid stock count ID"
+// @Failure      400      {string}  string  "Invalid request body"
+// @Failure      404      {string}  string  "Stock count not found"
+// @Failure      409      {string}  string  "Stock count is already approved or cancelled"
+// @Router       /stock-counts/{id}/entries [post]
+func (h *StockCountHandler) Count(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockCountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var input domain.StockCountEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	count, err := h.service.Count(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error recording stock count entries:", err)
+		writeStockCountError(w, err, "Stock count is already approved or cancelled",
+			"Failed to record stock count entries")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, count)
+}
+
+// HandleApprove handles POST requests for /api/stock-counts/{id}/approve
+func (h *StockCountHandler) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Approve(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Approve godoc
+// @Summary      Approve a stock count
+// @Description  Add the variance of every counted product, counted less the system quantity when the count started, to the outlet's current stock, posting each to the stock ledger as an adjustment
+// @Tags         stock-counts
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Stock count ID"
+// @Param        X-User  header    string  false  "User approving the count"
+// @Success      200     {object}  domain.StockCount
+// @Failure      400     {string}  string  "Invalid stock count ID"
+// @Failure      404     {string}  string  "Stock count not found"
+// @Failure      409     {string}  string  "Stock count is already approved or cancelled, or stock is short of a variance"
+// @Router       /stock-counts/{id}/approve [post]
+func (h *StockCountHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockCountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.service.Approve(id, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error approving stock count:", err)
+		writeStockCountError(w, err, "Stock count is already approved or cancelled", "Failed to approve stock count")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, count)
+}
+
+// HandleCancel handles POST requests for /api/stock-counts/{id}/cancel
+func (h *StockCountHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Cancel(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Cancel godoc
+// @Summary      Cancel a stock count
+// @Description  Abandon a stock count without changing any stock
+// @Tags         stock-counts
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Stock count ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200     {object}  domain.StockCount
+// @Failure      400     {string}  string  "Invalid stock count ID"
+// @Failure      404     {string}  string  "Stock count not found"
+// @Failure      409     {string}  string  "Stock count is already approved or cancelled"
+// @Router       /stock-counts/{id}/cancel [post]
+func (h *StockCountHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockCountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.service.Cancel(id, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error cancelling stock count:", err)
+		writeStockCountError(w, err, "Stock count is already approved or cancelled", "Failed to cancel stock count")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, count)
+}
+
+func stockCountIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid stock count ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeStockCountError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Stock count not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrInsufficientStock):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, apperrors.ErrOutletNotFound):
+		WriteError(w, http.StatusBadRequest, "Outlet not found")
+	case errors.Is(err, apperrors.ErrCategoryNotFound):
+		WriteError(w, http.StatusBadRequest, "Category not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
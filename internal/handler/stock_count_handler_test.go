@@ -0,0 +1,289 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newStockCountMux wires the stock count and product stock ledger routes the
+// same way router.New does. Category 2 holds product 2 with 24 in stock and a
+// bundle of it, and count 1 of every product at outlet 1 is cancelled.
+func newStockCountMux(t *testing.T) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := newRepos(t)
+	drinks := domain.Category{Name: "Minuman"}
+	if err := repos.Categories.Create(&drinks); err != nil {
+		t.Fatalf("seed category: %v", err)
+	}
+	for _, p := range []domain.Product{
+		{Name: "Teh Botol", Type: domain.ProductTypeStandard, Price: 5000, CategoryID: drinks.ID, UnitID: domain.DefaultUnitID},
+		{Name: "Paket Teh", Type: domain.ProductTypeBundle, Price: 25000, CategoryID: drinks.ID, UnitID: domain.DefaultUnitID},
+	} {
+		if err := repos.Products.Create(&p); err != nil {
+			t.Fatalf("seed product: %v", err)
+		}
+	}
+	if err := repos.Products.SetStock(2, domain.DefaultOutletID, 24); err != nil {
+		t.Fatalf("seed stock: %v", err)
+	}
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	cancelled := domain.StockCount{
+		OutletID: domain.DefaultOutletID, Status: domain.StockCountStatusCancelled, CreatedAt: createdAt, UpdatedAt: createdAt,
+		Lines: []domain.StockCountLine{{ProductID: 1, ProductName: "Indomie Goreng", SystemQuantity: 100}},
+	}
+	if err := repos.StockCounts.Create(&cancelled); err != nil {
+		t.Fatalf("seed stock count: %v", err)
+	}
+
+	tx := memory.NewTransactor(repos)
+	counts := handler.NewStockCountHandler(service.NewStockCountService(repos.StockCounts, repos.Outlets, tx))
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), tx))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/stock-counts", counts.HandleStockCounts)
+	mux.HandleFunc("/api/stock-counts/{id}", counts.HandleStockCountByID)
+	mux.HandleFunc("/api/stock-counts/{id}/entries", counts.HandleEntries)
+	mux.HandleFunc("/api/stock-counts/{id}/approve", counts.HandleApprove)
+	mux.HandleFunc("/api/stock-counts/{id}/cancel", counts.HandleCancel)
+	mux.HandleFunc("/api/products/{id}/stock-movements", products.HandleStockMovements)
+	return mux, repos
+}
+
+func TestStockCountHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/stock-counts", wantStatus: http.StatusOK},
+		{name: "list at outlet by status", method: http.MethodGet, path: "/api/stock-counts?outlet_id=1&status=cancelled", wantStatus: http.StatusOK},
+		{name: "list at missing outlet", method: http.MethodGet, path: "/api/stock-counts?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "list with invalid outlet", method: http.MethodGet, path: "/api/stock-counts?outlet_id=abc", wantStatus: http.StatusBadRequest, wantError: "Invalid outlet ID"},
+		{name: "list with unknown status", method: http.MethodGet, path: "/api/stock-counts?status=done", wantStatus: http.StatusBadRequest, wantError: "invalid input: status must be counting, approved or cancelled"},
+		{name: "create", method: http.MethodPost, path: "/api/stock-counts", body: `{"notes":"Month-end count"}`, wantStatus: http.StatusCreated},
+		{name: "create for category", method: http.MethodPost, path: "/api/stock-counts", body: `{"outlet_id":1,"category_id":2}`, wantStatus: http.StatusCreated},
+		{name: "create for missing category", method: http.MethodPost, path: "/api/stock-counts", body: `{"category_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Category not found"},
+		{name: "create for missing outlet", method: http.MethodPost, path: "/api/stock-counts", body: `{"outlet_id":99}`, wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/stock-counts", body: `{"outlet_id":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "stock counts method not allowed", method: http.MethodDelete, path: "/api/stock-counts", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/stock-counts/1", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/stock-counts/99", wantStatus: http.StatusNotFound, wantError: "Stock count not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/stock-counts/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid stock count ID"},
+		{name: "count cancelled count", method: http.MethodPost, path: "/api/stock-counts/1/entries", body: `{"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusConflict, wantError: "Stock count is already approved or cancelled"},
+		{name: "count missing count", method: http.MethodPost, path: "/api/stock-counts/99/entries", body: `{"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusNotFound, wantError: "Stock count not found"},
+		{name: "count nothing", method: http.MethodPost, path: "/api/stock-counts/1/entries", body: `{"lines":[]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: lines must not be empty"},
+		{name: "count with malformed body", method: http.MethodPost, path: "/api/stock-counts/1/entries", body: `{"lines":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "entries method not allowed", method: http.MethodGet, path: "/api/stock-counts/1/entries", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "approve cancelled count", method: http.MethodPost, path: "/api/stock-counts/1/approve", wantStatus: http.StatusConflict, wantError: "Stock count is already approved or cancelled"},
+		{name: "approve missing count", method: http.MethodPost, path: "/api/stock-counts/99/approve", wantStatus: http.StatusNotFound, wantError: "Stock count not found"},
+		{name: "cancel cancelled count", method: http.MethodPost, path: "/api/stock-counts/1/cancel", wantStatus: http.StatusConflict, wantError: "Stock count is already approved or cancelled"},
+		{name: "cancel method not allowed", method: http.MethodGet, path: "/api/stock-counts/1/cancel", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newStockCountMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStockCountHandler_CountValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantError string
+	}{
+		{name: "product not in count", body: `{"lines":[{"product_id":1,"quantity":1}]}`, wantError: "invalid input: product 1 is not in this stock count"},
+		{name: "bundle is not counted", body: `{"lines":[{"product_id":3,"quantity":1}]}`, wantError: "invalid input: product 3 is not in this stock count"},
+		{name: "duplicate product", body: `{"lines":[{"product_id":2,"quantity":1},{"product_id":2,"quantity":2}]}`, wantError: "invalid input: product 2 is listed more than once"},
+		{name: "total below zero", body: `{"lines":[{"product_id":2,"quantity":-1}]}`, wantError: "invalid input: counted quantity of product 2 must not drop below zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newStockCountMux(t)
+			if rec := serve(mux, http.MethodPost, "/api/stock-counts", `{"category_id":2}`, nil); rec.Code != http.StatusCreated {
+				t.Fatalf("create status = %d, body %s", rec.Code, rec.Body)
+			}
+
+			rec := serve(mux, http.MethodPost, "/api/stock-counts/2/entries", tt.body, nil)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if got := decodeResponse(t, rec).Error; got != tt.wantError {
+				t.Errorf("error = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStockCountHandler_CountAndApprove(t *testing.T) {
+	mux, repos := newStockCountMux(t)
+	expiresAt := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	batch := domain.StockBatch{ProductID: 1, OutletID: domain.DefaultOutletID, LotNumber: "L1", ExpiresAt: &expiresAt,
+		Quantity: 100, Remaining: 100, CreatedAt: expiresAt}
+	if err := repos.StockBatches.Create(&batch); err != nil {
+		t.Fatalf("seed batch: %v", err)
+	}
+
+	post := func(path, body, user string) domain.StockCount {
+		t.Helper()
+		rec := serve(mux, http.MethodPost, path, body, map[string]string{"X-User": user})
+		if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+			t.Fatalf("POST %s: status = %d, body %s", path, rec.Code, rec.Body)
+		}
+		var count domain.StockCount
+		if err := json.Unmarshal(decodeResponse(t, rec).Data, &count); err != nil {
+			t.Fatalf("decode stock count: %v", err)
+		}
+		return count
+	}
+
+	count := post("/api/stock-counts", `{"notes":"Month-end count"}`, "budi")
+	if count.Status != domain.StockCountStatusCounting || count.OutletID != domain.DefaultOutletID || count.CreatedBy != "budi" {
+		t.Errorf("count = %+v, want counting at the default outlet by budi", count)
+	}
+	if len(count.Lines) != 2 || count.Lines[0].SystemQuantity != 100 || count.Lines[1].SystemQuantity != 24 ||
+		count.Lines[1].ProductName != "Teh Botol" || count.Lines[0].CountedQuantity != nil {
+		t.Fatalf("lines = %+v, want Indomie Goreng at 100 and Teh Botol at 24, uncounted", count.Lines)
+	}
+
+	// Two counters find Indomie on the shelf and in the storeroom; one
+	// corrects a miscount
+	post("/api/stock-counts/2/entries", `{"lines":[{"product_id":1,"quantity":60}]}`, "siti")
+	post("/api/stock-counts/2/entries", `{"lines":[{"product_id":1,"quantity":40}]}`, "andi")
+	count = post("/api/stock-counts/2/entries", `{"lines":[{"product_id":1,"quantity":-3}]}`, "andi")
+	line := count.Lines[0]
+	if line.CountedQuantity == nil || *line.CountedQuantity != 97 || line.Variance == nil || *line.Variance != -3 {
+		t.Errorf("line = %+v, want 97 counted and a variance of -3", line)
+	}
+	if count.Lines[1].Variance != nil {
+		t.Errorf("uncounted line variance = %v, want nil", *count.Lines[1].Variance)
+	}
+	if len(count.Entries) != 3 || count.Entries[0].CountedBy != "siti" || count.Entries[2].Quantity != -3 {
+		t.Errorf("entries = %+v, want 60 by siti, 40 and -3 by andi", count.Entries)
+	}
+
+	count = post("/api/stock-counts/2/approve", "", "budi")
+	if count.Status != domain.StockCountStatusApproved || count.ApprovedBy != "budi" {
+		t.Errorf("count = %+v, want approved by budi", count)
+	}
+
+	// Indomie is corrected, Teh Botol was not counted and is left alone
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if product.Stock != 97 {
+		t.Errorf("stock = %d, want 97", product.Stock)
+	}
+	if stock, _ := repos.Products.GetStock(2, domain.DefaultOutletID); stock != 24 {
+		t.Errorf("uncounted stock = %d, want 24", stock)
+	}
+	if got, err := repos.StockBatches.GetByID(batch.ID); err != nil || got.Remaining != 97 {
+		t.Errorf("batch = %+v, %v, want 97 remaining", got, err)
+	}
+
+	rec := serve(mux, http.MethodGet, "/api/products/1/stock-movements", "", nil)
+	var movements []domain.StockMovement
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &movements); err != nil {
+		t.Fatalf("decode stock movements: %v", err)
+	}
+	if len(movements) != 1 {
+		t.Fatalf("stock movements = %+v, want one", movements)
+	}
+	if m := movements[0]; m.Quantity != -3 || m.Reason != domain.StockMovementAdjustment || m.Reference != "SO-2" || m.Actor != "budi" {
+		t.Errorf("movement = %+v, want a -3 adjustment for SO-2 by budi", m)
+	}
+
+	rec = serve(mux, http.MethodPost, "/api/stock-counts/2/approve", "", nil)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("second approve status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestStockCountHandler_ApproveAddsVarianceToCurrentStock(t *testing.T) {
+	mux, repos := newStockCountMux(t)
+	if rec := serve(mux, http.MethodPost, "/api/stock-counts", `{"category_id":2}`, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/stock-counts/2/approve", "", nil); rec.Code != http.StatusBadRequest ||
+		decodeResponse(t, rec).Error != "invalid input: nothing has been counted" {
+		t.Fatalf("approve uncounted: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/stock-counts/2/entries", `{"lines":[{"product_id":2,"quantity":30}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("count status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// Stock received while counting is kept on top of the variance
+	if err := repos.Products.AddStock(2, domain.DefaultOutletID, 4); err != nil {
+		t.Fatalf("add stock: %v", err)
+	}
+	rec := serve(mux, http.MethodPost, "/api/stock-counts/2/approve", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("approve status = %d, body %s", rec.Code, rec.Body)
+	}
+	var count domain.StockCount
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &count); err != nil {
+		t.Fatalf("decode stock count: %v", err)
+	}
+	if line := count.Lines[0]; line.SystemQuantity != 24 || line.Variance == nil || *line.Variance != 6 {
+		t.Errorf("line = %+v, want 30 counted against 24", line)
+	}
+	if stock, _ := repos.Products.GetStock(2, domain.DefaultOutletID); stock != 34 {
+		t.Errorf("stock = %d, want 28 + 6", stock)
+	}
+	rec = serve(mux, http.MethodGet, "/api/products/2/stock-movements", "", nil)
+	var movements []domain.StockMovement
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &movements); err != nil {
+		t.Fatalf("decode stock movements: %v", err)
+	}
+	if len(movements) != 1 || movements[0].Quantity != 6 {
+		t.Errorf("stock movements = %+v, want one +6 adjustment", movements)
+	}
+}
+
+func TestStockCountHandler_ApproveShortOfStock(t *testing.T) {
+	mux, repos := newStockCountMux(t)
+	if rec := serve(mux, http.MethodPost, "/api/stock-counts", `{"category_id":2}`, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/stock-counts/2/entries", `{"lines":[{"product_id":2,"quantity":4}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("count status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// 20 short, but only 14 are left after sales made while counting
+	if err := repos.Products.DecrementStock(2, domain.DefaultOutletID, 10); err != nil {
+		t.Fatalf("decrement stock: %v", err)
+	}
+	rec := serve(mux, http.MethodPost, "/api/stock-counts/2/approve", "", nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("approve status = %d, body %s", rec.Code, rec.Body)
+	}
+	if stock, _ := repos.Products.GetStock(2, domain.DefaultOutletID); stock != 14 {
+		t.Errorf("stock = %d, want 14 untouched", stock)
+	}
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// StockTransferHandler handles HTTP requests for stock transfers between outlets
+type StockTransferHandler struct {
+	service *service.StockTransferService
+}
+
+// NewStockTransferHandler creates a new stock transfer handler
+func NewStockTransferHandler(service *service.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{service: service}
+}
+
+// HandleStockTransfers handles GET and POST requests for /api/stock-transfers
+func (h *StockTransferHandler) HandleStockTransfers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all stock transfers
+// @Description  Retrieve stock transfers, optionally only those from or to one outlet
+// @Tags         stock-transfers
+// @Accept       json
+// @Produce      json
+// @Param        outlet_id  query     int  false  "Only transfers from or to this outlet"
+// @Success      200        {array}   domain.StockTransfer
+// @Failure      400        {string}  string  "Invalid outlet ID"
+// @Failure      400        {string}  string  "Outlet not found"
+// @Failure      500        {string}  string  "Failed to fetch stock transfers"
+// @Router       /stock-transfers [get]
+func (h *StockTransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outletID, err := outletIDFromQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid outlet ID")
+		return
+	}
+
+	transfers, err := h.service.GetAll(outletID)
+	if err != nil {
+		log.Println("Error fetching stock transfers:", err)
+		writeStockTransferError(w, err, "", "Failed to fetch stock transfers")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, transfers)
+}
+
+// Create godoc
+// @Summary      Create a stock transfer
+// @Description  Send stock from one outlet to another. The lines leave the source outlet's stock straight away and arrive at the destination when the transfer is received.
+// @Tags         stock-transfers
+// @Accept       json
+// @Produce      json
+// @Param        transfer  body      domain.StockTransferInput  true   "Outlets and lines to transfer"
+// @Param        X-User    header    string                     false  "User making the change, recorded in the audit log"
+// @Success      201       {object}  domain.StockTransfer
+// @Failure      400       {string}  string  "Invalid request body"
+// @Failure      400       {string}  string  "Outlet not found"
+// @Failure      400       {string}  string  "Product not found"
+// @Failure      409       {string}  string  "Insufficient stock"
+// @Router       /stock-transfers [post]
+func (h *StockTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.StockTransferInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	transfer, err := h.service.Create(input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error creating stock transfer:", err)
+		writeStockTransferError(w, err, "", "Failed to create stock transfer")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, transfer)
+}
+
+// HandleStockTransferByID handles GET requests for /api/stock-transfers/{id}
+func (h *StockTransferHandler) HandleStockTransferByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get stock transfer by ID
+// @Description  Retrieve a single stock transfer with its lines
+// @Tags         stock-transfers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Stock transfer ID"
+// @Success      200  {object}  domain.StockTransfer
+// @Failure      400  {string}  string  "Invalid stock transfer ID"
+// @Failure      404  {string}  string  "Stock transfer not found"
+// @Router       /stock-transfers/{id} [get]
+func (h *StockTransferHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockTransferIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching stock transfer by ID:", err)
+		writeStockTransferError(w, err, "", "Failed to fetch stock transfer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, transfer)
+}
+
+// HandleReceive handles POST requests for /api/stock-transfers/{id}/receive
+func (h *StockTransferHandler) HandleReceive(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Receive(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Receive godoc
+// @Summary      Receive a stock transfer
+// @Description  Add an in-transit transfer's lines to the destination outlet's stock through the stock ledger
+// @Tags         stock-transfers
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Stock transfer ID"
+// @Param        X-User  header    string  false  "User receiving the transfer"
+// @Success      200     {object}  domain.StockTransfer
+// @Failure      400     {string}  string  "Invalid stock transfer ID"
+// @Failure      404     {string}  string  "Stock transfer not found"
+// @Failure      409     {string}  string  "Stock transfer is already received or cancelled"
+// @Router       /stock-transfers/{id}/receive [post]
+func (h *StockTransferHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockTransferIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.Receive(id, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error receiving stock transfer:", err)
+		writeStockTransferError(w, err, "Stock transfer is already received or cancelled",
+			"Failed to receive stock transfer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, transfer)
+}
+
+// HandleCancel handles POST requests for /api/stock-transfers/{id}/cancel
+func (h *StockTransferHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Cancel(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Cancel godoc
+// @Summary      Cancel a stock transfer
+// @Description  Return an in-transit transfer's lines to the source outlet's stock through the stock ledger
+// @Tags         stock-transfers
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Stock transfer ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200     {object}  domain.StockTransfer
+// @Failure      400     {string}  string  "Invalid stock transfer ID"
+// @Failure      404     {string}  string  "Stock transfer not found"
+// @Failure      409     {string}  string  "Stock transfer is already received or cancelled"
+// @Router       /stock-transfers/{id}/cancel [post]
+func (h *StockTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockTransferIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.Cancel(id, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error cancelling stock transfer:", err)
+		writeStockTransferError(w, err, "Stock transfer is already received or cancelled",
+			"Failed to cancel stock transfer")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, transfer)
+}
+
+func stockTransferIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid stock transfer ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func writeStockTransferError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Stock transfer not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrInsufficientStock):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, apperrors.ErrOutletNotFound):
+		WriteError(w, http.StatusBadRequest, "Outlet not found")
+	case errors.Is(err, apperrors.ErrProductNotFound):
+		WriteError(w, http.StatusBadRequest, "Product not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,174 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newStockTransferMux wires the stock transfer and product routes the same
+// way router.New does. Outlet 2 has no stock, and transfer 1 from outlet 1
+// to outlet 2 is already received.
+func newStockTransferMux(t *testing.T) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := newRepos(t)
+	outlet := domain.Outlet{Code: "BDG", Name: "Bandung"}
+	if err := repos.Outlets.Create(&outlet); err != nil {
+		t.Fatalf("seed outlet: %v", err)
+	}
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	received := domain.StockTransfer{
+		FromOutletID: domain.DefaultOutletID, ToOutletID: outlet.ID, Status: domain.StockTransferStatusReceived,
+		Lines: []domain.StockTransferLine{{ProductID: 1, Quantity: 1}}, CreatedAt: createdAt, UpdatedAt: createdAt,
+	}
+	if err := repos.StockTransfers.Create(&received); err != nil {
+		t.Fatalf("seed transfer: %v", err)
+	}
+
+	tx := memory.NewTransactor(repos)
+	transfers := handler.NewStockTransferHandler(service.NewStockTransferService(repos.StockTransfers, repos.Outlets, tx))
+	products := handler.NewProductHandler(service.NewProductService(repos.Products, repos.Categories,
+		repos.PriceHistory, repos.TaxRates, repos.Reservations, repos.StockMovements, repos.Outlets, repos.Units, repos.ProductComponents,
+		repos.ProductImages, newImageStore(t), tx))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/stock-transfers", transfers.HandleStockTransfers)
+	mux.HandleFunc("/api/stock-transfers/{id}", transfers.HandleStockTransferByID)
+	mux.HandleFunc("/api/stock-transfers/{id}/receive", transfers.HandleReceive)
+	mux.HandleFunc("/api/stock-transfers/{id}/cancel", transfers.HandleCancel)
+	mux.HandleFunc("/api/products/", products.HandleProductByID)
+	mux.HandleFunc("/api/products/{id}/stock-movements", products.HandleStockMovements)
+	return mux, repos
+}
+
+func TestStockTransferHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/stock-transfers", wantStatus: http.StatusOK},
+		{name: "list at outlet", method: http.MethodGet, path: "/api/stock-transfers?outlet_id=2", wantStatus: http.StatusOK},
+		{name: "list at missing outlet", method: http.MethodGet, path: "/api/stock-transfers?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "list with invalid outlet", method: http.MethodGet, path: "/api/stock-transfers?outlet_id=abc", wantStatus: http.StatusBadRequest, wantError: "Invalid outlet ID"},
+		{name: "create", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":2,"lines":[{"product_id":1,"quantity":30}]}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create to the same outlet", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":1,"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: from_outlet_id and to_outlet_id must differ"},
+		{name: "create without source", method: http.MethodPost, path: "/api/stock-transfers", body: `{"to_outlet_id":2,"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: from_outlet_id and to_outlet_id are required"},
+		{name: "create without lines", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":2,"lines":[]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: lines must not be empty"},
+		{name: "create with zero quantity", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":2,"lines":[{"product_id":1,"quantity":0}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must be greater than zero"},
+		{name: "create with duplicate product", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":2,"lines":[{"product_id":1,"quantity":1},{"product_id":1,"quantity":2}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product 1 is listed more than once"},
+		{name: "create to missing outlet", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":99,"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "create for missing product", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":1,"to_outlet_id":2,"lines":[{"product_id":99,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "Product not found"},
+		{name: "create beyond source stock", method: http.MethodPost, path: "/api/stock-transfers", body: `{"from_outlet_id":2,"to_outlet_id":1,"lines":[{"product_id":1,"quantity":1}]}`, wantStatus: http.StatusConflict, wantError: "insufficient stock: Indomie Goreng"},
+		{name: "stock transfers method not allowed", method: http.MethodDelete, path: "/api/stock-transfers", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/stock-transfers/1", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/stock-transfers/99", wantStatus: http.StatusNotFound, wantError: "Stock transfer not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/stock-transfers/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid stock transfer ID"},
+		{name: "receive received transfer", method: http.MethodPost, path: "/api/stock-transfers/1/receive", wantStatus: http.StatusConflict, wantError: "Stock transfer is already received or cancelled"},
+		{name: "receive missing transfer", method: http.MethodPost, path: "/api/stock-transfers/99/receive", wantStatus: http.StatusNotFound, wantError: "Stock transfer not found"},
+		{name: "receive method not allowed", method: http.MethodGet, path: "/api/stock-transfers/1/receive", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "cancel received transfer", method: http.MethodPost, path: "/api/stock-transfers/1/cancel", wantStatus: http.StatusConflict, wantError: "Stock transfer is already received or cancelled"},
+		{name: "product at outlet", method: http.MethodGet, path: "/api/products/1?outlet_id=2", wantStatus: http.StatusOK},
+		{name: "product at missing outlet", method: http.MethodGet, path: "/api/products/1?outlet_id=99", wantStatus: http.StatusBadRequest, wantError: "Outlet not found"},
+		{name: "product with invalid outlet", method: http.MethodGet, path: "/api/products/1?outlet_id=-1", wantStatus: http.StatusBadRequest, wantError: "Invalid outlet ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newStockTransferMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStockTransferHandler_MovesStockBetweenOutlets(t *testing.T) {
+	mux, repos := newStockTransferMux(t)
+	headers := map[string]string{"X-User": "budi"}
+
+	stockAt := func(outletID string) int {
+		t.Helper()
+		rec := serve(mux, http.MethodGet, "/api/products/1?outlet_id="+outletID, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("get product status = %d", rec.Code)
+		}
+		var product domain.Product
+		if err := json.Unmarshal(decodeResponse(t, rec).Data, &product); err != nil {
+			t.Fatalf("decode product: %v", err)
+		}
+		return product.Stock
+	}
+	send := func(path, body string) domain.StockTransfer {
+		t.Helper()
+		rec := serve(mux, http.MethodPost, path, body, headers)
+		if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+			t.Fatalf("%s status = %d: %s", path, rec.Code, decodeResponse(t, rec).Error)
+		}
+		var transfer domain.StockTransfer
+		if err := json.Unmarshal(decodeResponse(t, rec).Data, &transfer); err != nil {
+			t.Fatalf("decode transfer: %v", err)
+		}
+		return transfer
+	}
+
+	body := `{"from_outlet_id":1,"to_outlet_id":2,"lines":[{"product_id":1,"quantity":30}]}`
+	transfer := send("/api/stock-transfers", body)
+	if transfer.Status != domain.StockTransferStatusInTransit || transfer.CreatedBy != "budi" {
+		t.Errorf("transfer = %+v, want in transit created by budi", transfer)
+	}
+	if from, to := stockAt("1"), stockAt("2"); from != 70 || to != 0 {
+		t.Errorf("stock in transit = %d at source, %d at destination, want 70 and 0", from, to)
+	}
+
+	transfer = send("/api/stock-transfers/2/receive", "")
+	if transfer.Status != domain.StockTransferStatusReceived {
+		t.Errorf("status = %q, want received", transfer.Status)
+	}
+	if from, to := stockAt("1"), stockAt("2"); from != 70 || to != 30 {
+		t.Errorf("stock after receipt = %d at source, %d at destination, want 70 and 30", from, to)
+	}
+
+	send("/api/stock-transfers", body)
+	transfer = send("/api/stock-transfers/3/cancel", "")
+	if transfer.Status != domain.StockTransferStatusCancelled {
+		t.Errorf("status = %q, want cancelled", transfer.Status)
+	}
+	if from := stockAt("1"); from != 70 {
+		t.Errorf("stock after cancel = %d at source, want 70", from)
+	}
+
+	movements, err := repos.StockMovements.GetByProductID(1, 2)
+	if err != nil {
+		t.Fatalf("get movements: %v", err)
+	}
+	if len(movements) != 1 || movements[0].Quantity != 30 || movements[0].Reason != domain.StockMovementTransferIn ||
+		movements[0].Reference != "TR-2" {
+		t.Errorf("destination movements = %+v, want one transfer_in of 30 for TR-2", movements)
+	}
+	movements, err = repos.StockMovements.GetByProductID(1, domain.DefaultOutletID)
+	if err != nil {
+		t.Fatalf("get movements: %v", err)
+	}
+	if len(movements) != 3 || movements[0].Reference != "TR-3" || movements[0].Quantity != 30 ||
+		movements[2].Quantity != -30 || movements[2].Reason != domain.StockMovementTransferOut {
+		t.Errorf("source movements = %+v, want TR-2 out, TR-3 out and back", movements)
+	}
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// SupplierHandler handles HTTP requests for suppliers
+type SupplierHandler struct {
+	service *service.SupplierService
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(service *service.SupplierService) *SupplierHandler {
+	return &SupplierHandler{service: service}
+}
+
+// HandleSuppliers handles GET and POST requests for /api/suppliers
+func (h *SupplierHandler) HandleSuppliers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all suppliers
+// @Description  Retrieve a list of all suppliers
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.Supplier
+// @Failure      500  {string}  string  "Failed to fetch suppliers"
+// @Router       /suppliers [get]
+func (h *SupplierHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.service.GetAll()
+	if err != nil {
+		log.Println("Error fetching suppliers:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch suppliers")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, suppliers)
+}
+
+// Create godoc
+// @Summary      Create a new supplier
+// @Description  Create a new supplier to order stock from
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        supplier  body      domain.SupplierInput  true   "Supplier data"
+// @Param        X-User    header    string                false  "User making the change, recorded in the audit log"
+// @Success      201       {object}  domain.Supplier
+// @Failure      400       {string}  string  "Invalid request body"
+// @Router       /suppliers [post]
+func (h *SupplierHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.SupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	supplier := supplierFromInput(input)
+	if err := h.service.Create(&supplier, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error creating supplier:", err)
+		writeSupplierError(w, err, "Failed to create supplier")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, supplier)
+}
+
+// HandleSupplierByID handles GET, PUT, DELETE requests for /api/suppliers/{id}
+func (h *SupplierHandler) HandleSupplierByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get supplier by ID
+// @Description  Retrieve a single supplier by its ID
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Supplier ID"
+// @Success      200  {object}  domain.Supplier
+// @Failure      400  {string}  string  "Invalid supplier ID"
+// @Failure      404  {string}  string  "Supplier not found"
+// @Router       /suppliers/{id} [get]
+func (h *SupplierHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/suppliers/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid supplier ID")
+		return
+	}
+
+	supplier, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching supplier by ID:", err)
+		writeSupplierError(w, err, "Failed to fetch supplier")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, supplier)
+}
+
+// Update godoc
+// @Summary      Update a supplier
+// @Description  Update an existing supplier by its ID
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                   true   "Supplier ID"
+// @Param        supplier  body      domain.SupplierInput  true   "Supplier data"
+// @Param        X-User    header    string                false  "User making the change, recorded in the audit log"
+// @Success      200       {object}  domain.Supplier
+// @Failure      400       {string}  string  "Invalid supplier ID or request body"
+// @Failure      404       {string}  string  "Supplier not found"
+// @Router       /suppliers/{id} [put]
+func (h *SupplierHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/suppliers/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid supplier ID")
+		return
+	}
+
+	var input domain.SupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	supplier := supplierFromInput(input)
+	supplier.ID = id
+	if err := h.service.Update(&supplier, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error updating supplier:", err)
+		writeSupplierError(w, err, "Failed to update supplier")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, supplier)
+}
+
+// Delete godoc
+// @Summary      Delete a supplier
+// @Description  Delete a supplier by its ID. Suppliers with purchase orders cannot be deleted.
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Supplier ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Supplier deleted successfully"
+// @Failure      400  {string}  string  "Invalid supplier ID"
+// @Failure      404  {string}  string  "Supplier not found"
+// @Failure      409  {string}  string  "Supplier has purchase orders"
+// @Router       /suppliers/{id} [delete]
+func (h *SupplierHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/suppliers/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid supplier ID")
+		return
+	}
+
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting supplier:", err)
+		writeSupplierError(w, err, "Failed to delete supplier")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Supplier deleted successfully"})
+}
+
+func supplierFromInput(input domain.SupplierInput) domain.Supplier {
+	return domain.Supplier{
+		Name:    input.Name,
+		Phone:   input.Phone,
+		Email:   input.Email,
+		Address: input.Address,
+	}
+}
+
+func writeSupplierError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Supplier not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, "Supplier has purchase orders")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
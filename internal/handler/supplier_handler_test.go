@@ -0,0 +1,69 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newSupplierMux wires the supplier routes the same way router.New does.
+// Supplier 1 is seeded.
+func newSupplierMux(t *testing.T) http.Handler {
+	t.Helper()
+	repos := newRepos(t)
+	supplier := domain.Supplier{Name: "PT Indofood", Phone: "0215795822"}
+	if err := repos.Suppliers.Create(&supplier); err != nil {
+		t.Fatalf("seed supplier: %v", err)
+	}
+
+	suppliers := handler.NewSupplierHandler(service.NewSupplierService(repos.Suppliers, memory.NewTransactor(repos)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suppliers", suppliers.HandleSuppliers)
+	mux.HandleFunc("/api/suppliers/", suppliers.HandleSupplierByID)
+	return mux
+}
+
+func TestSupplierHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/suppliers", wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/api/suppliers", body: `{"name":"CV Sumber Rejeki","address":"Bandung"}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/suppliers", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create without name", method: http.MethodPost, path: "/api/suppliers", body: `{"phone":"0811"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: name is required"},
+		{name: "create with invalid email", method: http.MethodPost, path: "/api/suppliers", body: `{"name":"CV","email":"sales"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: email is not valid"},
+		{name: "suppliers method not allowed", method: http.MethodDelete, path: "/api/suppliers", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/suppliers/1", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/suppliers/99", wantStatus: http.StatusNotFound, wantError: "Supplier not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/suppliers/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid supplier ID"},
+		{name: "update", method: http.MethodPut, path: "/api/suppliers/1", body: `{"name":"PT Indofood Sukses Makmur"}`, wantStatus: http.StatusOK},
+		{name: "update missing", method: http.MethodPut, path: "/api/suppliers/99", body: `{"name":"Ghost"}`, wantStatus: http.StatusNotFound, wantError: "Supplier not found"},
+		{name: "delete", method: http.MethodDelete, path: "/api/suppliers/1", wantStatus: http.StatusOK},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/suppliers/99", wantStatus: http.StatusNotFound, wantError: "Supplier not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newSupplierMux(t)
+
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// TaxRateHandler handles HTTP requests for tax rates
+type TaxRateHandler struct {
+	service *service.TaxRateService
+}
+
+// NewTaxRateHandler creates a new tax rate handler
+func NewTaxRateHandler(service *service.TaxRateService) *TaxRateHandler {
+	return &TaxRateHandler{service: service}
+}
+
+// HandleTaxRates handles GET and POST requests for /api/tax-rates
+func (h *TaxRateHandler) HandleTaxRates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all tax rates
+// @Description  Retrieve a list of all tax rates
+// @Tags         tax-rates
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.TaxRate
+// @Failure      500  {string}  string  "Failed to fetch tax rates"
+// @Router       /tax-rates [get]
+func (h *TaxRateHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.GetAll()
+	if err != nil {
+		log.Println("Error fetching tax rates:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch tax rates")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rates)
+}
+
+// Create godoc
+// @Summary      Create a new tax rate
+// @Description  Create a tax rate that is either included in prices or added on top of them
+// @Tags         tax-rates
+// @Accept       json
+// @Produce      json
+// @Param        tax_rate  body      domain.TaxRateInput  true   "Tax rate data"
+// @Param        X-User    header    string               false  "User making the change, recorded in the audit log"
+// @Success      201       {object}  domain.TaxRate
+// @Failure      400       {string}  string  "Invalid request body"
+// @Router       /tax-rates [post]
+func (h *TaxRateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var rate domain.TaxRate
+	if err := json.NewDecoder(r.Body).Decode(&rate); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.Create(&rate, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error creating tax rate:", err)
+		writeTaxRateError(w, err, "Failed to create tax rate")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, rate)
+}
+
+// HandleTaxRateByID handles GET, PUT, DELETE requests for /api/tax-rates/{id}
+func (h *TaxRateHandler) HandleTaxRateByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get tax rate by ID
+// @Description  Retrieve a single tax rate by its ID
+// @Tags         tax-rates
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Tax rate ID"
+// @Success      200  {object}  domain.TaxRate
+// @Failure      400  {string}  string  "Invalid tax rate ID"
+// @Failure      404  {string}  string  "Tax rate not found"
+// @Router       /tax-rates/{id} [get]
+func (h *TaxRateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/tax-rates/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	rate, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching tax rate by ID:", err)
+		writeTaxRateError(w, err, "Failed to fetch tax rate")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rate)
+}
+
+// Update godoc
+// @Summary      Update a tax rate
+// @Description  Update an existing tax rate by its ID
+// @Tags         tax-rates
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                  true   "Tax rate ID"
+// @Param        tax_rate  body      domain.TaxRateInput  true   "Tax rate data"
+// @Param        X-User    header    string               false  "User making the change, recorded in the audit log"
+// @Success      200       {object}  domain.TaxRate
+// @Failure      400       {string}  string  "Invalid tax rate ID or request body"
+// @Failure      404       {string}  string  "Tax rate not found"
+// @Router       /tax-rates/{id} [put]
+func (h *TaxRateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/tax-rates/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	var rate domain.TaxRate
+	if err := json.NewDecoder(r.Body).Decode(&rate); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rate.ID = id
+	if err := h.service.Update(&rate, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error updating tax rate:", err)
+		writeTaxRateError(w, err, "Failed to update tax rate")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rate)
+}
+
+// Delete godoc
+// @Summary      Delete a tax rate
+// @Description  Delete a tax rate by its ID. Rates still used by products or categories cannot be deleted.
+// @Tags         tax-rates
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Tax rate ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Tax rate deleted successfully"
+// @Failure      400  {string}  string  "Invalid tax rate ID"
+// @Failure      404  {string}  string  "Tax rate not found"
+// @Failure      409  {string}  string  "Tax rate is in use"
+// @Router       /tax-rates/{id} [delete]
+func (h *TaxRateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/tax-rates/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting tax rate:", err)
+		writeTaxRateError(w, err, "Failed to delete tax rate")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Tax rate deleted successfully"})
+}
+
+func writeTaxRateError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Tax rate not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, "Tax rate is in use")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
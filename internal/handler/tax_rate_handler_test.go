@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+func newTaxRateHandler(t *testing.T) *handler.TaxRateHandler {
+	t.Helper()
+	repos := newRepos(t)
+	if err := repos.TaxRates.Create(&domain.TaxRate{Name: "PPN", Rate: 11, Inclusive: true}); err != nil {
+		t.Fatalf("seed tax rate: %v", err)
+	}
+	return handler.NewTaxRateHandler(service.NewTaxRateService(repos.TaxRates, memory.NewTransactor(repos)))
+}
+
+func TestTaxRateHandler_HandleTaxRates(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "create exclusive", method: http.MethodPost, body: `{"name":"PB1","rate":10}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create without name", method: http.MethodPost, body: `{"rate":10}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: name is required"},
+		{name: "create with zero rate", method: http.MethodPost, body: `{"name":"X","rate":0}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: rate must be greater than 0 and at most 100"},
+		{name: "create with rate over 100", method: http.MethodPost, body: `{"name":"X","rate":101}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: rate must be greater than 0 and at most 100"},
+		{name: "method not allowed", method: http.MethodDelete, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTaxRateHandler(t)
+			req := httptest.NewRequest(tt.method, "/api/tax-rates", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleTaxRates(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTaxRateHandler_HandleTaxRateByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "get", method: http.MethodGet, path: "/api/tax-rates/1", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/tax-rates/99", wantStatus: http.StatusNotFound, wantError: "Tax rate not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/tax-rates/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid tax rate ID"},
+		{name: "update", method: http.MethodPut, path: "/api/tax-rates/1", body: `{"name":"PPN","rate":12,"inclusive":true}`, wantStatus: http.StatusOK},
+		{name: "update missing", method: http.MethodPut, path: "/api/tax-rates/99", body: `{"name":"PPN","rate":12}`, wantStatus: http.StatusNotFound, wantError: "Tax rate not found"},
+		{name: "update with invalid rate", method: http.MethodPut, path: "/api/tax-rates/1", body: `{"name":"PPN","rate":-1}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: rate must be greater than 0 and at most 100"},
+		{name: "delete", method: http.MethodDelete, path: "/api/tax-rates/1", wantStatus: http.StatusOK},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/tax-rates/99", wantStatus: http.StatusNotFound, wantError: "Tax rate not found"},
+		{name: "method not allowed", method: http.MethodPatch, path: "/api/tax-rates/1", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTaxRateHandler(t)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			h.HandleTaxRateByID(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
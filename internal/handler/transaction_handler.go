@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/receipt"
+	"kasir-api/internal/service"
+)
+
+// TransactionHandler handles HTTP requests for completed sales
+type TransactionHandler struct {
+	service *service.TransactionService
+}
+
+// NewTransactionHandler creates a new transaction handler
+func NewTransactionHandler(service *service.TransactionService) *TransactionHandler {
+	return &TransactionHandler{service: service}
+}
+
+// HandleReceipt handles GET requests for /api/transactions/{id}/receipt
+func (h *TransactionHandler) HandleReceipt(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.Receipt(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Receipt godoc
+// @Summary      Print a sale's receipt
+// @Description  Render a sale's receipt with the store header, lines and their discounts, tax, payments and change, laid out for 58mm or 80mm paper as plain text, an ESC/POS byte stream for thermal printers or a PDF
+// @Tags         transactions
+// @Produce      plain
+// @Produce      octet-stream
+// @Produce      application/pdf
+// @Param        id      path      int     true   "Transaction (checked out cart) ID"
+// @Param        format  query     string  false  "Receipt format (default text)"  Enums(text, escpos, pdf)
+// @Param        paper   query     int     false  "Paper width in millimetres (default RECEIPT_PAPER)"  Enums(58, 80)
+// @Success      200     {string}  string  "Rendered receipt"
+// @Failure      400     {string}  string  "Invalid transaction ID or query parameter"
+// @Failure      404     {string}  string  "Transaction not found"
+// @Failure      500     {string}  string  "Failed to render receipt"
+// @Router       /transactions/{id}/receipt [get]
+func (h *TransactionHandler) Receipt(w http.ResponseWriter, r *http.Request) {
+	id, ok := transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	paper, err := optionalIDFromQuery(r, "paper")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid query parameter")
+		return
+	}
+
+	body, err := h.service.Receipt(id, format, paper)
+	if err != nil {
+		log.Println("Error rendering receipt:", err)
+		writeTransactionError(w, err, "Transaction is voided", "Failed to render receipt")
+		return
+	}
+
+	switch format {
+	case receipt.FormatESCPOS:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%d.bin"`, id))
+	case receipt.FormatPDF:
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="receipt-%d.pdf"`, id))
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// HandleRefunds handles GET and POST requests for /api/transactions/{id}/refunds
+func (h *TransactionHandler) HandleRefunds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetRefunds(w, r)
+	case http.MethodPost:
+		h.Refund(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetRefunds godoc
+// @Summary      List a sale's refunds
+// @Description  List the refunds made against a checked out cart, oldest first
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Transaction (checked out cart) ID"
+// @Success      200  {array}   domain.Refund
+// @Failure      400  {string}  string  "Invalid transaction ID"
+// @Failure      404  {string}  string  "Transaction not found"
+// @Failure      500  {string}  string  "Failed to fetch refunds"
+// @Router       /transactions/{id}/refunds [get]
+func (h *TransactionHandler) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	id, ok := transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	refunds, err := h.service.GetRefunds(id)
+	if err != nil {
+		log.Println("Error fetching refunds:", err)
+		writeTransactionError(w, err, "Transaction is voided", "Failed to fetch refunds")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, refunds)
+}
+
+// Refund godoc
+// @Summary      Refund a sale
+// @Description  Refund some or all of a sale's lines, each for its share of what the sale took, optionally putting the stock they took at checkout back through the stock ledger. Without lines everything not yet refunded is refunded. The refund is paid back in the given method, by default cash when the sale took cash and otherwise its first tender, and counts against the refunding user's open shift; cash refunds need one
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                 true   "Transaction (checked out cart) ID"
+// @Param        refund  body      domain.RefundInput  true   "Lines to refund, whether to restock and why"
+// @Param        X-User  header    string              false  "User making the refund"
+// @Success      201     {object}  domain.Refund
+// @Failure      400     {string}  string  "Invalid transaction ID, request body or method"
+// @Failure      400     {string}  string  "Product not found"
+// @Failure      404     {string}  string  "Transaction not found"
+// @Failure      409     {string}  string  "Transaction is voided"
+// @Failure      409     {string}  string  "Cash payments need an open shift"
+// @Failure      500     {string}  string  "Failed to refund transaction"
+// @Router       /transactions/{id}/refunds [post]
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	id, ok := transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var input domain.RefundInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	refund, err := h.service.Refund(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error refunding transaction:", err)
+		writeTransactionError(w, err, "Transaction is voided", "Failed to refund transaction")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, refund)
+}
+
+// HandleVoid handles POST requests for /api/transactions/{id}/void
+func (h *TransactionHandler) HandleVoid(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.Void(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Void godoc
+// @Summary      Void a sale
+// @Description  Void a sale within the void window after checkout or while its shift is open: put back the stock its lines took at checkout through the stock ledger, reverse the customer's points and mark it voided so it no longer counts as a sale. Needs the supervisor token
+// @Tags         transactions
+// @Accept       json
+// @Produce      json
+// @Param        id                  path      int               true   "Transaction (checked out cart) ID"
+// @Param        void                body      domain.VoidInput  true   "Reason for voiding"
+// @Param        X-Supervisor-Token  header    string            true   "Supervisor token"
+// @Param        X-User              header    string            false  "Supervisor voiding the sale"
+// @Success      200                 {object}  domain.Cart
+// @Failure      400                 {string}  string  "Invalid transaction ID or request body"
+// @Failure      401                 {string}  string  "Invalid supervisor token"
+// @Failure      403                 {string}  string  "Supervisor access is not configured"
+// @Failure      404                 {string}  string  "Transaction not found"
+// @Failure      409                 {string}  string  "Transaction is already voided"
+// @Failure      409                 {string}  string  "Sale can no longer be voided"
+// @Failure      409                 {string}  string  "Insufficient loyalty points"
+// @Failure      500                 {string}  string  "Failed to void transaction"
+// @Router       /transactions/{id}/void [post]
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request) {
+	id, ok := transactionIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var input domain.VoidInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	cart, err := h.service.Void(id, input, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error voiding transaction:", err)
+		writeTransactionError(w, err, "Transaction is already voided", "Failed to void transaction")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, cart)
+}
+
+func transactionIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid transaction ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeTransactionError maps transaction errors to responses. conflict is the
+// message for a sale whose status does not allow the operation.
+func writeTransactionError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Transaction not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrVoidNotAllowed), errors.Is(err, apperrors.ErrInsufficientPoints),
+		errors.Is(err, apperrors.ErrNoOpenShift):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, apperrors.ErrProductNotFound):
+		WriteError(w, http.StatusBadRequest, "Product not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
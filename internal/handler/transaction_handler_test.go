@@ -0,0 +1,471 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/receipt"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newTransactionService builds a transaction service printing the default
+// receipt on 58mm paper
+func newTransactionService(t *testing.T, repos repository.Repositories, voidWindow time.Duration) *service.TransactionService {
+	t.Helper()
+	receipts, err := receipt.New(receipt.Options{Footer: []string{"Terima kasih"}})
+	if err != nil {
+		t.Fatalf("receipt renderer: %v", err)
+	}
+	return service.NewTransactionService(repos.Carts, repos.Refunds, repos.Outlets, memory.NewTransactor(repos), voidWindow,
+		receipts, receipt.Paper58)
+}
+
+func TestTransactionHandler_Refunds(t *testing.T) {
+	mux, repos := newCartMux(t)
+	product, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	product.CostPrice = 2800
+	if err := repos.Products.Update(product); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":7000}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	user := map[string]string{"X-User": "siti"}
+	if rec := serve(mux, http.MethodPost, "/api/shifts/open", `{"opening_cash":50000}`, user); rec.Code != http.StatusCreated {
+		t.Fatalf("open shift status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	transactions := handler.NewTransactionHandler(newTransactionService(t, repos, 15*time.Minute))
+	reports := handler.NewReportHandler(service.NewReportService(repos.Carts, repos.Refunds, repos.Outlets))
+	txMux := http.NewServeMux()
+	txMux.HandleFunc("/api/transactions/{id}/refunds", transactions.HandleRefunds)
+	txMux.HandleFunc("/api/reports/sales", reports.HandleSales)
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "refund one", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"lines":[{"product_id":1,"quantity":1}],"reason":"damaged"}`, wantStatus: http.StatusCreated},
+		{name: "refund more than is left", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"lines":[{"product_id":1,"quantity":2}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: only 1 of Indomie Goreng left to refund"},
+		{name: "refund product not sold", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"lines":[{"product_id":99,"quantity":1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: product 99 was not sold in this unit"},
+		{name: "refund zero", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"lines":[{"product_id":1,"quantity":0}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: quantity must be positive"},
+		{name: "refund in an unknown method", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"method":"cheque"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: method must be cash, qris, debit_card or e_wallet"},
+		{name: "refund malformed body", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"lines":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "refund cart not checked out", method: http.MethodPost, path: "/api/transactions/2/refunds", body: `{}`, wantStatus: http.StatusNotFound, wantError: "Transaction not found"},
+		{name: "refund missing", method: http.MethodPost, path: "/api/transactions/99/refunds", body: `{}`, wantStatus: http.StatusNotFound, wantError: "Transaction not found"},
+		{name: "refund invalid id", method: http.MethodPost, path: "/api/transactions/abc/refunds", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "Invalid transaction ID"},
+		{name: "refund the rest into stock", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{"restock":true}`, wantStatus: http.StatusCreated},
+		{name: "refund when nothing is left", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: nothing left to refund"},
+		{name: "list", method: http.MethodGet, path: "/api/transactions/1/refunds", wantStatus: http.StatusOK},
+		{name: "list missing", method: http.MethodGet, path: "/api/transactions/99/refunds", wantStatus: http.StatusNotFound, wantError: "Transaction not found"},
+		{name: "method not allowed", method: http.MethodDelete, path: "/api/transactions/1/refunds", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+	for _, tt := range steps {
+		rec := serve(txMux, tt.method, tt.path, tt.body, user)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+		if resp := decodeResponse(t, rec); resp.Error != tt.wantError {
+			t.Errorf("%s: error = %q, want %q", tt.name, resp.Error, tt.wantError)
+		}
+	}
+
+	// Cash goes back out of a drawer, so a cashier without a shift cannot pay it
+	rec := serve(txMux, http.MethodPost, "/api/transactions/1/refunds", `{}`, map[string]string{"X-User": "andi"})
+	if rec.Code != http.StatusConflict || decodeResponse(t, rec).Error != "cash payments need an open shift" {
+		t.Errorf("refund without a shift: status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var refunds []domain.Refund
+	rec = serve(txMux, http.MethodGet, "/api/transactions/1/refunds", "", nil)
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &refunds); err != nil {
+		t.Fatalf("decode refunds: %v", err)
+	}
+	if len(refunds) != 2 {
+		t.Fatalf("refunds = %d, want 2", len(refunds))
+	}
+	for i, want := range []struct {
+		restock bool
+		reason  string
+	}{{false, "damaged"}, {true, ""}} {
+		refund := refunds[i]
+		if refund.CartID != 1 || refund.Amount != 3500 || refund.Restock != want.restock || refund.Reason != want.reason ||
+			refund.RefundedBy != "siti" || refund.Method != domain.PaymentCash || refund.ShiftID == nil || *refund.ShiftID != 2 ||
+			len(refund.Lines) != 1 || refund.Lines[0].Quantity != 1 {
+			t.Errorf("refund %d = %+v", i, refund)
+		}
+	}
+
+	// Only the restocked refund goes back on the shelf, through the ledger
+	if stock, err := repos.Products.GetStock(1, 1); err != nil || stock != 99 {
+		t.Errorf("stock = %d (%v), want 99", stock, err)
+	}
+	movements, err := repos.StockMovements.GetByProductID(1, 1)
+	if err != nil {
+		t.Fatalf("get movements: %v", err)
+	}
+	if len(movements) == 0 || movements[0].Reason != domain.StockMovementRefund || movements[0].Quantity != 1 ||
+		movements[0].Reference != "REFUND-2" || movements[0].Actor != "siti" {
+		t.Errorf("latest movement = %+v, want the refund", movements)
+	}
+
+	// Refunds take back the revenue; the restocked item's cost comes back too
+	rec = serve(txMux, http.MethodGet, "/api/reports/sales", "", nil)
+	var report domain.SalesReport
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	want := domain.SalesReport{Sales: 1, Refunds: 2, Revenue: 0, Cost: 2800, GrossProfit: -2800}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+
+	// The refunds come out of the drawer of the shift that paid them
+	rec = serve(mux, http.MethodPost, "/api/shifts/2/close", `{"counted_cash":43000}`, user)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("close shift status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var closing domain.ShiftReport
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &closing); err != nil {
+		t.Fatalf("decode shift report: %v", err)
+	}
+	if closing.CashSales != 0 || closing.CashRefunds != 7000 || closing.ExpectedCash != 43000 || closing.Variance != 0 ||
+		closing.PaymentTotals[domain.PaymentCash] != -7000 || closing.RefundTotals[domain.PaymentCash] != 7000 {
+		t.Errorf("shift report = %+v", closing)
+	}
+}
+
+func TestTransactionHandler_RefundRestocksWhatWasTaken(t *testing.T) {
+	mux, repos := newCartMux(t)
+	syrup := domain.Product{Name: "Sirup Marjan", Type: domain.ProductTypeStandard, Price: 20000, CategoryID: 1, UnitID: domain.DefaultUnitID}
+	parcel := domain.Product{Name: "Paket Lebaran", Type: domain.ProductTypeBundle, Price: 90000, CategoryID: 1, UnitID: domain.DefaultUnitID}
+	for _, p := range []*domain.Product{&syrup, &parcel} {
+		if err := repos.Products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	if err := repos.Products.SetStock(syrup.ID, domain.DefaultOutletID, 10); err != nil {
+		t.Fatalf("set stock: %v", err)
+	}
+	components := []domain.BundleComponent{{ProductID: 1, Quantity: 5}, {ProductID: syrup.ID, Quantity: 1}}
+	if err := repos.ProductComponents.ReplaceForBundle(parcel.ID, components); err != nil {
+		t.Fatalf("set components: %v", err)
+	}
+	body := fmt.Sprintf(`{"product_id":%d,"quantity":3}`, parcel.ID)
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/items", body, nil); rec.Code != http.StatusOK {
+		t.Fatalf("add item status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":300000}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	stock := func(id int) int {
+		t.Helper()
+		got, err := repos.Products.GetStock(id, domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("get stock: %v", err)
+		}
+		return got
+	}
+	if stock(1) != 83 || stock(syrup.ID) != 7 {
+		t.Fatalf("stock after checkout = %d, %d, want 83, 7", stock(1), stock(syrup.ID))
+	}
+
+	// A changed recipe does not change what a sold parcel gives back
+	if err := repos.ProductComponents.ReplaceForBundle(parcel.ID, []domain.BundleComponent{{ProductID: 1, Quantity: 1}}); err != nil {
+		t.Fatalf("set components: %v", err)
+	}
+	transactions := handler.NewTransactionHandler(newTransactionService(t, repos, 15*time.Minute))
+	txMux := http.NewServeMux()
+	txMux.HandleFunc("/api/transactions/{id}/refunds", transactions.HandleRefunds)
+	cashier := map[string]string{"X-User": "budi"}
+	body = fmt.Sprintf(`{"lines":[{"product_id":%d,"quantity":1}],"restock":true}`, parcel.ID)
+	if rec := serve(txMux, http.MethodPost, "/api/transactions/1/refunds", body, cashier); rec.Code != http.StatusCreated {
+		t.Fatalf("refund status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if stock(1) != 88 || stock(syrup.ID) != 8 {
+		t.Errorf("stock after refund = %d, %d, want 88, 8", stock(1), stock(syrup.ID))
+	}
+
+	// A component deleted since is skipped rather than failing the refund
+	if err := repos.Products.Delete(syrup.ID); err != nil {
+		t.Fatalf("delete product: %v", err)
+	}
+	if rec := serve(txMux, http.MethodPost, "/api/transactions/1/refunds", `{"restock":true}`, cashier); rec.Code != http.StatusCreated {
+		t.Fatalf("refund the rest status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if stock(1) != 100 {
+		t.Errorf("stock after refunding the rest = %d, want 100", stock(1))
+	}
+}
+
+func TestTransactionHandler_Void(t *testing.T) {
+	mux, repos := newCartMux(t)
+	customer := domain.Customer{Name: "Siti Aminah"}
+	if err := repos.Customers.Create(&customer); err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"customer_id":1,"payments":[{"method":"cash","amount":7000}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Without a void window a sale can only be voided while its shift is open
+	newTxMux := func(window time.Duration) *http.ServeMux {
+		transactions := handler.NewTransactionHandler(newTransactionService(t, repos, window))
+		txMux := http.NewServeMux()
+		txMux.HandleFunc("/api/transactions/{id}/refunds", transactions.HandleRefunds)
+		txMux.HandleFunc("/api/transactions/{id}/void", handler.RequireSupervisorToken("s3cret", transactions.HandleVoid))
+		return txMux
+	}
+	txMux := newTxMux(0)
+	supervisor := map[string]string{"X-Supervisor-Token": "s3cret", "X-User": "siti"}
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		headers    map[string]string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "void without token", method: http.MethodPost, path: "/api/transactions/1/void", body: `{}`, wantStatus: http.StatusUnauthorized, wantError: "Invalid supervisor token"},
+		{name: "void with wrong token", method: http.MethodPost, path: "/api/transactions/1/void", body: `{}`, headers: map[string]string{"X-Supervisor-Token": "nope"}, wantStatus: http.StatusUnauthorized, wantError: "Invalid supervisor token"},
+		{name: "void cart not checked out", method: http.MethodPost, path: "/api/transactions/2/void", body: `{}`, headers: supervisor, wantStatus: http.StatusNotFound, wantError: "Transaction not found"},
+		{name: "void invalid id", method: http.MethodPost, path: "/api/transactions/abc/void", body: `{}`, headers: supervisor, wantStatus: http.StatusBadRequest, wantError: "Invalid transaction ID"},
+		{name: "void malformed body", method: http.MethodPost, path: "/api/transactions/1/void", body: `{"reason":`, headers: supervisor, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "void while the shift is open", method: http.MethodPost, path: "/api/transactions/1/void", body: `{"reason":"Salah input"}`, headers: supervisor, wantStatus: http.StatusOK},
+		{name: "void again", method: http.MethodPost, path: "/api/transactions/1/void", body: `{}`, headers: supervisor, wantStatus: http.StatusConflict, wantError: "Transaction is already voided"},
+		{name: "refund voided sale", method: http.MethodPost, path: "/api/transactions/1/refunds", body: `{}`, headers: map[string]string{"X-User": "budi"}, wantStatus: http.StatusConflict, wantError: "Transaction is voided"},
+		{name: "method not allowed", method: http.MethodGet, path: "/api/transactions/1/void", headers: supervisor, wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+	for _, tt := range steps {
+		rec := serve(txMux, tt.method, tt.path, tt.body, tt.headers)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+		if resp := decodeResponse(t, rec); resp.Error != tt.wantError {
+			t.Errorf("%s: error = %q, want %q", tt.name, resp.Error, tt.wantError)
+		}
+	}
+
+	cart, err := repos.Carts.GetByID(1)
+	if err != nil {
+		t.Fatalf("get cart: %v", err)
+	}
+	if cart.Status != domain.CartStatusVoided || cart.VoidedBy != "siti" || cart.VoidReason != "Salah input" ||
+		cart.VoidedAt == nil || len(cart.Items) != 1 {
+		t.Errorf("cart = %+v, want it voided by siti with its lines", cart)
+	}
+	// The stock comes back through the ledger and the points earned go again
+	if stock, err := repos.Products.GetStock(1, 1); err != nil || stock != 100 {
+		t.Errorf("stock = %d (%v), want 100", stock, err)
+	}
+	movements, err := repos.StockMovements.GetByProductID(1, 1)
+	if err != nil {
+		t.Fatalf("get movements: %v", err)
+	}
+	if len(movements) == 0 || movements[0].Reason != domain.StockMovementVoid || movements[0].Quantity != 2 ||
+		movements[0].Reference != "CART-1" || movements[0].Actor != "siti" {
+		t.Errorf("latest movement = %+v, want the void", movements)
+	}
+	if got, err := repos.Customers.GetByID(customer.ID); err != nil || got.Points != 0 {
+		t.Errorf("customer = %+v (%v), want the 7 points earned taken back", got, err)
+	}
+
+	// The shift closes without the voided cash sale
+	rec := serve(mux, http.MethodPost, "/api/shifts/1/close", `{"counted_cash":100000}`, map[string]string{"X-User": "budi"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("close shift status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var report domain.ShiftReport
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &report); err != nil {
+		t.Fatalf("decode shift report: %v", err)
+	}
+	if report.ExpectedCash != 100000 || report.Variance != 0 {
+		t.Errorf("shift report = %+v, want expected cash 100000 without the voided sale", report)
+	}
+
+	// Sales outside any open shift can only be voided within the window
+	for id := 3; id <= 4; id++ {
+		if rec := serve(mux, http.MethodPost, "/api/carts", `{"items":[{"product_id":1,"quantity":1}]}`, nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create cart status = %d", rec.Code)
+		}
+		if rec := serve(mux, http.MethodPost, fmt.Sprintf("/api/carts/%d/checkout", id), `{"payments":[{"method":"qris","amount":3500}]}`, nil); rec.Code != http.StatusOK {
+			t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serve(txMux, http.MethodPost, "/api/transactions/3/refunds", `{}`, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("refund status = %d: %s", rec.Code, rec.Body.String())
+	}
+	voids := []struct {
+		name       string
+		mux        *http.ServeMux
+		id         int
+		wantStatus int
+		wantError  string
+	}{
+		{name: "refunded sale", mux: newTxMux(15 * time.Minute), id: 3, wantStatus: http.StatusConflict, wantError: "sale can no longer be voided: it has refunds"},
+		{name: "past the window", mux: txMux, id: 4, wantStatus: http.StatusConflict, wantError: "sale can no longer be voided: the void window has passed and its shift is closed"},
+		{name: "within the window", mux: newTxMux(15 * time.Minute), id: 4, wantStatus: http.StatusOK},
+	}
+	for _, tt := range voids {
+		rec := serve(tt.mux, http.MethodPost, fmt.Sprintf("/api/transactions/%d/void", tt.id), `{}`, supervisor)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+		if resp := decodeResponse(t, rec); resp.Error != tt.wantError {
+			t.Errorf("%s: error = %q, want %q", tt.name, resp.Error, tt.wantError)
+		}
+	}
+
+	// Voided sales drop out of revenue
+	reports := handler.NewReportHandler(service.NewReportService(repos.Carts, repos.Refunds, repos.Outlets))
+	reportMux := http.NewServeMux()
+	reportMux.HandleFunc("/api/reports/sales", reports.HandleSales)
+	var sales domain.SalesReport
+	rec = serve(reportMux, http.MethodGet, "/api/reports/sales", "", nil)
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &sales); err != nil {
+		t.Fatalf("decode sales report: %v", err)
+	}
+	if sales.Sales != 1 || sales.Refunds != 1 || sales.Revenue != 0 {
+		t.Errorf("sales report = %+v, want only the refunded sale", sales)
+	}
+}
+
+func TestTransactionHandler_VoidRestocksWhatWasTaken(t *testing.T) {
+	mux, repos := newCartMux(t)
+	dus := domain.Unit{Code: "dus", Name: "Dus"}
+	if err := repos.Units.Create(&dus); err != nil {
+		t.Fatalf("create unit: %v", err)
+	}
+	if err := repos.ProductUnits.ReplaceForProduct(1, []domain.ProductUnit{{UnitID: dus.ID, Factor: 40, Price: 130000}}); err != nil {
+		t.Fatalf("set product units: %v", err)
+	}
+	tea := domain.Product{Name: "Teh Botol", Type: domain.ProductTypeStandard, Price: 4000, CategoryID: 1, UnitID: domain.DefaultUnitID}
+	if err := repos.Products.Create(&tea); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if err := repos.Products.SetStock(tea.ID, domain.DefaultOutletID, 10); err != nil {
+		t.Fatalf("set stock: %v", err)
+	}
+	for _, body := range []string{
+		fmt.Sprintf(`{"product_id":1,"unit_id":%d,"quantity":1}`, dus.ID),
+		fmt.Sprintf(`{"product_id":%d,"quantity":2}`, tea.ID),
+	} {
+		if rec := serve(mux, http.MethodPost, "/api/carts/1/items", body, nil); rec.Code != http.StatusOK {
+			t.Fatalf("add item status = %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":150000}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// The dus is repacked and the tea delisted after the sale
+	if err := repos.ProductUnits.ReplaceForProduct(1, []domain.ProductUnit{{UnitID: dus.ID, Factor: 24, Price: 80000}}); err != nil {
+		t.Fatalf("set product units: %v", err)
+	}
+	if err := repos.Products.Delete(tea.ID); err != nil {
+		t.Fatalf("delete product: %v", err)
+	}
+
+	transactions := handler.NewTransactionHandler(newTransactionService(t, repos, 15*time.Minute))
+	txMux := http.NewServeMux()
+	txMux.HandleFunc("/api/transactions/{id}/void", handler.RequireSupervisorToken("s3cret", transactions.HandleVoid))
+	supervisor := map[string]string{"X-Supervisor-Token": "s3cret", "X-User": "siti"}
+	if rec := serve(txMux, http.MethodPost, "/api/transactions/1/void", `{}`, supervisor); rec.Code != http.StatusOK {
+		t.Fatalf("void status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if stock, err := repos.Products.GetStock(1, domain.DefaultOutletID); err != nil || stock != 100 {
+		t.Errorf("stock = %d (%v), want the 2 pieces and 40 in the dus back", stock, err)
+	}
+}
+
+func TestRequireSupervisorToken_Unconfigured(t *testing.T) {
+	h := handler.RequireSupervisorToken("", func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run without a configured token")
+	})
+	rec := serve(h, http.MethodPost, "/api/transactions/1/void", `{}`, map[string]string{"X-Supervisor-Token": ""})
+	if rec.Code != http.StatusForbidden || decodeResponse(t, rec).Error != "Supervisor access is not configured" {
+		t.Errorf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTransactionHandler_Receipt(t *testing.T) {
+	mux, repos := newCartMux(t)
+	if rec := serve(mux, http.MethodPost, "/api/carts/1/checkout", `{"payments":[{"method":"cash","amount":10000}]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d: %s", rec.Code, rec.Body.String())
+	}
+	transactions := handler.NewTransactionHandler(newTransactionService(t, repos, 15*time.Minute))
+	txMux := http.NewServeMux()
+	txMux.HandleFunc("/api/transactions/{id}/receipt", transactions.HandleReceipt)
+
+	tests := []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantContentType string
+		wantBody        []string
+		wantError       string
+	}{
+		{name: "text", wantStatus: http.StatusOK, wantContentType: "text/plain; charset=utf-8",
+			wantBody: []string{"Indomie Goreng\n  2 x 3.500                7.000\n", "TOTAL                      7.000\n", "Cash                      10.000\nChange                     3.000\n", "Terima kasih"}},
+		{name: "80mm text", query: "?format=text&paper=80", wantStatus: http.StatusOK, wantContentType: "text/plain; charset=utf-8",
+			wantBody: []string{"TOTAL" + strings.Repeat(" ", 38) + "7.000\n"}},
+		{name: "escpos", query: "?format=escpos", wantStatus: http.StatusOK, wantContentType: "application/octet-stream",
+			wantBody: []string{"\x1b@", "TOTAL", "\x1dV\x01"}},
+		{name: "pdf", query: "?format=pdf&paper=80", wantStatus: http.StatusOK, wantContentType: "application/pdf",
+			wantBody: []string{"%PDF-1.4", "(TOTAL", "%%EOF"}},
+		{name: "unknown format", query: "?format=html", wantStatus: http.StatusBadRequest, wantError: "invalid input: format must be text, escpos or pdf"},
+		{name: "unknown paper", query: "?paper=76", wantStatus: http.StatusBadRequest, wantError: "invalid input: paper must be 58 or 80"},
+		{name: "invalid paper", query: "?paper=wide", wantStatus: http.StatusBadRequest, wantError: "Invalid query parameter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(txMux, http.MethodGet, "/api/transactions/1/receipt"+tt.query, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantError != "" {
+				if resp := decodeResponse(t, rec); resp.Error != tt.wantError {
+					t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+				}
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body is missing %q:\n%s", want, rec.Body.String())
+				}
+			}
+		})
+	}
+
+	for path, wantError := range map[string]string{
+		"/api/transactions/2/receipt":   "Transaction not found",
+		"/api/transactions/abc/receipt": "Invalid transaction ID",
+	} {
+		rec := serve(txMux, http.MethodGet, path, "", nil)
+		if resp := decodeResponse(t, rec); resp.Error != wantError {
+			t.Errorf("%s: error = %q, want %q", path, resp.Error, wantError)
+		}
+	}
+}
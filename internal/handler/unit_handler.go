@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/service"
+)
+
+// UnitHandler handles HTTP requests for units of measure and product unit
+// conversions
+type UnitHandler struct {
+	service *service.UnitService
+}
+
+// NewUnitHandler creates a new unit handler
+func NewUnitHandler(service *service.UnitService) *UnitHandler {
+	return &UnitHandler{service: service}
+}
+
+// HandleUnits handles GET and POST requests for /api/units
+func (h *UnitHandler) HandleUnits(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetAll godoc
+// @Summary      Get all units
+// @Description  Retrieve a list of all units of measure
+// @Tags         units
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   domain.Unit
+// @Failure      500  {string}  string  "Failed to fetch units"
+// @Router       /units [get]
+func (h *UnitHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	units, err := h.service.GetAll()
+	if err != nil {
+		log.Println("Error fetching units:", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch units")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, units)
+}
+
+// Create godoc
+// @Summary      Create a new unit
+// @Description  Create a new unit of measure such as a box or carton
+// @Tags         units
+// @Accept       json
+// @Produce      json
+// @Param        unit    body      domain.UnitInput  true   "Unit data"
+// @Param        X-User  header    string            false  "User making the change, recorded in the audit log"
+// @Success      201     {object}  domain.Unit
+// @Failure      400     {string}  string  "Invalid request body"
+// @Failure      409     {string}  string  "Unit code already in use"
+// @Router       /units [post]
+func (h *UnitHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input domain.UnitInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	unit := domain.Unit{Code: input.Code, Name: input.Name}
+	if err := h.service.Create(&unit, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error creating unit:", err)
+		writeUnitError(w, err, "Unit code already in use", "Failed to create unit")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, unit)
+}
+
+// HandleUnitByID handles GET, PUT, DELETE requests for /api/units/{id}
+func (h *UnitHandler) HandleUnitByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetByID godoc
+// @Summary      Get unit by ID
+// @Description  Retrieve a single unit of measure by its ID
+// @Tags         units
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Unit ID"
+// @Success      200  {object}  domain.Unit
+// @Failure      400  {string}  string  "Invalid unit ID"
+// @Failure      404  {string}  string  "Unit not found"
+// @Router       /units/{id} [get]
+func (h *UnitHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/units/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid unit ID")
+		return
+	}
+
+	unit, err := h.service.GetByID(id)
+	if err != nil {
+		log.Println("Error fetching unit by ID:", err)
+		writeUnitError(w, err, "", "Failed to fetch unit")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, unit)
+}
+
+// Update godoc
+// @Summary      Update a unit
+// @Description  Update an existing unit of measure by its ID
+// @Tags         units
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int               true   "Unit ID"
+// @Param        unit    body      domain.UnitInput  true   "Unit data"
+// @Param        X-User  header    string            false  "User making the change, recorded in the audit log"
+// @Success      200     {object}  domain.Unit
+// @Failure      400     {string}  string  "Invalid unit ID or request body"
+// @Failure      404     {string}  string  "Unit not found"
+// @Failure      409     {string}  string  "Unit code already in use"
+// @Router       /units/{id} [put]
+func (h *UnitHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/units/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid unit ID")
+		return
+	}
+
+	var input domain.UnitInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	unit := domain.Unit{ID: id, Code: input.Code, Name: input.Name}
+	if err := h.service.Update(&unit, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error updating unit:", err)
+		writeUnitError(w, err, "Unit code already in use", "Failed to update unit")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, unit)
+}
+
+// Delete godoc
+// @Summary      Delete a unit
+// @Description  Delete a unit of measure by its ID. The piece unit and units used by products, carts or purchase orders cannot be deleted.
+// @Tags         units
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "Unit ID"
+// @Param        X-User  header    string  false  "User making the change, recorded in the audit log"
+// @Success      200  {object}  handler.APIResponse  "Unit deleted successfully"
+// @Failure      400  {string}  string  "Invalid unit ID"
+// @Failure      404  {string}  string  "Unit not found"
+// @Failure      409  {string}  string  "Unit is in use"
+// @Router       /units/{id} [delete]
+func (h *UnitHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDFromPath(r.URL.Path, "/api/units/")
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid unit ID")
+		return
+	}
+
+	if err := h.service.Delete(id, changeMetaFromRequest(r)); err != nil {
+		log.Println("Error deleting unit:", err)
+		writeUnitError(w, err, "Unit is in use", "Failed to delete unit")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Unit deleted successfully"})
+}
+
+// HandleProductUnits handles GET and PUT requests for /api/products/{id}/units
+func (h *UnitHandler) HandleProductUnits(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetProductUnits(w, r)
+	case http.MethodPut:
+		h.SetProductUnits(w, r)
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetProductUnits godoc
+// @Summary      Get product units
+// @Description  Retrieve the units a product can be sold or bought in besides its base unit
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Product ID"
+// @Success      200  {array}   domain.ProductUnit
+// @Failure      400  {string}  string  "Invalid product ID"
+// @Failure      404  {string}  string  "Product not found"
+// @Failure      500  {string}  string  "Failed to fetch product units"
+// @Router       /products/{id}/units [get]
+func (h *UnitHandler) GetProductUnits(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	units, err := h.service.GetProductUnits(id)
+	if err != nil {
+		log.Println("Error fetching product units:", err)
+		writeProductUnitError(w, err, "Failed to fetch product units")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, units)
+}
+
+// SetProductUnits godoc
+// @Summary      Set product units
+// @Description  Replace the units a product converts into its base unit. Each unit has a factor of base units and an optional price; without a price it sells for factor times the base price.
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                       true   "Product ID"
+// @Param        units   body      domain.ProductUnitsInput  true   "Unit conversions"
+// @Param        X-User  header    string                    false  "User making the change, recorded in the audit log"
+// @Success      200     {array}   domain.ProductUnit
+// @Failure      400     {string}  string  "Invalid product ID or request body"
+// @Failure      400     {string}  string  "Unit not found"
+// @Failure      404     {string}  string  "Product not found"
+// @Failure      500     {string}  string  "Failed to update product units"
+// @Router       /products/{id}/units [put]
+func (h *UnitHandler) SetProductUnits(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		WriteError(w, http.StatusBadRequest, "Invalid product ID")
+		return
+	}
+
+	var input domain.ProductUnitsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	units, err := h.service.SetProductUnits(id, input.Units, changeMetaFromRequest(r))
+	if err != nil {
+		log.Println("Error updating product units:", err)
+		writeProductUnitError(w, err, "Failed to update product units")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, units)
+}
+
+func writeUnitError(w http.ResponseWriter, err error, conflict, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Unit not found")
+	case errors.Is(err, apperrors.ErrConflict):
+		WriteError(w, http.StatusConflict, conflict)
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+func writeProductUnitError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "Product not found")
+	case errors.Is(err, apperrors.ErrUnitNotFound):
+		WriteError(w, http.StatusBadRequest, "Unit not found")
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
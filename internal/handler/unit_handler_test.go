@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/handler"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/memory"
+	"kasir-api/internal/service"
+)
+
+// newUnitMux wires the unit and product unit routes the same way router.New
+// does. Unit 2 is a dus (carton) and product 1 sells by the dus of 40 at
+// 130000.
+func newUnitMux(t *testing.T) (http.Handler, repository.Repositories) {
+	t.Helper()
+	repos := newRepos(t)
+	unit := domain.Unit{Code: "dus", Name: "Dus"}
+	if err := repos.Units.Create(&unit); err != nil {
+		t.Fatalf("seed unit: %v", err)
+	}
+	if err := repos.ProductUnits.ReplaceForProduct(1, []domain.ProductUnit{{UnitID: unit.ID, Factor: 40, Price: 130000}}); err != nil {
+		t.Fatalf("seed product unit: %v", err)
+	}
+
+	units := handler.NewUnitHandler(service.NewUnitService(repos.Units, repos.Products, repos.ProductUnits,
+		memory.NewTransactor(repos)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/units", units.HandleUnits)
+	mux.HandleFunc("/api/units/", units.HandleUnitByID)
+	mux.HandleFunc("/api/products/{id}/units", units.HandleProductUnits)
+	return mux, repos
+}
+
+func TestUnitHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/units", wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/api/units", body: `{"code":"pak","name":"Pak"}`, wantStatus: http.StatusCreated},
+		{name: "create with malformed body", method: http.MethodPost, path: "/api/units", body: `{"code":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "create without code", method: http.MethodPost, path: "/api/units", body: `{"name":"Pak"}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: code is required"},
+		{name: "create with taken code", method: http.MethodPost, path: "/api/units", body: `{"code":"DUS","name":"Karton"}`, wantStatus: http.StatusConflict, wantError: "Unit code already in use"},
+		{name: "units method not allowed", method: http.MethodDelete, path: "/api/units", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+		{name: "get", method: http.MethodGet, path: "/api/units/2", wantStatus: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: "/api/units/99", wantStatus: http.StatusNotFound, wantError: "Unit not found"},
+		{name: "get invalid id", method: http.MethodGet, path: "/api/units/abc", wantStatus: http.StatusBadRequest, wantError: "Invalid unit ID"},
+		{name: "update", method: http.MethodPut, path: "/api/units/2", body: `{"code":"dus","name":"Karton"}`, wantStatus: http.StatusOK},
+		{name: "update to taken code", method: http.MethodPut, path: "/api/units/2", body: `{"code":"pcs","name":"Karton"}`, wantStatus: http.StatusConflict, wantError: "Unit code already in use"},
+		{name: "delete", method: http.MethodDelete, path: "/api/units/2", wantStatus: http.StatusOK},
+		{name: "delete piece unit", method: http.MethodDelete, path: "/api/units/1", wantStatus: http.StatusConflict, wantError: "Unit is in use"},
+		{name: "delete missing", method: http.MethodDelete, path: "/api/units/99", wantStatus: http.StatusNotFound, wantError: "Unit not found"},
+		{name: "get product units", method: http.MethodGet, path: "/api/products/1/units", wantStatus: http.StatusOK},
+		{name: "get units of missing product", method: http.MethodGet, path: "/api/products/99/units", wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "get units invalid product id", method: http.MethodGet, path: "/api/products/abc/units", wantStatus: http.StatusBadRequest, wantError: "Invalid product ID"},
+		{name: "set product units", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[{"unit_id":2,"factor":40}]}`, wantStatus: http.StatusOK},
+		{name: "clear product units", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[]}`, wantStatus: http.StatusOK},
+		{name: "set units with malformed body", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":`, wantStatus: http.StatusBadRequest, wantError: "Invalid request body"},
+		{name: "set units without factor", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[{"unit_id":2}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: factor must be greater than zero"},
+		{name: "set units with negative price", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[{"unit_id":2,"factor":40,"price":-1}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: price must not be negative"},
+		{name: "set duplicate units", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[{"unit_id":2,"factor":40},{"unit_id":2,"factor":20}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: unit 2 is listed more than once"},
+		{name: "set base unit", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[{"unit_id":1,"factor":2}]}`, wantStatus: http.StatusBadRequest, wantError: "invalid input: unit 1 is the product's base unit"},
+		{name: "set missing unit", method: http.MethodPut, path: "/api/products/1/units", body: `{"units":[{"unit_id":99,"factor":2}]}`, wantStatus: http.StatusBadRequest, wantError: "Unit not found"},
+		{name: "set units of missing product", method: http.MethodPut, path: "/api/products/99/units", body: `{"units":[]}`, wantStatus: http.StatusNotFound, wantError: "Product not found"},
+		{name: "product units method not allowed", method: http.MethodPost, path: "/api/products/1/units", wantStatus: http.StatusMethodNotAllowed, wantError: "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newUnitMux(t)
+			rec := serve(mux, tt.method, tt.path, tt.body, nil)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			resp := decodeResponse(t, rec)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestUnitHandler_SetProductUnitsAudited(t *testing.T) {
+	mux, repos := newUnitMux(t)
+	rec := serve(mux, http.MethodPut, "/api/products/1/units", `{"units":[{"unit_id":2,"factor":24}]}`,
+		map[string]string{"X-User": "budi"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var units []domain.ProductUnit
+	if err := json.Unmarshal(decodeResponse(t, rec).Data, &units); err != nil {
+		t.Fatalf("decode units: %v", err)
+	}
+	if len(units) != 1 || units[0].UnitID != 2 || units[0].Factor != 24 || units[0].Price != 0 {
+		t.Errorf("units = %+v, want a dus of 24 priced from the base price", units)
+	}
+
+	entries, err := repos.Audit.List(domain.AuditFilter{EntityType: domain.AuditEntityProduct, EntityID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != domain.AuditActionUpdate || entries[0].Actor != "budi" {
+		t.Errorf("audit = %+v, want one product update by budi", entries)
+	}
+}
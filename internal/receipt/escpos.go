@@ -0,0 +1,21 @@
+package receipt
+
+import "bytes"
+
+// ESC/POS commands
+var (
+	escInit = []byte{0x1b, 0x40}       // ESC @: reset the printer
+	escFeed = []byte{0x1b, 0x64, 0x04} // ESC d 4: feed four lines past the cutter
+	gsCut   = []byte{0x1d, 0x56, 0x01} // GS V 1: partial cut
+)
+
+// ESCPOS wraps receipt text in the commands a thermal printer needs: a
+// reset, the text with characters outside ASCII replaced, a feed and a cut
+func ESCPOS(text []byte) []byte {
+	var b bytes.Buffer
+	b.Write(escInit)
+	b.WriteString(ascii(string(text)))
+	b.Write(escFeed)
+	b.Write(gsCut)
+	return b.Bytes()
+}
@@ -0,0 +1,64 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pointsPerMM = 72 / 25.4
+	// pdfMargin is the blank border around the receipt, in points
+	pdfMargin = 4 * pointsPerMM
+	// courierAdvance is the width of a Courier character as a fraction of
+	// the font size
+	courierAdvance = 0.6
+)
+
+// PDF lays receipt text out on a single page as wide as paper, in Courier
+// sized so a full line fills the width, and as long as the receipt
+func PDF(text []byte, paper int) []byte {
+	lines := strings.Split(strings.TrimSuffix(ascii(string(text)), "\n"), "\n")
+	columns, ok := Columns(paper)
+	if !ok {
+		columns = 32
+	}
+	width := float64(paper) * pointsPerMM
+	size := (width - 2*pdfMargin) / (float64(columns) * courierAdvance)
+	leading := size * 1.2
+	height := 2*pdfMargin + leading*float64(len(lines))
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n/F1 %.2f Tf\n%.2f TL\n%.2f %.2f Td\n", size, leading, pdfMargin, height-pdfMargin-size)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscaper.Replace(line))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+			width, height),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// pdfEscaper escapes the characters that end or escape a PDF string
+var pdfEscaper = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
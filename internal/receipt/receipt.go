@@ -0,0 +1,238 @@
+// Package receipt renders sales as printable receipts: fixed-width text for
+// 58mm and 80mm paper, the same text as an ESC/POS byte stream for thermal
+// printers, and a PDF of the paper's width.
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"kasir-api/internal/domain"
+)
+
+// Receipt formats
+const (
+	FormatText   = "text"
+	FormatESCPOS = "escpos"
+	FormatPDF    = "pdf"
+)
+
+// Paper widths in millimetres
+const (
+	Paper58 = 58
+	Paper80 = 80
+)
+
+// DefaultTemplate lays out the store header, the sold lines with their
+// discounts, the totals with the tax under each rate, the payments and the
+// change
+const DefaultTemplate = `{{range .Header}}{{center .}}
+{{end}}{{center .Outlet.Name}}
+{{with .Outlet.Address}}{{center .}}
+{{end}}{{rule}}
+{{columns (printf "No. %d" .Sale.ID) (date .Sale.CheckedOutAt)}}
+{{columns "Cashier" .Sale.Cashier}}
+{{if .Voided}}{{center "*** VOID ***"}}
+{{end}}{{rule}}
+{{range .Sale.Items}}{{left .Name}}
+{{columns (printf "  %d x %s" .Quantity (rupiah .UnitPrice)) (rupiah (mul .Quantity .UnitPrice))}}
+{{range .Discounts}}{{columns (printf "  %s" .Name) (rupiah (neg .Amount))}}
+{{end}}{{end}}{{rule}}
+{{columns "Subtotal" (rupiah .Sale.Subtotal)}}
+{{range .Sale.CartDiscounts}}{{columns .Name (rupiah (neg .Amount))}}
+{{end}}{{if .Sale.Discount}}{{columns "Total discount" (rupiah (neg .Sale.Discount))}}
+{{end}}{{range .Sale.Taxes}}{{if .Inclusive}}{{columns (printf "%s %s%% incl." .Name (rate .Rate)) (rupiah .Tax)}}{{else}}{{columns (printf "%s %s%%" .Name (rate .Rate)) (rupiah .Tax)}}{{end}}
+{{end}}{{if .Sale.PointsDiscount}}{{columns (printf "Points (%d)" .Sale.PointsRedeemed) (rupiah (neg .Sale.PointsDiscount))}}
+{{end}}{{rule}}
+{{columns "TOTAL" (rupiah .Sale.Total)}}
+{{range .Sale.Payments}}{{columns (method .Method) (rupiah .Amount)}}
+{{end}}{{columns "Change" (rupiah .Sale.Change)}}
+{{if .Sale.PointsEarned}}{{columns "Points earned" (printf "%d" .Sale.PointsEarned)}}
+{{end}}{{rule}}
+{{range .Footer}}{{center .}}
+{{end}}`
+
+// Options configure a Renderer. Header and Footer are lines printed above
+// and below the receipt, and Template a text/template replacing
+// DefaultTemplate.
+type Options struct {
+	Header   []string
+	Footer   []string
+	Template string
+}
+
+// Data is what a receipt template renders. Width is the number of
+// characters that fit on a line.
+type Data struct {
+	Header []string
+	Footer []string
+	Outlet domain.Outlet
+	Sale   domain.Cart
+	Voided bool
+	Width  int
+}
+
+// Renderer renders sales as receipts from a template. Besides the standard
+// functions, templates can use center, left, columns and rule, which fit text
+// to the paper's width, and rupiah, date, method, rate, mul and neg.
+type Renderer struct {
+	tmpl   *template.Template
+	header []string
+	footer []string
+}
+
+// New parses the template in opts
+func New(opts Options) (*Renderer, error) {
+	source := opts.Template
+	if source == "" {
+		source = DefaultTemplate
+	}
+	tmpl, err := template.New("receipt").Funcs(funcs(0)).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse receipt template: %w", err)
+	}
+	return &Renderer{tmpl: tmpl, header: opts.Header, footer: opts.Footer}, nil
+}
+
+// Columns returns the characters per line of paper, or false if the width
+// is not supported
+func Columns(paper int) (int, bool) {
+	switch paper {
+	case Paper58:
+		return 32, true
+	case Paper80:
+		return 48, true
+	}
+	return 0, false
+}
+
+// Render renders a sale at an outlet in format for paper
+func (r *Renderer) Render(format string, outlet domain.Outlet, sale domain.Cart, paper int) ([]byte, error) {
+	text, err := r.Text(outlet, sale, paper)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatText:
+		return text, nil
+	case FormatESCPOS:
+		return ESCPOS(text), nil
+	case FormatPDF:
+		return PDF(text, paper), nil
+	}
+	return nil, fmt.Errorf("unknown receipt format %q", format)
+}
+
+// Text renders a sale at an outlet as lines of text for paper
+func (r *Renderer) Text(outlet domain.Outlet, sale domain.Cart, paper int) ([]byte, error) {
+	width, ok := Columns(paper)
+	if !ok {
+		return nil, fmt.Errorf("unsupported paper width %dmm", paper)
+	}
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	data := Data{
+		Header: r.header,
+		Footer: r.footer,
+		Outlet: outlet,
+		Sale:   sale,
+		Voided: sale.Status == domain.CartStatusVoided,
+		Width:  width,
+	}
+	if err := tmpl.Funcs(funcs(width)).Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render receipt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// funcs returns the template functions fitting text to width characters
+func funcs(width int) template.FuncMap {
+	return template.FuncMap{
+		"center": func(s string) string {
+			s = truncate(s, width)
+			return strings.Repeat(" ", (width-utf8.RuneCountInString(s))/2) + s
+		},
+		"left": func(s string) string {
+			return truncate(s, width)
+		},
+		"columns": func(left, right string) string {
+			right = truncate(right, width)
+			room := width - utf8.RuneCountInString(right)
+			left = truncate(left, max(room-1, 0))
+			return left + strings.Repeat(" ", room-utf8.RuneCountInString(left)) + right
+		},
+		"rule": func() string {
+			return strings.Repeat("-", width)
+		},
+		"rupiah": rupiah,
+		"date": func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.Format("02/01/2006 15:04")
+		},
+		"method": method,
+		"rate": func(rate float64) string {
+			return strconv.FormatFloat(rate, 'f', -1, 64)
+		},
+		"mul": func(a, b int) int { return a * b },
+		"neg": func(n int) int { return -n },
+	}
+}
+
+// rupiah formats an amount with dots between thousands, such as 10.500
+func rupiah(n int) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+func method(m string) string {
+	switch m {
+	case domain.PaymentCash:
+		return "Cash"
+	case domain.PaymentQRIS:
+		return "QRIS"
+	case domain.PaymentDebitCard:
+		return "Debit card"
+	case domain.PaymentEWallet:
+		return "E-wallet"
+	}
+	return m
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// ascii replaces what printers and the PDF's standard font cannot show
+func ascii(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || (r >= ' ' && r < utf8.RuneSelf && r != 0x7f) {
+			return r
+		}
+		return '?'
+	}, s)
+}
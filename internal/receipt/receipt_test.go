@@ -0,0 +1,157 @@
+package receipt_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/receipt"
+)
+
+func sale() domain.Cart {
+	checkedOut := time.Date(2026, 3, 1, 8, 5, 0, 0, time.UTC)
+	customerID := 1
+	return domain.Cart{
+		ID: 42, Cashier: "budi", Status: domain.CartStatusCheckedOut, CustomerID: &customerID,
+		Items: []domain.CartItem{
+			{ProductID: 1, Quantity: 3, Name: "Indomie Goreng", UnitPrice: 3500, Discount: 1050, Total: 9450,
+				Discounts: []domain.AppliedDiscount{{PromotionID: 1, Name: "Promo Mie", Amount: 1050}}},
+			{ProductID: 2, Quantity: 1, Name: "Teh Botol Sosro Kotak 250ml Rasa Original", UnitPrice: 4000, Total: 4000},
+		},
+		Subtotal: 14500, Discount: 1550, Tax: 1282, Total: 12750,
+		CartDiscounts: []domain.AppliedDiscount{{PromotionID: 2, Name: "Belanja 10rb", Amount: 500}},
+		Taxes:         []domain.TaxSummary{{TaxRateID: 1, Name: "PPN", Rate: 11, Inclusive: true, Taxable: 11668, Tax: 1282}},
+		Payments:      []domain.Payment{{Method: domain.PaymentQRIS, Amount: 2750}, {Method: domain.PaymentCash, Amount: 20000}},
+		Paid:          22750, Change: 10000, PointsRedeemed: 2, PointsDiscount: 200, PointsEarned: 12,
+		CheckedOutAt: &checkedOut,
+	}
+}
+
+func TestRenderer_Text(t *testing.T) {
+	r, err := receipt.New(receipt.Options{Header: []string{"KASIR MART"}, Footer: []string{"Terima kasih"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	outlet := domain.Outlet{Name: "Toko Pusat", Address: "Jl. Merdeka No. 1, Bandung"}
+
+	for _, paper := range []int{receipt.Paper58, receipt.Paper80} {
+		text, err := r.Text(outlet, sale(), paper)
+		if err != nil {
+			t.Fatalf("Text(%d): %v", paper, err)
+		}
+		width, _ := receipt.Columns(paper)
+		for _, line := range strings.Split(strings.TrimSuffix(string(text), "\n"), "\n") {
+			if n := utf8.RuneCountInString(line); n > width {
+				t.Errorf("%dmm line %q is %d characters, want at most %d", paper, line, n, width)
+			}
+		}
+	}
+
+	text, err := r.Text(outlet, sale(), receipt.Paper58)
+	if err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+	want := `           KASIR MART
+           Toko Pusat
+   Jl. Merdeka No. 1, Bandung
+--------------------------------
+No. 42          01/03/2026 08:05
+Cashier                     budi
+--------------------------------
+Indomie Goreng
+  3 x 3.500               10.500
+  Promo Mie               -1.050
+Teh Botol Sosro Kotak 250ml Rasa
+  1 x 4.000                4.000
+--------------------------------
+Subtotal                  14.500
+Belanja 10rb                -500
+Total discount            -1.550
+PPN 11% incl.              1.282
+Points (2)                  -200
+--------------------------------
+TOTAL                     12.750
+QRIS                       2.750
+Cash                      20.000
+Change                    10.000
+Points earned                 12
+--------------------------------
+          Terima kasih
+`
+	if string(text) != want {
+		t.Errorf("Text =\n%s\nwant\n%s", text, want)
+	}
+
+	voided := sale()
+	voided.Status = domain.CartStatusVoided
+	if text, err := r.Text(outlet, voided, receipt.Paper58); err != nil || !strings.Contains(string(text), "*** VOID ***") {
+		t.Errorf("voided Text = %s, %v, want it marked void", text, err)
+	}
+	if _, err := r.Text(outlet, sale(), 76); err == nil {
+		t.Error("Text on 76mm paper: want an error")
+	}
+}
+
+func TestRenderer_Template(t *testing.T) {
+	r, err := receipt.New(receipt.Options{Template: `{{center .Outlet.Name}}|{{columns "TOTAL" (rupiah .Sale.Total)}}`})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	text, err := r.Text(domain.Outlet{Name: "Cabang"}, sale(), receipt.Paper80)
+	if err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+	if want := "                     Cabang|TOTAL" + strings.Repeat(" ", 37) + "12.750"; string(text) != want {
+		t.Errorf("Text = %q, want %q", text, want)
+	}
+
+	if _, err := receipt.New(receipt.Options{Template: "{{.Sale"}); err == nil {
+		t.Error("New with a broken template: want an error")
+	}
+}
+
+func TestESCPOS(t *testing.T) {
+	got := receipt.ESCPOS([]byte("Kopi Susu\nRp 5.000 ✓\n"))
+	want := append([]byte("\x1b@Kopi Susu\nRp 5.000 ?\n"), 0x1b, 'd', 4, 0x1d, 'V', 1)
+	if !bytes.Equal(got, want) {
+		t.Errorf("ESCPOS = %q, want %q", got, want)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	pdf := receipt.PDF([]byte("Toko (Pusat)\nTOTAL 12.750\n"), receipt.Paper58)
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("PDF does not start and end like a PDF: %q", pdf)
+	}
+	if !bytes.Contains(pdf, []byte(`(Toko \(Pusat\)) Tj`)) || !bytes.Contains(pdf, []byte("(TOTAL 12.750) Tj")) {
+		t.Errorf("PDF is missing the escaped lines: %s", pdf)
+	}
+	if !bytes.Contains(pdf, []byte("/MediaBox [0 0 164.41 ")) {
+		t.Errorf("PDF page is not 58mm wide: %s", pdf)
+	}
+
+	// Every object starts where the cross-reference table says
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatal("PDF has no startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	if len(entries) != 5 {
+		t.Fatalf("xref has %d objects, want 5", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("object %d is not at offset %d", i+1, offset)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kasir-api/internal/domain"
+)
+
+type auditRepository struct {
+	db DBTX
+}
+
+// NewAuditRepository creates a new audit log repository
+func NewAuditRepository(db DBTX) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, before, after, changes, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(query, entry.Actor, entry.Action, entry.EntityType, entry.EntityID,
+		nullableJSON(entry.Before), nullableJSON(entry.After), nullableJSON(entry.Changes), entry.RequestID).
+		Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *auditRepository) List(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(clause string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		addCondition("entity_id = $%d", filter.EntityID)
+	}
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+
+	query := "SELECT id, actor, action, entity_type, entity_id, before, after, changes, request_id, created_at FROM audit_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		var e domain.AuditEntry
+		var before, after, changes []byte
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.EntityType, &e.EntityID,
+			&before, &after, &changes, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Before, e.After, e.Changes = before, after, changes
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// nullableJSON maps an empty document to SQL NULL and passes JSON as text,
+// since lib/pq would otherwise encode []byte as bytea
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
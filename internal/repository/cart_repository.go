@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+type cartRepository struct {
+	db DBTX
+}
+
+// NewCartRepository creates a new cart repository. Update rewrites items,
+// their stock, discounts, taxes and payments in several statements, so it should run
+// inside a transaction.
+func NewCartRepository(db DBTX) CartRepository {
+	return &cartRepository{db: db}
+}
+
+const cartColumns = `id, cashier, outlet_id, status, reserve_stock, shift_id, customer_id, subtotal, discount, tax, total, paid,
+	change_due, points_redeemed, points_discount, points_earned, created_at, updated_at, checked_out_at, voided_at, voided_by,
+	void_reason`
+
+func (r *cartRepository) GetAll(status string, outletID int) ([]domain.Cart, error) {
+	query := "SELECT " + cartColumns + ` FROM carts
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR outlet_id = $2)
+		ORDER BY id`
+	return r.list(query, status, outletID)
+}
+
+func (r *cartRepository) GetByCustomer(customerID int) ([]domain.Cart, error) {
+	query := "SELECT " + cartColumns + ` FROM carts
+		WHERE customer_id = $1 AND status = $2
+		ORDER BY checked_out_at DESC, id DESC`
+	return r.list(query, customerID, domain.CartStatusCheckedOut)
+}
+
+func (r *cartRepository) GetSales(filter domain.SalesFilter) ([]domain.Cart, error) {
+	args := []any{domain.CartStatusCheckedOut}
+	query := "SELECT " + cartColumns + " FROM carts WHERE status = $1"
+	addCondition := func(clause string, arg any) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+clause, len(args))
+	}
+
+	if filter.OutletID != 0 {
+		addCondition("outlet_id = $%d", filter.OutletID)
+	}
+	if !filter.From.IsZero() {
+		addCondition("checked_out_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("checked_out_at < $%d", filter.To)
+	}
+	return r.list(query+" ORDER BY checked_out_at, id", args...)
+}
+
+func (r *cartRepository) list(query string, args ...any) ([]domain.Cart, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carts := make([]domain.Cart, 0)
+	for rows.Next() {
+		c, err := scanCart(rows)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range carts {
+		if err := r.loadLines(&carts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return carts, nil
+}
+
+func (r *cartRepository) Create(cart *domain.Cart) error {
+	query := `
+		INSERT INTO carts (cashier, outlet_id, status, reserve_stock, customer_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := r.db.QueryRow(query, cart.Cashier, cart.OutletID, cart.Status, cart.ReserveStock, cart.CustomerID,
+		cart.CreatedAt, cart.UpdatedAt).Scan(&cart.ID)
+	if err != nil {
+		return err
+	}
+	return r.insertItems(cart)
+}
+
+func (r *cartRepository) GetByID(id int) (*domain.Cart, error) {
+	return r.get("SELECT "+cartColumns+" FROM carts WHERE id = $1", id)
+}
+
+func (r *cartRepository) GetByIDForUpdate(id int) (*domain.Cart, error) {
+	return r.get("SELECT "+cartColumns+" FROM carts WHERE id = $1 FOR UPDATE", id)
+}
+
+func (r *cartRepository) get(query string, id int) (*domain.Cart, error) {
+	c, err := scanCart(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadLines(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *cartRepository) Update(cart *domain.Cart) error {
+	query := `
+		UPDATE carts
+		SET cashier = $1, status = $2, shift_id = $3, customer_id = $4, subtotal = $5, discount = $6, tax = $7,
+			total = $8, paid = $9, change_due = $10, points_redeemed = $11, points_discount = $12,
+			points_earned = $13, updated_at = $14, checked_out_at = $15
+		WHERE id = $16 AND status NOT IN ($17, $18)
+	`
+	c := cart
+	result, err := r.db.Exec(query, c.Cashier, c.Status, c.ShiftID, c.CustomerID, c.Subtotal, c.Discount, c.Tax,
+		c.Total, c.Paid, c.Change, c.PointsRedeemed, c.PointsDiscount, c.PointsEarned, c.UpdatedAt, c.CheckedOutAt,
+		c.ID, domain.CartStatusCheckedOut, domain.CartStatusVoided)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		// The row lock taken by a concurrent checkout makes this update wait
+		// and then see the cart already checked out
+		var exists bool
+		if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM carts WHERE id = $1)", c.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return apperrors.ErrConflict
+		}
+		return apperrors.ErrNotFound
+	}
+
+	if _, err := r.db.Exec("DELETE FROM cart_items WHERE cart_id = $1", cart.ID); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec("DELETE FROM cart_item_stock WHERE cart_id = $1", cart.ID); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec("DELETE FROM payments WHERE cart_id = $1", cart.ID); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec("DELETE FROM cart_discounts WHERE cart_id = $1", cart.ID); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec("DELETE FROM cart_taxes WHERE cart_id = $1", cart.ID); err != nil {
+		return err
+	}
+	if err := r.insertItems(cart); err != nil {
+		return err
+	}
+	if err := r.insertStock(cart); err != nil {
+		return err
+	}
+	if err := r.insertDiscounts(cart); err != nil {
+		return err
+	}
+	for _, t := range cart.Taxes {
+		query := "INSERT INTO cart_taxes (cart_id, tax_rate_id, name, rate, inclusive, taxable, tax) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+		if _, err := r.db.Exec(query, cart.ID, t.TaxRateID, t.Name, t.Rate, t.Inclusive, t.Taxable, t.Tax); err != nil {
+			return err
+		}
+	}
+	for _, p := range cart.Payments {
+		query := "INSERT INTO payments (cart_id, method, amount, reference) VALUES ($1, $2, $3, $4)"
+		if _, err := r.db.Exec(query, cart.ID, p.Method, p.Amount, p.Reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *cartRepository) Void(cart *domain.Cart) error {
+	query := `
+		UPDATE carts SET status = $1, voided_at = $2, voided_by = $3, void_reason = $4, updated_at = $5
+		WHERE id = $6 AND status = $7
+	`
+	c := cart
+	result, err := r.db.Exec(query, domain.CartStatusVoided, c.VoidedAt, c.VoidedBy, c.VoidReason, c.UpdatedAt, c.ID,
+		domain.CartStatusCheckedOut)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		var exists bool
+		if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM carts WHERE id = $1)", c.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return apperrors.ErrConflict
+		}
+		return apperrors.ErrNotFound
+	}
+	cart.Status = domain.CartStatusVoided
+	return nil
+}
+
+func (r *cartRepository) PaymentTotals(shiftID int) (map[string]int, error) {
+	query := `
+		SELECT p.method, SUM(p.amount)
+		FROM payments p
+		JOIN carts c ON p.cart_id = c.id
+		WHERE c.shift_id = $1 AND c.status = $2
+		GROUP BY p.method
+	`
+	rows, err := r.db.Query(query, shiftID, domain.CartStatusCheckedOut)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var method string
+		var amount int
+		if err := rows.Scan(&method, &amount); err != nil {
+			return nil, err
+		}
+		totals[method] = amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var change int
+	query = "SELECT COALESCE(SUM(change_due), 0) FROM carts WHERE shift_id = $1 AND status = $2"
+	if err := r.db.QueryRow(query, shiftID, domain.CartStatusCheckedOut).Scan(&change); err != nil {
+		return nil, err
+	}
+	if change > 0 {
+		totals[domain.PaymentCash] -= change
+	}
+	return totals, nil
+}
+
+func (r *cartRepository) insertItems(cart *domain.Cart) error {
+	query := `
+		INSERT INTO cart_items (cart_id, position, product_id, unit_id, quantity, name, unit_price, discount, tax, total, cost_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	for i, item := range cart.Items {
+		if _, err := r.db.Exec(query, cart.ID, i, item.ProductID, item.UnitID, item.Quantity, item.Name, item.UnitPrice,
+			item.Discount, item.Tax, item.Total, item.CostPrice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertStock stores the stock each item took, by position
+func (r *cartRepository) insertStock(cart *domain.Cart) error {
+	query := "INSERT INTO cart_item_stock (cart_id, position, product_id, quantity) VALUES ($1, $2, $3, $4)"
+	for i, item := range cart.Items {
+		for _, s := range item.Stock {
+			if _, err := r.db.Exec(query, cart.ID, i, s.ProductID, s.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertDiscounts stores the promotions applied to each item, by position,
+// and to the whole cart
+func (r *cartRepository) insertDiscounts(cart *domain.Cart) error {
+	query := "INSERT INTO cart_discounts (cart_id, position, promotion_id, name, amount) VALUES ($1, $2, $3, $4, $5)"
+	for i, item := range cart.Items {
+		for _, d := range item.Discounts {
+			if _, err := r.db.Exec(query, cart.ID, i, d.PromotionID, d.Name, d.Amount); err != nil {
+				return err
+			}
+		}
+	}
+	for _, d := range cart.CartDiscounts {
+		if _, err := r.db.Exec(query, cart.ID, nil, d.PromotionID, d.Name, d.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLines fills in the cart's items with their stock, discounts, taxes and
+// payments
+func (r *cartRepository) loadLines(cart *domain.Cart) error {
+	query := `
+		SELECT product_id, unit_id, quantity, name, unit_price, discount, tax, total, cost_price
+		FROM cart_items WHERE cart_id = $1 ORDER BY position
+	`
+	rows, err := r.db.Query(query, cart.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cart.Items = make([]domain.CartItem, 0)
+	for rows.Next() {
+		var item domain.CartItem
+		if err := rows.Scan(&item.ProductID, &item.UnitID, &item.Quantity, &item.Name, &item.UnitPrice,
+			&item.Discount, &item.Tax, &item.Total, &item.CostPrice); err != nil {
+			return err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := r.loadStock(cart); err != nil {
+		return err
+	}
+	if err := r.loadDiscounts(cart); err != nil {
+		return err
+	}
+	if err := r.loadTaxes(cart); err != nil {
+		return err
+	}
+
+	query = "SELECT method, amount, reference FROM payments WHERE cart_id = $1 ORDER BY id"
+	payments, err := r.db.Query(query, cart.ID)
+	if err != nil {
+		return err
+	}
+	defer payments.Close()
+
+	cart.Payments = make([]domain.Payment, 0)
+	for payments.Next() {
+		var p domain.Payment
+		if err := payments.Scan(&p.Method, &p.Amount, &p.Reference); err != nil {
+			return err
+		}
+		cart.Payments = append(cart.Payments, p)
+	}
+	return payments.Err()
+}
+
+// loadStock attaches the stock each item took to the item
+func (r *cartRepository) loadStock(cart *domain.Cart) error {
+	query := "SELECT position, product_id, quantity FROM cart_item_stock WHERE cart_id = $1 ORDER BY position, product_id"
+	rows, err := r.db.Query(query, cart.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var position int
+		var s domain.StockTake
+		if err := rows.Scan(&position, &s.ProductID, &s.Quantity); err != nil {
+			return err
+		}
+		if position < len(cart.Items) {
+			item := &cart.Items[position]
+			item.Stock = append(item.Stock, s)
+		}
+	}
+	return rows.Err()
+}
+
+// loadDiscounts attaches the cart's discounts to its items and to the cart
+func (r *cartRepository) loadDiscounts(cart *domain.Cart) error {
+	query := "SELECT position, promotion_id, name, amount FROM cart_discounts WHERE cart_id = $1 ORDER BY id"
+	rows, err := r.db.Query(query, cart.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var position *int
+		var d domain.AppliedDiscount
+		if err := rows.Scan(&position, &d.PromotionID, &d.Name, &d.Amount); err != nil {
+			return err
+		}
+		switch {
+		case position == nil:
+			cart.CartDiscounts = append(cart.CartDiscounts, d)
+		case *position < len(cart.Items):
+			item := &cart.Items[*position]
+			item.Discounts = append(item.Discounts, d)
+		}
+	}
+	return rows.Err()
+}
+
+// loadTaxes fills in the tax charged under each rate, leaving Taxes nil for
+// carts that were never checked out or charged no tax
+func (r *cartRepository) loadTaxes(cart *domain.Cart) error {
+	query := "SELECT tax_rate_id, name, rate, inclusive, taxable, tax FROM cart_taxes WHERE cart_id = $1 ORDER BY id"
+	rows, err := r.db.Query(query, cart.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t domain.TaxSummary
+		if err := rows.Scan(&t.TaxRateID, &t.Name, &t.Rate, &t.Inclusive, &t.Taxable, &t.Tax); err != nil {
+			return err
+		}
+		cart.Taxes = append(cart.Taxes, t)
+	}
+	return rows.Err()
+}
+
+func scanCart(row rowScanner) (domain.Cart, error) {
+	var c domain.Cart
+	err := row.Scan(&c.ID, &c.Cashier, &c.OutletID, &c.Status, &c.ReserveStock, &c.ShiftID, &c.CustomerID, &c.Subtotal, &c.Discount,
+		&c.Tax, &c.Total, &c.Paid, &c.Change, &c.PointsRedeemed, &c.PointsDiscount, &c.PointsEarned, &c.CreatedAt,
+		&c.UpdatedAt, &c.CheckedOutAt, &c.VoidedAt, &c.VoidedBy, &c.VoidReason)
+	return c, err
+}
@@ -2,22 +2,25 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 
 	"kasir-api/internal/apperrors"
 	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 type categoryRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewCategoryRepository creates a new category repository
-func NewCategoryRepository(db *sql.DB) CategoryRepository {
+func NewCategoryRepository(db DBTX) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
 func (r *categoryRepository) GetAll() ([]domain.Category, error) {
-	query := "SELECT id, name, description FROM categories"
+	query := "SELECT id, name, description, tax_rate_id, parent_id FROM categories ORDER BY id"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -27,7 +30,7 @@ func (r *categoryRepository) GetAll() ([]domain.Category, error) {
 	categories := make([]domain.Category, 0)
 	for rows.Next() {
 		var c domain.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description); err != nil {
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.TaxRateID, &c.ParentID); err != nil {
 			return nil, err
 		}
 		categories = append(categories, c)
@@ -41,8 +44,8 @@ func (r *categoryRepository) GetAll() ([]domain.Category, error) {
 }
 
 func (r *categoryRepository) Create(category *domain.Category) error {
-	query := "INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id"
-	err := r.db.QueryRow(query, category.Name, category.Description).Scan(&category.ID)
+	query := "INSERT INTO categories (name, description, tax_rate_id, parent_id) VALUES ($1, $2, $3, $4) RETURNING id"
+	err := r.db.QueryRow(query, category.Name, category.Description, category.TaxRateID, category.ParentID).Scan(&category.ID)
 	if err != nil {
 		return err
 	}
@@ -50,11 +53,18 @@ func (r *categoryRepository) Create(category *domain.Category) error {
 }
 
 func (r *categoryRepository) GetByID(id int) (*domain.Category, error) {
-	query := "SELECT id, name, description FROM categories WHERE id = $1"
+	return r.get("SELECT id, name, description, tax_rate_id, parent_id FROM categories WHERE id = $1", id)
+}
+
+func (r *categoryRepository) GetByIDForUpdate(id int) (*domain.Category, error) {
+	return r.get("SELECT id, name, description, tax_rate_id, parent_id FROM categories WHERE id = $1 FOR UPDATE", id)
+}
+
+func (r *categoryRepository) get(query string, id int) (*domain.Category, error) {
 	row := r.db.QueryRow(query, id)
 
 	var c domain.Category
-	if err := row.Scan(&c.ID, &c.Name, &c.Description); err != nil {
+	if err := row.Scan(&c.ID, &c.Name, &c.Description, &c.TaxRateID, &c.ParentID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, apperrors.ErrNotFound
 		}
@@ -65,8 +75,9 @@ func (r *categoryRepository) GetByID(id int) (*domain.Category, error) {
 }
 
 func (r *categoryRepository) Update(category *domain.Category) error {
-	query := "UPDATE categories SET name = $1, description = $2 WHERE id = $3"
-	result, err := r.db.Exec(query, category.Name, category.Description, category.ID)
+	query := "UPDATE categories SET name = $1, description = $2, tax_rate_id = $3, parent_id = $4 WHERE id = $5"
+	result, err := r.db.Exec(query, category.Name, category.Description, category.TaxRateID, category.ParentID,
+		category.ID)
 	if err != nil {
 		return err
 	}
@@ -86,6 +97,11 @@ func (r *categoryRepository) Delete(id int) error {
 	query := "DELETE FROM categories WHERE id = $1"
 	result, err := r.db.Exec(query, id)
 	if err != nil {
+		// Subcategories and products reference the category
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrConflict
+		}
 		return err
 	}
 
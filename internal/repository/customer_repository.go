@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type customerRepository struct {
+	db DBTX
+}
+
+// NewCustomerRepository creates a new customer repository. An empty member
+// code is stored as NULL so it does not collide with other customers.
+func NewCustomerRepository(db DBTX) CustomerRepository {
+	return &customerRepository{db: db}
+}
+
+const customerColumns = "id, name, phone, email, COALESCE(member_code, ''), points"
+
+func (r *customerRepository) GetAll() ([]domain.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers ORDER BY id"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]domain.Customer, 0)
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return customers, nil
+}
+
+func (r *customerRepository) Create(customer *domain.Customer) error {
+	query := `
+		INSERT INTO customers (name, phone, email, member_code)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, points
+	`
+	c := customer
+	err := r.db.QueryRow(query, c.Name, c.Phone, c.Email, c.MemberCode).Scan(&customer.ID, &customer.Points)
+	return memberCodeConflict(err)
+}
+
+func (r *customerRepository) GetByID(id int) (*domain.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers WHERE id = $1"
+	return r.get(query, id)
+}
+
+func (r *customerRepository) GetByIDForUpdate(id int) (*domain.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers WHERE id = $1 FOR UPDATE"
+	return r.get(query, id)
+}
+
+func (r *customerRepository) get(query string, id int) (*domain.Customer, error) {
+	c, err := scanCustomer(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *customerRepository) Update(customer *domain.Customer) error {
+	query := `
+		UPDATE customers
+		SET name = $1, phone = $2, email = $3, member_code = NULLIF($4, '')
+		WHERE id = $5
+		RETURNING points
+	`
+	c := customer
+	err := r.db.QueryRow(query, c.Name, c.Phone, c.Email, c.MemberCode, c.ID).Scan(&customer.Points)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrNotFound
+	}
+	return memberCodeConflict(err)
+}
+
+func (r *customerRepository) Delete(id int) error {
+	query := "DELETE FROM customers WHERE id = $1"
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *customerRepository) AddPoints(id, delta int) error {
+	query := "UPDATE customers SET points = points + $1 WHERE id = $2 AND points + $1 >= 0"
+	result, err := r.db.Exec(query, delta, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM customers WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return apperrors.ErrNotFound
+	}
+	return apperrors.ErrInsufficientPoints
+}
+
+// memberCodeConflict turns a duplicate member code into ErrConflict
+func memberCodeConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrConflict
+	}
+	return err
+}
+
+func scanCustomer(row rowScanner) (domain.Customer, error) {
+	var c domain.Customer
+	err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.MemberCode, &c.Points)
+	return c, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+type idempotencyRepository struct {
+	db DBTX
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db DBTX) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Get(key, route string, at time.Time) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT key, route, request_hash, status_code, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND route = $2 AND expires_at > $3
+	`
+	var record domain.IdempotencyRecord
+	var statusCode sql.NullInt64
+	err := r.db.QueryRow(query, key, route, at).Scan(&record.Key, &record.Route, &record.RequestHash, &statusCode,
+		&record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	record.StatusCode = int(statusCode.Int64)
+	return &record, nil
+}
+
+// Reserve inserts the record, or takes over an expired one for the same key
+// and route. The conditional upsert affects no row while an unexpired record
+// exists.
+func (r *idempotencyRepository) Reserve(record *domain.IdempotencyRecord) error {
+	query := `
+		INSERT INTO idempotency_keys (key, route, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key, route) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL,
+			created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	`
+	result, err := r.db.Exec(query, record.Key, record.Route, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrConflict
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Complete(key, route string, statusCode int, body []byte) error {
+	query := "UPDATE idempotency_keys SET status_code = $1, response_body = $2 WHERE key = $3 AND route = $4"
+	result, err := r.db.Exec(query, statusCode, body, key, route)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(key, route string) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE key = $1 AND route = $2", key, route)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(at time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE expires_at <= $1", at)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rowsAffected), nil
+}
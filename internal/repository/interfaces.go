@@ -1,21 +1,364 @@
 package repository
 
-import "kasir-api/internal/domain"
+import (
+	"time"
 
-// ProductRepository defines the interface for product data access
+	"kasir-api/internal/domain"
+)
+
+// ProductRepository defines the interface for product data access. Stock is
+// kept per outlet: GetAll and GetByID report the total over all outlets, and
+// Create and Update leave stock alone. Create and Update fail with
+// ErrConflict on a duplicate barcode, and Delete with ErrConflict while the
+// product has variants.
 type ProductRepository interface {
 	GetAll() ([]domain.Product, error)
+	// GetVariants returns the variants of a product
+	GetVariants(parentID int) ([]domain.Product, error)
+	// GetByCategory returns the products in a category or any of its
+	// subcategories, however deeply nested
+	GetByCategory(categoryID int) ([]domain.Product, error)
 	Create(product *domain.Product) error
 	GetByID(id int) (*domain.Product, error)
+	// GetByIDForUpdate is GetByID that also locks the product and its stock
+	// at every outlet until the transaction ends, so its cost price can be
+	// averaged over a total stock no concurrent receipt or sale is changing
+	GetByIDForUpdate(id int) (*domain.Product, error)
 	Update(product *domain.Product) error
 	Delete(id int) error
+	// GetStock returns a product's stock at an outlet, zero if it has none
+	GetStock(id, outletID int) (int, error)
+	// LockStock is GetStock that also locks the product's stock at the
+	// outlet until the transaction ends, so checks of stock against
+	// reservations made by concurrent transactions take turns
+	LockStock(id, outletID int) (int, error)
+	// StockLevels returns the stock of every product stocked at an outlet
+	StockLevels(outletID int) (map[int]int, error)
+	// SetStock sets a product's stock at an outlet
+	SetStock(id, outletID, quantity int) error
+	// AddStock adds quantity to a product's stock at an outlet
+	AddStock(id, outletID, quantity int) error
+	// DecrementStock takes quantity off a product's stock at an outlet,
+	// failing with ErrInsufficientStock instead of letting it go negative
+	DecrementStock(id, outletID, quantity int) error
+	// ReceiveStock adds quantity to a product's stock at an outlet and sets
+	// its cost price
+	ReceiveStock(id, outletID, quantity, costPrice int) error
 }
 
-// CategoryRepository defines the interface for category data access
+// CategoryRepository defines the interface for category data access. Delete
+// fails with ErrConflict while the category has subcategories or products.
 type CategoryRepository interface {
 	GetAll() ([]domain.Category, error)
 	Create(category *domain.Category) error
 	GetByID(id int) (*domain.Category, error)
+	// GetByIDForUpdate is GetByID that also locks the category until the
+	// transaction ends, so concurrent moves cannot together form a cycle
+	GetByIDForUpdate(id int) (*domain.Category, error)
 	Update(category *domain.Category) error
 	Delete(id int) error
 }
+
+// PriceHistoryRepository defines the interface for product price history data access
+type PriceHistoryRepository interface {
+	Create(change *domain.PriceChange) error
+	GetByProductID(productID int) ([]domain.PriceChange, error)
+}
+
+// AuditRepository defines the interface for audit log data access
+type AuditRepository interface {
+	Create(entry *domain.AuditEntry) error
+	List(filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// PromotionRepository defines the interface for promotion data access
+type PromotionRepository interface {
+	GetAll() ([]domain.Promotion, error)
+	GetActive(at time.Time) ([]domain.Promotion, error)
+	Create(promotion *domain.Promotion) error
+	GetByID(id int) (*domain.Promotion, error)
+	Update(promotion *domain.Promotion) error
+	Delete(id int) error
+}
+
+// TaxRateRepository defines the interface for tax rate data access
+type TaxRateRepository interface {
+	GetAll() ([]domain.TaxRate, error)
+	Create(rate *domain.TaxRate) error
+	GetByID(id int) (*domain.TaxRate, error)
+	Update(rate *domain.TaxRate) error
+	Delete(id int) error
+}
+
+// ShiftRepository defines the interface for cashier shift data access
+type ShiftRepository interface {
+	Create(shift *domain.Shift) error
+	GetByID(id int) (*domain.Shift, error)
+	// GetByIDForUpdate is GetByID that also locks the shift until the
+	// transaction ends, so no sale joins it while it is being reconciled
+	GetByIDForUpdate(id int) (*domain.Shift, error)
+	// GetOpen returns the cashier's open shift, or ErrNotFound if there is none
+	GetOpen(cashier string) (*domain.Shift, error)
+	// GetOpenForUpdate is GetOpen that also locks the shift until the
+	// transaction ends, so a sale cannot land in a shift closed meanwhile
+	GetOpenForUpdate(cashier string) (*domain.Shift, error)
+	// Close stores the close fields of a shift that is still open
+	Close(shift *domain.Shift) error
+}
+
+// CartRepository defines the interface for cart data access.
+// Carts are loaded and saved together with their items and payments.
+type CartRepository interface {
+	// GetAll lists carts with the given status, or every cart if status is
+	// empty, only those at outletID unless it is zero
+	GetAll(status string, outletID int) ([]domain.Cart, error)
+	Create(cart *domain.Cart) error
+	GetByID(id int) (*domain.Cart, error)
+	// GetByIDForUpdate is GetByID that also locks the cart until the
+	// transaction ends, so changes to an open cart are made one at a time
+	// and refunds and voids of a sale are checked one at a time against
+	// what was already refunded
+	GetByIDForUpdate(id int) (*domain.Cart, error)
+	// Update saves the cart and replaces its items and payments. A checked
+	// out or voided cart is final: updating one fails with ErrConflict, so of
+	// two concurrent checkouts of a cart only the first can save.
+	Update(cart *domain.Cart) error
+	// Void marks a checked out cart voided with its VoidedAt, VoidedBy,
+	// VoidReason and UpdatedAt, failing with ErrConflict for any other cart
+	Void(cart *domain.Cart) error
+	// PaymentTotals sums the payments of carts checked out in a shift per
+	// method. Cash is net of the change given back.
+	PaymentTotals(shiftID int) (map[string]int, error)
+	// GetByCustomer returns the carts a customer checked out, newest first
+	GetByCustomer(customerID int) ([]domain.Cart, error)
+	// GetSales returns the carts checked out at or after filter.From and
+	// before filter.To, oldest first
+	GetSales(filter domain.SalesFilter) ([]domain.Cart, error)
+}
+
+// RefundRepository defines the interface for refund data access. Refunds are
+// read with their lines.
+type RefundRepository interface {
+	Create(refund *domain.Refund) error
+	// GetByCart returns a cart's refunds, oldest first
+	GetByCart(cartID int) ([]domain.Refund, error)
+	// GetAll returns the refunds made at or after filter.From and before
+	// filter.To, oldest first, only those at filter.OutletID unless it is zero
+	GetAll(filter domain.SalesFilter) ([]domain.Refund, error)
+	// ShiftTotals sums the refunds paid back in a shift per method
+	ShiftTotals(shiftID int) (map[string]int, error)
+}
+
+// ReservationRepository defines the interface for cart stock reservations
+type ReservationRepository interface {
+	// ReplaceForCart drops the cart's reservations and stores the given ones
+	ReplaceForCart(cartID int, reservations []domain.Reservation) error
+	DeleteByCart(cartID int) error
+	// ReservedTotals sums unexpired reservations at an outlet per product,
+	// leaving out those of excludeCartID (0 to include every cart)
+	ReservedTotals(at time.Time, outletID, excludeCartID int) (map[int]int, error)
+	// DeleteExpired removes reservations that expired by at and returns how many
+	DeleteExpired(at time.Time) (int, error)
+}
+
+// CustomerRepository defines the interface for customer data access. Create
+// and Update fail with ErrConflict when the member code is already taken,
+// and Update leaves points alone.
+type CustomerRepository interface {
+	GetAll() ([]domain.Customer, error)
+	Create(customer *domain.Customer) error
+	GetByID(id int) (*domain.Customer, error)
+	// GetByIDForUpdate is GetByID that also locks the customer until the
+	// transaction ends, so points are checked and spent one sale at a time
+	GetByIDForUpdate(id int) (*domain.Customer, error)
+	Update(customer *domain.Customer) error
+	Delete(id int) error
+	// AddPoints adds delta, which may be negative, to the customer's points,
+	// failing with ErrInsufficientPoints instead of letting them go negative
+	AddPoints(id, delta int) error
+}
+
+// SupplierRepository defines the interface for supplier data access. Delete
+// fails with ErrConflict while the supplier has purchase orders.
+type SupplierRepository interface {
+	GetAll() ([]domain.Supplier, error)
+	Create(supplier *domain.Supplier) error
+	GetByID(id int) (*domain.Supplier, error)
+	Update(supplier *domain.Supplier) error
+	Delete(id int) error
+}
+
+// PurchaseOrderRepository defines the interface for purchase order data access
+type PurchaseOrderRepository interface {
+	// GetAll lists purchase orders, only those with status unless it is empty
+	// and only those for outletID unless it is zero
+	GetAll(status string, outletID int) ([]domain.PurchaseOrder, error)
+	Create(order *domain.PurchaseOrder) error
+	GetByID(id int) (*domain.PurchaseOrder, error)
+	// GetByIDForUpdate is GetByID that also locks the order until the
+	// transaction ends, so concurrent deliveries are booked one at a time
+	// against up-to-date received quantities
+	GetByIDForUpdate(id int) (*domain.PurchaseOrder, error)
+	// Update saves the order's status, notes and received quantities
+	Update(order *domain.PurchaseOrder) error
+}
+
+// StockMovementRepository defines the interface for the stock ledger
+type StockMovementRepository interface {
+	Create(movement *domain.StockMovement) error
+	// GetByProductID returns a product's movements, newest first, only those
+	// at outletID unless it is zero
+	GetByProductID(productID, outletID int) ([]domain.StockMovement, error)
+}
+
+// StockBatchRepository defines the interface for stock batches. Batches
+// are read with the name of their product.
+type StockBatchRepository interface {
+	Create(batch *domain.StockBatch) error
+	GetByID(id int) (*domain.StockBatch, error)
+	// GetInStock returns a product's batches with stock remaining at an
+	// outlet, first expired first out
+	GetInStock(productID, outletID int) ([]domain.StockBatch, error)
+	// GetExpiring returns the batches with stock remaining that expire before
+	// before, soonest first, only those at outletID unless it is zero
+	GetExpiring(before time.Time, outletID int) ([]domain.StockBatch, error)
+	// Take removes quantity from a batch's remaining stock, failing with
+	// ErrInsufficientStock instead of letting it go negative
+	Take(id, quantity int) error
+}
+
+// OutletRepository defines the interface for outlet data access. Create and
+// Update fail with ErrConflict when the code is already taken, and Delete
+// while the outlet still has stock, carts, orders or transfers.
+type OutletRepository interface {
+	GetAll() ([]domain.Outlet, error)
+	Create(outlet *domain.Outlet) error
+	GetByID(id int) (*domain.Outlet, error)
+	Update(outlet *domain.Outlet) error
+	Delete(id int) error
+}
+
+// StockTransferRepository defines the interface for stock transfer data access
+type StockTransferRepository interface {
+	// GetAll lists transfers, only those from or to outletID unless it is zero
+	GetAll(outletID int) ([]domain.StockTransfer, error)
+	Create(transfer *domain.StockTransfer) error
+	GetByID(id int) (*domain.StockTransfer, error)
+	// GetByIDForUpdate is GetByID that also locks the transfer until the
+	// transaction ends, so concurrent receipts and cancellations settle it
+	// once
+	GetByIDForUpdate(id int) (*domain.StockTransfer, error)
+	// Update saves the transfer's status and updated time
+	Update(transfer *domain.StockTransfer) error
+}
+
+// StockCountRepository defines the interface for stock count data access.
+// Counts are read with their lines and entries.
+type StockCountRepository interface {
+	// GetAll lists counts, only those at outletID unless it is zero and with
+	// status unless it is empty
+	GetAll(outletID int, status string) ([]domain.StockCount, error)
+	Create(count *domain.StockCount) error
+	GetByID(id int) (*domain.StockCount, error)
+	// GetByIDForUpdate is GetByID that also locks the count until the
+	// transaction ends, so concurrent counters add to up-to-date totals and
+	// a count is approved only once
+	GetByIDForUpdate(id int) (*domain.StockCount, error)
+	// Update saves the count's status, approver, updated time and the system
+	// and counted quantities of its lines
+	Update(count *domain.StockCount) error
+	// AddEntry records what a counter found against a count
+	AddEntry(countID int, entry *domain.StockCountEntry) error
+}
+
+// UnitRepository defines the interface for unit of measure data access.
+// Create and Update fail with ErrConflict when the code is already taken, and
+// Delete while products are counted or converted in the unit.
+type UnitRepository interface {
+	GetAll() ([]domain.Unit, error)
+	Create(unit *domain.Unit) error
+	GetByID(id int) (*domain.Unit, error)
+	Update(unit *domain.Unit) error
+	Delete(id int) error
+}
+
+// ProductUnitRepository defines the interface for the unit conversions of
+// products
+type ProductUnitRepository interface {
+	// GetByProductID returns a product's conversions in unit ID order
+	GetByProductID(productID int) ([]domain.ProductUnit, error)
+	// ReplaceForProduct swaps a product's conversions for units
+	ReplaceForProduct(productID int, units []domain.ProductUnit) error
+}
+
+// ProductComponentRepository defines the interface for the components of
+// bundles
+type ProductComponentRepository interface {
+	// GetByBundleID returns a bundle's components in product ID order
+	GetByBundleID(bundleID int) ([]domain.BundleComponent, error)
+	// ReplaceForBundle swaps a bundle's components for components
+	ReplaceForBundle(bundleID int, components []domain.BundleComponent) error
+	// GetBundleIDs returns the IDs of the bundles a product is part of, in
+	// ascending order
+	GetBundleIDs(productID int) ([]int, error)
+}
+
+// ProductImageRepository defines the interface for product image records;
+// the files themselves live in storage
+type ProductImageRepository interface {
+	// GetByProductIDs returns the images of the given products, ordered by
+	// product and then by ID
+	GetByProductIDs(productIDs []int) ([]domain.ProductImage, error)
+	Create(image *domain.ProductImage) error
+	GetByID(id int) (*domain.ProductImage, error)
+	Delete(id int) error
+}
+
+// IdempotencyRepository defines the interface for stored idempotent responses,
+// keyed by idempotency key and route
+type IdempotencyRepository interface {
+	// Get returns the record for key and route unless it expired by at
+	Get(key, route string, at time.Time) (*domain.IdempotencyRecord, error)
+	// Reserve stores a record for a request that has not completed yet. It
+	// fails with ErrConflict while an unexpired record exists for the key and
+	// route, and replaces an expired one.
+	Reserve(record *domain.IdempotencyRecord) error
+	// Complete stores the response of a reserved request
+	Complete(key, route string, statusCode int, body []byte) error
+	Delete(key, route string) error
+	// DeleteExpired removes records that expired by at and returns how many
+	DeleteExpired(at time.Time) (int, error)
+}
+
+// Repositories groups the repositories a service may need to use together
+type Repositories struct {
+	Products          ProductRepository
+	Categories        CategoryRepository
+	PriceHistory      PriceHistoryRepository
+	Audit             AuditRepository
+	Promotions        PromotionRepository
+	TaxRates          TaxRateRepository
+	Shifts            ShiftRepository
+	Carts             CartRepository
+	Refunds           RefundRepository
+	Reservations      ReservationRepository
+	Idempotency       IdempotencyRepository
+	Customers         CustomerRepository
+	Suppliers         SupplierRepository
+	PurchaseOrders    PurchaseOrderRepository
+	StockMovements    StockMovementRepository
+	Outlets           OutletRepository
+	StockTransfers    StockTransferRepository
+	Units             UnitRepository
+	ProductUnits      ProductUnitRepository
+	ProductComponents ProductComponentRepository
+	ProductImages     ProductImageRepository
+	StockBatches      StockBatchRepository
+	StockCounts       StockCountRepository
+}
+
+// Transactor runs fn with repositories that share a single transaction.
+// The transaction is committed when fn returns nil and rolled back otherwise.
+type Transactor interface {
+	WithinTx(fn func(repos Repositories) error) error
+}
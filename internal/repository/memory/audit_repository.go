@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type auditRepository struct {
+	mu      sync.RWMutex
+	nextID  int
+	entries []domain.AuditEntry
+}
+
+// NewAuditRepository creates a new in-memory audit log repository
+func NewAuditRepository() repository.AuditRepository {
+	return &auditRepository{nextID: 1}
+}
+
+func (r *auditRepository) Create(entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = r.nextID
+	r.nextID++
+	entry.CreatedAt = time.Now().UTC()
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *auditRepository) List(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]domain.AuditEntry, 0)
+	skipped := 0
+	for i := len(r.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		e := r.entries[i]
+		if !matchesAuditFilter(e, filter) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func matchesAuditFilter(e domain.AuditEntry, f domain.AuditFilter) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor,
+		f.Action != "" && e.Action != f.Action,
+		f.EntityType != "" && e.EntityType != f.EntityType,
+		f.EntityID != 0 && e.EntityID != f.EntityID,
+		!f.From.IsZero() && e.CreatedAt.Before(f.From),
+		!f.To.IsZero() && !e.CreatedAt.Before(f.To):
+		return false
+	}
+	return true
+}
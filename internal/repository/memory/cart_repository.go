@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type cartRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	carts  map[int]domain.Cart
+}
+
+// NewCartRepository creates a new in-memory cart repository
+func NewCartRepository() repository.CartRepository {
+	return &cartRepository{
+		nextID: 1,
+		carts:  make(map[int]domain.Cart),
+	}
+}
+
+func (r *cartRepository) GetAll(status string, outletID int) ([]domain.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	carts := make([]domain.Cart, 0)
+	for id := 1; id < r.nextID; id++ {
+		c, ok := r.carts[id]
+		if !ok || (status != "" && c.Status != status) || (outletID != 0 && c.OutletID != outletID) {
+			continue
+		}
+		carts = append(carts, cloneCart(c))
+	}
+	return carts, nil
+}
+
+func (r *cartRepository) Create(cart *domain.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart.ID = r.nextID
+	r.nextID++
+	r.carts[cart.ID] = cloneCart(*cart)
+	return nil
+}
+
+func (r *cartRepository) GetByID(id int) (*domain.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.carts[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	c = cloneCart(c)
+	return &c, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *cartRepository) GetByIDForUpdate(id int) (*domain.Cart, error) {
+	return r.GetByID(id)
+}
+
+func (r *cartRepository) Update(cart *domain.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.carts[cart.ID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	if stored.Status == domain.CartStatusCheckedOut || stored.Status == domain.CartStatusVoided {
+		return apperrors.ErrConflict
+	}
+	r.carts[cart.ID] = cloneCart(*cart)
+	return nil
+}
+
+func (r *cartRepository) Void(cart *domain.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.carts[cart.ID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	if stored.Status != domain.CartStatusCheckedOut {
+		return apperrors.ErrConflict
+	}
+	stored.Status = domain.CartStatusVoided
+	stored.VoidedAt = cart.VoidedAt
+	stored.VoidedBy = cart.VoidedBy
+	stored.VoidReason = cart.VoidReason
+	stored.UpdatedAt = cart.UpdatedAt
+	r.carts[cart.ID] = stored
+	cart.Status = domain.CartStatusVoided
+	return nil
+}
+
+func (r *cartRepository) PaymentTotals(shiftID int) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	totals := make(map[string]int)
+	change := 0
+	for _, c := range r.carts {
+		if c.ShiftID == nil || *c.ShiftID != shiftID || c.Status != domain.CartStatusCheckedOut {
+			continue
+		}
+		for _, p := range c.Payments {
+			totals[p.Method] += p.Amount
+		}
+		change += c.Change
+	}
+	if change > 0 {
+		totals[domain.PaymentCash] -= change
+	}
+	return totals, nil
+}
+
+func (r *cartRepository) GetByCustomer(customerID int) ([]domain.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	carts := make([]domain.Cart, 0)
+	for id := r.nextID - 1; id >= 1; id-- {
+		c, ok := r.carts[id]
+		if !ok || c.CustomerID == nil || *c.CustomerID != customerID || c.Status != domain.CartStatusCheckedOut {
+			continue
+		}
+		carts = append(carts, cloneCart(c))
+	}
+	slices.SortStableFunc(carts, func(a, b domain.Cart) int {
+		return b.CheckedOutAt.Compare(*a.CheckedOutAt)
+	})
+	return carts, nil
+}
+
+func (r *cartRepository) GetSales(filter domain.SalesFilter) ([]domain.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	carts := make([]domain.Cart, 0)
+	for id := 1; id < r.nextID; id++ {
+		c, ok := r.carts[id]
+		if !ok || c.Status != domain.CartStatusCheckedOut || (filter.OutletID != 0 && c.OutletID != filter.OutletID) {
+			continue
+		}
+		// Like SQL, a missing checkout time matches no period
+		if (!filter.From.IsZero() || !filter.To.IsZero()) && c.CheckedOutAt == nil {
+			continue
+		}
+		if (!filter.From.IsZero() && c.CheckedOutAt.Before(filter.From)) ||
+			(!filter.To.IsZero() && !c.CheckedOutAt.Before(filter.To)) {
+			continue
+		}
+		carts = append(carts, cloneCart(c))
+	}
+	slices.SortStableFunc(carts, func(a, b domain.Cart) int {
+		// Postgres sorts a missing checkout time last
+		switch {
+		case a.CheckedOutAt == nil && b.CheckedOutAt == nil:
+			return 0
+		case a.CheckedOutAt == nil:
+			return 1
+		case b.CheckedOutAt == nil:
+			return -1
+		}
+		return a.CheckedOutAt.Compare(*b.CheckedOutAt)
+	})
+	return carts, nil
+}
+
+// cloneCart copies the item, discount, tax and payment slices so callers
+// never share backing arrays with the stored cart. Nil item and payment
+// slices become empty and empty discount and tax slices nil, as they do when
+// Postgres loads a cart.
+func cloneCart(c domain.Cart) domain.Cart {
+	c.Items = append(make([]domain.CartItem, 0, len(c.Items)), c.Items...)
+	for i := range c.Items {
+		c.Items[i].Discounts = cloneDiscounts(c.Items[i].Discounts)
+		if len(c.Items[i].Stock) == 0 {
+			c.Items[i].Stock = nil
+		} else {
+			c.Items[i].Stock = append([]domain.StockTake(nil), c.Items[i].Stock...)
+		}
+	}
+	c.CartDiscounts = cloneDiscounts(c.CartDiscounts)
+	if len(c.Taxes) == 0 {
+		c.Taxes = nil
+	} else {
+		c.Taxes = append([]domain.TaxSummary(nil), c.Taxes...)
+	}
+	c.Payments = append(make([]domain.Payment, 0, len(c.Payments)), c.Payments...)
+	return c
+}
+
+func cloneDiscounts(discounts []domain.AppliedDiscount) []domain.AppliedDiscount {
+	if len(discounts) == 0 {
+		return nil
+	}
+	return append([]domain.AppliedDiscount(nil), discounts...)
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type categoryRepository struct {
+	mu         sync.RWMutex
+	nextID     int
+	categories map[int]domain.Category
+}
+
+// NewCategoryRepository creates a new in-memory category repository. Like the
+// Postgres implementation it refuses to delete a category with subcategories,
+// but it does not know which products use a category.
+func NewCategoryRepository() repository.CategoryRepository {
+	return &categoryRepository{
+		nextID:     1,
+		categories: make(map[int]domain.Category),
+	}
+}
+
+func (r *categoryRepository) GetAll() ([]domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]domain.Category, 0, len(r.categories))
+	for id := 1; id < r.nextID; id++ {
+		if c, ok := r.categories[id]; ok {
+			categories = append(categories, c)
+		}
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) Create(category *domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	category.ID = r.nextID
+	r.nextID++
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *categoryRepository) GetByID(id int) (*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.categories[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &c, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *categoryRepository) GetByIDForUpdate(id int) (*domain.Category, error) {
+	return r.GetByID(id)
+}
+
+func (r *categoryRepository) Update(category *domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[category.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *categoryRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			return apperrors.ErrConflict
+		}
+	}
+	delete(r.categories, id)
+	return nil
+}
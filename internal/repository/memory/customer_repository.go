@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type customerRepository struct {
+	mu        sync.RWMutex
+	nextID    int
+	customers map[int]domain.Customer
+}
+
+// NewCustomerRepository creates a new in-memory customer repository.
+// Unlike Postgres it does not detach deleted customers from their carts.
+func NewCustomerRepository() repository.CustomerRepository {
+	return &customerRepository{
+		nextID:    1,
+		customers: make(map[int]domain.Customer),
+	}
+}
+
+func (r *customerRepository) GetAll() ([]domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	customers := make([]domain.Customer, 0, len(r.customers))
+	for id := 1; id < r.nextID; id++ {
+		if c, ok := r.customers[id]; ok {
+			customers = append(customers, c)
+		}
+	}
+	return customers, nil
+}
+
+func (r *customerRepository) Create(customer *domain.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.memberCodeTaken(customer.MemberCode, 0) {
+		return apperrors.ErrConflict
+	}
+	customer.ID = r.nextID
+	customer.Points = 0
+	r.nextID++
+	r.customers[customer.ID] = *customer
+	return nil
+}
+
+func (r *customerRepository) GetByID(id int) (*domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.customers[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &c, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *customerRepository) GetByIDForUpdate(id int) (*domain.Customer, error) {
+	return r.GetByID(id)
+}
+
+func (r *customerRepository) Update(customer *domain.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.customers[customer.ID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	if r.memberCodeTaken(customer.MemberCode, customer.ID) {
+		return apperrors.ErrConflict
+	}
+	customer.Points = current.Points
+	r.customers[customer.ID] = *customer
+	return nil
+}
+
+func (r *customerRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.customers[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.customers, id)
+	return nil
+}
+
+func (r *customerRepository) AddPoints(id, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.customers[id]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	if c.Points+delta < 0 {
+		return apperrors.ErrInsufficientPoints
+	}
+	c.Points += delta
+	r.customers[id] = c
+	return nil
+}
+
+// memberCodeTaken reports whether another customer than exceptID already
+// has the member code. Empty codes never collide.
+func (r *customerRepository) memberCodeTaken(code string, exceptID int) bool {
+	if code == "" {
+		return false
+	}
+	for id, c := range r.customers {
+		if id != exceptID && c.MemberCode == code {
+			return true
+		}
+	}
+	return false
+}
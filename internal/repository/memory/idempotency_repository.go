@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type idempotencyKey struct {
+	key, route string
+}
+
+type idempotencyRepository struct {
+	mu      sync.RWMutex
+	records map[idempotencyKey]domain.IdempotencyRecord
+}
+
+// NewIdempotencyRepository creates a new in-memory idempotency key repository
+func NewIdempotencyRepository() repository.IdempotencyRepository {
+	return &idempotencyRepository{records: make(map[idempotencyKey]domain.IdempotencyRecord)}
+}
+
+func (r *idempotencyRepository) Get(key, route string, at time.Time) (*domain.IdempotencyRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[idempotencyKey{key, route}]
+	if !ok || !record.ExpiresAt.After(at) {
+		return nil, apperrors.ErrNotFound
+	}
+	record.Body = slices.Clone(record.Body)
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Reserve(record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKey{record.Key, record.Route}
+	if existing, ok := r.records[id]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return apperrors.ErrConflict
+	}
+	stored := *record
+	stored.StatusCode, stored.Body = 0, nil
+	r.records[id] = stored
+	return nil
+}
+
+func (r *idempotencyRepository) Complete(key, route string, statusCode int, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKey{key, route}
+	record, ok := r.records[id]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	record.StatusCode = statusCode
+	record.Body = slices.Clone(body)
+	r.records[id] = record
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(key, route string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, idempotencyKey{key, route})
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for id, record := range r.records {
+		if !record.ExpiresAt.After(at) {
+			delete(r.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,51 @@
+package memory_test
+
+import (
+	"sync"
+	"testing"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository/memory"
+)
+
+func TestConcurrentCreateAssignsUniqueIDs(t *testing.T) {
+	categoryRepo := memory.NewCategoryRepository()
+	productRepo := memory.NewProductRepository(categoryRepo)
+
+	category := domain.Category{Name: "Minuman"}
+	if err := categoryRepo.Create(&category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	const n = 50
+	ids := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := domain.Product{Name: "Teh Botol", Price: 5000, CategoryID: category.ID}
+			if err := productRepo.Create(&p); err != nil {
+				t.Errorf("create product: %v", err)
+				return
+			}
+			ids <- p.ID
+			if _, err := productRepo.GetAll(); err != nil {
+				t.Errorf("get all: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != n {
+		t.Errorf("created %d products, want %d", len(seen), n)
+	}
+}
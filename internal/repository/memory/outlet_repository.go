@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type outletRepository struct {
+	mu      sync.RWMutex
+	nextID  int
+	outlets map[int]domain.Outlet
+}
+
+// NewOutletRepository creates a new in-memory outlet repository holding the
+// default outlet, as the Postgres schema does. Unlike Postgres it does not
+// refuse to delete outlets that are still in use.
+func NewOutletRepository() repository.OutletRepository {
+	return &outletRepository{
+		nextID: domain.DefaultOutletID + 1,
+		outlets: map[int]domain.Outlet{
+			domain.DefaultOutletID: {ID: domain.DefaultOutletID, Code: "MAIN", Name: "Main outlet"},
+		},
+	}
+}
+
+func (r *outletRepository) GetAll() ([]domain.Outlet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	outlets := make([]domain.Outlet, 0, len(r.outlets))
+	for id := 1; id < r.nextID; id++ {
+		if o, ok := r.outlets[id]; ok {
+			outlets = append(outlets, o)
+		}
+	}
+	return outlets, nil
+}
+
+func (r *outletRepository) Create(outlet *domain.Outlet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.codeTaken(outlet.Code, 0) {
+		return apperrors.ErrConflict
+	}
+	outlet.ID = r.nextID
+	r.nextID++
+	r.outlets[outlet.ID] = *outlet
+	return nil
+}
+
+func (r *outletRepository) GetByID(id int) (*domain.Outlet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	o, ok := r.outlets[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &o, nil
+}
+
+func (r *outletRepository) Update(outlet *domain.Outlet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.outlets[outlet.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	if r.codeTaken(outlet.Code, outlet.ID) {
+		return apperrors.ErrConflict
+	}
+	r.outlets[outlet.ID] = *outlet
+	return nil
+}
+
+func (r *outletRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.outlets[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.outlets, id)
+	return nil
+}
+
+// codeTaken reports whether another outlet than exceptID already has the code
+func (r *outletRepository) codeTaken(code string, exceptID int) bool {
+	for id, o := range r.outlets {
+		if id != exceptID && o.Code == code {
+			return true
+		}
+	}
+	return false
+}
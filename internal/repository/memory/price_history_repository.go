@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type priceHistoryRepository struct {
+	mu      sync.RWMutex
+	nextID  int
+	changes []domain.PriceChange
+}
+
+// NewPriceHistoryRepository creates a new in-memory price history repository
+func NewPriceHistoryRepository() repository.PriceHistoryRepository {
+	return &priceHistoryRepository{nextID: 1}
+}
+
+func (r *priceHistoryRepository) Create(change *domain.PriceChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change.ID = r.nextID
+	r.nextID++
+	change.ChangedAt = time.Now().UTC()
+	r.changes = append(r.changes, *change)
+	return nil
+}
+
+func (r *priceHistoryRepository) GetByProductID(productID int) ([]domain.PriceChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Newest first, matching the Postgres ORDER BY
+	changes := make([]domain.PriceChange, 0)
+	for i := len(r.changes) - 1; i >= 0; i-- {
+		if r.changes[i].ProductID == productID {
+			changes = append(changes, r.changes[i])
+		}
+	}
+	return changes, nil
+}
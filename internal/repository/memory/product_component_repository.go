@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type productComponentRepository struct {
+	mu         sync.RWMutex
+	components map[int][]domain.BundleComponent
+}
+
+// NewProductComponentRepository creates a new in-memory bundle component
+// repository. It does not check that the bundle and components exist.
+func NewProductComponentRepository() repository.ProductComponentRepository {
+	return &productComponentRepository{components: make(map[int][]domain.BundleComponent)}
+}
+
+func (r *productComponentRepository) GetByBundleID(bundleID int) ([]domain.BundleComponent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	components := make([]domain.BundleComponent, len(r.components[bundleID]))
+	copy(components, r.components[bundleID])
+	return components, nil
+}
+
+func (r *productComponentRepository) ReplaceForBundle(bundleID int, components []domain.BundleComponent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := slices.Clone(components)
+	slices.SortFunc(stored, func(a, b domain.BundleComponent) int { return cmp.Compare(a.ProductID, b.ProductID) })
+	if len(stored) == 0 {
+		delete(r.components, bundleID)
+		return nil
+	}
+	r.components[bundleID] = stored
+	return nil
+}
+
+func (r *productComponentRepository) GetBundleIDs(productID int) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0)
+	for bundleID, components := range r.components {
+		if slices.ContainsFunc(components, func(c domain.BundleComponent) bool { return c.ProductID == productID }) {
+			ids = append(ids, bundleID)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type productImageRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	images map[int]domain.ProductImage
+}
+
+// NewProductImageRepository creates a new in-memory product image repository.
+// It does not check that the product exists.
+func NewProductImageRepository() repository.ProductImageRepository {
+	return &productImageRepository{nextID: 1, images: make(map[int]domain.ProductImage)}
+}
+
+func (r *productImageRepository) GetByProductIDs(productIDs []int) ([]domain.ProductImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	images := make([]domain.ProductImage, 0)
+	for id := 1; id < r.nextID; id++ {
+		if image, ok := r.images[id]; ok && slices.Contains(productIDs, image.ProductID) {
+			images = append(images, image)
+		}
+	}
+	slices.SortStableFunc(images, func(a, b domain.ProductImage) int { return a.ProductID - b.ProductID })
+	return images, nil
+}
+
+func (r *productImageRepository) Create(image *domain.ProductImage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	image.ID = r.nextID
+	image.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.images[image.ID] = *image
+	return nil
+}
+
+func (r *productImageRepository) GetByID(id int) (*domain.ProductImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	image, ok := r.images[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &image, nil
+}
+
+func (r *productImageRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.images[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.images, id)
+	return nil
+}
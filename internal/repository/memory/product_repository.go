@@ -0,0 +1,283 @@
+package memory
+
+import (
+	"errors"
+	"maps"
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type productRepository struct {
+	mu           sync.RWMutex
+	nextID       int
+	products     map[int]domain.Product
+	stock        map[int]map[int]int // product ID -> outlet ID -> quantity
+	categoryRepo repository.CategoryRepository
+}
+
+// NewProductRepository creates a new in-memory product repository.
+// Categories are resolved through categoryRepo, mirroring the JOIN done by
+// the Postgres implementation. Stock is not checked against known outlets.
+func NewProductRepository(categoryRepo repository.CategoryRepository) repository.ProductRepository {
+	return &productRepository{
+		nextID:       1,
+		products:     make(map[int]domain.Product),
+		stock:        make(map[int]map[int]int),
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (r *productRepository) GetAll() ([]domain.Product, error) {
+	return r.list(func(domain.Product) bool { return true })
+}
+
+func (r *productRepository) GetVariants(parentID int) ([]domain.Product, error) {
+	return r.list(func(p domain.Product) bool { return p.ParentID != nil && *p.ParentID == parentID })
+}
+
+func (r *productRepository) GetByCategory(categoryID int) ([]domain.Product, error) {
+	categories, err := r.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	tree := map[int]bool{categoryID: true}
+	// Categories come in ID order, but a subcategory can be created before
+	// it is moved under a later one, so sweep until nothing new joins
+	for grown := true; grown; {
+		grown = false
+		for _, c := range categories {
+			if c.ParentID != nil && tree[*c.ParentID] && !tree[c.ID] {
+				tree[c.ID] = true
+				grown = true
+			}
+		}
+	}
+	return r.list(func(p domain.Product) bool { return tree[p.CategoryID] })
+}
+
+// list returns the products match keeps, in ID order
+func (r *productRepository) list(match func(domain.Product) bool) ([]domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]domain.Product, 0, len(r.products))
+	for id := 1; id < r.nextID; id++ {
+		p, ok := r.products[id]
+		if !ok || !match(p) {
+			continue
+		}
+		c, err := r.categoryRepo.GetByID(p.CategoryID)
+		if err != nil {
+			// Rows without a matching category are dropped by the inner JOIN
+			if errors.Is(err, apperrors.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		p.Category = c
+		p.Stock = r.totalStock(id)
+		p.Options = cloneOptions(p.Options)
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+func (r *productRepository) Create(product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.barcodeTaken(product.Barcode, 0) {
+		return apperrors.ErrConflict
+	}
+	product.ID = r.nextID
+	r.nextID++
+	r.products[product.ID] = stripCategory(*product)
+	return nil
+}
+
+func (r *productRepository) GetByID(id int) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	c, err := r.categoryRepo.GetByID(p.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	p.Category = c
+	p.Stock = r.totalStock(id)
+	p.Options = cloneOptions(p.Options)
+	return &p, nil
+}
+
+func (r *productRepository) Update(product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[product.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	if r.barcodeTaken(product.Barcode, product.ID) {
+		return apperrors.ErrConflict
+	}
+	r.products[product.ID] = stripCategory(*product)
+	return nil
+}
+
+func (r *productRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	for _, p := range r.products {
+		if p.ParentID != nil && *p.ParentID == id {
+			return apperrors.ErrConflict
+		}
+	}
+	delete(r.products, id)
+	delete(r.stock, id)
+	return nil
+}
+
+func (r *productRepository) GetStock(id, outletID int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.stock[id][outletID], nil
+}
+
+// LockStock is GetStock; the memory transactor already serialises
+// transactions
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *productRepository) GetByIDForUpdate(id int) (*domain.Product, error) {
+	return r.GetByID(id)
+}
+
+func (r *productRepository) LockStock(id, outletID int) (int, error) {
+	return r.GetStock(id, outletID)
+}
+
+func (r *productRepository) StockLevels(outletID int) (map[int]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[int]int)
+	for productID, outlets := range r.stock {
+		if quantity, ok := outlets[outletID]; ok {
+			levels[productID] = quantity
+		}
+	}
+	return levels, nil
+}
+
+func (r *productRepository) SetStock(id, outletID, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	r.outletStock(id)[outletID] = quantity
+	return nil
+}
+
+func (r *productRepository) AddStock(id, outletID, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	r.outletStock(id)[outletID] += quantity
+	return nil
+}
+
+func (r *productRepository) DecrementStock(id, outletID, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	if r.stock[id][outletID] < quantity {
+		return apperrors.ErrInsufficientStock
+	}
+	r.outletStock(id)[outletID] -= quantity
+	return nil
+}
+
+func (r *productRepository) ReceiveStock(id, outletID, quantity, costPrice int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	p.CostPrice = costPrice
+	r.products[id] = p
+	r.outletStock(id)[outletID] += quantity
+	return nil
+}
+
+// totalStock sums a product's stock over every outlet. Callers must hold r.mu.
+func (r *productRepository) totalStock(id int) int {
+	total := 0
+	for _, quantity := range r.stock[id] {
+		total += quantity
+	}
+	return total
+}
+
+// outletStock returns a product's per-outlet stock, creating it if needed.
+// Callers must hold r.mu for writing.
+func (r *productRepository) outletStock(id int) map[int]int {
+	outlets, ok := r.stock[id]
+	if !ok {
+		outlets = make(map[int]int)
+		r.stock[id] = outlets
+	}
+	return outlets
+}
+
+// barcodeTaken reports whether a product other than exceptID already has
+// the barcode. Products without a barcode never clash. Callers must hold r.mu.
+func (r *productRepository) barcodeTaken(barcode string, exceptID int) bool {
+	if barcode == "" {
+		return false
+	}
+	for id, p := range r.products {
+		if id != exceptID && p.Barcode == barcode {
+			return true
+		}
+	}
+	return false
+}
+
+// stripCategory drops the joined category, the stock total and any grouped
+// variants so stored rows only hold the product's own columns
+func stripCategory(p domain.Product) domain.Product {
+	p.Category = nil
+	p.Stock = 0
+	p.Variants = nil
+	p.Options = cloneOptions(p.Options)
+	return p
+}
+
+// cloneOptions copies variant options so callers cannot change stored rows,
+// reading no options as nil like the Postgres implementation
+func cloneOptions(options map[string]string) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+	return maps.Clone(options)
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type productUnitRepository struct {
+	mu    sync.RWMutex
+	units map[int][]domain.ProductUnit
+}
+
+// NewProductUnitRepository creates a new in-memory product unit conversion
+// repository. It does not check that the product and units exist.
+func NewProductUnitRepository() repository.ProductUnitRepository {
+	return &productUnitRepository{units: make(map[int][]domain.ProductUnit)}
+}
+
+func (r *productUnitRepository) GetByProductID(productID int) ([]domain.ProductUnit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	units := make([]domain.ProductUnit, len(r.units[productID]))
+	copy(units, r.units[productID])
+	return units, nil
+}
+
+func (r *productUnitRepository) ReplaceForProduct(productID int, units []domain.ProductUnit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := slices.Clone(units)
+	slices.SortFunc(stored, func(a, b domain.ProductUnit) int { return cmp.Compare(a.UnitID, b.UnitID) })
+	r.units[productID] = stored
+	return nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type promotionRepository struct {
+	mu         sync.RWMutex
+	nextID     int
+	promotions map[int]domain.Promotion
+}
+
+// NewPromotionRepository creates a new in-memory promotion repository
+func NewPromotionRepository() repository.PromotionRepository {
+	return &promotionRepository{
+		nextID:     1,
+		promotions: make(map[int]domain.Promotion),
+	}
+}
+
+func (r *promotionRepository) GetAll() ([]domain.Promotion, error) {
+	return r.filter(func(domain.Promotion) bool { return true }), nil
+}
+
+func (r *promotionRepository) GetActive(at time.Time) ([]domain.Promotion, error) {
+	return r.filter(func(p domain.Promotion) bool { return p.IsActiveAt(at) }), nil
+}
+
+func (r *promotionRepository) Create(promotion *domain.Promotion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	promotion.ID = r.nextID
+	r.nextID++
+	r.promotions[promotion.ID] = *promotion
+	return nil
+}
+
+func (r *promotionRepository) GetByID(id int) (*domain.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.promotions[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *promotionRepository) Update(promotion *domain.Promotion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.promotions[promotion.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	r.promotions[promotion.ID] = *promotion
+	return nil
+}
+
+func (r *promotionRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.promotions[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.promotions, id)
+	return nil
+}
+
+func (r *promotionRepository) filter(keep func(domain.Promotion) bool) []domain.Promotion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	promotions := make([]domain.Promotion, 0)
+	for id := 1; id < r.nextID; id++ {
+		if p, ok := r.promotions[id]; ok && keep(p) {
+			promotions = append(promotions, p)
+		}
+	}
+	return promotions
+}
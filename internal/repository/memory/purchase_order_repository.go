@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type purchaseOrderRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	orders map[int]domain.PurchaseOrder
+}
+
+// NewPurchaseOrderRepository creates a new in-memory purchase order repository
+func NewPurchaseOrderRepository() repository.PurchaseOrderRepository {
+	return &purchaseOrderRepository{
+		nextID: 1,
+		orders: make(map[int]domain.PurchaseOrder),
+	}
+}
+
+func (r *purchaseOrderRepository) GetAll(status string, outletID int) ([]domain.PurchaseOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := make([]domain.PurchaseOrder, 0)
+	for id := 1; id < r.nextID; id++ {
+		po, ok := r.orders[id]
+		if !ok || (status != "" && po.Status != status) || (outletID != 0 && po.OutletID != outletID) {
+			continue
+		}
+		orders = append(orders, clonePurchaseOrder(po))
+	}
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) Create(order *domain.PurchaseOrder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order.ID = r.nextID
+	r.nextID++
+	r.orders[order.ID] = clonePurchaseOrder(*order)
+	return nil
+}
+
+func (r *purchaseOrderRepository) GetByID(id int) (*domain.PurchaseOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	po, ok := r.orders[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	po = clonePurchaseOrder(po)
+	return &po, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *purchaseOrderRepository) GetByIDForUpdate(id int) (*domain.PurchaseOrder, error) {
+	return r.GetByID(id)
+}
+
+func (r *purchaseOrderRepository) Update(order *domain.PurchaseOrder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.orders[order.ID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	// Only the status, notes and received quantities change, as in Postgres
+	stored = clonePurchaseOrder(stored)
+	stored.Status, stored.Notes, stored.UpdatedAt = order.Status, order.Notes, order.UpdatedAt
+	for i := range min(len(stored.Lines), len(order.Lines)) {
+		stored.Lines[i].ReceivedQuantity = order.Lines[i].ReceivedQuantity
+	}
+	r.orders[order.ID] = stored
+	return nil
+}
+
+// clonePurchaseOrder copies the lines so callers never share a backing array
+// with the stored order
+func clonePurchaseOrder(po domain.PurchaseOrder) domain.PurchaseOrder {
+	po.Lines = append(make([]domain.PurchaseOrderLine, 0, len(po.Lines)), po.Lines...)
+	return po
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type refundRepository struct {
+	mu      sync.RWMutex
+	nextID  int
+	refunds map[int]domain.Refund
+}
+
+// NewRefundRepository creates a new in-memory refund repository
+func NewRefundRepository() repository.RefundRepository {
+	return &refundRepository{
+		nextID:  1,
+		refunds: make(map[int]domain.Refund),
+	}
+}
+
+func (r *refundRepository) Create(refund *domain.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refund.ID = r.nextID
+	r.nextID++
+	r.refunds[refund.ID] = cloneRefund(*refund)
+	return nil
+}
+
+func (r *refundRepository) GetByCart(cartID int) ([]domain.Refund, error) {
+	return r.list(func(f domain.Refund) bool { return f.CartID == cartID }), nil
+}
+
+func (r *refundRepository) GetAll(filter domain.SalesFilter) ([]domain.Refund, error) {
+	refunds := r.list(func(f domain.Refund) bool {
+		return (filter.OutletID == 0 || f.OutletID == filter.OutletID) &&
+			(filter.From.IsZero() || !f.CreatedAt.Before(filter.From)) &&
+			(filter.To.IsZero() || f.CreatedAt.Before(filter.To))
+	})
+	slices.SortStableFunc(refunds, func(a, b domain.Refund) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return refunds, nil
+}
+
+func (r *refundRepository) ShiftTotals(shiftID int) (map[string]int, error) {
+	totals := make(map[string]int)
+	for _, f := range r.list(func(f domain.Refund) bool { return f.ShiftID != nil && *f.ShiftID == shiftID }) {
+		totals[f.Method] += f.Amount
+	}
+	return totals, nil
+}
+
+// list returns the refunds matching keep in ID order
+func (r *refundRepository) list(keep func(domain.Refund) bool) []domain.Refund {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	refunds := make([]domain.Refund, 0)
+	for id := 1; id < r.nextID; id++ {
+		if f, ok := r.refunds[id]; ok && keep(f) {
+			refunds = append(refunds, cloneRefund(f))
+		}
+	}
+	return refunds
+}
+
+// cloneRefund copies the lines so callers never share a backing array with
+// the stored refund
+func cloneRefund(f domain.Refund) domain.Refund {
+	f.Lines = append(make([]domain.RefundLine, 0, len(f.Lines)), f.Lines...)
+	return f
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type reservationRepository struct {
+	mu           sync.RWMutex
+	reservations map[int][]domain.Reservation
+}
+
+// NewReservationRepository creates a new in-memory stock reservation repository
+func NewReservationRepository() repository.ReservationRepository {
+	return &reservationRepository{reservations: make(map[int][]domain.Reservation)}
+}
+
+func (r *reservationRepository) ReplaceForCart(cartID int, reservations []domain.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := make([]domain.Reservation, len(reservations))
+	for i, res := range reservations {
+		res.CartID = cartID
+		stored[i] = res
+	}
+	r.reservations[cartID] = stored
+	return nil
+}
+
+func (r *reservationRepository) DeleteByCart(cartID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reservations, cartID)
+	return nil
+}
+
+func (r *reservationRepository) ReservedTotals(at time.Time, outletID, excludeCartID int) (map[int]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	totals := make(map[int]int)
+	for cartID, reservations := range r.reservations {
+		if cartID == excludeCartID {
+			continue
+		}
+		for _, res := range reservations {
+			if res.OutletID == outletID && res.ExpiresAt.After(at) {
+				totals[res.ProductID] += res.Quantity
+			}
+		}
+	}
+	return totals, nil
+}
+
+func (r *reservationRepository) DeleteExpired(at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for cartID, reservations := range r.reservations {
+		kept := reservations[:0]
+		for _, res := range reservations {
+			if res.ExpiresAt.After(at) {
+				kept = append(kept, res)
+			} else {
+				deleted++
+			}
+		}
+		if len(kept) == 0 {
+			delete(r.reservations, cartID)
+		} else {
+			r.reservations[cartID] = kept
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type shiftRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	shifts map[int]domain.Shift
+}
+
+// NewShiftRepository creates a new in-memory shift repository
+func NewShiftRepository() repository.ShiftRepository {
+	return &shiftRepository{
+		nextID: 1,
+		shifts: make(map[int]domain.Shift),
+	}
+}
+
+func (r *shiftRepository) Create(shift *domain.Shift) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.shifts {
+		if s.Cashier == shift.Cashier && s.IsOpen() {
+			return apperrors.ErrConflict
+		}
+	}
+	shift.ID = r.nextID
+	r.nextID++
+	r.shifts[shift.ID] = *shift
+	return nil
+}
+
+func (r *shiftRepository) GetByID(id int) (*domain.Shift, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.shifts[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &s, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *shiftRepository) GetByIDForUpdate(id int) (*domain.Shift, error) {
+	return r.GetByID(id)
+}
+
+func (r *shiftRepository) GetOpen(cashier string) (*domain.Shift, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.shifts {
+		if s.Cashier == cashier && s.IsOpen() {
+			return &s, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (r *shiftRepository) Close(shift *domain.Shift) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.shifts[shift.ID]
+	if !ok || !s.IsOpen() {
+		return apperrors.ErrNotFound
+	}
+	s.ClosedAt = shift.ClosedAt
+	s.ExpectedCash = shift.ExpectedCash
+	s.CountedCash = shift.CountedCash
+	s.Variance = shift.Variance
+	r.shifts[shift.ID] = s
+	return nil
+}
+
+// GetOpenForUpdate is GetOpen; the memory transactor already serialises
+// transactions
+func (r *shiftRepository) GetOpenForUpdate(cashier string) (*domain.Shift, error) {
+	return r.GetOpen(cashier)
+}
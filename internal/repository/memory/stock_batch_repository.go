@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type stockBatchRepository struct {
+	mu          sync.RWMutex
+	nextID      int
+	batches     map[int]domain.StockBatch
+	productRepo repository.ProductRepository
+}
+
+// NewStockBatchRepository creates a new in-memory stock batch repository.
+// Product names are resolved through productRepo, mirroring the JOIN done by
+// the Postgres implementation; batches of deleted products are dropped.
+func NewStockBatchRepository(productRepo repository.ProductRepository) repository.StockBatchRepository {
+	return &stockBatchRepository{nextID: 1, batches: make(map[int]domain.StockBatch), productRepo: productRepo}
+}
+
+func (r *stockBatchRepository) Create(batch *domain.StockBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batch.ID = r.nextID
+	r.nextID++
+	b := *batch
+	b.ProductName = ""
+	r.batches[b.ID] = b
+	return nil
+}
+
+func (r *stockBatchRepository) GetByID(id int) (*domain.StockBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.batches[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	b, err := r.withName(b)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *stockBatchRepository) GetInStock(productID, outletID int) ([]domain.StockBatch, error) {
+	return r.list(func(b domain.StockBatch) bool {
+		return b.ProductID == productID && b.OutletID == outletID
+	})
+}
+
+func (r *stockBatchRepository) GetExpiring(before time.Time, outletID int) ([]domain.StockBatch, error) {
+	return r.list(func(b domain.StockBatch) bool {
+		return b.ExpiresAt != nil && b.ExpiresAt.Before(before) && (outletID == 0 || b.OutletID == outletID)
+	})
+}
+
+func (r *stockBatchRepository) Take(id, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.batches[id]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	if b.Remaining < quantity {
+		return apperrors.ErrInsufficientStock
+	}
+	b.Remaining -= quantity
+	r.batches[id] = b
+	return nil
+}
+
+// list returns the batches with stock remaining that match keeps, first
+// expired first out
+func (r *stockBatchRepository) list(match func(domain.StockBatch) bool) ([]domain.StockBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	batches := make([]domain.StockBatch, 0)
+	for _, b := range r.batches {
+		if b.Remaining <= 0 || !match(b) {
+			continue
+		}
+		b, err := r.withName(b)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	slices.SortFunc(batches, domain.CompareExpiry)
+	return batches, nil
+}
+
+func (r *stockBatchRepository) withName(b domain.StockBatch) (domain.StockBatch, error) {
+	p, err := r.productRepo.GetByID(b.ProductID)
+	if err != nil {
+		return b, err
+	}
+	b.ProductName = p.Name
+	return b, nil
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type stockCountRepository struct {
+	mu          sync.RWMutex
+	nextID      int
+	nextEntryID int
+	counts      map[int]domain.StockCount
+}
+
+// NewStockCountRepository creates a new in-memory stock count repository
+func NewStockCountRepository() repository.StockCountRepository {
+	return &stockCountRepository{
+		nextID:      1,
+		nextEntryID: 1,
+		counts:      make(map[int]domain.StockCount),
+	}
+}
+
+func (r *stockCountRepository) GetAll(outletID int, status string) ([]domain.StockCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make([]domain.StockCount, 0)
+	for id := 1; id < r.nextID; id++ {
+		c, ok := r.counts[id]
+		if !ok || (outletID != 0 && c.OutletID != outletID) || (status != "" && c.Status != status) {
+			continue
+		}
+		counts = append(counts, cloneStockCount(c))
+	}
+	return counts, nil
+}
+
+func (r *stockCountRepository) Create(count *domain.StockCount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count.ID = r.nextID
+	r.nextID++
+	r.counts[count.ID] = cloneStockCount(*count)
+	return nil
+}
+
+func (r *stockCountRepository) GetByID(id int) (*domain.StockCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.counts[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	c = cloneStockCount(c)
+	return &c, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *stockCountRepository) GetByIDForUpdate(id int) (*domain.StockCount, error) {
+	return r.GetByID(id)
+}
+
+func (r *stockCountRepository) Update(count *domain.StockCount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.counts[count.ID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	// Only the status, approver, updated time and line quantities change, as
+	// in Postgres
+	stored.Status, stored.ApprovedBy, stored.UpdatedAt = count.Status, count.ApprovedBy, count.UpdatedAt
+	stored = cloneStockCount(stored)
+	for i := range stored.Lines {
+		if i < len(count.Lines) {
+			stored.Lines[i].SystemQuantity = count.Lines[i].SystemQuantity
+			stored.Lines[i].CountedQuantity = cloneIntPtr(count.Lines[i].CountedQuantity)
+		}
+	}
+	r.counts[count.ID] = stored
+	return nil
+}
+
+func (r *stockCountRepository) AddEntry(countID int, entry *domain.StockCountEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.counts[countID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	entry.ID = r.nextEntryID
+	r.nextEntryID++
+	stored = cloneStockCount(stored)
+	stored.Entries = append(stored.Entries, *entry)
+	r.counts[countID] = stored
+	return nil
+}
+
+// cloneStockCount copies the lines, entries and pointers so callers never
+// share them with the stored count. Nil slices become empty and lines lose
+// their variance, as they do when Postgres loads a count.
+func cloneStockCount(c domain.StockCount) domain.StockCount {
+	c.CategoryID = cloneIntPtr(c.CategoryID)
+	lines := make([]domain.StockCountLine, len(c.Lines))
+	for i, line := range c.Lines {
+		line.CountedQuantity = cloneIntPtr(line.CountedQuantity)
+		line.Variance = nil
+		lines[i] = line
+	}
+	c.Lines = lines
+	c.Entries = append(make([]domain.StockCountEntry, 0, len(c.Entries)), c.Entries...)
+	return c
+}
+
+func cloneIntPtr(p *int) *int {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
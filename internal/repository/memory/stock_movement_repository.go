@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type stockMovementRepository struct {
+	mu        sync.RWMutex
+	nextID    int
+	movements []domain.StockMovement
+}
+
+// NewStockMovementRepository creates a new in-memory stock ledger repository
+func NewStockMovementRepository() repository.StockMovementRepository {
+	return &stockMovementRepository{nextID: 1}
+}
+
+func (r *stockMovementRepository) Create(movement *domain.StockMovement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	movement.ID = r.nextID
+	r.nextID++
+	r.movements = append(r.movements, *movement)
+	return nil
+}
+
+func (r *stockMovementRepository) GetByProductID(productID, outletID int) ([]domain.StockMovement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Newest first, matching the Postgres ORDER BY
+	movements := make([]domain.StockMovement, 0)
+	for i := len(r.movements) - 1; i >= 0; i-- {
+		m := r.movements[i]
+		if m.ProductID == productID && (outletID == 0 || m.OutletID == outletID) {
+			movements = append(movements, r.movements[i])
+		}
+	}
+	return movements, nil
+}
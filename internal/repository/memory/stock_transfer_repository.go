@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type stockTransferRepository struct {
+	mu        sync.RWMutex
+	nextID    int
+	transfers map[int]domain.StockTransfer
+}
+
+// NewStockTransferRepository creates a new in-memory stock transfer repository
+func NewStockTransferRepository() repository.StockTransferRepository {
+	return &stockTransferRepository{
+		nextID:    1,
+		transfers: make(map[int]domain.StockTransfer),
+	}
+}
+
+func (r *stockTransferRepository) GetAll(outletID int) ([]domain.StockTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transfers := make([]domain.StockTransfer, 0)
+	for id := 1; id < r.nextID; id++ {
+		t, ok := r.transfers[id]
+		if !ok || (outletID != 0 && t.FromOutletID != outletID && t.ToOutletID != outletID) {
+			continue
+		}
+		transfers = append(transfers, cloneStockTransfer(t))
+	}
+	return transfers, nil
+}
+
+func (r *stockTransferRepository) Create(transfer *domain.StockTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transfer.ID = r.nextID
+	r.nextID++
+	r.transfers[transfer.ID] = cloneStockTransfer(*transfer)
+	return nil
+}
+
+func (r *stockTransferRepository) GetByID(id int) (*domain.StockTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.transfers[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	t = cloneStockTransfer(t)
+	return &t, nil
+}
+
+// GetByIDForUpdate is GetByID; the memory transactor already serialises
+// transactions
+func (r *stockTransferRepository) GetByIDForUpdate(id int) (*domain.StockTransfer, error) {
+	return r.GetByID(id)
+}
+
+func (r *stockTransferRepository) Update(transfer *domain.StockTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.transfers[transfer.ID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	// Only the status and updated time change, as in Postgres
+	stored.Status, stored.UpdatedAt = transfer.Status, transfer.UpdatedAt
+	r.transfers[transfer.ID] = stored
+	return nil
+}
+
+// cloneStockTransfer copies the lines so callers never share a backing array
+// with the stored transfer
+func cloneStockTransfer(t domain.StockTransfer) domain.StockTransfer {
+	t.Lines = append(make([]domain.StockTransferLine, 0, len(t.Lines)), t.Lines...)
+	return t
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type supplierRepository struct {
+	mu        sync.RWMutex
+	nextID    int
+	suppliers map[int]domain.Supplier
+}
+
+// NewSupplierRepository creates a new in-memory supplier repository.
+// Unlike Postgres it does not refuse to delete suppliers with purchase orders.
+func NewSupplierRepository() repository.SupplierRepository {
+	return &supplierRepository{
+		nextID:    1,
+		suppliers: make(map[int]domain.Supplier),
+	}
+}
+
+func (r *supplierRepository) GetAll() ([]domain.Supplier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	suppliers := make([]domain.Supplier, 0, len(r.suppliers))
+	for id := 1; id < r.nextID; id++ {
+		if s, ok := r.suppliers[id]; ok {
+			suppliers = append(suppliers, s)
+		}
+	}
+	return suppliers, nil
+}
+
+func (r *supplierRepository) Create(supplier *domain.Supplier) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	supplier.ID = r.nextID
+	r.nextID++
+	r.suppliers[supplier.ID] = *supplier
+	return nil
+}
+
+func (r *supplierRepository) GetByID(id int) (*domain.Supplier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.suppliers[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *supplierRepository) Update(supplier *domain.Supplier) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.suppliers[supplier.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	r.suppliers[supplier.ID] = *supplier
+	return nil
+}
+
+func (r *supplierRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.suppliers[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.suppliers, id)
+	return nil
+}
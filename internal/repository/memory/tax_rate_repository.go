@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type taxRateRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	rates  map[int]domain.TaxRate
+}
+
+// NewTaxRateRepository creates a new in-memory tax rate repository.
+// Unlike Postgres it does not refuse to delete rates that are still assigned.
+func NewTaxRateRepository() repository.TaxRateRepository {
+	return &taxRateRepository{
+		nextID: 1,
+		rates:  make(map[int]domain.TaxRate),
+	}
+}
+
+func (r *taxRateRepository) GetAll() ([]domain.TaxRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rates := make([]domain.TaxRate, 0, len(r.rates))
+	for id := 1; id < r.nextID; id++ {
+		if t, ok := r.rates[id]; ok {
+			rates = append(rates, t)
+		}
+	}
+	return rates, nil
+}
+
+func (r *taxRateRepository) Create(rate *domain.TaxRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rate.ID = r.nextID
+	r.nextID++
+	r.rates[rate.ID] = *rate
+	return nil
+}
+
+func (r *taxRateRepository) GetByID(id int) (*domain.TaxRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.rates[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (r *taxRateRepository) Update(rate *domain.TaxRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rates[rate.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	r.rates[rate.ID] = *rate
+	return nil
+}
+
+func (r *taxRateRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rates[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.rates, id)
+	return nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/repository"
+)
+
+type transactor struct {
+	mu    sync.Mutex
+	repos repository.Repositories
+}
+
+// NewTransactor creates a Transactor over in-memory repositories.
+// Transactions are serialised but not isolated: writes made before fn
+// returns an error are not rolled back.
+func NewTransactor(repos repository.Repositories) repository.Transactor {
+	return &transactor{repos: repos}
+}
+
+// NewRepositories creates a full set of empty in-memory repositories
+func NewRepositories() repository.Repositories {
+	categories := NewCategoryRepository()
+	products := NewProductRepository(categories)
+	return repository.Repositories{
+		Products:          products,
+		Categories:        categories,
+		PriceHistory:      NewPriceHistoryRepository(),
+		Audit:             NewAuditRepository(),
+		Promotions:        NewPromotionRepository(),
+		TaxRates:          NewTaxRateRepository(),
+		Shifts:            NewShiftRepository(),
+		Carts:             NewCartRepository(),
+		Refunds:           NewRefundRepository(),
+		Reservations:      NewReservationRepository(),
+		Idempotency:       NewIdempotencyRepository(),
+		Customers:         NewCustomerRepository(),
+		Suppliers:         NewSupplierRepository(),
+		PurchaseOrders:    NewPurchaseOrderRepository(),
+		StockMovements:    NewStockMovementRepository(),
+		Outlets:           NewOutletRepository(),
+		StockTransfers:    NewStockTransferRepository(),
+		Units:             NewUnitRepository(),
+		ProductUnits:      NewProductUnitRepository(),
+		ProductComponents: NewProductComponentRepository(),
+		ProductImages:     NewProductImageRepository(),
+		StockBatches:      NewStockBatchRepository(products),
+		StockCounts:       NewStockCountRepository(),
+	}
+}
+
+func (t *transactor) WithinTx(fn func(repos repository.Repositories) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(t.repos)
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"sync"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+type unitRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	units  map[int]domain.Unit
+}
+
+// NewUnitRepository creates a new in-memory unit repository holding the piece
+// unit, as the Postgres schema does. Unlike Postgres it does not refuse to
+// delete units that are still in use.
+func NewUnitRepository() repository.UnitRepository {
+	return &unitRepository{
+		nextID: domain.DefaultUnitID + 1,
+		units: map[int]domain.Unit{
+			domain.DefaultUnitID: {ID: domain.DefaultUnitID, Code: "pcs", Name: "Piece"},
+		},
+	}
+}
+
+func (r *unitRepository) GetAll() ([]domain.Unit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	units := make([]domain.Unit, 0, len(r.units))
+	for id := 1; id < r.nextID; id++ {
+		if u, ok := r.units[id]; ok {
+			units = append(units, u)
+		}
+	}
+	return units, nil
+}
+
+func (r *unitRepository) Create(unit *domain.Unit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.codeTaken(unit.Code, 0) {
+		return apperrors.ErrConflict
+	}
+	unit.ID = r.nextID
+	r.nextID++
+	r.units[unit.ID] = *unit
+	return nil
+}
+
+func (r *unitRepository) GetByID(id int) (*domain.Unit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.units[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *unitRepository) Update(unit *domain.Unit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.units[unit.ID]; !ok {
+		return apperrors.ErrNotFound
+	}
+	if r.codeTaken(unit.Code, unit.ID) {
+		return apperrors.ErrConflict
+	}
+	r.units[unit.ID] = *unit
+	return nil
+}
+
+func (r *unitRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.units[id]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(r.units, id)
+	return nil
+}
+
+// codeTaken reports whether another unit than exceptID already has the code
+func (r *unitRepository) codeTaken(code string, exceptID int) bool {
+	for id, u := range r.units {
+		if id != exceptID && u.Code == code {
+			return true
+		}
+	}
+	return false
+}
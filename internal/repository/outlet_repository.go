@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type outletRepository struct {
+	db DBTX
+}
+
+// NewOutletRepository creates a new outlet repository
+func NewOutletRepository(db DBTX) OutletRepository {
+	return &outletRepository{db: db}
+}
+
+func (r *outletRepository) GetAll() ([]domain.Outlet, error) {
+	query := "SELECT id, code, name, address FROM outlets ORDER BY id"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outlets := make([]domain.Outlet, 0)
+	for rows.Next() {
+		var o domain.Outlet
+		if err := rows.Scan(&o.ID, &o.Code, &o.Name, &o.Address); err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return outlets, nil
+}
+
+func (r *outletRepository) Create(outlet *domain.Outlet) error {
+	query := "INSERT INTO outlets (code, name, address) VALUES ($1, $2, $3) RETURNING id"
+	err := r.db.QueryRow(query, outlet.Code, outlet.Name, outlet.Address).Scan(&outlet.ID)
+	return outletCodeConflict(err)
+}
+
+func (r *outletRepository) GetByID(id int) (*domain.Outlet, error) {
+	query := "SELECT id, code, name, address FROM outlets WHERE id = $1"
+
+	var o domain.Outlet
+	if err := r.db.QueryRow(query, id).Scan(&o.ID, &o.Code, &o.Name, &o.Address); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (r *outletRepository) Update(outlet *domain.Outlet) error {
+	query := "UPDATE outlets SET code = $1, name = $2, address = $3 WHERE id = $4"
+	result, err := r.db.Exec(query, outlet.Code, outlet.Name, outlet.Address, outlet.ID)
+	if err != nil {
+		return outletCodeConflict(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *outletRepository) Delete(id int) error {
+	query := "DELETE FROM outlets WHERE id = $1"
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrConflict
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// outletCodeConflict turns a duplicate outlet code into ErrConflict
+func outletCodeConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"kasir-api/internal/database/pgtest"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/repository/repotest"
+)
+
+var cluster *pgtest.Cluster
+
+func TestMain(m *testing.M) {
+	c, err := pgtest.Start()
+	switch {
+	case errors.Is(err, pgtest.ErrNotInstalled):
+		log.Println("Skipping Postgres integration tests:", err)
+	case err != nil:
+		log.Fatal("Failed to start Postgres:", err)
+	default:
+		cluster = c
+	}
+
+	code := m.Run()
+	if cluster != nil {
+		if err := cluster.Stop(); err != nil {
+			log.Println("Failed to stop Postgres:", err)
+		}
+	}
+	os.Exit(code)
+}
+
+func newRepos(t *testing.T) repository.Repositories {
+	if cluster == nil {
+		t.Skip("Postgres not available")
+	}
+	return repository.NewRepositories(cluster.NewDB(t))
+}
+
+func TestCategoryRepositoryContract(t *testing.T) {
+	repotest.RunCategoryContract(t, newRepos)
+}
+
+func TestProductRepositoryContract(t *testing.T) {
+	repotest.RunProductContract(t, newRepos)
+}
+
+func TestPriceHistoryRepositoryContract(t *testing.T) {
+	repotest.RunPriceHistoryContract(t, newRepos)
+}
+
+func TestAuditRepositoryContract(t *testing.T) {
+	repotest.RunAuditContract(t, newRepos)
+}
+
+func TestPromotionRepositoryContract(t *testing.T) {
+	repotest.RunPromotionContract(t, newRepos)
+}
+
+func TestTaxRateRepositoryContract(t *testing.T) {
+	repotest.RunTaxRateContract(t, newRepos)
+}
+
+func TestShiftRepositoryContract(t *testing.T) {
+	repotest.RunShiftContract(t, newRepos)
+}
+
+func TestCartRepositoryContract(t *testing.T) {
+	repotest.RunCartContract(t, newRepos)
+}
+
+func TestReservationRepositoryContract(t *testing.T) {
+	repotest.RunReservationContract(t, newRepos)
+}
+
+func TestIdempotencyRepositoryContract(t *testing.T) {
+	repotest.RunIdempotencyContract(t, newRepos)
+}
+
+func TestCustomerRepositoryContract(t *testing.T) {
+	repotest.RunCustomerContract(t, newRepos)
+}
+
+func TestSupplierRepositoryContract(t *testing.T) {
+	repotest.RunSupplierContract(t, newRepos)
+}
+
+func TestPurchaseOrderRepositoryContract(t *testing.T) {
+	repotest.RunPurchaseOrderContract(t, newRepos)
+}
+
+func TestStockMovementRepositoryContract(t *testing.T) {
+	repotest.RunStockMovementContract(t, newRepos)
+}
+
+func TestOutletRepositoryContract(t *testing.T) {
+	repotest.RunOutletContract(t, newRepos)
+}
+
+func TestStockTransferRepositoryContract(t *testing.T) {
+	repotest.RunStockTransferContract(t, newRepos)
+}
+
+func TestUnitRepositoryContract(t *testing.T) {
+	repotest.RunUnitContract(t, newRepos)
+}
+
+func TestProductUnitRepositoryContract(t *testing.T) {
+	repotest.RunProductUnitContract(t, newRepos)
+}
+
+func TestProductComponentRepositoryContract(t *testing.T) {
+	repotest.RunProductComponentContract(t, newRepos)
+}
+
+func TestProductImageRepositoryContract(t *testing.T) {
+	repotest.RunProductImageContract(t, newRepos)
+}
+
+func TestStockBatchRepositoryContract(t *testing.T) {
+	repotest.RunStockBatchContract(t, newRepos)
+}
+
+func TestStockCountRepositoryContract(t *testing.T) {
+	repotest.RunStockCountContract(t, newRepos)
+}
+
+func TestRefundRepositoryContract(t *testing.T) {
+	repotest.RunRefundContract(t, newRepos)
+}
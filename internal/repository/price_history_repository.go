@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"kasir-api/internal/domain"
+)
+
+type priceHistoryRepository struct {
+	db DBTX
+}
+
+// NewPriceHistoryRepository creates a new product price history repository
+func NewPriceHistoryRepository(db DBTX) PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+func (r *priceHistoryRepository) Create(change *domain.PriceChange) error {
+	query := `
+		INSERT INTO product_price_history (product_id, old_price, new_price, changed_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, changed_at
+	`
+	return r.db.QueryRow(query, change.ProductID, change.OldPrice, change.NewPrice, change.ChangedBy).
+		Scan(&change.ID, &change.ChangedAt)
+}
+
+func (r *priceHistoryRepository) GetByProductID(productID int) ([]domain.PriceChange, error) {
+	query := `
+		SELECT id, product_id, old_price, new_price, changed_by, changed_at
+		FROM product_price_history
+		WHERE product_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`
+	rows, err := r.db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]domain.PriceChange, 0)
+	for rows.Next() {
+		var c domain.PriceChange
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.OldPrice, &c.NewPrice, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"kasir-api/internal/domain"
+)
+
+type productComponentRepository struct {
+	db DBTX
+}
+
+// NewProductComponentRepository creates a new bundle component repository
+func NewProductComponentRepository(db DBTX) ProductComponentRepository {
+	return &productComponentRepository{db: db}
+}
+
+func (r *productComponentRepository) GetByBundleID(bundleID int) ([]domain.BundleComponent, error) {
+	query := "SELECT component_id, quantity FROM product_components WHERE bundle_id = $1 ORDER BY component_id"
+	rows, err := r.db.Query(query, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := make([]domain.BundleComponent, 0)
+	for rows.Next() {
+		var c domain.BundleComponent
+		if err := rows.Scan(&c.ProductID, &c.Quantity); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+func (r *productComponentRepository) ReplaceForBundle(bundleID int, components []domain.BundleComponent) error {
+	if _, err := r.db.Exec("DELETE FROM product_components WHERE bundle_id = $1", bundleID); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO product_components (bundle_id, component_id, quantity) VALUES ($1, $2, $3)"
+	for _, c := range components {
+		if _, err := r.db.Exec(query, bundleID, c.ProductID, c.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *productComponentRepository) GetBundleIDs(productID int) ([]int, error) {
+	query := "SELECT bundle_id FROM product_components WHERE component_id = $1 ORDER BY bundle_id"
+	rows, err := r.db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
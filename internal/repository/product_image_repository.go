@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+const productImageSelect = `SELECT id, product_id, key, thumbnail_key, content_type, size, width, height, created_at
+	FROM product_images`
+
+type productImageRepository struct {
+	db DBTX
+}
+
+// NewProductImageRepository creates a new product image repository
+func NewProductImageRepository(db DBTX) ProductImageRepository {
+	return &productImageRepository{db: db}
+}
+
+func (r *productImageRepository) GetByProductIDs(productIDs []int) ([]domain.ProductImage, error) {
+	query := productImageSelect + " WHERE product_id = ANY($1) ORDER BY product_id, id"
+	rows, err := r.db.Query(query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := make([]domain.ProductImage, 0)
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (r *productImageRepository) Create(image *domain.ProductImage) error {
+	query := `INSERT INTO product_images (product_id, key, thumbnail_key, content_type, size, width, height)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+	return r.db.QueryRow(query, image.ProductID, image.Key, image.ThumbnailKey, image.ContentType,
+		image.Size, image.Width, image.Height).Scan(&image.ID, &image.CreatedAt)
+}
+
+func (r *productImageRepository) GetByID(id int) (*domain.ProductImage, error) {
+	image, err := scanProductImage(r.db.QueryRow(productImageSelect+" WHERE id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *productImageRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM product_images WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func scanProductImage(row rowScanner) (domain.ProductImage, error) {
+	var image domain.ProductImage
+	err := row.Scan(&image.ID, &image.ProductID, &image.Key, &image.ThumbnailKey, &image.ContentType,
+		&image.Size, &image.Width, &image.Height, &image.CreatedAt)
+	return image, err
+}
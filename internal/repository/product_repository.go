@@ -2,28 +2,56 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 
 	"kasir-api/internal/apperrors"
 	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 type productRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewProductRepository creates a new product repository
-func NewProductRepository(db *sql.DB) ProductRepository {
+func NewProductRepository(db DBTX) ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) GetAll() ([]domain.Product, error) {
-	query := `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id,
-		       c.id, c.name, c.description
+// productStockTotal sums a product's stock over every outlet
+const productStockTotal = "COALESCE((SELECT SUM(s.quantity) FROM product_stocks s WHERE s.product_id = p.id), 0)"
+
+// productSelect reads products joined with their category
+const productSelect = `
+		SELECT p.id, p.name, p.type, p.price, p.cost_price, ` + productStockTotal + `, p.category_id, p.tax_rate_id,
+		       p.unit_id, p.parent_id, COALESCE(p.barcode, ''), p.options,
+		       c.id, c.name, c.description, c.tax_rate_id, c.parent_id
 		FROM products p
 		JOIN categories c ON p.category_id = c.id
-	`
-	rows, err := r.db.Query(query)
+`
+
+func (r *productRepository) GetAll() ([]domain.Product, error) {
+	return r.list(productSelect + " ORDER BY p.id")
+}
+
+func (r *productRepository) GetVariants(parentID int) ([]domain.Product, error) {
+	return r.list(productSelect+" WHERE p.parent_id = $1 ORDER BY p.id", parentID)
+}
+
+func (r *productRepository) GetByCategory(categoryID int) ([]domain.Product, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT child.id FROM categories child JOIN tree ON child.parent_id = tree.id
+		)` + productSelect + " WHERE p.category_id IN (SELECT id FROM tree) ORDER BY p.id"
+	return r.list(query, categoryID)
+}
+
+func (r *productRepository) list(query string, args ...any) ([]domain.Product, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -31,13 +59,10 @@ func (r *productRepository) GetAll() ([]domain.Product, error) {
 
 	products := make([]domain.Product, 0)
 	for rows.Next() {
-		var p domain.Product
-		var c domain.Category
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID,
-			&c.ID, &c.Name, &c.Description); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, err
 		}
-		p.Category = &c
 		products = append(products, p)
 	}
 
@@ -49,44 +74,64 @@ func (r *productRepository) GetAll() ([]domain.Product, error) {
 }
 
 func (r *productRepository) Create(product *domain.Product) error {
-	query := "INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := r.db.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
+	options, err := productOptions(product.Options)
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (r *productRepository) GetByID(id int) (*domain.Product, error) {
 	query := `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id,
-		       c.id, c.name, c.description
-		FROM products p
-		JOIN categories c ON p.category_id = c.id
-		WHERE p.id = $1
+		INSERT INTO products (name, type, price, cost_price, category_id, tax_rate_id, unit_id, parent_id, barcode, options)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10)
+		RETURNING id
 	`
-	row := r.db.QueryRow(query, id)
+	err = r.db.QueryRow(query, product.Name, product.Type, product.Price, product.CostPrice, product.CategoryID,
+		product.TaxRateID, product.UnitID, product.ParentID, product.Barcode, options).Scan(&product.ID)
+	return barcodeConflict(err)
+}
 
-	var p domain.Product
-	var c domain.Category
-	if err := row.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID,
-		&c.ID, &c.Name, &c.Description); err != nil {
+func (r *productRepository) GetByID(id int) (*domain.Product, error) {
+	p, err := scanProduct(r.db.QueryRow(productSelect+" WHERE p.id = $1", id))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, apperrors.ErrNotFound
 		}
 		return nil, err
 	}
-	p.Category = &c
-
 	return &p, nil
 }
 
+// GetByIDForUpdate locks the product row before its stock rows, the order
+// Update followed by a stock change takes them in, and reads the product
+// only once both are held so its total stock is current
+func (r *productRepository) GetByIDForUpdate(id int) (*domain.Product, error) {
+	var locked int
+	if err := r.db.QueryRow("SELECT id FROM products WHERE id = $1 FOR UPDATE", id).Scan(&locked); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if _, err := r.db.Exec("SELECT 1 FROM product_stocks WHERE product_id = $1 ORDER BY outlet_id FOR UPDATE", id); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
 func (r *productRepository) Update(product *domain.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4 WHERE id = $5"
-	result, err := r.db.Exec(query, product.Name, product.Price, product.Stock, product.CategoryID, product.ID)
+	options, err := productOptions(product.Options)
 	if err != nil {
 		return err
 	}
+	query := `
+		UPDATE products
+		SET name = $1, type = $2, price = $3, cost_price = $4, category_id = $5, tax_rate_id = $6, unit_id = $7,
+		    parent_id = $8, barcode = NULLIF($9, ''), options = $10
+		WHERE id = $11
+	`
+	result, err := r.db.Exec(query, product.Name, product.Type, product.Price, product.CostPrice, product.CategoryID,
+		product.TaxRateID, product.UnitID, product.ParentID, product.Barcode, options, product.ID)
+	if err != nil {
+		return barcodeConflict(err)
+	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
@@ -103,6 +148,11 @@ func (r *productRepository) Delete(id int) error {
 	query := "DELETE FROM products WHERE id = $1"
 	result, err := r.db.Exec(query, id)
 	if err != nil {
+		// Variants reference their parent, which cannot go while they exist
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrConflict
+		}
 		return err
 	}
 
@@ -116,3 +166,154 @@ func (r *productRepository) Delete(id int) error {
 	}
 	return nil
 }
+
+func (r *productRepository) GetStock(id, outletID int) (int, error) {
+	query := "SELECT quantity FROM product_stocks WHERE product_id = $1 AND outlet_id = $2"
+	var quantity int
+	if err := r.db.QueryRow(query, id, outletID).Scan(&quantity); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return quantity, nil
+}
+
+func (r *productRepository) LockStock(id, outletID int) (int, error) {
+	query := "SELECT quantity FROM product_stocks WHERE product_id = $1 AND outlet_id = $2 FOR UPDATE"
+	var quantity int
+	if err := r.db.QueryRow(query, id, outletID).Scan(&quantity); err != nil {
+		if err == sql.ErrNoRows {
+			// Nothing to lock: with no stock there is nothing to oversell
+			return 0, nil
+		}
+		return 0, err
+	}
+	return quantity, nil
+}
+
+func (r *productRepository) StockLevels(outletID int) (map[int]int, error) {
+	rows, err := r.db.Query("SELECT product_id, quantity FROM product_stocks WHERE outlet_id = $1", outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[int]int)
+	for rows.Next() {
+		var productID, quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		levels[productID] = quantity
+	}
+	return levels, rows.Err()
+}
+
+func (r *productRepository) SetStock(id, outletID, quantity int) error {
+	query := `
+		INSERT INTO product_stocks (product_id, outlet_id, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, outlet_id) DO UPDATE SET quantity = EXCLUDED.quantity
+	`
+	return r.changeStock(query, id, outletID, quantity)
+}
+
+func (r *productRepository) AddStock(id, outletID, quantity int) error {
+	query := `
+		INSERT INTO product_stocks (product_id, outlet_id, quantity) VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, outlet_id) DO UPDATE SET quantity = product_stocks.quantity + EXCLUDED.quantity
+	`
+	return r.changeStock(query, id, outletID, quantity)
+}
+
+// changeStock runs an upsert into product_stocks, reporting a missing
+// product or outlet as ErrNotFound
+func (r *productRepository) changeStock(query string, id, outletID, quantity int) error {
+	if _, err := r.db.Exec(query, id, outletID, quantity); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *productRepository) DecrementStock(id, outletID, quantity int) error {
+	query := "UPDATE product_stocks SET quantity = quantity - $1 WHERE product_id = $2 AND outlet_id = $3 AND quantity >= $1"
+	result, err := r.db.Exec(query, quantity, id, outletID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return apperrors.ErrNotFound
+	}
+	return apperrors.ErrInsufficientStock
+}
+
+func (r *productRepository) ReceiveStock(id, outletID, quantity, costPrice int) error {
+	result, err := r.db.Exec("UPDATE products SET cost_price = $1 WHERE id = $2", costPrice, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return r.AddStock(id, outletID, quantity)
+}
+
+// barcodeConflict turns a duplicate barcode into ErrConflict
+func barcodeConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrConflict
+	}
+	return err
+}
+
+// productOptions encodes variant options as a JSON object, empty for
+// products without options
+func productOptions(options map[string]string) (string, error) {
+	if len(options) == 0 {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(options)
+	return string(raw), err
+}
+
+func scanProduct(row rowScanner) (domain.Product, error) {
+	var p domain.Product
+	var c domain.Category
+	var options []byte
+	if err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Price, &p.CostPrice, &p.Stock, &p.CategoryID, &p.TaxRateID,
+		&p.UnitID, &p.ParentID, &p.Barcode, &options,
+		&c.ID, &c.Name, &c.Description, &c.TaxRateID, &c.ParentID); err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(options, &p.Options); err != nil {
+		return p, err
+	}
+	if len(p.Options) == 0 {
+		p.Options = nil
+	}
+	p.Category = &c
+	return p, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"kasir-api/internal/domain"
+)
+
+type productUnitRepository struct {
+	db DBTX
+}
+
+// NewProductUnitRepository creates a new product unit conversion repository
+func NewProductUnitRepository(db DBTX) ProductUnitRepository {
+	return &productUnitRepository{db: db}
+}
+
+func (r *productUnitRepository) GetByProductID(productID int) ([]domain.ProductUnit, error) {
+	query := "SELECT unit_id, factor, price FROM product_units WHERE product_id = $1 ORDER BY unit_id"
+	rows, err := r.db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make([]domain.ProductUnit, 0)
+	for rows.Next() {
+		var u domain.ProductUnit
+		if err := rows.Scan(&u.UnitID, &u.Factor, &u.Price); err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return units, nil
+}
+
+func (r *productUnitRepository) ReplaceForProduct(productID int, units []domain.ProductUnit) error {
+	if _, err := r.db.Exec("DELETE FROM product_units WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO product_units (product_id, unit_id, factor, price) VALUES ($1, $2, $3, $4)"
+	for _, u := range units {
+		if _, err := r.db.Exec(query, productID, u.UnitID, u.Factor, u.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
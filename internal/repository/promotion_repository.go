@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+type promotionRepository struct {
+	db DBTX
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db DBTX) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+const promotionColumns = `id, name, type, scope, product_id, category_id, value, buy_quantity, free_quantity,
+	min_subtotal, stackable, active, starts_at, ends_at`
+
+func (r *promotionRepository) GetAll() ([]domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions ORDER BY id"
+	return r.list(query)
+}
+
+func (r *promotionRepository) GetActive(at time.Time) ([]domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + ` FROM promotions
+		WHERE active
+		  AND (starts_at IS NULL OR starts_at <= $1)
+		  AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY id`
+	return r.list(query, at)
+}
+
+func (r *promotionRepository) Create(promotion *domain.Promotion) error {
+	query := `
+		INSERT INTO promotions (name, type, scope, product_id, category_id, value, buy_quantity, free_quantity,
+			min_subtotal, stackable, active, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+	p := promotion
+	return r.db.QueryRow(query, p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQuantity,
+		p.FreeQuantity, p.MinSubtotal, p.Stackable, p.Active, p.StartsAt, p.EndsAt).Scan(&promotion.ID)
+}
+
+func (r *promotionRepository) GetByID(id int) (*domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE id = $1"
+	p, err := scanPromotion(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *promotionRepository) Update(promotion *domain.Promotion) error {
+	query := `
+		UPDATE promotions
+		SET name = $1, type = $2, scope = $3, product_id = $4, category_id = $5, value = $6, buy_quantity = $7,
+			free_quantity = $8, min_subtotal = $9, stackable = $10, active = $11, starts_at = $12, ends_at = $13
+		WHERE id = $14
+	`
+	p := promotion
+	result, err := r.db.Exec(query, p.Name, p.Type, p.Scope, p.ProductID, p.CategoryID, p.Value, p.BuyQuantity,
+		p.FreeQuantity, p.MinSubtotal, p.Stackable, p.Active, p.StartsAt, p.EndsAt, p.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promotionRepository) Delete(id int) error {
+	query := "DELETE FROM promotions WHERE id = $1"
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *promotionRepository) list(query string, args ...any) ([]domain.Promotion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]domain.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromotion(row rowScanner) (domain.Promotion, error) {
+	var p domain.Promotion
+	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Scope, &p.ProductID, &p.CategoryID, &p.Value, &p.BuyQuantity,
+		&p.FreeQuantity, &p.MinSubtotal, &p.Stackable, &p.Active, &p.StartsAt, &p.EndsAt)
+	return p, err
+}
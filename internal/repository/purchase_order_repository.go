@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+type purchaseOrderRepository struct {
+	db DBTX
+}
+
+// NewPurchaseOrderRepository creates a new purchase order repository. Create
+// and Update write the lines in several statements, so they should run
+// inside a transaction.
+func NewPurchaseOrderRepository(db DBTX) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+const purchaseOrderColumns = "id, supplier_id, outlet_id, status, notes, created_at, updated_at"
+
+func (r *purchaseOrderRepository) GetAll(status string, outletID int) ([]domain.PurchaseOrder, error) {
+	query := "SELECT " + purchaseOrderColumns + ` FROM purchase_orders
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR outlet_id = $2)
+		ORDER BY id`
+	rows, err := r.db.Query(query, status, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]domain.PurchaseOrder, 0)
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range orders {
+		if err := r.loadLines(&orders[i]); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) Create(order *domain.PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_orders (supplier_id, outlet_id, status, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	po := order
+	err := r.db.QueryRow(query, po.SupplierID, po.OutletID, po.Status, po.Notes, po.CreatedAt,
+		po.UpdatedAt).Scan(&order.ID)
+	if err != nil {
+		return err
+	}
+	return r.insertLines(order)
+}
+
+func (r *purchaseOrderRepository) GetByID(id int) (*domain.PurchaseOrder, error) {
+	return r.get("SELECT "+purchaseOrderColumns+" FROM purchase_orders WHERE id = $1", id)
+}
+
+func (r *purchaseOrderRepository) GetByIDForUpdate(id int) (*domain.PurchaseOrder, error) {
+	return r.get("SELECT "+purchaseOrderColumns+" FROM purchase_orders WHERE id = $1 FOR UPDATE", id)
+}
+
+func (r *purchaseOrderRepository) get(query string, id int) (*domain.PurchaseOrder, error) {
+	po, err := scanPurchaseOrder(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadLines(&po); err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+func (r *purchaseOrderRepository) Update(order *domain.PurchaseOrder) error {
+	query := "UPDATE purchase_orders SET status = $1, notes = $2, updated_at = $3 WHERE id = $4"
+	result, err := r.db.Exec(query, order.Status, order.Notes, order.UpdatedAt, order.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	query = "UPDATE purchase_order_lines SET received_quantity = $1 WHERE purchase_order_id = $2 AND position = $3"
+	for i, line := range order.Lines {
+		if _, err := r.db.Exec(query, line.ReceivedQuantity, order.ID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *purchaseOrderRepository) insertLines(order *domain.PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_order_lines
+			(purchase_order_id, position, product_id, unit_id, factor, quantity, unit_cost, received_quantity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for i, line := range order.Lines {
+		if _, err := r.db.Exec(query, order.ID, i, line.ProductID, line.UnitID, line.Factor, line.Quantity,
+			line.UnitCost, line.ReceivedQuantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *purchaseOrderRepository) loadLines(order *domain.PurchaseOrder) error {
+	query := `
+		SELECT product_id, unit_id, factor, quantity, unit_cost, received_quantity
+		FROM purchase_order_lines WHERE purchase_order_id = $1 ORDER BY position
+	`
+	rows, err := r.db.Query(query, order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	order.Lines = make([]domain.PurchaseOrderLine, 0)
+	for rows.Next() {
+		var line domain.PurchaseOrderLine
+		if err := rows.Scan(&line.ProductID, &line.UnitID, &line.Factor, &line.Quantity, &line.UnitCost,
+			&line.ReceivedQuantity); err != nil {
+			return err
+		}
+		order.Lines = append(order.Lines, line)
+	}
+	return rows.Err()
+}
+
+func scanPurchaseOrder(row rowScanner) (domain.PurchaseOrder, error) {
+	var po domain.PurchaseOrder
+	err := row.Scan(&po.ID, &po.SupplierID, &po.OutletID, &po.Status, &po.Notes, &po.CreatedAt, &po.UpdatedAt)
+	return po, err
+}
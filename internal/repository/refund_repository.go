@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"fmt"
+
+	"kasir-api/internal/domain"
+)
+
+type refundRepository struct {
+	db DBTX
+}
+
+// NewRefundRepository creates a new refund repository. Create writes the
+// lines in several statements, so it should run inside a transaction.
+func NewRefundRepository(db DBTX) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+const refundColumns = "id, cart_id, outlet_id, shift_id, restock, reason, method, amount, tax, refunded_by, created_at"
+
+func (r *refundRepository) Create(refund *domain.Refund) error {
+	query := `
+		INSERT INTO refunds (cart_id, outlet_id, shift_id, restock, reason, method, amount, tax, refunded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	f := refund
+	err := r.db.QueryRow(query, f.CartID, f.OutletID, f.ShiftID, f.Restock, f.Reason, f.Method, f.Amount, f.Tax,
+		f.RefundedBy, f.CreatedAt).Scan(&refund.ID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO refund_lines (refund_id, position, product_id, unit_id, quantity, amount, tax, cost_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for i, line := range refund.Lines {
+		if _, err := r.db.Exec(query, refund.ID, i, line.ProductID, line.UnitID, line.Quantity, line.Amount, line.Tax,
+			line.CostPrice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *refundRepository) GetByCart(cartID int) ([]domain.Refund, error) {
+	query := "SELECT " + refundColumns + " FROM refunds WHERE cart_id = $1 ORDER BY id"
+	return r.list(query, cartID)
+}
+
+func (r *refundRepository) GetAll(filter domain.SalesFilter) ([]domain.Refund, error) {
+	args := []any{filter.OutletID}
+	query := "SELECT " + refundColumns + " FROM refunds WHERE ($1 = 0 OR outlet_id = $1)"
+	addCondition := func(clause string, arg any) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+clause, len(args))
+	}
+
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+	return r.list(query+" ORDER BY created_at, id", args...)
+}
+
+func (r *refundRepository) ShiftTotals(shiftID int) (map[string]int, error) {
+	rows, err := r.db.Query("SELECT method, SUM(amount) FROM refunds WHERE shift_id = $1 GROUP BY method", shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var method string
+		var amount int
+		if err := rows.Scan(&method, &amount); err != nil {
+			return nil, err
+		}
+		totals[method] = amount
+	}
+	return totals, rows.Err()
+}
+
+func (r *refundRepository) list(query string, args ...any) ([]domain.Refund, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := make([]domain.Refund, 0)
+	for rows.Next() {
+		var f domain.Refund
+		if err := rows.Scan(&f.ID, &f.CartID, &f.OutletID, &f.ShiftID, &f.Restock, &f.Reason, &f.Method, &f.Amount,
+			&f.Tax, &f.RefundedBy, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range refunds {
+		if err := r.loadLines(&refunds[i]); err != nil {
+			return nil, err
+		}
+	}
+	return refunds, nil
+}
+
+func (r *refundRepository) loadLines(refund *domain.Refund) error {
+	query := `
+		SELECT product_id, unit_id, quantity, amount, tax, cost_price
+		FROM refund_lines WHERE refund_id = $1 ORDER BY position
+	`
+	rows, err := r.db.Query(query, refund.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	refund.Lines = make([]domain.RefundLine, 0)
+	for rows.Next() {
+		var line domain.RefundLine
+		if err := rows.Scan(&line.ProductID, &line.UnitID, &line.Quantity, &line.Amount, &line.Tax,
+			&line.CostPrice); err != nil {
+			return err
+		}
+		refund.Lines = append(refund.Lines, line)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,120 @@
+package repotest
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+)
+
+// RunAuditContract verifies AuditRepository behaviour
+func RunAuditContract(t *testing.T, newRepos Factory) {
+	t.Run("create round-trips json", func(t *testing.T) {
+		repo := newRepos(t).Audit
+		entry := domain.AuditEntry{
+			Actor:      "budi",
+			Action:     domain.AuditActionUpdate,
+			EntityType: domain.AuditEntityProduct,
+			EntityID:   7,
+			Before:     json.RawMessage(`{"price":3500}`),
+			After:      json.RawMessage(`{"price":4000}`),
+			Changes:    json.RawMessage(`{"price":{"old":3500,"new":4000}}`),
+			RequestID:  "req-1",
+		}
+		if err := repo.Create(&entry); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if entry.ID == 0 || entry.CreatedAt.IsZero() {
+			t.Fatalf("Create did not set id/created_at: %+v", entry)
+		}
+
+		got, err := repo.List(domain.AuditFilter{Limit: 10})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("len = %d, want 1", len(got))
+		}
+		assertJSON(t, "before", got[0].Before, `{"price":3500}`)
+		assertJSON(t, "changes", got[0].Changes, `{"price":{"old":3500,"new":4000}}`)
+		if got[0].Actor != "budi" || got[0].RequestID != "req-1" || got[0].EntityID != 7 {
+			t.Errorf("entry = %+v", got[0])
+		}
+	})
+
+	t.Run("null documents stay empty", func(t *testing.T) {
+		repo := newRepos(t).Audit
+		entry := domain.AuditEntry{Action: domain.AuditActionDelete, EntityType: domain.AuditEntityCategory, EntityID: 1,
+			Before: json.RawMessage(`{"name":"Minuman"}`)}
+		if err := repo.Create(&entry); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		got, err := repo.List(domain.AuditFilter{Limit: 10})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(got) != 1 || got[0].After != nil || got[0].Changes != nil {
+			t.Fatalf("List = %+v, want nil after/changes", got)
+		}
+	})
+
+	t.Run("filters and paginates newest first", func(t *testing.T) {
+		repo := newRepos(t).Audit
+		seed := []domain.AuditEntry{
+			{Actor: "budi", Action: domain.AuditActionCreate, EntityType: domain.AuditEntityCategory, EntityID: 1},
+			{Actor: "budi", Action: domain.AuditActionCreate, EntityType: domain.AuditEntityProduct, EntityID: 1},
+			{Actor: "siti", Action: domain.AuditActionUpdate, EntityType: domain.AuditEntityProduct, EntityID: 1},
+			{Actor: "siti", Action: domain.AuditActionDelete, EntityType: domain.AuditEntityProduct, EntityID: 2},
+		}
+		for i := range seed {
+			if err := repo.Create(&seed[i]); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		tests := []struct {
+			name    string
+			filter  domain.AuditFilter
+			wantIDs []int
+		}{
+			{"all", domain.AuditFilter{Limit: 10}, []int{seed[3].ID, seed[2].ID, seed[1].ID, seed[0].ID}},
+			{"actor", domain.AuditFilter{Actor: "budi", Limit: 10}, []int{seed[1].ID, seed[0].ID}},
+			{"action", domain.AuditFilter{Action: domain.AuditActionUpdate, Limit: 10}, []int{seed[2].ID}},
+			{"entity", domain.AuditFilter{EntityType: domain.AuditEntityProduct, EntityID: 1, Limit: 10}, []int{seed[2].ID, seed[1].ID}},
+			{"limit and offset", domain.AuditFilter{Limit: 2, Offset: 1}, []int{seed[2].ID, seed[1].ID}},
+			{"future window", domain.AuditFilter{From: time.Now().Add(time.Hour), Limit: 10}, []int{}},
+			{"past window", domain.AuditFilter{To: time.Now().Add(-time.Hour), Limit: 10}, []int{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := repo.List(tt.filter)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				ids := make([]int, 0, len(got))
+				for _, e := range got {
+					ids = append(ids, e.ID)
+				}
+				if !slices.Equal(ids, tt.wantIDs) {
+					t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			})
+		}
+	})
+}
+
+func assertJSON(t *testing.T, field string, got json.RawMessage, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("%s: invalid json %q: %v", field, got, err)
+	}
+	json.Unmarshal([]byte(want), &w)
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	if string(gb) != string(wb) {
+		t.Errorf("%s = %s, want %s", field, gb, wb)
+	}
+}
@@ -0,0 +1,142 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunStockBatchContract verifies StockBatchRepository behaviour
+func RunStockBatchContract(t *testing.T, newRepos Factory) {
+	t.Run("create, get and list in stock first expired first out", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Minuman")
+		milk := mustCreateProduct(t, repos.Products, "Susu UHT 1L", c.ID)
+		juice := mustCreateProduct(t, repos.Products, "Jus Jeruk", c.ID)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+
+		at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		late := mustCreateBatch(t, repos.StockBatches, milk.ID, domain.DefaultOutletID, "L2", expiry(at, 30), 24)
+		undated := mustCreateBatch(t, repos.StockBatches, milk.ID, domain.DefaultOutletID, "L3", nil, 12)
+		early := mustCreateBatch(t, repos.StockBatches, milk.ID, domain.DefaultOutletID, "L1", expiry(at, 10), 6)
+		mustCreateBatch(t, repos.StockBatches, milk.ID, branch.ID, "L4", expiry(at, 5), 6)
+		mustCreateBatch(t, repos.StockBatches, juice.ID, domain.DefaultOutletID, "J1", expiry(at, 5), 6)
+		if late.ID == 0 || early.ID == 0 || late.ID == early.ID {
+			t.Fatalf("ids = %d, %d, want distinct non-zero", late.ID, early.ID)
+		}
+
+		got, err := repos.StockBatches.GetByID(early.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		early.ProductName = milk.Name
+		if !equalBatch(*got, early) {
+			t.Errorf("GetByID = %+v, want %+v", *got, early)
+		}
+		if _, err := repos.StockBatches.GetByID(early.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+
+		batches, err := repos.StockBatches.GetInStock(milk.ID, domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("GetInStock: %v", err)
+		}
+		if len(batches) != 3 || batches[0].ID != early.ID || batches[1].ID != late.ID || batches[2].ID != undated.ID {
+			t.Errorf("GetInStock = %+v, want L1, L2, L3", batches)
+		}
+
+		empty, err := repos.StockBatches.GetInStock(999, domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("GetInStock: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Errorf("GetInStock unknown product = %#v, want empty non-nil slice", empty)
+		}
+	})
+
+	t.Run("take and expiring", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Minuman")
+		milk := mustCreateProduct(t, repos.Products, "Susu UHT 1L", c.ID)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+
+		at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		soon := mustCreateBatch(t, repos.StockBatches, milk.ID, domain.DefaultOutletID, "L1", expiry(at, 3), 6)
+		elsewhere := mustCreateBatch(t, repos.StockBatches, milk.ID, branch.ID, "L2", expiry(at, 1), 6)
+		mustCreateBatch(t, repos.StockBatches, milk.ID, domain.DefaultOutletID, "L3", expiry(at, 30), 6)
+		mustCreateBatch(t, repos.StockBatches, milk.ID, domain.DefaultOutletID, "L4", nil, 6)
+
+		if err := repos.StockBatches.Take(soon.ID, 4); err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		if err := repos.StockBatches.Take(soon.ID, 3); !errors.Is(err, apperrors.ErrInsufficientStock) {
+			t.Errorf("Take more than remaining: err = %v, want ErrInsufficientStock", err)
+		}
+		if err := repos.StockBatches.Take(soon.ID+1000, 1); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Take missing: err = %v, want ErrNotFound", err)
+		}
+		got, err := repos.StockBatches.GetByID(soon.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Remaining != 2 || got.Quantity != 6 {
+			t.Errorf("after Take quantity, remaining = %d, %d, want 6, 2", got.Quantity, got.Remaining)
+		}
+
+		expiring, err := repos.StockBatches.GetExpiring(at.AddDate(0, 0, 7), 0)
+		if err != nil {
+			t.Fatalf("GetExpiring: %v", err)
+		}
+		if len(expiring) != 2 || expiring[0].ID != elsewhere.ID || expiring[1].ID != soon.ID {
+			t.Errorf("GetExpiring = %+v, want L2 then L1", expiring)
+		}
+		if expiring[1].ProductName != milk.Name {
+			t.Errorf("ProductName = %q, want %q", expiring[1].ProductName, milk.Name)
+		}
+		atOutlet, err := repos.StockBatches.GetExpiring(at.AddDate(0, 0, 7), domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("GetExpiring at outlet: %v", err)
+		}
+		if len(atOutlet) != 1 || atOutlet[0].ID != soon.ID {
+			t.Errorf("GetExpiring at outlet = %+v, want L1", atOutlet)
+		}
+
+		if err := repos.StockBatches.Take(soon.ID, 2); err != nil {
+			t.Fatalf("Take rest: %v", err)
+		}
+		atOutlet, err = repos.StockBatches.GetExpiring(at.AddDate(0, 0, 7), domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("GetExpiring at outlet: %v", err)
+		}
+		if len(atOutlet) != 0 {
+			t.Errorf("GetExpiring after batch is used up = %+v, want none", atOutlet)
+		}
+	})
+}
+
+func expiry(at time.Time, days int) *time.Time {
+	e := at.AddDate(0, 0, days)
+	return &e
+}
+
+func mustCreateBatch(t *testing.T, repo repository.StockBatchRepository, productID, outletID int, lot string, expiresAt *time.Time,
+	quantity int) domain.StockBatch {
+	t.Helper()
+	batch := domain.StockBatch{ProductID: productID, OutletID: outletID, LotNumber: lot, ExpiresAt: expiresAt, Quantity: quantity,
+		Remaining: quantity, CreatedAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	if err := repo.Create(&batch); err != nil {
+		t.Fatalf("create batch %q: %v", lot, err)
+	}
+	return batch
+}
+
+func equalBatch(a, b domain.StockBatch) bool {
+	expiresEqual := (a.ExpiresAt == nil) == (b.ExpiresAt == nil) && (a.ExpiresAt == nil || a.ExpiresAt.Equal(*b.ExpiresAt))
+	return a.ID == b.ID && a.ProductID == b.ProductID && a.ProductName == b.ProductName && a.OutletID == b.OutletID &&
+		a.LotNumber == b.LotNumber && expiresEqual && a.Quantity == b.Quantity && a.Remaining == b.Remaining &&
+		a.CreatedAt.Equal(b.CreatedAt)
+}
@@ -0,0 +1,66 @@
+package repotest
+
+import (
+	"slices"
+	"testing"
+
+	"kasir-api/internal/domain"
+)
+
+// RunProductComponentContract verifies ProductComponentRepository behaviour
+func RunProductComponentContract(t *testing.T, newRepos Factory) {
+	t.Run("replace and get in product order", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Paket")
+		sugar := mustCreateProduct(t, repos.Products, "Gula 1kg", c.ID)
+		syrup := mustCreateProduct(t, repos.Products, "Sirup Marjan", c.ID)
+		parcel := mustCreateProduct(t, repos.Products, "Paket Lebaran", c.ID)
+		small := mustCreateProduct(t, repos.Products, "Paket Hemat", c.ID)
+
+		empty, err := repos.ProductComponents.GetByBundleID(parcel.ID)
+		if err != nil {
+			t.Fatalf("GetByBundleID: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetByBundleID without components = %#v, want empty non-nil slice", empty)
+		}
+
+		components := []domain.BundleComponent{{ProductID: syrup.ID, Quantity: 2}, {ProductID: sugar.ID, Quantity: 1}}
+		if err := repos.ProductComponents.ReplaceForBundle(parcel.ID, components); err != nil {
+			t.Fatalf("ReplaceForBundle: %v", err)
+		}
+		if err := repos.ProductComponents.ReplaceForBundle(small.ID, components[1:]); err != nil {
+			t.Fatalf("ReplaceForBundle other bundle: %v", err)
+		}
+		got, err := repos.ProductComponents.GetByBundleID(parcel.ID)
+		if err != nil {
+			t.Fatalf("GetByBundleID: %v", err)
+		}
+		want := []domain.BundleComponent{components[1], components[0]}
+		if !slices.Equal(got, want) {
+			t.Errorf("GetByBundleID = %+v, want %+v", got, want)
+		}
+
+		ids, err := repos.ProductComponents.GetBundleIDs(sugar.ID)
+		if err != nil {
+			t.Fatalf("GetBundleIDs: %v", err)
+		}
+		if !slices.Equal(ids, []int{parcel.ID, small.ID}) {
+			t.Errorf("GetBundleIDs = %v, want [%d %d]", ids, parcel.ID, small.ID)
+		}
+
+		if err := repos.ProductComponents.ReplaceForBundle(parcel.ID, nil); err != nil {
+			t.Fatalf("ReplaceForBundle with none: %v", err)
+		}
+		if got, err := repos.ProductComponents.GetByBundleID(parcel.ID); err != nil || len(got) != 0 {
+			t.Errorf("GetByBundleID after clearing = %+v, %v, want none", got, err)
+		}
+		ids, err = repos.ProductComponents.GetBundleIDs(syrup.ID)
+		if err != nil {
+			t.Fatalf("GetBundleIDs: %v", err)
+		}
+		if ids == nil || len(ids) != 0 {
+			t.Errorf("GetBundleIDs after clearing = %#v, want empty non-nil slice", ids)
+		}
+	})
+}
@@ -0,0 +1,359 @@
+package repotest
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunCartContract verifies CartRepository behaviour
+func RunCartContract(t *testing.T, newRepos Factory) {
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("create and get round-trips items", func(t *testing.T) {
+		repo := newRepos(t).Carts
+		want := domain.Cart{
+			Cashier: "budi", OutletID: domain.DefaultOutletID, Status: domain.CartStatusOpen, ReserveStock: true, CreatedAt: createdAt, UpdatedAt: createdAt,
+			Items: []domain.CartItem{{ProductID: 2, UnitID: 2, Quantity: 1}, {ProductID: 1, UnitID: domain.DefaultUnitID, Quantity: 3}},
+		}
+		if err := repo.Create(&want); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if want.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+
+		got, err := repo.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Cashier != "budi" || got.OutletID != domain.DefaultOutletID || got.Status != domain.CartStatusOpen || !got.ReserveStock || !got.CreatedAt.Equal(createdAt) ||
+			got.ShiftID != nil || got.CheckedOutAt != nil || len(got.Payments) != 0 || got.Payments == nil {
+			t.Errorf("GetByID = %+v, want %+v", got, want)
+		}
+		if !reflect.DeepEqual(got.Items, want.Items) {
+			t.Errorf("items = %+v, want %+v in order", got.Items, want.Items)
+		}
+
+		locked, err := repo.GetByIDForUpdate(want.ID)
+		if err != nil {
+			t.Fatalf("GetByIDForUpdate: %v", err)
+		}
+		if locked.ID != want.ID || !reflect.DeepEqual(locked.Items, want.Items) {
+			t.Errorf("GetByIDForUpdate = %+v, want %+v", locked, want)
+		}
+	})
+
+	t.Run("update replaces items, discounts, taxes and payments", func(t *testing.T) {
+		repos := newRepos(t)
+		shift := domain.Shift{Cashier: "budi", OpeningCash: 100000, OpenedAt: createdAt}
+		if err := repos.Shifts.Create(&shift); err != nil {
+			t.Fatalf("create shift: %v", err)
+		}
+		cart := domain.Cart{
+			Cashier: "budi", OutletID: domain.DefaultOutletID, Status: domain.CartStatusOpen, CreatedAt: createdAt, UpdatedAt: createdAt,
+			Items: []domain.CartItem{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 1}},
+		}
+		if err := repos.Carts.Create(&cart); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		checkedOut := createdAt.Add(5 * time.Minute)
+		cart.Status = domain.CartStatusCheckedOut
+		cart.ShiftID = &shift.ID
+		cart.Items = []domain.CartItem{
+			{ProductID: 1, Quantity: 3, Name: "Indomie Goreng", UnitPrice: 3500, Discount: 1050, Tax: 936, Total: 9450, CostPrice: 2800,
+				Discounts: []domain.AppliedDiscount{{PromotionID: 4, Name: "Promo Mie", Amount: 700}, {PromotionID: 5, Name: "Member", Amount: 350}},
+				Stock:     []domain.StockTake{{ProductID: 1, Quantity: 3}}},
+			{ProductID: 2, Quantity: 1, Name: "Paket Sarapan", UnitPrice: 4000, Total: 4000,
+				Stock: []domain.StockTake{{ProductID: 1, Quantity: 2}, {ProductID: 3, Quantity: 1}}},
+		}
+		cart.CartDiscounts = []domain.AppliedDiscount{{PromotionID: 6, Name: "Belanja 10rb", Amount: 500}}
+		cart.Taxes = []domain.TaxSummary{{TaxRateID: 1, Name: "PPN", Rate: 11, Inclusive: true, Taxable: 8514, Tax: 936}}
+		cart.Subtotal, cart.Discount, cart.Tax, cart.Total = 10500, 1050, 936, 9450
+		cart.Payments = []domain.Payment{
+			{Method: domain.PaymentQRIS, Amount: 5000, Reference: "QR-1"},
+			{Method: domain.PaymentCash, Amount: 5000},
+		}
+		cart.Paid, cart.Change = 10000, 550
+		cart.UpdatedAt, cart.CheckedOutAt = checkedOut, &checkedOut
+		if err := repos.Carts.Update(&cart); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, err := repos.Carts.GetByID(cart.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.CartStatusCheckedOut || !equalIntPtr(got.ShiftID, &shift.ID) || got.Subtotal != 10500 ||
+			got.Discount != 1050 || got.Tax != 936 || got.Total != 9450 || got.Paid != 10000 || got.Change != 550 ||
+			!got.UpdatedAt.Equal(checkedOut) || got.CheckedOutAt == nil || !got.CheckedOutAt.Equal(checkedOut) {
+			t.Errorf("GetByID = %+v, want %+v", got, cart)
+		}
+		if !reflect.DeepEqual(got.Items, cart.Items) || !slices.Equal(got.Payments, cart.Payments) {
+			t.Errorf("items, payments = %+v, %+v, want %+v, %+v", got.Items, got.Payments, cart.Items, cart.Payments)
+		}
+		if !slices.Equal(got.CartDiscounts, cart.CartDiscounts) || !slices.Equal(got.Taxes, cart.Taxes) {
+			t.Errorf("cart discounts, taxes = %+v, %+v, want %+v, %+v", got.CartDiscounts, got.Taxes, cart.CartDiscounts, cart.Taxes)
+		}
+
+		// Checking the same cart out again, as a concurrent checkout would,
+		// leaves the first sale untouched
+		again := cart
+		again.Items = []domain.CartItem{{ProductID: 2, Quantity: 1}}
+		again.Total, again.Paid = 1, 1
+		if err := repos.Carts.Update(&again); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Update checked out: err = %v, want ErrConflict", err)
+		}
+		got, err = repos.Carts.GetByID(cart.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Total != 9450 || !reflect.DeepEqual(got.Items, cart.Items) {
+			t.Errorf("after second checkout = %+v, want the first sale", got)
+		}
+
+		missing := domain.Cart{ID: 999, Status: domain.CartStatusOpen, CreatedAt: createdAt, UpdatedAt: createdAt}
+		if err := repos.Carts.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("get all filters by status", func(t *testing.T) {
+		repo := newRepos(t).Carts
+		for _, status := range []string{domain.CartStatusOpen, domain.CartStatusHeld, domain.CartStatusHeld} {
+			c := domain.Cart{OutletID: domain.DefaultOutletID, Status: status, CreatedAt: createdAt, UpdatedAt: createdAt}
+			if err := repo.Create(&c); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		all, err := repo.GetAll("", 0)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		held, err := repo.GetAll(domain.CartStatusHeld, domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("GetAll held: %v", err)
+		}
+		if len(all) != 3 || len(held) != 2 || held[0].ID != 2 || held[1].ID != 3 || held[0].Items == nil {
+			t.Errorf("GetAll = %d carts, held = %+v, want 3 and carts 2 and 3", len(all), held)
+		}
+	})
+
+	t.Run("payment totals per shift", func(t *testing.T) {
+		repos := newRepos(t)
+		var shifts [2]domain.Shift
+		for i, cashier := range []string{"budi", "siti"} {
+			shifts[i] = domain.Shift{Cashier: cashier, OpenedAt: createdAt}
+			if err := repos.Shifts.Create(&shifts[i]); err != nil {
+				t.Fatalf("create shift: %v", err)
+			}
+		}
+		sales := []struct {
+			shiftID  int
+			status   string
+			payments []domain.Payment
+			change   int
+		}{
+			{shifts[0].ID, domain.CartStatusCheckedOut, []domain.Payment{{Method: domain.PaymentCash, Amount: 20000}}, 1500},
+			{shifts[0].ID, domain.CartStatusCheckedOut, []domain.Payment{{Method: domain.PaymentQRIS, Amount: 7000}, {Method: domain.PaymentCash, Amount: 5000}}, 0},
+			{shifts[1].ID, domain.CartStatusCheckedOut, []domain.Payment{{Method: domain.PaymentCash, Amount: 9000}}, 0},
+			{shifts[0].ID, domain.CartStatusHeld, nil, 0},
+		}
+		for _, sale := range sales {
+			c := domain.Cart{OutletID: domain.DefaultOutletID, Status: domain.CartStatusOpen, CreatedAt: createdAt, UpdatedAt: createdAt}
+			if err := repos.Carts.Create(&c); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			c.Status, c.ShiftID, c.Payments, c.Change = sale.status, &sale.shiftID, sale.payments, sale.change
+			if err := repos.Carts.Update(&c); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+		}
+
+		totals, err := repos.Carts.PaymentTotals(shifts[0].ID)
+		if err != nil {
+			t.Fatalf("PaymentTotals: %v", err)
+		}
+		if len(totals) != 2 || totals[domain.PaymentCash] != 23500 || totals[domain.PaymentQRIS] != 7000 {
+			t.Errorf("PaymentTotals = %v, want cash 23500 net of change and qris 7000", totals)
+		}
+		empty, err := repos.Carts.PaymentTotals(999)
+		if err != nil {
+			t.Fatalf("PaymentTotals: %v", err)
+		}
+		if len(empty) != 0 {
+			t.Errorf("PaymentTotals for unused shift = %v, want empty", empty)
+		}
+	})
+
+	t.Run("get by customer lists checked out carts newest first", func(t *testing.T) {
+		repos := newRepos(t)
+		customer := domain.Customer{Name: "Siti Aminah"}
+		if err := repos.Customers.Create(&customer); err != nil {
+			t.Fatalf("create customer: %v", err)
+		}
+		var ids []int
+		for i, status := range []string{domain.CartStatusCheckedOut, domain.CartStatusCheckedOut, domain.CartStatusOpen} {
+			cart := domain.Cart{OutletID: domain.DefaultOutletID, Status: domain.CartStatusOpen, CustomerID: &customer.ID, CreatedAt: createdAt, UpdatedAt: createdAt}
+			if err := repos.Carts.Create(&cart); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if status == domain.CartStatusCheckedOut {
+				checkedOut := createdAt.Add(time.Duration(i) * time.Minute)
+				cart.Status, cart.CheckedOutAt = status, &checkedOut
+				cart.Total, cart.PointsRedeemed, cart.PointsDiscount, cart.PointsEarned = 9000, 10, 1000, 1
+				if err := repos.Carts.Update(&cart); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			}
+			ids = append(ids, cart.ID)
+		}
+		other := domain.Cart{OutletID: domain.DefaultOutletID, Status: domain.CartStatusCheckedOut, CreatedAt: createdAt, UpdatedAt: createdAt}
+		if err := repos.Carts.Create(&other); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repos.Carts.GetByCustomer(customer.ID)
+		if err != nil {
+			t.Fatalf("GetByCustomer: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != ids[1] || got[1].ID != ids[0] {
+			t.Fatalf("GetByCustomer = %+v, want carts %d then %d", got, ids[1], ids[0])
+		}
+		if !equalIntPtr(got[0].CustomerID, &customer.ID) || got[0].PointsRedeemed != 10 || got[0].PointsDiscount != 1000 ||
+			got[0].PointsEarned != 1 {
+			t.Errorf("cart = %+v, want customer and points round-tripped", got[0])
+		}
+	})
+
+	t.Run("get sales filters by outlet and checkout time", func(t *testing.T) {
+		repos := newRepos(t)
+		branch := domain.Outlet{Code: "BDG", Name: "Cabang Bandung"}
+		if err := repos.Outlets.Create(&branch); err != nil {
+			t.Fatalf("create outlet: %v", err)
+		}
+		sales := []struct {
+			outletID int
+			status   string
+			minutes  int
+		}{
+			{domain.DefaultOutletID, domain.CartStatusCheckedOut, 30},
+			{domain.DefaultOutletID, domain.CartStatusCheckedOut, 10},
+			{branch.ID, domain.CartStatusCheckedOut, 20},
+			{domain.DefaultOutletID, domain.CartStatusCheckedOut, 90},
+			{domain.DefaultOutletID, domain.CartStatusHeld, 0},
+		}
+		var ids []int
+		for _, sale := range sales {
+			c := domain.Cart{OutletID: sale.outletID, Status: domain.CartStatusOpen, CreatedAt: createdAt, UpdatedAt: createdAt}
+			if err := repos.Carts.Create(&c); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			checkedOut := createdAt.Add(time.Duration(sale.minutes) * time.Minute)
+			c.Status, c.Total = sale.status, 1000
+			c.Items = []domain.CartItem{{ProductID: 1, Quantity: 1, CostPrice: 800}}
+			if sale.status == domain.CartStatusCheckedOut {
+				c.CheckedOutAt = &checkedOut
+			}
+			if err := repos.Carts.Update(&c); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			ids = append(ids, c.ID)
+		}
+
+		got, err := repos.Carts.GetSales(domain.SalesFilter{
+			OutletID: domain.DefaultOutletID, From: createdAt.Add(10 * time.Minute), To: createdAt.Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("GetSales: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != ids[1] || got[1].ID != ids[0] || len(got[0].Items) != 1 || got[0].Items[0].CostPrice != 800 {
+			t.Errorf("GetSales = %+v, want carts %d then %d with their items", got, ids[1], ids[0])
+		}
+		all, err := repos.Carts.GetSales(domain.SalesFilter{})
+		if err != nil {
+			t.Fatalf("GetSales: %v", err)
+		}
+		if len(all) != 4 || all[0].ID != ids[1] || all[3].ID != ids[3] {
+			t.Errorf("GetSales without filter = %+v, want the 4 checked out carts oldest first", all)
+		}
+	})
+
+	t.Run("void marks a checked out cart voided", func(t *testing.T) {
+		repos := newRepos(t)
+		shift := domain.Shift{Cashier: "budi", OpenedAt: createdAt}
+		if err := repos.Shifts.Create(&shift); err != nil {
+			t.Fatalf("create shift: %v", err)
+		}
+		cart := domain.Cart{OutletID: domain.DefaultOutletID, Status: domain.CartStatusOpen, CreatedAt: createdAt, UpdatedAt: createdAt}
+		if err := repos.Carts.Create(&cart); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Carts.Void(&cart); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Void open cart: err = %v, want ErrConflict", err)
+		}
+
+		checkedOut := createdAt.Add(time.Minute)
+		cart.Status, cart.ShiftID, cart.CheckedOutAt, cart.Total = domain.CartStatusCheckedOut, &shift.ID, &checkedOut, 7000
+		cart.Items = []domain.CartItem{{ProductID: 1, Quantity: 2, Total: 7000}}
+		cart.Payments = []domain.Payment{{Method: domain.PaymentCash, Amount: 7000}}
+		if err := repos.Carts.Update(&cart); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		voidedAt := createdAt.Add(5 * time.Minute)
+		void := cart
+		void.VoidedAt, void.VoidedBy, void.VoidReason, void.UpdatedAt = &voidedAt, "siti", "Salah input", voidedAt
+		if err := repos.Carts.Void(&void); err != nil {
+			t.Fatalf("Void: %v", err)
+		}
+		if void.Status != domain.CartStatusVoided {
+			t.Errorf("status after Void = %q, want voided", void.Status)
+		}
+		got, err := repos.Carts.GetByID(cart.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.CartStatusVoided || got.VoidedAt == nil || !got.VoidedAt.Equal(voidedAt) ||
+			got.VoidedBy != "siti" || got.VoidReason != "Salah input" || !got.UpdatedAt.Equal(voidedAt) ||
+			got.Total != 7000 || len(got.Items) != 1 || len(got.Payments) != 1 {
+			t.Errorf("GetByID = %+v, want the sale voided with its lines", got)
+		}
+
+		// A voided sale is final and no longer counts as a sale
+		if err := repos.Carts.Void(&void); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Void voided cart: err = %v, want ErrConflict", err)
+		}
+		if err := repos.Carts.Update(&void); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Update voided cart: err = %v, want ErrConflict", err)
+		}
+		if sales, err := repos.Carts.GetSales(domain.SalesFilter{}); err != nil || len(sales) != 0 {
+			t.Errorf("GetSales = %+v, %v, want no sales", sales, err)
+		}
+		if totals, err := repos.Carts.PaymentTotals(shift.ID); err != nil || len(totals) != 0 {
+			t.Errorf("PaymentTotals = %v, %v, want empty", totals, err)
+		}
+
+		missing := domain.Cart{ID: 999, VoidedAt: &voidedAt, UpdatedAt: voidedAt}
+		if err := repos.Carts.Void(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Void missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repo := newRepos(t).Carts
+		if _, err := repo.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetByIDForUpdate(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate: err = %v, want ErrNotFound", err)
+		}
+	})
+}
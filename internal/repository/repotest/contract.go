@@ -0,0 +1,473 @@
+// Package repotest holds behavioural contracts that every implementation of
+// the repository interfaces must satisfy, whether backed by Postgres or memory.
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// Factory returns fresh, empty repositories for a single subtest
+type Factory func(t *testing.T) repository.Repositories
+
+// RunCategoryContract verifies CategoryRepository behaviour
+func RunCategoryContract(t *testing.T, newRepos Factory) {
+	t.Run("create assigns id", func(t *testing.T) {
+		repo := newRepos(t).Categories
+		first := mustCreateCategory(t, repo, "Makanan Ringan")
+		second := mustCreateCategory(t, repo, "Minuman")
+		if first.ID == 0 || second.ID == 0 || first.ID == second.ID {
+			t.Fatalf("ids = %d, %d, want distinct non-zero", first.ID, second.ID)
+		}
+	})
+
+	t.Run("get all returns categories in id order", func(t *testing.T) {
+		repo := newRepos(t).Categories
+		empty, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetAll on empty repo = %#v, want empty non-nil slice", empty)
+		}
+
+		a := mustCreateCategory(t, repo, "Makanan Ringan")
+		b := mustCreateCategory(t, repo, "Minuman")
+		got, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(got) != 2 || !equalCategory(got[0], a) || !equalCategory(got[1], b) {
+			t.Fatalf("GetAll = %+v, want [%+v %+v]", got, a, b)
+		}
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		repo := newRepos(t).Categories
+		want := mustCreateCategory(t, repo, "Makanan Ringan")
+		got, err := repo.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalCategory(*got, want) {
+			t.Fatalf("GetByID = %+v, want %+v", *got, want)
+		}
+
+		if _, err := repo.GetByID(want.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepos(t).Categories
+		c := mustCreateCategory(t, repo, "Makanan Ringan")
+		c.Name = "Snack"
+		c.Description = "Keripik dan biskuit"
+		if err := repo.Update(&c); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repo.GetByID(c.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalCategory(*got, c) {
+			t.Fatalf("after Update = %+v, want %+v", *got, c)
+		}
+
+		missing := domain.Category{ID: c.ID + 1000, Name: "Ghost"}
+		if err := repo.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepos(t).Categories
+		c := mustCreateCategory(t, repo, "Makanan Ringan")
+		if err := repo.Delete(c.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(c.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("GetByID after Delete: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(c.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Delete twice: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("subcategories keep their parent", func(t *testing.T) {
+		repo := newRepos(t).Categories
+		parent := mustCreateCategory(t, repo, "Makanan")
+		child := domain.Category{Name: "Mi Instan", ParentID: &parent.ID}
+		if err := repo.Create(&child); err != nil {
+			t.Fatalf("Create child: %v", err)
+		}
+		got, err := repo.GetByID(child.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalCategory(*got, child) {
+			t.Fatalf("GetByID = %+v, want %+v", *got, child)
+		}
+
+		if err := repo.Delete(parent.ID); !errors.Is(err, apperrors.ErrConflict) {
+			t.Fatalf("Delete parent with subcategory: err = %v, want ErrConflict", err)
+		}
+		child.ParentID = nil
+		if err := repo.Update(&child); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if got, err := repo.GetByID(child.ID); err != nil || got.ParentID != nil {
+			t.Fatalf("after Update = %+v, %v, want no parent", got, err)
+		}
+		if err := repo.Delete(parent.ID); err != nil {
+			t.Fatalf("Delete parent without subcategories: %v", err)
+		}
+	})
+}
+
+// RunProductContract verifies ProductRepository behaviour, including that
+// reads join the owning category
+func RunProductContract(t *testing.T, newRepos Factory) {
+	t.Run("create assigns id", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		first := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		second := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+		if first.ID == 0 || second.ID == 0 || first.ID == second.ID {
+			t.Fatalf("ids = %d, %d, want distinct non-zero", first.ID, second.ID)
+		}
+	})
+
+	t.Run("get all joins category", func(t *testing.T) {
+		repos := newRepos(t)
+		empty, err := repos.Products.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetAll on empty repo = %#v, want empty non-nil slice", empty)
+		}
+
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		a := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		b := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+		got, err := repos.Products.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("len(GetAll) = %d, want 2", len(got))
+		}
+		for i, want := range []domain.Product{a, b} {
+			assertProduct(t, got[i], want, c)
+		}
+	})
+
+	t.Run("get by id joins category", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		want := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		got, err := repos.Products.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		assertProduct(t, *got, want, c)
+
+		if _, err := repos.Products.GetByID(want.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repos := newRepos(t)
+		c1 := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		c2 := mustCreateCategory(t, repos.Categories, "Minuman")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c1.ID)
+
+		p.Name = "Teh Botol"
+		p.Price = 5000
+		p.CostPrice = 4100
+		p.Stock = 24 // ignored, stock is set per outlet
+		p.CategoryID = c2.ID
+		if err := repos.Products.Update(&p); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repos.Products.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		p.Stock = 100
+		assertProduct(t, *got, p, c2)
+
+		missing := domain.Product{ID: p.ID + 1000, Name: "Ghost", Type: domain.ProductTypeStandard, CategoryID: c1.ID, UnitID: domain.DefaultUnitID}
+		if err := repos.Products.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		if err := repos.Products.Delete(p.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Products.GetByID(p.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("GetByID after Delete: err = %v, want ErrNotFound", err)
+		}
+		if err := repos.Products.Delete(p.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Delete twice: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("decrement stock", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		if err := repos.Products.DecrementStock(p.ID, domain.DefaultOutletID, 60); err != nil {
+			t.Fatalf("DecrementStock: %v", err)
+		}
+		if err := repos.Products.DecrementStock(p.ID, domain.DefaultOutletID, 41); !errors.Is(err, apperrors.ErrInsufficientStock) {
+			t.Fatalf("DecrementStock past zero: err = %v, want ErrInsufficientStock", err)
+		}
+		if err := repos.Products.DecrementStock(p.ID, domain.DefaultOutletID, 40); err != nil {
+			t.Fatalf("DecrementStock to zero: %v", err)
+		}
+		got, err := repos.Products.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Stock != 0 {
+			t.Errorf("stock = %d, want 0", got.Stock)
+		}
+		if err := repos.Products.DecrementStock(999, domain.DefaultOutletID, 1); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("DecrementStock missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("receive stock adds quantity and sets cost", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		if err := repos.Products.ReceiveStock(p.ID, domain.DefaultOutletID, 48, 2900); err != nil {
+			t.Fatalf("ReceiveStock: %v", err)
+		}
+		got, err := repos.Products.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Stock != 148 || got.CostPrice != 2900 || got.Price != 3500 {
+			t.Errorf("product = %+v, want stock 148, cost 2900 and price unchanged", got)
+		}
+		if err := repos.Products.ReceiveStock(999, domain.DefaultOutletID, 1, 1); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("ReceiveStock missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("stock is kept per outlet", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+
+		if got, err := repos.Products.GetStock(p.ID, branch.ID); err != nil || got != 0 {
+			t.Fatalf("GetStock at unstocked outlet = %d, %v, want 0", got, err)
+		}
+		if err := repos.Products.AddStock(p.ID, branch.ID, 30); err != nil {
+			t.Fatalf("AddStock: %v", err)
+		}
+		if err := repos.Products.DecrementStock(p.ID, branch.ID, 31); !errors.Is(err, apperrors.ErrInsufficientStock) {
+			t.Fatalf("DecrementStock past outlet stock: err = %v, want ErrInsufficientStock", err)
+		}
+		if err := repos.Products.DecrementStock(p.ID, branch.ID, 10); err != nil {
+			t.Fatalf("DecrementStock: %v", err)
+		}
+		if got, err := repos.Products.GetStock(p.ID, branch.ID); err != nil || got != 20 {
+			t.Errorf("GetStock at branch = %d, %v, want 20", got, err)
+		}
+		if got, err := repos.Products.GetStock(p.ID, domain.DefaultOutletID); err != nil || got != 100 {
+			t.Errorf("GetStock at default outlet = %d, %v, want 100", got, err)
+		}
+		if got, err := repos.Products.LockStock(p.ID, branch.ID); err != nil || got != 20 {
+			t.Errorf("LockStock at branch = %d, %v, want 20", got, err)
+		}
+		if got, err := repos.Products.LockStock(p.ID, 999); err != nil || got != 0 {
+			t.Errorf("LockStock at unstocked outlet = %d, %v, want 0", got, err)
+		}
+		levels, err := repos.Products.StockLevels(branch.ID)
+		if err != nil {
+			t.Fatalf("StockLevels: %v", err)
+		}
+		if len(levels) != 1 || levels[p.ID] != 20 {
+			t.Errorf("StockLevels = %v, want only product %d at 20", levels, p.ID)
+		}
+		got, err := repos.Products.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Stock != 120 {
+			t.Errorf("total stock = %d, want 120", got.Stock)
+		}
+		if locked, err := repos.Products.GetByIDForUpdate(p.ID); err != nil || locked.Stock != 120 {
+			t.Errorf("GetByIDForUpdate total stock = %+v, %v, want 120", locked, err)
+		}
+		if _, err := repos.Products.GetByIDForUpdate(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate missing: err = %v, want ErrNotFound", err)
+		}
+
+		if err := repos.Products.SetStock(p.ID, branch.ID, 5); err != nil {
+			t.Fatalf("SetStock: %v", err)
+		}
+		if got, err := repos.Products.GetStock(p.ID, branch.ID); err != nil || got != 5 {
+			t.Errorf("GetStock after SetStock = %d, %v, want 5", got, err)
+		}
+		if err := repos.Products.SetStock(999, branch.ID, 5); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("SetStock missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("variants keep parent, barcode and options", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		parent := mustCreateProduct(t, repos.Products, "Indomie", c.ID)
+		mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+		variant := domain.Product{Name: "Indomie Soto", Type: domain.ProductTypeStandard, Price: 3400, CategoryID: c.ID, UnitID: domain.DefaultUnitID, ParentID: &parent.ID,
+			Barcode: "089686010015", Options: map[string]string{"flavour": "soto"}}
+		if err := repos.Products.Create(&variant); err != nil {
+			t.Fatalf("Create variant: %v", err)
+		}
+
+		got, err := repos.Products.GetByID(variant.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalIntPtr(got.ParentID, &parent.ID) || got.Barcode != "089686010015" || got.Options["flavour"] != "soto" {
+			t.Errorf("variant = %+v, want parent %d, barcode and flavour option", got, parent.ID)
+		}
+		if top, err := repos.Products.GetByID(parent.ID); err != nil || top.ParentID != nil || top.Options != nil {
+			t.Errorf("parent = %+v, %v, want no parent and no options", top, err)
+		}
+
+		variants, err := repos.Products.GetVariants(parent.ID)
+		if err != nil {
+			t.Fatalf("GetVariants: %v", err)
+		}
+		if len(variants) != 1 || variants[0].ID != variant.ID || variants[0].Category == nil {
+			t.Errorf("GetVariants = %+v, want only variant %d with its category", variants, variant.ID)
+		}
+
+		clash := domain.Product{Name: "Indomie Kari", Type: domain.ProductTypeStandard, Price: 3400, CategoryID: c.ID, UnitID: domain.DefaultUnitID,
+			Barcode: "089686010015"}
+		if err := repos.Products.Create(&clash); !errors.Is(err, apperrors.ErrConflict) {
+			t.Fatalf("Create with taken barcode: err = %v, want ErrConflict", err)
+		}
+		parent.Barcode = "089686010015"
+		if err := repos.Products.Update(&parent); !errors.Is(err, apperrors.ErrConflict) {
+			t.Fatalf("Update to taken barcode: err = %v, want ErrConflict", err)
+		}
+		if err := repos.Products.Delete(parent.ID); !errors.Is(err, apperrors.ErrConflict) {
+			t.Fatalf("Delete parent with variants: err = %v, want ErrConflict", err)
+		}
+		if err := repos.Products.Delete(variant.ID); err != nil {
+			t.Fatalf("Delete variant: %v", err)
+		}
+		if err := repos.Products.Delete(parent.ID); err != nil {
+			t.Fatalf("Delete parent without variants: %v", err)
+		}
+	})
+
+	t.Run("get by category includes subcategories", func(t *testing.T) {
+		repos := newRepos(t)
+		food := mustCreateCategory(t, repos.Categories, "Makanan")
+		noodles := domain.Category{Name: "Mi Instan", ParentID: &food.ID}
+		if err := repos.Categories.Create(&noodles); err != nil {
+			t.Fatalf("Create subcategory: %v", err)
+		}
+		cup := domain.Category{Name: "Mi Cup", ParentID: &noodles.ID}
+		if err := repos.Categories.Create(&cup); err != nil {
+			t.Fatalf("Create nested subcategory: %v", err)
+		}
+		drinks := mustCreateCategory(t, repos.Categories, "Minuman")
+		a := mustCreateProduct(t, repos.Products, "Roti Tawar", food.ID)
+		b := mustCreateProduct(t, repos.Products, "Indomie Goreng", noodles.ID)
+		c := mustCreateProduct(t, repos.Products, "Pop Mie", cup.ID)
+		mustCreateProduct(t, repos.Products, "Teh Botol", drinks.ID)
+
+		got, err := repos.Products.GetByCategory(food.ID)
+		if err != nil {
+			t.Fatalf("GetByCategory: %v", err)
+		}
+		if len(got) != 3 || got[0].ID != a.ID || got[1].ID != b.ID || got[2].ID != c.ID {
+			t.Fatalf("GetByCategory(food) = %+v, want products %d, %d, %d", got, a.ID, b.ID, c.ID)
+		}
+		if got[2].Category == nil || got[2].Category.ID != cup.ID {
+			t.Errorf("category = %+v, want the product's own category %d", got[2].Category, cup.ID)
+		}
+
+		got, err = repos.Products.GetByCategory(noodles.ID)
+		if err != nil {
+			t.Fatalf("GetByCategory: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != b.ID || got[1].ID != c.ID {
+			t.Errorf("GetByCategory(noodles) = %+v, want products %d, %d", got, b.ID, c.ID)
+		}
+
+		empty, err := repos.Products.GetByCategory(drinks.ID + 1000)
+		if err != nil {
+			t.Fatalf("GetByCategory missing: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Errorf("GetByCategory missing = %#v, want empty non-nil slice", empty)
+		}
+	})
+}
+
+func mustCreateCategory(t *testing.T, repo repository.CategoryRepository, name string) domain.Category {
+	t.Helper()
+	c := domain.Category{Name: name, Description: name + " description"}
+	if err := repo.Create(&c); err != nil {
+		t.Fatalf("create category %q: %v", name, err)
+	}
+	return c
+}
+
+func mustCreateProduct(t *testing.T, repo repository.ProductRepository, name string, categoryID int) domain.Product {
+	t.Helper()
+	p := domain.Product{Name: name, Type: domain.ProductTypeStandard, Price: 3500, CostPrice: 2800, CategoryID: categoryID, UnitID: domain.DefaultUnitID}
+	if err := repo.Create(&p); err != nil {
+		t.Fatalf("create product %q: %v", name, err)
+	}
+	if err := repo.SetStock(p.ID, domain.DefaultOutletID, 100); err != nil {
+		t.Fatalf("stock product %q: %v", name, err)
+	}
+	p.Stock = 100
+	return p
+}
+
+func assertProduct(t *testing.T, got, want domain.Product, category domain.Category) {
+	t.Helper()
+	if got.ID != want.ID || got.Name != want.Name || got.Price != want.Price || got.CostPrice != want.CostPrice ||
+		got.Stock != want.Stock || got.CategoryID != want.CategoryID || !equalIntPtr(got.TaxRateID, want.TaxRateID) ||
+		got.UnitID != want.UnitID || got.Type != want.Type {
+		t.Errorf("product = %+v, want %+v", got, want)
+	}
+	if got.Category == nil || !equalCategory(*got.Category, category) {
+		t.Errorf("product.Category = %+v, want %+v", got.Category, category)
+	}
+}
+
+func equalCategory(a, b domain.Category) bool {
+	return a.ID == b.ID && a.Name == b.Name && a.Description == b.Description && equalIntPtr(a.TaxRateID, b.TaxRateID) &&
+		equalIntPtr(a.ParentID, b.ParentID)
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
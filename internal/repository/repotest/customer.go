@@ -0,0 +1,144 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunCustomerContract verifies CustomerRepository behaviour
+func RunCustomerContract(t *testing.T, newRepos Factory) {
+	t.Run("create, get and list", func(t *testing.T) {
+		repo := newRepos(t).Customers
+		empty, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetAll on empty repo = %#v, want empty non-nil slice", empty)
+		}
+
+		a := mustCreateCustomer(t, repo, "Siti Aminah", "MBR-0001")
+		b := mustCreateCustomer(t, repo, "Budi Santoso", "")
+		if a.ID == 0 || b.ID == 0 || a.ID == b.ID {
+			t.Fatalf("ids = %d, %d, want distinct non-zero", a.ID, b.ID)
+		}
+
+		got, err := repo.GetByID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != a {
+			t.Errorf("GetByID = %+v, want %+v", *got, a)
+		}
+		all, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 2 || all[0] != a || all[1] != b {
+			t.Errorf("GetAll = %+v, want [%+v %+v]", all, a, b)
+		}
+		if _, err := repo.GetByID(a.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+
+		locked, err := repo.GetByIDForUpdate(a.ID)
+		if err != nil {
+			t.Fatalf("GetByIDForUpdate: %v", err)
+		}
+		if *locked != a {
+			t.Errorf("GetByIDForUpdate = %+v, want %+v", *locked, a)
+		}
+		if _, err := repo.GetByIDForUpdate(a.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("member codes are unique but optional", func(t *testing.T) {
+		repo := newRepos(t).Customers
+		a := mustCreateCustomer(t, repo, "Siti Aminah", "MBR-0001")
+		mustCreateCustomer(t, repo, "Budi Santoso", "")
+		b := mustCreateCustomer(t, repo, "Dewi Lestari", "")
+
+		dup := domain.Customer{Name: "Joko", MemberCode: "MBR-0001"}
+		if err := repo.Create(&dup); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Create duplicate: err = %v, want ErrConflict", err)
+		}
+		b.MemberCode = a.MemberCode
+		if err := repo.Update(&b); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Update to duplicate: err = %v, want ErrConflict", err)
+		}
+		a.Name = "Siti A."
+		if err := repo.Update(&a); err != nil {
+			t.Errorf("Update keeping own code: %v", err)
+		}
+	})
+
+	t.Run("update keeps points", func(t *testing.T) {
+		repo := newRepos(t).Customers
+		c := mustCreateCustomer(t, repo, "Siti Aminah", "MBR-0001")
+		if err := repo.AddPoints(c.ID, 50); err != nil {
+			t.Fatalf("AddPoints: %v", err)
+		}
+
+		c.Name, c.Phone, c.Email, c.Points = "Siti A.", "0811", "siti@example.com", 9999
+		if err := repo.Update(&c); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repo.GetByID(c.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Siti A." || got.Phone != "0811" || got.Email != "siti@example.com" || got.Points != 50 || c.Points != 50 {
+			t.Errorf("after Update = %+v (returned %+v), want new details and 50 points", *got, c)
+		}
+
+		missing := domain.Customer{ID: c.ID + 1000, Name: "Ghost"}
+		if err := repo.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("add points never goes negative", func(t *testing.T) {
+		repo := newRepos(t).Customers
+		c := mustCreateCustomer(t, repo, "Siti Aminah", "")
+		if err := repo.AddPoints(c.ID, 30); err != nil {
+			t.Fatalf("AddPoints: %v", err)
+		}
+		if err := repo.AddPoints(c.ID, -31); !errors.Is(err, apperrors.ErrInsufficientPoints) {
+			t.Errorf("AddPoints past zero: err = %v, want ErrInsufficientPoints", err)
+		}
+		if err := repo.AddPoints(c.ID, -30); err != nil {
+			t.Errorf("AddPoints to zero: %v", err)
+		}
+		if err := repo.AddPoints(c.ID+1000, 1); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("AddPoints missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepos(t).Customers
+		c := mustCreateCustomer(t, repo, "Siti Aminah", "")
+		if err := repo.Delete(c.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(c.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID after Delete: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(c.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Delete twice: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func mustCreateCustomer(t *testing.T, repo repository.CustomerRepository, name, memberCode string) domain.Customer {
+	t.Helper()
+	c := domain.Customer{Name: name, Phone: "081234567890", Email: "customer@example.com", MemberCode: memberCode}
+	if err := repo.Create(&c); err != nil {
+		t.Fatalf("create customer %q: %v", name, err)
+	}
+	return c
+}
@@ -0,0 +1,124 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunIdempotencyContract verifies IdempotencyRepository behaviour
+func RunIdempotencyContract(t *testing.T, newRepos Factory) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	record := func(key, route, hash string, expires time.Time) *domain.IdempotencyRecord {
+		return &domain.IdempotencyRecord{Key: key, Route: route, RequestHash: hash, CreatedAt: now, ExpiresAt: expires}
+	}
+
+	t.Run("reserve, complete and get", func(t *testing.T) {
+		repo := newRepos(t).Idempotency
+		if _, err := repo.Get("k1", "POST /api/products", now); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Get before Reserve: err = %v, want ErrNotFound", err)
+		}
+		mustReserveKey(t, repo, record("k1", "POST /api/products", hashOf("a"), now.Add(time.Hour)))
+
+		pending, err := repo.Get("k1", "POST /api/products", now)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if pending.Completed() || pending.RequestHash != hashOf("a") {
+			t.Errorf("pending record = %+v, want uncompleted with the request hash", pending)
+		}
+
+		if err := repo.Complete("k1", "POST /api/products", 201, []byte(`{"success":true}`)); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		done, err := repo.Get("k1", "POST /api/products", now)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if done.StatusCode != 201 || string(done.Body) != `{"success":true}` || !done.ExpiresAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("completed record = %+v", done)
+		}
+
+		if err := repo.Complete("missing", "POST /api/products", 201, nil); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Complete missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("keys are scoped by route", func(t *testing.T) {
+		repo := newRepos(t).Idempotency
+		mustReserveKey(t, repo, record("k1", "POST /api/products", hashOf("a"), now.Add(time.Hour)))
+		mustReserveKey(t, repo, record("k1", "POST /api/carts/1/checkout", hashOf("a"), now.Add(time.Hour)))
+
+		err := repo.Reserve(record("k1", "POST /api/products", hashOf("b"), now.Add(time.Hour)))
+		if !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Reserve twice: err = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("expired records are hidden and can be replaced", func(t *testing.T) {
+		repo := newRepos(t).Idempotency
+		mustReserveKey(t, repo, record("k1", "POST /api/products", hashOf("a"), now))
+		if err := repo.Complete("k1", "POST /api/products", 201, []byte("{}")); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		if _, err := repo.Get("k1", "POST /api/products", now); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Get expired: err = %v, want ErrNotFound", err)
+		}
+
+		mustReserveKey(t, repo, record("k1", "POST /api/products", hashOf("b"), now.Add(time.Hour)))
+		got, err := repo.Get("k1", "POST /api/products", now)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got.Completed() || got.RequestHash != hashOf("b") {
+			t.Errorf("replaced record = %+v, want uncompleted with the new hash", got)
+		}
+	})
+
+	t.Run("delete and delete expired", func(t *testing.T) {
+		repo := newRepos(t).Idempotency
+		mustReserveKey(t, repo, record("k1", "POST /api/products", hashOf("a"), now))
+		mustReserveKey(t, repo, record("k2", "POST /api/products", hashOf("a"), now.Add(-time.Minute)))
+		mustReserveKey(t, repo, record("k3", "POST /api/products", hashOf("a"), now.Add(time.Minute)))
+		mustReserveKey(t, repo, record("k4", "POST /api/products", hashOf("a"), now.Add(time.Minute)))
+
+		if err := repo.Delete("k4", "POST /api/products"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.Get("k4", "POST /api/products", now); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+		}
+
+		deleted, err := repo.DeleteExpired(now)
+		if err != nil {
+			t.Fatalf("DeleteExpired: %v", err)
+		}
+		if deleted != 2 {
+			t.Errorf("DeleteExpired = %d, want 2", deleted)
+		}
+		if _, err := repo.Get("k3", "POST /api/products", now); err != nil {
+			t.Errorf("Get unexpired after DeleteExpired: %v", err)
+		}
+	})
+}
+
+func mustReserveKey(t *testing.T, repo repository.IdempotencyRepository, record *domain.IdempotencyRecord) {
+	t.Helper()
+	if err := repo.Reserve(record); err != nil {
+		t.Fatalf("reserve idempotency key %q: %v", record.Key, err)
+	}
+}
+
+// hashOf pads s to the width of a SHA-256 hex digest, as stored by the
+// idempotency middleware
+func hashOf(s string) string {
+	const width = 64
+	for len(s) < width {
+		s += "0"
+	}
+	return s
+}
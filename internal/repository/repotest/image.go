@@ -0,0 +1,90 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunProductImageContract verifies ProductImageRepository behaviour
+func RunProductImageContract(t *testing.T, newRepos Factory) {
+	t.Run("create, get and list by product", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Roti")
+		bread := mustCreateProduct(t, repos.Products, "Roti Tawar", c.ID)
+		milk := mustCreateProduct(t, repos.Products, "Susu UHT", c.ID)
+		other := mustCreateProduct(t, repos.Products, "Keju", c.ID)
+
+		empty, err := repos.ProductImages.GetByProductIDs([]int{bread.ID})
+		if err != nil {
+			t.Fatalf("GetByProductIDs: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetByProductIDs without images = %#v, want empty non-nil slice", empty)
+		}
+
+		milkImage := mustCreateProductImage(t, repos.ProductImages, milk.ID, "milk")
+		breadImage := mustCreateProductImage(t, repos.ProductImages, bread.ID, "bread")
+		mustCreateProductImage(t, repos.ProductImages, other.ID, "cheese")
+		if milkImage.ID == 0 || breadImage.ID == 0 || milkImage.ID == breadImage.ID {
+			t.Fatalf("ids = %d, %d, want distinct non-zero", milkImage.ID, breadImage.ID)
+		}
+		if breadImage.CreatedAt.IsZero() {
+			t.Errorf("CreatedAt not set")
+		}
+
+		got, err := repos.ProductImages.GetByID(breadImage.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalProductImage(*got, breadImage) {
+			t.Errorf("GetByID = %+v, want %+v", *got, breadImage)
+		}
+		if _, err := repos.ProductImages.GetByID(breadImage.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+
+		images, err := repos.ProductImages.GetByProductIDs([]int{milk.ID, bread.ID})
+		if err != nil {
+			t.Fatalf("GetByProductIDs: %v", err)
+		}
+		if len(images) != 2 || !equalProductImage(images[0], breadImage) || !equalProductImage(images[1], milkImage) {
+			t.Errorf("GetByProductIDs = %+v, want bread then milk image", images)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Roti")
+		bread := mustCreateProduct(t, repos.Products, "Roti Tawar", c.ID)
+		image := mustCreateProductImage(t, repos.ProductImages, bread.ID, "bread")
+
+		if err := repos.ProductImages.Delete(image.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.ProductImages.GetByID(image.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID after Delete: err = %v, want ErrNotFound", err)
+		}
+		if err := repos.ProductImages.Delete(image.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Delete twice: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func mustCreateProductImage(t *testing.T, repo repository.ProductImageRepository, productID int, name string) domain.ProductImage {
+	t.Helper()
+	image := domain.ProductImage{ProductID: productID, Key: "products/" + name + ".jpg", ThumbnailKey: "products/" + name + "_thumb.jpg",
+		ContentType: "image/jpeg", Size: 2048, Width: 640, Height: 480}
+	if err := repo.Create(&image); err != nil {
+		t.Fatalf("create image %q: %v", name, err)
+	}
+	return image
+}
+
+func equalProductImage(a, b domain.ProductImage) bool {
+	return a.ID == b.ID && a.ProductID == b.ProductID && a.Key == b.Key && a.ThumbnailKey == b.ThumbnailKey &&
+		a.ContentType == b.ContentType && a.Size == b.Size && a.Width == b.Width && a.Height == b.Height
+}
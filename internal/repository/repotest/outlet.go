@@ -0,0 +1,111 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunOutletContract verifies OutletRepository behaviour
+func RunOutletContract(t *testing.T, newRepos Factory) {
+	t.Run("default outlet exists", func(t *testing.T) {
+		repo := newRepos(t).Outlets
+		got, err := repo.GetByID(domain.DefaultOutletID)
+		if err != nil {
+			t.Fatalf("GetByID default: %v", err)
+		}
+		if got.Code != "MAIN" {
+			t.Errorf("default outlet = %+v, want code MAIN", got)
+		}
+	})
+
+	t.Run("create, get and list", func(t *testing.T) {
+		repo := newRepos(t).Outlets
+		a := mustCreateOutlet(t, repo, "BDG")
+		b := mustCreateOutlet(t, repo, "JKT")
+		if a.ID == 0 || b.ID == 0 || a.ID == b.ID || a.ID == domain.DefaultOutletID {
+			t.Fatalf("ids = %d, %d, want distinct non-default ids", a.ID, b.ID)
+		}
+
+		got, err := repo.GetByID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != a {
+			t.Errorf("GetByID = %+v, want %+v", *got, a)
+		}
+		all, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 3 || all[0].ID != domain.DefaultOutletID || all[1] != a || all[2] != b {
+			t.Errorf("GetAll = %+v, want the default outlet then %+v and %+v", all, a, b)
+		}
+	})
+
+	t.Run("codes are unique", func(t *testing.T) {
+		repo := newRepos(t).Outlets
+		a := mustCreateOutlet(t, repo, "BDG")
+		b := mustCreateOutlet(t, repo, "JKT")
+		dup := domain.Outlet{Code: "BDG", Name: "Copy"}
+		if err := repo.Create(&dup); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Create with taken code: err = %v, want ErrConflict", err)
+		}
+		b.Code = a.Code
+		if err := repo.Update(&b); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Update to taken code: err = %v, want ErrConflict", err)
+		}
+		a.Name = "Bandung Dago"
+		if err := repo.Update(&a); err != nil {
+			t.Errorf("Update keeping own code: %v", err)
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repo := newRepos(t).Outlets
+		o := mustCreateOutlet(t, repo, "BDG")
+		o.Name, o.Address = "Bandung Dago", "Jl. Dago No. 10"
+		if err := repo.Update(&o); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repo.GetByID(o.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != o {
+			t.Errorf("GetByID after update = %+v, want %+v", *got, o)
+		}
+
+		if err := repo.Delete(o.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(o.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID after delete: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repo := newRepos(t).Outlets
+		if _, err := repo.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Update(&domain.Outlet{ID: 999, Code: "X", Name: "x"}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Delete: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func mustCreateOutlet(t *testing.T, repo repository.OutletRepository, code string) domain.Outlet {
+	t.Helper()
+	o := domain.Outlet{Code: code, Name: "Outlet " + code, Address: "Jl. Merdeka No. 1"}
+	if err := repo.Create(&o); err != nil {
+		t.Fatalf("create outlet %q: %v", code, err)
+	}
+	return o
+}
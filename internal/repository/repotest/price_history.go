@@ -0,0 +1,61 @@
+package repotest
+
+import (
+	"testing"
+
+	"kasir-api/internal/domain"
+)
+
+// RunPriceHistoryContract verifies PriceHistoryRepository behaviour
+func RunPriceHistoryContract(t *testing.T, newRepos Factory) {
+	t.Run("create and list newest first", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		other := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+
+		changes := []struct{ productID, oldPrice, newPrice int }{
+			{p.ID, 3500, 4000},
+			{other.ID, 3500, 9000},
+			{p.ID, 4000, 4500},
+		}
+		for _, ch := range changes {
+			change := newPriceChange(ch.productID, ch.oldPrice, ch.newPrice)
+			if err := repos.PriceHistory.Create(&change); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if change.ID == 0 || change.ChangedAt.IsZero() {
+				t.Fatalf("Create did not set id/changed_at: %+v", change)
+			}
+		}
+
+		got, err := repos.PriceHistory.GetByProductID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("len = %d, want 2", len(got))
+		}
+		if got[0].OldPrice != 4000 || got[0].NewPrice != 4500 || got[1].OldPrice != 3500 || got[1].NewPrice != 4000 {
+			t.Errorf("history = %+v, want newest first", got)
+		}
+		if got[0].ChangedBy != "supervisor" {
+			t.Errorf("ChangedBy = %q, want supervisor", got[0].ChangedBy)
+		}
+	})
+
+	t.Run("list for product without history", func(t *testing.T) {
+		repos := newRepos(t)
+		got, err := repos.PriceHistory.GetByProductID(1)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Fatalf("GetByProductID = %#v, want empty non-nil slice", got)
+		}
+	})
+}
+
+func newPriceChange(productID, oldPrice, newPrice int) domain.PriceChange {
+	return domain.PriceChange{ProductID: productID, OldPrice: oldPrice, NewPrice: newPrice, ChangedBy: "supervisor"}
+}
@@ -0,0 +1,122 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunPromotionContract verifies PromotionRepository behaviour
+func RunPromotionContract(t *testing.T, newRepos Factory) {
+	t.Run("create and get round-trips optional fields", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(0, 1, 0)
+		want := domain.Promotion{
+			Name: "Diskon Snack", Type: domain.PromotionPercentage, Scope: domain.PromotionScopeCategory,
+			CategoryID: &c.ID, Value: 10, Stackable: true, Active: true, StartsAt: &start, EndsAt: &end,
+		}
+		if err := repos.Promotions.Create(&want); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if want.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+
+		got, err := repos.Promotions.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != want.Name || got.Type != want.Type || got.Scope != want.Scope || got.Value != 10 ||
+			!got.Stackable || !got.Active || got.ProductID != nil || got.CategoryID == nil || *got.CategoryID != c.ID {
+			t.Errorf("GetByID = %+v, want %+v", got, want)
+		}
+		if got.StartsAt == nil || !got.StartsAt.Equal(start) || got.EndsAt == nil || !got.EndsAt.Equal(end) {
+			t.Errorf("window = %v..%v, want %v..%v", got.StartsAt, got.EndsAt, start, end)
+		}
+
+		if _, err := repos.Promotions.GetByID(want.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("get active honours flag and window", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+		past, future := now.Add(-time.Hour), now.Add(time.Hour)
+		seed := []domain.Promotion{
+			{Name: "always", Active: true},
+			{Name: "disabled", Active: false},
+			{Name: "started", Active: true, StartsAt: &past},
+			{Name: "not started", Active: true, StartsAt: &future},
+			{Name: "ending", Active: true, EndsAt: &future},
+			{Name: "ended", Active: true, EndsAt: &past},
+			{Name: "ends now", Active: true, EndsAt: &now},
+		}
+		for i := range seed {
+			seed[i].Type, seed[i].Scope, seed[i].Value = domain.PromotionFixedAmount, domain.PromotionScopeCart, 1000
+			if err := repos.Promotions.Create(&seed[i]); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		active, err := repos.Promotions.GetActive(now)
+		if err != nil {
+			t.Fatalf("GetActive: %v", err)
+		}
+		var names []string
+		for _, p := range active {
+			names = append(names, p.Name)
+		}
+		if want := []string{"always", "started", "ending"}; !slices.Equal(names, want) {
+			t.Errorf("GetActive = %v, want %v", names, want)
+		}
+
+		all, err := repos.Promotions.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != len(seed) {
+			t.Errorf("len(GetAll) = %d, want %d", len(all), len(seed))
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repos := newRepos(t)
+		p := domain.Promotion{Name: "Potongan 5rb", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeCart,
+			Value: 5000, MinSubtotal: 50000, Active: true}
+		if err := repos.Promotions.Create(&p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		p.Value = 7500
+		p.Active = false
+		if err := repos.Promotions.Update(&p); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repos.Promotions.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Value != 7500 || got.Active || got.MinSubtotal != 50000 {
+			t.Errorf("after Update = %+v", got)
+		}
+
+		missing := p
+		missing.ID += 1000
+		if err := repos.Promotions.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Update missing: err = %v, want ErrNotFound", err)
+		}
+		if err := repos.Promotions.Delete(p.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Promotions.Delete(p.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Delete twice: err = %v, want ErrNotFound", err)
+		}
+	})
+}
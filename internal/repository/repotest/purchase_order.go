@@ -0,0 +1,133 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunPurchaseOrderContract verifies PurchaseOrderRepository behaviour
+func RunPurchaseOrderContract(t *testing.T, newRepos Factory) {
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("create and get round-trips lines", func(t *testing.T) {
+		repos := newRepos(t)
+		supplier := mustCreateSupplier(t, repos.Suppliers, "PT Indofood")
+		want := domain.PurchaseOrder{
+			SupplierID: supplier.ID, OutletID: domain.DefaultOutletID, Status: domain.PurchaseOrderStatusOrdered, Notes: "Deliver before Lebaran",
+			CreatedAt: createdAt, UpdatedAt: createdAt,
+			Lines: []domain.PurchaseOrderLine{
+				{ProductID: 2, UnitID: 2, Factor: 12, Quantity: 1, UnitCost: 108000},
+				{ProductID: 1, UnitID: domain.DefaultUnitID, Factor: 1, Quantity: 48, UnitCost: 2800},
+			},
+		}
+		if err := repos.PurchaseOrders.Create(&want); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if want.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+
+		got, err := repos.PurchaseOrders.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.SupplierID != supplier.ID || got.OutletID != domain.DefaultOutletID || got.Status != domain.PurchaseOrderStatusOrdered || got.Notes != want.Notes ||
+			!got.CreatedAt.Equal(createdAt) || !got.UpdatedAt.Equal(createdAt) {
+			t.Errorf("GetByID = %+v, want %+v", got, want)
+		}
+		if !slices.Equal(got.Lines, want.Lines) {
+			t.Errorf("lines = %+v, want %+v in order", got.Lines, want.Lines)
+		}
+		locked, err := repos.PurchaseOrders.GetByIDForUpdate(want.ID)
+		if err != nil {
+			t.Fatalf("GetByIDForUpdate: %v", err)
+		}
+		if locked.Status != got.Status || !slices.Equal(locked.Lines, got.Lines) {
+			t.Errorf("GetByIDForUpdate = %+v, want %+v", locked, got)
+		}
+	})
+
+	t.Run("update saves status and received quantities", func(t *testing.T) {
+		repos := newRepos(t)
+		supplier := mustCreateSupplier(t, repos.Suppliers, "PT Indofood")
+		po := domain.PurchaseOrder{
+			SupplierID: supplier.ID, OutletID: domain.DefaultOutletID, Status: domain.PurchaseOrderStatusOrdered, CreatedAt: createdAt, UpdatedAt: createdAt,
+			Lines: []domain.PurchaseOrderLine{{ProductID: 1, Quantity: 48, UnitCost: 2800}, {ProductID: 2, Quantity: 12, UnitCost: 9000}},
+		}
+		if err := repos.PurchaseOrders.Create(&po); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		updatedAt := createdAt.Add(24 * time.Hour)
+		po.Status, po.Notes, po.UpdatedAt = domain.PurchaseOrderStatusPartiallyReceived, "First delivery", updatedAt
+		po.Lines[0].ReceivedQuantity = 24
+		if err := repos.PurchaseOrders.Update(&po); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, err := repos.PurchaseOrders.GetByID(po.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.PurchaseOrderStatusPartiallyReceived || got.Notes != "First delivery" ||
+			!got.UpdatedAt.Equal(updatedAt) || !slices.Equal(got.Lines, po.Lines) {
+			t.Errorf("GetByID = %+v, want %+v", got, po)
+		}
+
+		missing := domain.PurchaseOrder{ID: 999, Status: domain.PurchaseOrderStatusOrdered, UpdatedAt: updatedAt}
+		if err := repos.PurchaseOrders.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("get all filters by status and outlet", func(t *testing.T) {
+		repos := newRepos(t)
+		supplier := mustCreateSupplier(t, repos.Suppliers, "PT Indofood")
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+		statuses := []string{domain.PurchaseOrderStatusOrdered, domain.PurchaseOrderStatusReceived, domain.PurchaseOrderStatusReceived}
+		outlets := []int{domain.DefaultOutletID, domain.DefaultOutletID, branch.ID}
+		for i, status := range statuses {
+			po := domain.PurchaseOrder{
+				SupplierID: supplier.ID, OutletID: outlets[i], Status: status, CreatedAt: createdAt, UpdatedAt: createdAt,
+				Lines: []domain.PurchaseOrderLine{{ProductID: 1, Quantity: 1, UnitCost: 2800}},
+			}
+			if err := repos.PurchaseOrders.Create(&po); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		all, err := repos.PurchaseOrders.GetAll("", 0)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		received, err := repos.PurchaseOrders.GetAll(domain.PurchaseOrderStatusReceived, 0)
+		if err != nil {
+			t.Fatalf("GetAll received: %v", err)
+		}
+		if len(all) != 3 || len(received) != 2 || received[0].ID != 2 || received[1].ID != 3 || len(received[0].Lines) != 1 {
+			t.Errorf("GetAll = %d orders, received = %+v, want 3 and orders 2 and 3", len(all), received)
+		}
+		atBranch, err := repos.PurchaseOrders.GetAll("", branch.ID)
+		if err != nil {
+			t.Fatalf("GetAll at outlet: %v", err)
+		}
+		if len(atBranch) != 1 || atBranch[0].ID != 3 || atBranch[0].OutletID != branch.ID {
+			t.Errorf("GetAll at outlet %d = %+v, want order 3", branch.ID, atBranch)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repos := newRepos(t)
+		if _, err := repos.PurchaseOrders.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repos.PurchaseOrders.GetByIDForUpdate(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate: err = %v, want ErrNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package repotest
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunRefundContract verifies RefundRepository behaviour
+func RunRefundContract(t *testing.T, newRepos Factory) {
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("create and get by cart round-trips lines", func(t *testing.T) {
+		repos := newRepos(t)
+		cart := mustCreateCart(t, repos.Carts, domain.DefaultOutletID)
+		other := mustCreateCart(t, repos.Carts, domain.DefaultOutletID)
+		shift := domain.Shift{Cashier: "budi", OpeningCash: 100000, OpenedAt: createdAt}
+		if err := repos.Shifts.Create(&shift); err != nil {
+			t.Fatalf("create shift: %v", err)
+		}
+		want := domain.Refund{
+			CartID: cart.ID, OutletID: domain.DefaultOutletID, ShiftID: &shift.ID, Restock: true, Reason: "Kemasan rusak",
+			Method: domain.PaymentCash, Amount: 9450, Tax: 936, RefundedBy: "budi", CreatedAt: createdAt,
+			Lines: []domain.RefundLine{
+				{ProductID: 2, UnitID: 2, Quantity: 1, Amount: 6300, Tax: 624, CostPrice: 5600},
+				{ProductID: 1, UnitID: domain.DefaultUnitID, Quantity: 1, Amount: 3150, Tax: 312, CostPrice: 2800},
+			},
+		}
+		for _, f := range []*domain.Refund{&want, {CartID: other.ID, OutletID: domain.DefaultOutletID, CreatedAt: createdAt}} {
+			if err := repos.Refunds.Create(f); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		if want.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+		later := domain.Refund{CartID: cart.ID, OutletID: domain.DefaultOutletID, Amount: 100, CreatedAt: createdAt.Add(time.Hour)}
+		if err := repos.Refunds.Create(&later); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repos.Refunds.GetByCart(cart.ID)
+		if err != nil {
+			t.Fatalf("GetByCart: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != want.ID || got[1].ID != later.ID {
+			t.Fatalf("GetByCart = %+v, want refunds %d then %d", got, want.ID, later.ID)
+		}
+		f := got[0]
+		if f.CartID != cart.ID || f.OutletID != domain.DefaultOutletID || !equalIntPtr(f.ShiftID, &shift.ID) || !f.Restock ||
+			f.Reason != want.Reason || f.Method != domain.PaymentCash || f.Amount != 9450 || f.Tax != 936 ||
+			f.RefundedBy != "budi" || !f.CreatedAt.Equal(createdAt) {
+			t.Errorf("refund = %+v, want %+v", f, want)
+		}
+		if !slices.Equal(f.Lines, want.Lines) {
+			t.Errorf("lines = %+v, want %+v in order", f.Lines, want.Lines)
+		}
+		if got[1].Lines == nil {
+			t.Error("lines of a refund without lines = nil, want empty")
+		}
+	})
+
+	t.Run("shift totals sum a shift's refunds per method", func(t *testing.T) {
+		repos := newRepos(t)
+		cart := mustCreateCart(t, repos.Carts, domain.DefaultOutletID)
+		var shifts []int
+		for _, cashier := range []string{"budi", "siti"} {
+			shift := domain.Shift{Cashier: cashier, OpeningCash: 100000, OpenedAt: createdAt}
+			if err := repos.Shifts.Create(&shift); err != nil {
+				t.Fatalf("create shift: %v", err)
+			}
+			shifts = append(shifts, shift.ID)
+		}
+		refunds := []struct {
+			shiftID *int
+			method  string
+			amount  int
+		}{
+			{&shifts[0], domain.PaymentCash, 3500},
+			{&shifts[0], domain.PaymentCash, 1500},
+			{&shifts[0], domain.PaymentQRIS, 4000},
+			{&shifts[1], domain.PaymentCash, 9000},
+			{nil, domain.PaymentQRIS, 2000},
+		}
+		for _, r := range refunds {
+			f := domain.Refund{CartID: cart.ID, OutletID: domain.DefaultOutletID, ShiftID: r.shiftID, Method: r.method,
+				Amount: r.amount, CreatedAt: createdAt}
+			if err := repos.Refunds.Create(&f); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		got, err := repos.Refunds.ShiftTotals(shifts[0])
+		if err != nil {
+			t.Fatalf("ShiftTotals: %v", err)
+		}
+		if want := map[string]int{domain.PaymentCash: 5000, domain.PaymentQRIS: 4000}; !maps.Equal(got, want) {
+			t.Errorf("ShiftTotals = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("get all filters by outlet and time", func(t *testing.T) {
+		repos := newRepos(t)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+		refunds := []struct {
+			outletID int
+			minutes  int
+		}{
+			{domain.DefaultOutletID, 30},
+			{domain.DefaultOutletID, 10},
+			{branch.ID, 20},
+			{domain.DefaultOutletID, 90},
+		}
+		var ids []int
+		for _, r := range refunds {
+			cart := mustCreateCart(t, repos.Carts, r.outletID)
+			f := domain.Refund{CartID: cart.ID, OutletID: r.outletID, CreatedAt: createdAt.Add(time.Duration(r.minutes) * time.Minute)}
+			if err := repos.Refunds.Create(&f); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids = append(ids, f.ID)
+		}
+
+		got, err := repos.Refunds.GetAll(domain.SalesFilter{
+			OutletID: domain.DefaultOutletID, From: createdAt.Add(10 * time.Minute), To: createdAt.Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != ids[1] || got[1].ID != ids[0] {
+			t.Errorf("GetAll = %+v, want refunds %d then %d", got, ids[1], ids[0])
+		}
+		all, err := repos.Refunds.GetAll(domain.SalesFilter{})
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 4 || all[0].ID != ids[1] || all[3].ID != ids[3] {
+			t.Errorf("GetAll without filter = %+v, want the 4 refunds oldest first", all)
+		}
+	})
+}
+
+func mustCreateCart(t *testing.T, repo repository.CartRepository, outletID int) domain.Cart {
+	t.Helper()
+	at := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	cart := domain.Cart{OutletID: outletID, Status: domain.CartStatusCheckedOut, CreatedAt: at, UpdatedAt: at}
+	if err := repo.Create(&cart); err != nil {
+		t.Fatalf("create cart: %v", err)
+	}
+	return cart
+}
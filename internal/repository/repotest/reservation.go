@@ -0,0 +1,120 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunReservationContract verifies ReservationRepository behaviour
+func RunReservationContract(t *testing.T, newRepos Factory) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	setup := func(t *testing.T) (repository.Repositories, [2]domain.Product, [2]domain.Cart) {
+		t.Helper()
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		products := [2]domain.Product{
+			mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID),
+			mustCreateProduct(t, repos.Products, "Chitato", c.ID),
+		}
+		var carts [2]domain.Cart
+		for i := range carts {
+			carts[i] = domain.Cart{OutletID: domain.DefaultOutletID, Status: domain.CartStatusOpen, ReserveStock: true, CreatedAt: now, UpdatedAt: now}
+			if err := repos.Carts.Create(&carts[i]); err != nil {
+				t.Fatalf("create cart: %v", err)
+			}
+		}
+		return repos, products, carts
+	}
+
+	t.Run("totals skip expired and excluded carts", func(t *testing.T) {
+		repos, products, carts := setup(t)
+		mustReserve(t, repos.Reservations, carts[0].ID,
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[0].ID, Quantity: 3, ExpiresAt: now.Add(time.Minute)},
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[1].ID, Quantity: 1, ExpiresAt: now},
+		)
+		mustReserve(t, repos.Reservations, carts[1].ID,
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[0].ID, Quantity: 2, ExpiresAt: now.Add(time.Hour)},
+		)
+
+		all, err := repos.Reservations.ReservedTotals(now, domain.DefaultOutletID, 0)
+		if err != nil {
+			t.Fatalf("ReservedTotals: %v", err)
+		}
+		if len(all) != 1 || all[products[0].ID] != 5 {
+			t.Errorf("ReservedTotals = %v, want only %d: 5", all, products[0].ID)
+		}
+		others, err := repos.Reservations.ReservedTotals(now, domain.DefaultOutletID, carts[1].ID)
+		if err != nil {
+			t.Fatalf("ReservedTotals: %v", err)
+		}
+		if len(others) != 1 || others[products[0].ID] != 3 {
+			t.Errorf("ReservedTotals excluding cart %d = %v, want %d: 3", carts[1].ID, others, products[0].ID)
+		}
+	})
+
+	t.Run("replace and delete by cart", func(t *testing.T) {
+		repos, products, carts := setup(t)
+		expires := now.Add(time.Minute)
+		mustReserve(t, repos.Reservations, carts[0].ID,
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[0].ID, Quantity: 3, ExpiresAt: expires},
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[1].ID, Quantity: 1, ExpiresAt: expires},
+		)
+		mustReserve(t, repos.Reservations, carts[0].ID,
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[1].ID, Quantity: 4, ExpiresAt: expires},
+		)
+		totals, err := repos.Reservations.ReservedTotals(now, domain.DefaultOutletID, 0)
+		if err != nil {
+			t.Fatalf("ReservedTotals: %v", err)
+		}
+		if len(totals) != 1 || totals[products[1].ID] != 4 {
+			t.Errorf("ReservedTotals after replace = %v, want %d: 4", totals, products[1].ID)
+		}
+
+		if err := repos.Reservations.DeleteByCart(carts[0].ID); err != nil {
+			t.Fatalf("DeleteByCart: %v", err)
+		}
+		totals, err = repos.Reservations.ReservedTotals(now, domain.DefaultOutletID, 0)
+		if err != nil {
+			t.Fatalf("ReservedTotals: %v", err)
+		}
+		if len(totals) != 0 {
+			t.Errorf("ReservedTotals after DeleteByCart = %v, want empty", totals)
+		}
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		repos, products, carts := setup(t)
+		mustReserve(t, repos.Reservations, carts[0].ID,
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[0].ID, Quantity: 3, ExpiresAt: now.Add(-time.Second)},
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[1].ID, Quantity: 1, ExpiresAt: now},
+		)
+		mustReserve(t, repos.Reservations, carts[1].ID,
+			domain.Reservation{OutletID: domain.DefaultOutletID, ProductID: products[0].ID, Quantity: 2, ExpiresAt: now.Add(time.Second)},
+		)
+
+		deleted, err := repos.Reservations.DeleteExpired(now)
+		if err != nil {
+			t.Fatalf("DeleteExpired: %v", err)
+		}
+		if deleted != 2 {
+			t.Errorf("DeleteExpired = %d, want 2", deleted)
+		}
+		totals, err := repos.Reservations.ReservedTotals(now.Add(-time.Hour), domain.DefaultOutletID, 0)
+		if err != nil {
+			t.Fatalf("ReservedTotals: %v", err)
+		}
+		if len(totals) != 1 || totals[products[0].ID] != 2 {
+			t.Errorf("ReservedTotals after DeleteExpired = %v, want %d: 2", totals, products[0].ID)
+		}
+	})
+}
+
+func mustReserve(t *testing.T, repo repository.ReservationRepository, cartID int, reservations ...domain.Reservation) {
+	t.Helper()
+	if err := repo.ReplaceForCart(cartID, reservations); err != nil {
+		t.Fatalf("ReplaceForCart: %v", err)
+	}
+}
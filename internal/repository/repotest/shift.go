@@ -0,0 +1,95 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunShiftContract verifies ShiftRepository behaviour
+func RunShiftContract(t *testing.T, newRepos Factory) {
+	openedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("open and close", func(t *testing.T) {
+		repo := newRepos(t).Shifts
+		shift := domain.Shift{Cashier: "budi", OpeningCash: 200000, OpenedAt: openedAt}
+		if err := repo.Create(&shift); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if shift.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+
+		open, err := repo.GetOpen("budi")
+		if err != nil {
+			t.Fatalf("GetOpen: %v", err)
+		}
+		if open.ID != shift.ID || open.OpeningCash != 200000 || !open.OpenedAt.Equal(openedAt) || !open.IsOpen() ||
+			open.ExpectedCash != nil || open.CountedCash != nil || open.Variance != nil {
+			t.Errorf("GetOpen = %+v, want open shift %d", open, shift.ID)
+		}
+		if locked, err := repo.GetOpenForUpdate("budi"); err != nil || locked.ID != shift.ID {
+			t.Errorf("GetOpenForUpdate = %+v, %v, want shift %d", locked, err, shift.ID)
+		}
+		if locked, err := repo.GetByIDForUpdate(shift.ID); err != nil || locked.ID != shift.ID || !locked.IsOpen() {
+			t.Errorf("GetByIDForUpdate = %+v, %v, want open shift %d", locked, err, shift.ID)
+		}
+
+		closedAt := openedAt.Add(8 * time.Hour)
+		expected, counted, variance := 200000, 199500, -500
+		shift.ClosedAt, shift.ExpectedCash, shift.CountedCash, shift.Variance = &closedAt, &expected, &counted, &variance
+		if err := repo.Close(&shift); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if err := repo.Close(&shift); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Close twice: err = %v, want ErrNotFound", err)
+		}
+
+		got, err := repo.GetByID(shift.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.ClosedAt == nil || !got.ClosedAt.Equal(closedAt) || *got.ExpectedCash != expected ||
+			*got.CountedCash != counted || *got.Variance != variance {
+			t.Errorf("GetByID = %+v, want closed with %d/%d/%d", got, expected, counted, variance)
+		}
+		if _, err := repo.GetOpen("budi"); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetOpen after Close: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetOpenForUpdate("budi"); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetOpenForUpdate after Close: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("one open shift per cashier", func(t *testing.T) {
+		repo := newRepos(t).Shifts
+		first := domain.Shift{Cashier: "budi", OpeningCash: 100000, OpenedAt: openedAt}
+		if err := repo.Create(&first); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		second := domain.Shift{Cashier: "budi", OpeningCash: 100000, OpenedAt: openedAt}
+		if err := repo.Create(&second); !errors.Is(err, apperrors.ErrConflict) {
+			t.Fatalf("Create second: err = %v, want ErrConflict", err)
+		}
+		other := domain.Shift{Cashier: "siti", OpeningCash: 100000, OpenedAt: openedAt}
+		if err := repo.Create(&other); err != nil {
+			t.Fatalf("Create for another cashier: %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repo := newRepos(t).Shifts
+		if _, err := repo.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetByIDForUpdate(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetOpen("nobody"); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetOpen: err = %v, want ErrNotFound", err)
+		}
+	})
+}
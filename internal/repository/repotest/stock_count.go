@@ -0,0 +1,153 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunStockCountContract verifies StockCountRepository behaviour
+func RunStockCountContract(t *testing.T, newRepos Factory) {
+	createdAt := time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC)
+
+	t.Run("create, count, update and get", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		a := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		b := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+		count := domain.StockCount{
+			OutletID: domain.DefaultOutletID, CategoryID: &c.ID, Status: domain.StockCountStatusCounting,
+			Notes: "Month-end count", CreatedBy: "budi", CreatedAt: createdAt, UpdatedAt: createdAt,
+			Lines: []domain.StockCountLine{
+				{ProductID: b.ID, ProductName: b.Name, SystemQuantity: 12},
+				{ProductID: a.ID, ProductName: a.Name, SystemQuantity: 100},
+			},
+		}
+		if err := repos.StockCounts.Create(&count); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if count.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+
+		got, err := repos.StockCounts.GetByID(count.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.OutletID != domain.DefaultOutletID || got.CategoryID == nil || *got.CategoryID != c.ID ||
+			got.Status != domain.StockCountStatusCounting || got.Notes != count.Notes || got.CreatedBy != "budi" ||
+			!got.CreatedAt.Equal(createdAt) {
+			t.Errorf("GetByID = %+v, want %+v", got, count)
+		}
+		locked, err := repos.StockCounts.GetByIDForUpdate(count.ID)
+		if err != nil {
+			t.Fatalf("GetByIDForUpdate: %v", err)
+		}
+		if locked.Status != got.Status || len(locked.Lines) != len(got.Lines) {
+			t.Errorf("GetByIDForUpdate = %+v, want %+v", locked, got)
+		}
+		if len(got.Lines) != 2 || got.Lines[0].ProductID != b.ID || got.Lines[0].ProductName != b.Name ||
+			got.Lines[0].SystemQuantity != 12 || got.Lines[0].CountedQuantity != nil || got.Lines[1].ProductID != a.ID {
+			t.Errorf("lines = %+v, want Chitato then Indomie Goreng, uncounted", got.Lines)
+		}
+		if got.Entries == nil || len(got.Entries) != 0 {
+			t.Errorf("entries = %#v, want empty non-nil slice", got.Entries)
+		}
+
+		for i, quantity := range []int{60, 37} {
+			entry := domain.StockCountEntry{ProductID: a.ID, Quantity: quantity, CountedBy: "siti",
+				CountedAt: createdAt.Add(time.Duration(i+1) * time.Minute)}
+			if err := repos.StockCounts.AddEntry(count.ID, &entry); err != nil {
+				t.Fatalf("AddEntry: %v", err)
+			}
+			if entry.ID == 0 {
+				t.Fatal("AddEntry did not set id")
+			}
+		}
+		if err := repos.StockCounts.AddEntry(count.ID+1000, &domain.StockCountEntry{ProductID: a.ID, Quantity: 1,
+			CountedAt: createdAt}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("AddEntry to missing count: err = %v, want ErrNotFound", err)
+		}
+
+		counted := 97
+		updatedAt := createdAt.Add(2 * time.Hour)
+		got.Lines[1].SystemQuantity, got.Lines[1].CountedQuantity = 98, &counted
+		got.Status, got.ApprovedBy, got.UpdatedAt = domain.StockCountStatusApproved, "budi", updatedAt
+		if err := repos.StockCounts.Update(got); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		got, err = repos.StockCounts.GetByID(count.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.StockCountStatusApproved || got.ApprovedBy != "budi" || !got.UpdatedAt.Equal(updatedAt) {
+			t.Errorf("GetByID after update = %+v, want approved by budi at %v", got, updatedAt)
+		}
+		line := got.Lines[1]
+		if line.SystemQuantity != 98 || line.CountedQuantity == nil || *line.CountedQuantity != 97 || got.Lines[0].CountedQuantity != nil {
+			t.Errorf("lines after update = %+v, want 97 counted against 98 for the second line only", got.Lines)
+		}
+		if len(got.Entries) != 2 || got.Entries[0].Quantity != 60 || got.Entries[1].Quantity != 37 ||
+			got.Entries[0].CountedBy != "siti" || !got.Entries[1].CountedAt.Equal(createdAt.Add(2*time.Minute)) {
+			t.Errorf("entries = %+v, want 60 then 37 by siti", got.Entries)
+		}
+
+		if _, err := repos.StockCounts.GetByID(count.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID missing: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repos.StockCounts.GetByIDForUpdate(count.ID + 1000); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate missing: err = %v, want ErrNotFound", err)
+		}
+		missing := domain.StockCount{ID: count.ID + 1000, Status: domain.StockCountStatusCancelled, UpdatedAt: updatedAt}
+		if err := repos.StockCounts.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("get all filters by outlet and status", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+		for _, seed := range []struct {
+			outletID int
+			status   string
+		}{
+			{domain.DefaultOutletID, domain.StockCountStatusApproved},
+			{branch.ID, domain.StockCountStatusCounting},
+			{domain.DefaultOutletID, domain.StockCountStatusCounting},
+		} {
+			count := domain.StockCount{OutletID: seed.outletID, Status: seed.status, CreatedAt: createdAt, UpdatedAt: createdAt,
+				Lines: []domain.StockCountLine{{ProductID: p.ID, ProductName: p.Name, SystemQuantity: 100}}}
+			if err := repos.StockCounts.Create(&count); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		all, err := repos.StockCounts.GetAll(0, "")
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 3 || all[0].ID > all[1].ID || all[1].ID > all[2].ID || len(all[0].Lines) != 1 || all[0].CategoryID != nil {
+			t.Errorf("GetAll = %+v, want 3 counts in ID order with their lines", all)
+		}
+		atOutlet, err := repos.StockCounts.GetAll(domain.DefaultOutletID, "")
+		if err != nil {
+			t.Fatalf("GetAll at outlet: %v", err)
+		}
+		if len(atOutlet) != 2 {
+			t.Errorf("GetAll at outlet = %+v, want 2", atOutlet)
+		}
+		counting, err := repos.StockCounts.GetAll(domain.DefaultOutletID, domain.StockCountStatusCounting)
+		if err != nil {
+			t.Fatalf("GetAll counting at outlet: %v", err)
+		}
+		if len(counting) != 1 || counting[0].Status != domain.StockCountStatusCounting || counting[0].OutletID != domain.DefaultOutletID {
+			t.Errorf("GetAll counting at outlet = %+v, want 1", counting)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"kasir-api/internal/domain"
+)
+
+// RunStockMovementContract verifies StockMovementRepository behaviour
+func RunStockMovementContract(t *testing.T, newRepos Factory) {
+	t.Run("movements are listed per product newest first", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		a := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		b := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+
+		at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		var created []domain.StockMovement
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+		for i, productID := range []int{a.ID, b.ID, a.ID} {
+			outletID := domain.DefaultOutletID
+			if i == 2 {
+				outletID = branch.ID
+			}
+			m := domain.StockMovement{
+				ProductID: productID, OutletID: outletID, Quantity: 24, Reason: domain.StockMovementPurchase, Reference: "PO-1",
+				Actor: "budi", CreatedAt: at.Add(time.Duration(i) * time.Minute),
+			}
+			if err := repos.StockMovements.Create(&m); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if m.ID == 0 {
+				t.Fatal("Create did not set id")
+			}
+			created = append(created, m)
+		}
+
+		got, err := repos.StockMovements.GetByProductID(a.ID, 0)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		if len(got) != 2 || !equalMovement(got[0], created[2]) || !equalMovement(got[1], created[0]) {
+			t.Errorf("GetByProductID = %+v, want %+v then %+v", got, created[2], created[0])
+		}
+		atBranch, err := repos.StockMovements.GetByProductID(a.ID, branch.ID)
+		if err != nil {
+			t.Fatalf("GetByProductID at outlet: %v", err)
+		}
+		if len(atBranch) != 1 || !equalMovement(atBranch[0], created[2]) {
+			t.Errorf("GetByProductID at outlet %d = %+v, want %+v", branch.ID, atBranch, created[2])
+		}
+
+		empty, err := repos.StockMovements.GetByProductID(999, 0)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Errorf("GetByProductID unknown product = %#v, want empty non-nil slice", empty)
+		}
+	})
+}
+
+func equalMovement(a, b domain.StockMovement) bool {
+	return a.ID == b.ID && a.ProductID == b.ProductID && a.OutletID == b.OutletID && a.Quantity == b.Quantity && a.Reason == b.Reason &&
+		a.Reference == b.Reference && a.Actor == b.Actor && a.CreatedAt.Equal(b.CreatedAt)
+}
@@ -0,0 +1,125 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunStockTransferContract verifies StockTransferRepository behaviour
+func RunStockTransferContract(t *testing.T, newRepos Factory) {
+	createdAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("create and get round-trips lines", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		a := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		b := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+		want := domain.StockTransfer{
+			FromOutletID: domain.DefaultOutletID, ToOutletID: branch.ID, Status: domain.StockTransferStatusInTransit,
+			Notes: "Weekly restock", CreatedBy: "budi", CreatedAt: createdAt, UpdatedAt: createdAt,
+			Lines: []domain.StockTransferLine{{ProductID: b.ID, Quantity: 6}, {ProductID: a.ID, Quantity: 24}},
+		}
+		if err := repos.StockTransfers.Create(&want); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if want.ID == 0 {
+			t.Fatal("Create did not set id")
+		}
+
+		got, err := repos.StockTransfers.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.FromOutletID != want.FromOutletID || got.ToOutletID != branch.ID || got.Status != want.Status ||
+			got.Notes != want.Notes || got.CreatedBy != "budi" || !got.CreatedAt.Equal(createdAt) {
+			t.Errorf("GetByID = %+v, want %+v", got, want)
+		}
+		if !slices.Equal(got.Lines, want.Lines) {
+			t.Errorf("lines = %+v, want %+v in order", got.Lines, want.Lines)
+		}
+		locked, err := repos.StockTransfers.GetByIDForUpdate(want.ID)
+		if err != nil {
+			t.Fatalf("GetByIDForUpdate: %v", err)
+		}
+		if locked.Status != got.Status || !slices.Equal(locked.Lines, got.Lines) {
+			t.Errorf("GetByIDForUpdate = %+v, want %+v", locked, got)
+		}
+	})
+
+	t.Run("update saves status", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		branch := mustCreateOutlet(t, repos.Outlets, "BR1")
+		tr := domain.StockTransfer{
+			FromOutletID: domain.DefaultOutletID, ToOutletID: branch.ID, Status: domain.StockTransferStatusInTransit,
+			CreatedAt: createdAt, UpdatedAt: createdAt, Lines: []domain.StockTransferLine{{ProductID: p.ID, Quantity: 24}},
+		}
+		if err := repos.StockTransfers.Create(&tr); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		updatedAt := createdAt.Add(6 * time.Hour)
+		tr.Status, tr.UpdatedAt = domain.StockTransferStatusReceived, updatedAt
+		if err := repos.StockTransfers.Update(&tr); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repos.StockTransfers.GetByID(tr.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Status != domain.StockTransferStatusReceived || !got.UpdatedAt.Equal(updatedAt) || len(got.Lines) != 1 {
+			t.Errorf("GetByID after update = %+v, want received at %v with its line", got, updatedAt)
+		}
+	})
+
+	t.Run("get all filters by either outlet", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		p := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		a := mustCreateOutlet(t, repos.Outlets, "BR1")
+		b := mustCreateOutlet(t, repos.Outlets, "BR2")
+		routes := [][2]int{{domain.DefaultOutletID, a.ID}, {a.ID, b.ID}, {b.ID, domain.DefaultOutletID}}
+		for _, route := range routes {
+			tr := domain.StockTransfer{
+				FromOutletID: route[0], ToOutletID: route[1], Status: domain.StockTransferStatusInTransit,
+				CreatedAt: createdAt, UpdatedAt: createdAt, Lines: []domain.StockTransferLine{{ProductID: p.ID, Quantity: 1}},
+			}
+			if err := repos.StockTransfers.Create(&tr); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		all, err := repos.StockTransfers.GetAll(0)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		atA, err := repos.StockTransfers.GetAll(a.ID)
+		if err != nil {
+			t.Fatalf("GetAll at outlet: %v", err)
+		}
+		if len(all) != 3 || len(atA) != 2 || atA[0].ID != 1 || atA[1].ID != 2 || len(atA[0].Lines) != 1 {
+			t.Errorf("GetAll = %d transfers, at outlet %d = %+v, want 3 and transfers 1 and 2", len(all), a.ID, atA)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repos := newRepos(t)
+		if _, err := repos.StockTransfers.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if _, err := repos.StockTransfers.GetByIDForUpdate(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByIDForUpdate: err = %v, want ErrNotFound", err)
+		}
+		missing := domain.StockTransfer{ID: 999, Status: domain.StockTransferStatusReceived, UpdatedAt: createdAt}
+		if err := repos.StockTransfers.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update: err = %v, want ErrNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunSupplierContract verifies SupplierRepository behaviour
+func RunSupplierContract(t *testing.T, newRepos Factory) {
+	t.Run("create, get and list", func(t *testing.T) {
+		repo := newRepos(t).Suppliers
+		empty, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetAll on empty repo = %#v, want empty non-nil slice", empty)
+		}
+
+		a := mustCreateSupplier(t, repo, "PT Indofood")
+		b := mustCreateSupplier(t, repo, "CV Sumber Rejeki")
+		if a.ID == 0 || b.ID == 0 || a.ID == b.ID {
+			t.Fatalf("ids = %d, %d, want distinct non-zero", a.ID, b.ID)
+		}
+
+		got, err := repo.GetByID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != a {
+			t.Errorf("GetByID = %+v, want %+v", *got, a)
+		}
+		all, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 2 || all[0] != a || all[1] != b {
+			t.Errorf("GetAll = %+v, want [%+v %+v]", all, a, b)
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repo := newRepos(t).Suppliers
+		s := mustCreateSupplier(t, repo, "PT Indofood")
+		s.Name, s.Phone = "PT Indofood Sukses Makmur", "021-555-0100"
+		if err := repo.Update(&s); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repo.GetByID(s.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != s {
+			t.Errorf("GetByID after update = %+v, want %+v", *got, s)
+		}
+
+		if err := repo.Delete(s.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(s.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID after delete: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repo := newRepos(t).Suppliers
+		if _, err := repo.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Update(&domain.Supplier{ID: 999, Name: "x"}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Delete: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func mustCreateSupplier(t *testing.T, repo repository.SupplierRepository, name string) domain.Supplier {
+	t.Helper()
+	s := domain.Supplier{Name: name, Phone: "021-555-0100", Email: "sales@example.com", Address: "Jakarta"}
+	if err := repo.Create(&s); err != nil {
+		t.Fatalf("create supplier %q: %v", name, err)
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+// RunTaxRateContract verifies TaxRateRepository behaviour and that products
+// and categories keep their tax rate assignment
+func RunTaxRateContract(t *testing.T, newRepos Factory) {
+	t.Run("crud", func(t *testing.T) {
+		repo := newRepos(t).TaxRates
+		rate := domain.TaxRate{Name: "PPN", Rate: 11, Inclusive: true}
+		if err := repo.Create(&rate); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		other := domain.TaxRate{Name: "PB1", Rate: 10.5}
+		if err := repo.Create(&other); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := repo.GetByID(rate.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != rate {
+			t.Errorf("GetByID = %+v, want %+v", *got, rate)
+		}
+
+		other.Rate = 12.25
+		if err := repo.Update(&other); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		all, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 2 || all[0] != rate || all[1] != other {
+			t.Errorf("GetAll = %+v, want [%+v %+v]", all, rate, other)
+		}
+
+		if err := repo.Delete(other.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(other.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("GetByID after Delete: err = %v, want ErrNotFound", err)
+		}
+		missing := domain.TaxRate{ID: other.ID, Name: "Ghost", Rate: 1}
+		if err := repo.Update(&missing); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Update missing: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("assigned to category and product", func(t *testing.T) {
+		repos := newRepos(t)
+		rate := domain.TaxRate{Name: "PPN", Rate: 11}
+		if err := repos.TaxRates.Create(&rate); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		c := domain.Category{Name: "Minuman", TaxRateID: &rate.ID}
+		if err := repos.Categories.Create(&c); err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+		p := domain.Product{Name: "Teh Botol", Type: domain.ProductTypeStandard, Price: 5000, CategoryID: c.ID, TaxRateID: &rate.ID, UnitID: domain.DefaultUnitID}
+		if err := repos.Products.Create(&p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+
+		got, err := repos.Products.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		assertProduct(t, *got, p, c)
+	})
+}
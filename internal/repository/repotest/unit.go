@@ -0,0 +1,162 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// RunUnitContract verifies UnitRepository behaviour
+func RunUnitContract(t *testing.T, newRepos Factory) {
+	t.Run("piece unit exists", func(t *testing.T) {
+		repo := newRepos(t).Units
+		got, err := repo.GetByID(domain.DefaultUnitID)
+		if err != nil {
+			t.Fatalf("GetByID default: %v", err)
+		}
+		if got.Code != "pcs" {
+			t.Errorf("default unit = %+v, want code pcs", got)
+		}
+	})
+
+	t.Run("create, get and list", func(t *testing.T) {
+		repo := newRepos(t).Units
+		a := mustCreateUnit(t, repo, "dus")
+		b := mustCreateUnit(t, repo, "pak")
+		if a.ID == 0 || b.ID == 0 || a.ID == b.ID || a.ID == domain.DefaultUnitID {
+			t.Fatalf("ids = %d, %d, want distinct non-default ids", a.ID, b.ID)
+		}
+
+		got, err := repo.GetByID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != a {
+			t.Errorf("GetByID = %+v, want %+v", *got, a)
+		}
+		all, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(all) != 3 || all[0].ID != domain.DefaultUnitID || all[1] != a || all[2] != b {
+			t.Errorf("GetAll = %+v, want the piece unit then %+v and %+v", all, a, b)
+		}
+	})
+
+	t.Run("codes are unique", func(t *testing.T) {
+		repo := newRepos(t).Units
+		a := mustCreateUnit(t, repo, "dus")
+		b := mustCreateUnit(t, repo, "pak")
+		dup := domain.Unit{Code: "dus", Name: "Copy"}
+		if err := repo.Create(&dup); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Create with taken code: err = %v, want ErrConflict", err)
+		}
+		b.Code = a.Code
+		if err := repo.Update(&b); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Update to taken code: err = %v, want ErrConflict", err)
+		}
+		a.Name = "Kardus"
+		if err := repo.Update(&a); err != nil {
+			t.Errorf("Update keeping own code: %v", err)
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repo := newRepos(t).Units
+		u := mustCreateUnit(t, repo, "dus")
+		u.Code, u.Name = "ctn", "Carton"
+		if err := repo.Update(&u); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		got, err := repo.GetByID(u.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != u {
+			t.Errorf("GetByID after update = %+v, want %+v", *got, u)
+		}
+
+		if err := repo.Delete(u.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(u.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID after delete: err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		repo := newRepos(t).Units
+		if _, err := repo.GetByID(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("GetByID: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Update(&domain.Unit{ID: 999, Code: "x", Name: "x"}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Update: err = %v, want ErrNotFound", err)
+		}
+		if err := repo.Delete(999); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Delete: err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// RunProductUnitContract verifies ProductUnitRepository behaviour
+func RunProductUnitContract(t *testing.T, newRepos Factory) {
+	t.Run("replace and get in unit order", func(t *testing.T) {
+		repos := newRepos(t)
+		c := mustCreateCategory(t, repos.Categories, "Makanan Ringan")
+		a := mustCreateProduct(t, repos.Products, "Indomie Goreng", c.ID)
+		b := mustCreateProduct(t, repos.Products, "Chitato", c.ID)
+		carton := mustCreateUnit(t, repos.Units, "dus")
+		pack := mustCreateUnit(t, repos.Units, "pak")
+
+		empty, err := repos.ProductUnits.GetByProductID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("GetByProductID without units = %#v, want empty non-nil slice", empty)
+		}
+
+		units := []domain.ProductUnit{{UnitID: pack.ID, Factor: 5, Price: 0}, {UnitID: carton.ID, Factor: 40, Price: 130000}}
+		if err := repos.ProductUnits.ReplaceForProduct(a.ID, units); err != nil {
+			t.Fatalf("ReplaceForProduct: %v", err)
+		}
+		if err := repos.ProductUnits.ReplaceForProduct(b.ID, units[:1]); err != nil {
+			t.Fatalf("ReplaceForProduct other product: %v", err)
+		}
+		got, err := repos.ProductUnits.GetByProductID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		want := []domain.ProductUnit{units[1], units[0]}
+		if !slices.Equal(got, want) {
+			t.Errorf("GetByProductID = %+v, want %+v", got, want)
+		}
+
+		if err := repos.ProductUnits.ReplaceForProduct(a.ID, units[1:]); err != nil {
+			t.Fatalf("ReplaceForProduct again: %v", err)
+		}
+		got, err = repos.ProductUnits.GetByProductID(a.ID)
+		if err != nil {
+			t.Fatalf("GetByProductID: %v", err)
+		}
+		if !slices.Equal(got, units[1:]) {
+			t.Errorf("GetByProductID after replace = %+v, want %+v", got, units[1:])
+		}
+		if got, err := repos.ProductUnits.GetByProductID(b.ID); err != nil || !slices.Equal(got, units[:1]) {
+			t.Errorf("other product = %+v, %v, want %+v", got, err, units[:1])
+		}
+	})
+}
+
+func mustCreateUnit(t *testing.T, repo repository.UnitRepository, code string) domain.Unit {
+	t.Helper()
+	u := domain.Unit{Code: code, Name: "Unit " + code}
+	if err := repo.Create(&u); err != nil {
+		t.Fatalf("create unit %q: %v", code, err)
+	}
+	return u
+}
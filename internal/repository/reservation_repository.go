@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"kasir-api/internal/domain"
+)
+
+type reservationRepository struct {
+	db DBTX
+}
+
+// NewReservationRepository creates a new stock reservation repository
+func NewReservationRepository(db DBTX) ReservationRepository {
+	return &reservationRepository{db: db}
+}
+
+func (r *reservationRepository) ReplaceForCart(cartID int, reservations []domain.Reservation) error {
+	if err := r.DeleteByCart(cartID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO stock_reservations (cart_id, outlet_id, product_id, quantity, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, res := range reservations {
+		if _, err := r.db.Exec(query, cartID, res.OutletID, res.ProductID, res.Quantity, res.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *reservationRepository) DeleteByCart(cartID int) error {
+	_, err := r.db.Exec("DELETE FROM stock_reservations WHERE cart_id = $1", cartID)
+	return err
+}
+
+func (r *reservationRepository) ReservedTotals(at time.Time, outletID, excludeCartID int) (map[int]int, error) {
+	query := `
+		SELECT product_id, SUM(quantity)
+		FROM stock_reservations
+		WHERE expires_at > $1 AND outlet_id = $2 AND cart_id <> $3
+		GROUP BY product_id
+	`
+	rows, err := r.db.Query(query, at, outletID, excludeCartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[int]int)
+	for rows.Next() {
+		var productID, quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		totals[productID] = quantity
+	}
+	return totals, rows.Err()
+}
+
+func (r *reservationRepository) DeleteExpired(at time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM stock_reservations WHERE expires_at <= $1", at)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for a duplicate key
+const uniqueViolation = "23505"
+
+type shiftRepository struct {
+	db DBTX
+}
+
+// NewShiftRepository creates a new shift repository
+func NewShiftRepository(db DBTX) ShiftRepository {
+	return &shiftRepository{db: db}
+}
+
+const shiftColumns = "id, cashier, opening_cash, opened_at, closed_at, expected_cash, counted_cash, variance"
+
+// Create inserts an open shift. It fails with ErrConflict if the cashier
+// already has one open.
+func (r *shiftRepository) Create(shift *domain.Shift) error {
+	query := "INSERT INTO shifts (cashier, opening_cash, opened_at) VALUES ($1, $2, $3) RETURNING id"
+	err := r.db.QueryRow(query, shift.Cashier, shift.OpeningCash, shift.OpenedAt).Scan(&shift.ID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrConflict
+	}
+	return err
+}
+
+func (r *shiftRepository) GetByID(id int) (*domain.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE id = $1"
+	return r.get(query, id)
+}
+
+func (r *shiftRepository) GetByIDForUpdate(id int) (*domain.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE id = $1 FOR UPDATE"
+	return r.get(query, id)
+}
+
+func (r *shiftRepository) GetOpen(cashier string) (*domain.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE cashier = $1 AND closed_at IS NULL"
+	return r.get(query, cashier)
+}
+
+// GetOpenForUpdate re-checks closed_at once it holds the lock, so waiting
+// behind a Close that commits finds no open shift
+func (r *shiftRepository) GetOpenForUpdate(cashier string) (*domain.Shift, error) {
+	query := "SELECT " + shiftColumns + " FROM shifts WHERE cashier = $1 AND closed_at IS NULL FOR UPDATE"
+	return r.get(query, cashier)
+}
+
+func (r *shiftRepository) Close(shift *domain.Shift) error {
+	query := `
+		UPDATE shifts
+		SET closed_at = $1, expected_cash = $2, counted_cash = $3, variance = $4
+		WHERE id = $5 AND closed_at IS NULL
+	`
+	result, err := r.db.Exec(query, shift.ClosedAt, shift.ExpectedCash, shift.CountedCash, shift.Variance, shift.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *shiftRepository) get(query string, arg any) (*domain.Shift, error) {
+	var s domain.Shift
+	err := r.db.QueryRow(query, arg).Scan(&s.ID, &s.Cashier, &s.OpeningCash, &s.OpenedAt, &s.ClosedAt,
+		&s.ExpectedCash, &s.CountedCash, &s.Variance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+const stockBatchSelect = `
+	SELECT b.id, b.product_id, p.name, b.outlet_id, b.lot_number, b.expires_at, b.quantity, b.remaining,
+		b.created_at
+	FROM stock_batches b
+	JOIN products p ON p.id = b.product_id`
+
+// fefoOrder sorts batches first expired first out
+const fefoOrder = " ORDER BY b.expires_at ASC NULLS LAST, b.id"
+
+type stockBatchRepository struct {
+	db DBTX
+}
+
+// NewStockBatchRepository creates a new stock batch repository
+func NewStockBatchRepository(db DBTX) StockBatchRepository {
+	return &stockBatchRepository{db: db}
+}
+
+func (r *stockBatchRepository) Create(batch *domain.StockBatch) error {
+	query := `
+		INSERT INTO stock_batches (product_id, outlet_id, lot_number, expires_at, quantity, remaining, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	b := batch
+	return r.db.QueryRow(query, b.ProductID, b.OutletID, b.LotNumber, b.ExpiresAt, b.Quantity, b.Remaining,
+		b.CreatedAt).Scan(&batch.ID)
+}
+
+func (r *stockBatchRepository) GetByID(id int) (*domain.StockBatch, error) {
+	b, err := scanStockBatch(r.db.QueryRow(stockBatchSelect+" WHERE b.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *stockBatchRepository) GetInStock(productID, outletID int) ([]domain.StockBatch, error) {
+	query := stockBatchSelect + " WHERE b.product_id = $1 AND b.outlet_id = $2 AND b.remaining > 0" + fefoOrder
+	return r.list(query, productID, outletID)
+}
+
+func (r *stockBatchRepository) GetExpiring(before time.Time, outletID int) ([]domain.StockBatch, error) {
+	query := stockBatchSelect + " WHERE b.expires_at < $1 AND ($2 = 0 OR b.outlet_id = $2) AND b.remaining > 0" + fefoOrder
+	return r.list(query, before, outletID)
+}
+
+func (r *stockBatchRepository) Take(id, quantity int) error {
+	result, err := r.db.Exec("UPDATE stock_batches SET remaining = remaining - $2 WHERE id = $1 AND remaining >= $2",
+		id, quantity)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return apperrors.ErrInsufficientStock
+	}
+	return nil
+}
+
+func (r *stockBatchRepository) list(query string, args ...any) ([]domain.StockBatch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := make([]domain.StockBatch, 0)
+	for rows.Next() {
+		b, err := scanStockBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+func scanStockBatch(row rowScanner) (domain.StockBatch, error) {
+	var b domain.StockBatch
+	var expiresAt sql.NullTime
+	err := row.Scan(&b.ID, &b.ProductID, &b.ProductName, &b.OutletID, &b.LotNumber, &expiresAt, &b.Quantity,
+		&b.Remaining, &b.CreatedAt)
+	if expiresAt.Valid {
+		b.ExpiresAt = &expiresAt.Time
+	}
+	return b, err
+}
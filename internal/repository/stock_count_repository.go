@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type stockCountRepository struct {
+	db DBTX
+}
+
+// NewStockCountRepository creates a new stock count repository. Create and
+// Update write the lines in several statements, so they should run inside a
+// transaction.
+func NewStockCountRepository(db DBTX) StockCountRepository {
+	return &stockCountRepository{db: db}
+}
+
+const stockCountColumns = "id, outlet_id, category_id, status, notes, created_by, approved_by, created_at, updated_at"
+
+func (r *stockCountRepository) GetAll(outletID int, status string) ([]domain.StockCount, error) {
+	query := "SELECT " + stockCountColumns + ` FROM stock_counts
+		WHERE ($1 = 0 OR outlet_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY id`
+	rows, err := r.db.Query(query, outletID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]domain.StockCount, 0)
+	for rows.Next() {
+		c, err := scanStockCount(rows)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range counts {
+		if err := r.loadDetails(&counts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+func (r *stockCountRepository) Create(count *domain.StockCount) error {
+	query := `
+		INSERT INTO stock_counts (outlet_id, category_id, status, notes, created_by, approved_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	c := count
+	err := r.db.QueryRow(query, c.OutletID, c.CategoryID, c.Status, c.Notes, c.CreatedBy, c.ApprovedBy, c.CreatedAt,
+		c.UpdatedAt).Scan(&count.ID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO stock_count_lines (count_id, position, product_id, product_name, system_quantity, counted_quantity)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for i, line := range count.Lines {
+		if _, err := r.db.Exec(query, count.ID, i, line.ProductID, line.ProductName, line.SystemQuantity,
+			line.CountedQuantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *stockCountRepository) GetByID(id int) (*domain.StockCount, error) {
+	return r.get("SELECT "+stockCountColumns+" FROM stock_counts WHERE id = $1", id)
+}
+
+func (r *stockCountRepository) GetByIDForUpdate(id int) (*domain.StockCount, error) {
+	return r.get("SELECT "+stockCountColumns+" FROM stock_counts WHERE id = $1 FOR UPDATE", id)
+}
+
+func (r *stockCountRepository) get(query string, id int) (*domain.StockCount, error) {
+	c, err := scanStockCount(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadDetails(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *stockCountRepository) Update(count *domain.StockCount) error {
+	query := "UPDATE stock_counts SET status = $1, approved_by = $2, updated_at = $3 WHERE id = $4"
+	result, err := r.db.Exec(query, count.Status, count.ApprovedBy, count.UpdatedAt, count.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	query = `
+		UPDATE stock_count_lines SET system_quantity = $1, counted_quantity = $2
+		WHERE count_id = $3 AND position = $4
+	`
+	for i, line := range count.Lines {
+		if _, err := r.db.Exec(query, line.SystemQuantity, line.CountedQuantity, count.ID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *stockCountRepository) AddEntry(countID int, entry *domain.StockCountEntry) error {
+	query := `
+		INSERT INTO stock_count_entries (count_id, product_id, quantity, counted_by, counted_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := r.db.QueryRow(query, countID, entry.ProductID, entry.Quantity, entry.CountedBy, entry.CountedAt).Scan(&entry.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// loadDetails reads a count's lines and entries
+func (r *stockCountRepository) loadDetails(count *domain.StockCount) error {
+	query := `
+		SELECT product_id, product_name, system_quantity, counted_quantity
+		FROM stock_count_lines WHERE count_id = $1 ORDER BY position
+	`
+	rows, err := r.db.Query(query, count.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count.Lines = make([]domain.StockCountLine, 0)
+	for rows.Next() {
+		var line domain.StockCountLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.SystemQuantity, &line.CountedQuantity); err != nil {
+			return err
+		}
+		count.Lines = append(count.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	query = `
+		SELECT id, product_id, quantity, counted_by, counted_at
+		FROM stock_count_entries WHERE count_id = $1 ORDER BY id
+	`
+	entryRows, err := r.db.Query(query, count.ID)
+	if err != nil {
+		return err
+	}
+	defer entryRows.Close()
+
+	count.Entries = make([]domain.StockCountEntry, 0)
+	for entryRows.Next() {
+		var e domain.StockCountEntry
+		if err := entryRows.Scan(&e.ID, &e.ProductID, &e.Quantity, &e.CountedBy, &e.CountedAt); err != nil {
+			return err
+		}
+		count.Entries = append(count.Entries, e)
+	}
+	return entryRows.Err()
+}
+
+func scanStockCount(row rowScanner) (domain.StockCount, error) {
+	var c domain.StockCount
+	err := row.Scan(&c.ID, &c.OutletID, &c.CategoryID, &c.Status, &c.Notes, &c.CreatedBy, &c.ApprovedBy, &c.CreatedAt,
+		&c.UpdatedAt)
+	return c, err
+}
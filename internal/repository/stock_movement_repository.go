@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"kasir-api/internal/domain"
+)
+
+type stockMovementRepository struct {
+	db DBTX
+}
+
+// NewStockMovementRepository creates a new stock ledger repository
+func NewStockMovementRepository(db DBTX) StockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+
+func (r *stockMovementRepository) Create(movement *domain.StockMovement) error {
+	query := `
+		INSERT INTO stock_movements (product_id, outlet_id, quantity, reason, reference, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	m := movement
+	return r.db.QueryRow(query, m.ProductID, m.OutletID, m.Quantity, m.Reason, m.Reference, m.Actor,
+		m.CreatedAt).Scan(&movement.ID)
+}
+
+func (r *stockMovementRepository) GetByProductID(productID, outletID int) ([]domain.StockMovement, error) {
+	query := `
+		SELECT id, product_id, outlet_id, quantity, reason, reference, actor, created_at
+		FROM stock_movements
+		WHERE product_id = $1 AND ($2 = 0 OR outlet_id = $2)
+		ORDER BY created_at DESC, id DESC
+	`
+	rows, err := r.db.Query(query, productID, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]domain.StockMovement, 0)
+	for rows.Next() {
+		var m domain.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.OutletID, &m.Quantity, &m.Reason, &m.Reference, &m.Actor,
+			&m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
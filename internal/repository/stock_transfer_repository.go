@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+type stockTransferRepository struct {
+	db DBTX
+}
+
+// NewStockTransferRepository creates a new stock transfer repository. Create
+// writes the lines in several statements, so it should run inside a
+// transaction.
+func NewStockTransferRepository(db DBTX) StockTransferRepository {
+	return &stockTransferRepository{db: db}
+}
+
+const stockTransferColumns = "id, from_outlet_id, to_outlet_id, status, notes, created_by, created_at, updated_at"
+
+func (r *stockTransferRepository) GetAll(outletID int) ([]domain.StockTransfer, error) {
+	query := "SELECT " + stockTransferColumns + ` FROM stock_transfers
+		WHERE $1 = 0 OR from_outlet_id = $1 OR to_outlet_id = $1
+		ORDER BY id`
+	rows, err := r.db.Query(query, outletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]domain.StockTransfer, 0)
+	for rows.Next() {
+		t, err := scanStockTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range transfers {
+		if err := r.loadLines(&transfers[i]); err != nil {
+			return nil, err
+		}
+	}
+	return transfers, nil
+}
+
+func (r *stockTransferRepository) Create(transfer *domain.StockTransfer) error {
+	query := `
+		INSERT INTO stock_transfers (from_outlet_id, to_outlet_id, status, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	t := transfer
+	err := r.db.QueryRow(query, t.FromOutletID, t.ToOutletID, t.Status, t.Notes, t.CreatedBy, t.CreatedAt,
+		t.UpdatedAt).Scan(&transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO stock_transfer_lines (transfer_id, position, product_id, quantity) VALUES ($1, $2, $3, $4)"
+	for i, line := range transfer.Lines {
+		if _, err := r.db.Exec(query, transfer.ID, i, line.ProductID, line.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *stockTransferRepository) GetByID(id int) (*domain.StockTransfer, error) {
+	return r.get("SELECT "+stockTransferColumns+" FROM stock_transfers WHERE id = $1", id)
+}
+
+func (r *stockTransferRepository) GetByIDForUpdate(id int) (*domain.StockTransfer, error) {
+	return r.get("SELECT "+stockTransferColumns+" FROM stock_transfers WHERE id = $1 FOR UPDATE", id)
+}
+
+func (r *stockTransferRepository) get(query string, id int) (*domain.StockTransfer, error) {
+	t, err := scanStockTransfer(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := r.loadLines(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *stockTransferRepository) Update(transfer *domain.StockTransfer) error {
+	query := "UPDATE stock_transfers SET status = $1, updated_at = $2 WHERE id = $3"
+	result, err := r.db.Exec(query, transfer.Status, transfer.UpdatedAt, transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *stockTransferRepository) loadLines(transfer *domain.StockTransfer) error {
+	query := "SELECT product_id, quantity FROM stock_transfer_lines WHERE transfer_id = $1 ORDER BY position"
+	rows, err := r.db.Query(query, transfer.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	transfer.Lines = make([]domain.StockTransferLine, 0)
+	for rows.Next() {
+		var line domain.StockTransferLine
+		if err := rows.Scan(&line.ProductID, &line.Quantity); err != nil {
+			return err
+		}
+		transfer.Lines = append(transfer.Lines, line)
+	}
+	return rows.Err()
+}
+
+func scanStockTransfer(row rowScanner) (domain.StockTransfer, error) {
+	var t domain.StockTransfer
+	err := row.Scan(&t.ID, &t.FromOutletID, &t.ToOutletID, &t.Status, &t.Notes, &t.CreatedBy, &t.CreatedAt,
+		&t.UpdatedAt)
+	return t, err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type supplierRepository struct {
+	db DBTX
+}
+
+// NewSupplierRepository creates a new supplier repository
+func NewSupplierRepository(db DBTX) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+func (r *supplierRepository) GetAll() ([]domain.Supplier, error) {
+	query := "SELECT id, name, phone, email, address FROM suppliers ORDER BY id"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]domain.Supplier, 0)
+	for rows.Next() {
+		var s domain.Supplier
+		if err := rows.Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suppliers, nil
+}
+
+func (r *supplierRepository) Create(supplier *domain.Supplier) error {
+	query := "INSERT INTO suppliers (name, phone, email, address) VALUES ($1, $2, $3, $4) RETURNING id"
+	s := supplier
+	return r.db.QueryRow(query, s.Name, s.Phone, s.Email, s.Address).Scan(&supplier.ID)
+}
+
+func (r *supplierRepository) GetByID(id int) (*domain.Supplier, error) {
+	query := "SELECT id, name, phone, email, address FROM suppliers WHERE id = $1"
+
+	var s domain.Supplier
+	if err := r.db.QueryRow(query, id).Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *supplierRepository) Update(supplier *domain.Supplier) error {
+	query := "UPDATE suppliers SET name = $1, phone = $2, email = $3, address = $4 WHERE id = $5"
+	s := supplier
+	result, err := r.db.Exec(query, s.Name, s.Phone, s.Email, s.Address, s.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *supplierRepository) Delete(id int) error {
+	query := "DELETE FROM suppliers WHERE id = $1"
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrConflict
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
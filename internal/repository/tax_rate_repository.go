@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// foreignKeyViolation is the Postgres error code for a row still referenced elsewhere
+const foreignKeyViolation = "23503"
+
+type taxRateRepository struct {
+	db DBTX
+}
+
+// NewTaxRateRepository creates a new tax rate repository
+func NewTaxRateRepository(db DBTX) TaxRateRepository {
+	return &taxRateRepository{db: db}
+}
+
+func (r *taxRateRepository) GetAll() ([]domain.TaxRate, error) {
+	query := "SELECT id, name, rate, inclusive FROM tax_rates ORDER BY id"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]domain.TaxRate, 0)
+	for rows.Next() {
+		var t domain.TaxRate
+		if err := rows.Scan(&t.ID, &t.Name, &t.Rate, &t.Inclusive); err != nil {
+			return nil, err
+		}
+		rates = append(rates, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *taxRateRepository) Create(rate *domain.TaxRate) error {
+	query := "INSERT INTO tax_rates (name, rate, inclusive) VALUES ($1, $2, $3) RETURNING id"
+	return r.db.QueryRow(query, rate.Name, rate.Rate, rate.Inclusive).Scan(&rate.ID)
+}
+
+func (r *taxRateRepository) GetByID(id int) (*domain.TaxRate, error) {
+	query := "SELECT id, name, rate, inclusive FROM tax_rates WHERE id = $1"
+
+	var t domain.TaxRate
+	if err := r.db.QueryRow(query, id).Scan(&t.ID, &t.Name, &t.Rate, &t.Inclusive); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *taxRateRepository) Update(rate *domain.TaxRate) error {
+	query := "UPDATE tax_rates SET name = $1, rate = $2, inclusive = $3 WHERE id = $4"
+	result, err := r.db.Exec(query, rate.Name, rate.Rate, rate.Inclusive, rate.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *taxRateRepository) Delete(id int) error {
+	query := "DELETE FROM tax_rates WHERE id = $1"
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrConflict
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
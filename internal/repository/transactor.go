@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so every repository can run
+// either standalone or inside a transaction
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor creates a Transactor backed by a Postgres connection pool
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// NewRepositories binds every repository to the same connection or transaction
+func NewRepositories(db DBTX) Repositories {
+	return Repositories{
+		Products:          NewProductRepository(db),
+		Categories:        NewCategoryRepository(db),
+		PriceHistory:      NewPriceHistoryRepository(db),
+		Audit:             NewAuditRepository(db),
+		Promotions:        NewPromotionRepository(db),
+		TaxRates:          NewTaxRateRepository(db),
+		Shifts:            NewShiftRepository(db),
+		Carts:             NewCartRepository(db),
+		Refunds:           NewRefundRepository(db),
+		Reservations:      NewReservationRepository(db),
+		Idempotency:       NewIdempotencyRepository(db),
+		Customers:         NewCustomerRepository(db),
+		Suppliers:         NewSupplierRepository(db),
+		PurchaseOrders:    NewPurchaseOrderRepository(db),
+		StockMovements:    NewStockMovementRepository(db),
+		Outlets:           NewOutletRepository(db),
+		StockTransfers:    NewStockTransferRepository(db),
+		Units:             NewUnitRepository(db),
+		ProductUnits:      NewProductUnitRepository(db),
+		ProductComponents: NewProductComponentRepository(db),
+		ProductImages:     NewProductImageRepository(db),
+		StockBatches:      NewStockBatchRepository(db),
+		StockCounts:       NewStockCountRepository(db),
+	}
+}
+
+func (t *transactor) WithinTx(fn func(repos Repositories) error) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(NewRepositories(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
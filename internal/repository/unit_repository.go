@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type unitRepository struct {
+	db DBTX
+}
+
+// NewUnitRepository creates a new unit of measure repository
+func NewUnitRepository(db DBTX) UnitRepository {
+	return &unitRepository{db: db}
+}
+
+func (r *unitRepository) GetAll() ([]domain.Unit, error) {
+	rows, err := r.db.Query("SELECT id, code, name FROM units ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make([]domain.Unit, 0)
+	for rows.Next() {
+		var u domain.Unit
+		if err := rows.Scan(&u.ID, &u.Code, &u.Name); err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return units, nil
+}
+
+func (r *unitRepository) Create(unit *domain.Unit) error {
+	query := "INSERT INTO units (code, name) VALUES ($1, $2) RETURNING id"
+	err := r.db.QueryRow(query, unit.Code, unit.Name).Scan(&unit.ID)
+	return unitCodeConflict(err)
+}
+
+func (r *unitRepository) GetByID(id int) (*domain.Unit, error) {
+	var u domain.Unit
+	if err := r.db.QueryRow("SELECT id, code, name FROM units WHERE id = $1", id).Scan(&u.ID, &u.Code, &u.Name); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *unitRepository) Update(unit *domain.Unit) error {
+	result, err := r.db.Exec("UPDATE units SET code = $1, name = $2 WHERE id = $3", unit.Code, unit.Name, unit.ID)
+	if err != nil {
+		return unitCodeConflict(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *unitRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM units WHERE id = $1", id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return apperrors.ErrConflict
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// unitCodeConflict turns a duplicate unit code into ErrConflict
+func unitCodeConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrConflict
+	}
+	return err
+}
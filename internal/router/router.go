@@ -4,27 +4,151 @@ import (
 	"net/http"
 
 	"kasir-api/internal/handler"
+	"kasir-api/internal/service"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-// New creates and configures the HTTP router with all routes
-func New(productHandler *handler.ProductHandler, categoryHandler *handler.CategoryHandler, healthHandler *handler.HealthHandler) http.Handler {
+// Handlers groups the HTTP handlers mounted by the router
+type Handlers struct {
+	Product       *handler.ProductHandler
+	Category      *handler.CategoryHandler
+	Health        *handler.HealthHandler
+	Audit         *handler.AuditHandler
+	Promotion     *handler.PromotionHandler
+	Quote         *handler.QuoteHandler
+	TaxRate       *handler.TaxRateHandler
+	Shift         *handler.ShiftHandler
+	Cart          *handler.CartHandler
+	Customer      *handler.CustomerHandler
+	Supplier      *handler.SupplierHandler
+	PurchaseOrder *handler.PurchaseOrderHandler
+	Outlet        *handler.OutletHandler
+	Unit          *handler.UnitHandler
+	StockTransfer *handler.StockTransferHandler
+	ProductImage  *handler.ProductImageHandler
+	Batch         *handler.BatchHandler
+	StockCount    *handler.StockCountHandler
+	Report        *handler.ReportHandler
+	Transaction   *handler.TransactionHandler
+	// Uploads serves stored files by key under /uploads/
+	Uploads http.Handler
+}
+
+// New creates and configures the HTTP router with all routes. POST requests
+// with an Idempotency-Key header are deduplicated through idempotency, their
+// bodies limited to maxBodyBytes. Deduplication wraps each route inside its
+// token check, so a request refused for its token never claims the key.
+func New(h Handlers, adminToken, supervisorToken string, idempotency *service.IdempotencyService,
+	maxBodyBytes int64) http.Handler {
 	mux := http.NewServeMux()
+	keyed := func(next http.HandlerFunc) http.HandlerFunc {
+		return handler.Idempotency(idempotency, maxBodyBytes, next).ServeHTTP
+	}
 
 	// Health check
-	mux.HandleFunc("/api/health", healthHandler.Health)
+	mux.HandleFunc("/api/health", h.Health.Health)
 
 	// Category routes
-	mux.HandleFunc("/api/categories", categoryHandler.HandleCategories)
-	mux.HandleFunc("/api/categories/", categoryHandler.HandleCategoryByID)
+	mux.HandleFunc("/api/categories", keyed(h.Category.HandleCategories))
+	mux.HandleFunc("/api/categories/tree", keyed(h.Category.GetTree))
+	mux.HandleFunc("/api/categories/", keyed(h.Category.HandleCategoryByID))
 
 	// Product routes
-	mux.HandleFunc("/api/products", productHandler.HandleProducts)
-	mux.HandleFunc("/api/products/", productHandler.HandleProductByID)
+	mux.HandleFunc("/api/products", keyed(h.Product.HandleProducts))
+	mux.HandleFunc("/api/products/expiring", keyed(h.Batch.HandleExpiring))
+	mux.HandleFunc("/api/products/", keyed(h.Product.HandleProductByID))
+	mux.HandleFunc("/api/products/{id}/price-history", keyed(h.Product.HandlePriceHistory))
+	mux.HandleFunc("/api/products/{id}/stock-movements", keyed(h.Product.HandleStockMovements))
+	mux.HandleFunc("/api/products/{id}/variants", keyed(h.Product.HandleVariants))
+	mux.HandleFunc("/api/products/{id}/components", keyed(h.Product.HandleComponents))
+	mux.HandleFunc("/api/products/{id}/units", keyed(h.Unit.HandleProductUnits))
+	mux.HandleFunc("/api/products/{id}/images", keyed(h.ProductImage.HandleImages))
+	mux.HandleFunc("/api/products/{id}/images/{image_id}", keyed(h.ProductImage.HandleImage))
+	mux.HandleFunc("/api/products/{id}/batches", keyed(h.Batch.HandleProductBatches))
+
+	// Promotion routes
+	mux.HandleFunc("/api/promotions", keyed(h.Promotion.HandlePromotions))
+	mux.HandleFunc("/api/promotions/", keyed(h.Promotion.HandlePromotionByID))
+
+	// Tax rate routes
+	mux.HandleFunc("/api/tax-rates", keyed(h.TaxRate.HandleTaxRates))
+	mux.HandleFunc("/api/tax-rates/", keyed(h.TaxRate.HandleTaxRateByID))
+
+	// Quote routes
+	mux.HandleFunc("/api/quotes", keyed(h.Quote.HandleQuotes))
+
+	// Cart routes
+	mux.HandleFunc("/api/carts", keyed(h.Cart.HandleCarts))
+	mux.HandleFunc("/api/carts/{id}", keyed(h.Cart.HandleCartByID))
+	mux.HandleFunc("/api/carts/{id}/items", keyed(h.Cart.HandleItems))
+	mux.HandleFunc("/api/carts/{id}/items/{product_id}", keyed(h.Cart.HandleItem))
+	mux.HandleFunc("/api/carts/{id}/hold", keyed(h.Cart.HandleHold))
+	mux.HandleFunc("/api/carts/{id}/resume", keyed(h.Cart.HandleResume))
+	mux.HandleFunc("/api/carts/{id}/checkout", keyed(h.Cart.HandleCheckout))
+
+	// Transaction routes
+	mux.HandleFunc("/api/transactions/{id}/receipt", keyed(h.Transaction.HandleReceipt))
+	mux.HandleFunc("/api/transactions/{id}/refunds", keyed(h.Transaction.HandleRefunds))
+	mux.HandleFunc("/api/transactions/{id}/void",
+		handler.RequireSupervisorToken(supervisorToken, keyed(h.Transaction.HandleVoid)))
+
+	// Customer routes
+	mux.HandleFunc("/api/customers", keyed(h.Customer.HandleCustomers))
+	mux.HandleFunc("/api/customers/", keyed(h.Customer.HandleCustomerByID))
+	mux.HandleFunc("/api/customers/{id}/transactions", keyed(h.Customer.HandleTransactions))
+
+	// Supplier routes
+	mux.HandleFunc("/api/suppliers", keyed(h.Supplier.HandleSuppliers))
+	mux.HandleFunc("/api/suppliers/", keyed(h.Supplier.HandleSupplierByID))
+
+	// Purchase order routes
+	mux.HandleFunc("/api/purchase-orders", keyed(h.PurchaseOrder.HandlePurchaseOrders))
+	mux.HandleFunc("/api/purchase-orders/{id}", keyed(h.PurchaseOrder.HandlePurchaseOrderByID))
+	mux.HandleFunc("/api/purchase-orders/{id}/receive", keyed(h.PurchaseOrder.HandleReceive))
+	mux.HandleFunc("/api/purchase-orders/{id}/cancel", keyed(h.PurchaseOrder.HandleCancel))
+
+	// Outlet routes
+	mux.HandleFunc("/api/outlets", keyed(h.Outlet.HandleOutlets))
+	mux.HandleFunc("/api/outlets/", keyed(h.Outlet.HandleOutletByID))
+
+	// Unit routes
+	mux.HandleFunc("/api/units", keyed(h.Unit.HandleUnits))
+	mux.HandleFunc("/api/units/", keyed(h.Unit.HandleUnitByID))
+
+	// Stock transfer routes
+	mux.HandleFunc("/api/stock-transfers", keyed(h.StockTransfer.HandleStockTransfers))
+	mux.HandleFunc("/api/stock-transfers/{id}", keyed(h.StockTransfer.HandleStockTransferByID))
+	mux.HandleFunc("/api/stock-transfers/{id}/receive", keyed(h.StockTransfer.HandleReceive))
+	mux.HandleFunc("/api/stock-transfers/{id}/cancel", keyed(h.StockTransfer.HandleCancel))
+
+	// Stock count routes
+	mux.HandleFunc("/api/stock-counts", keyed(h.StockCount.HandleStockCounts))
+	mux.HandleFunc("/api/stock-counts/{id}", keyed(h.StockCount.HandleStockCountByID))
+	mux.HandleFunc("/api/stock-counts/{id}/entries", keyed(h.StockCount.HandleEntries))
+	mux.HandleFunc("/api/stock-counts/{id}/approve", keyed(h.StockCount.HandleApprove))
+	mux.HandleFunc("/api/stock-counts/{id}/cancel", keyed(h.StockCount.HandleCancel))
+
+	// Stock batch routes
+	mux.HandleFunc("/api/stock-batches/{id}/write-off", keyed(h.Batch.HandleWriteOff))
+
+	// Shift routes
+	mux.HandleFunc("/api/shifts/open", keyed(h.Shift.HandleOpen))
+	mux.HandleFunc("/api/shifts/current", keyed(h.Shift.HandleCurrent))
+	mux.HandleFunc("/api/shifts/{id}", keyed(h.Shift.HandleShiftByID))
+	mux.HandleFunc("/api/shifts/{id}/close", keyed(h.Shift.HandleClose))
+
+	// Report routes
+	mux.HandleFunc("/api/reports/sales", keyed(h.Report.HandleSales))
+
+	// Audit routes (admin only)
+	mux.HandleFunc("/api/audit", handler.RequireAdminToken(adminToken, h.Audit.HandleAudit))
+
+	// Uploaded files such as product images
+	mux.Handle("/uploads/", http.StripPrefix("/uploads", h.Uploads))
 
 	// Swagger UI
 	mux.HandleFunc("/swagger/", httpSwagger.WrapHandler)
 
-	return mux
+	return handler.RequestID(mux)
 }
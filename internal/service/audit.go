@@ -0,0 +1,76 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// fieldChange is one entry of an audit diff
+type fieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// recordAudit writes an audit entry for one operation. before is nil for
+// creates and after is nil for deletes; for updates only the top-level JSON
+// fields that differ are stored in the diff.
+func recordAudit(repo repository.AuditRepository, meta domain.ChangeMeta, action, entityType string, entityID int, before, after any) error {
+	entry := domain.AuditEntry{
+		Actor:      meta.Actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  meta.RequestID,
+	}
+
+	var err error
+	if entry.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+	if entry.Before != nil && entry.After != nil {
+		if entry.Changes, err = diffSnapshots(entry.Before, entry.After); err != nil {
+			return err
+		}
+	}
+
+	return repo.Create(&entry)
+}
+
+func marshalSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func diffSnapshots(before, after json.RawMessage) (json.RawMessage, error) {
+	var b, a map[string]any
+	if err := json.Unmarshal(before, &b); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &a); err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]fieldChange)
+	for key, oldValue := range b {
+		if newValue, ok := a[key]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = fieldChange{Old: oldValue, New: a[key]}
+		}
+	}
+	for key, newValue := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = fieldChange{New: newValue}
+		}
+	}
+	return json.Marshal(changes)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// AuditService handles audit log queries
+type AuditService struct {
+	repo repository.AuditRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// List returns audit entries matching filter, newest first. The limit
+// defaults to 100 and is capped at 500.
+func (s *AuditService) List(filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.List(filter)
+}
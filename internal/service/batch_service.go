@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// DefaultExpiringDays is how far ahead GetExpiring looks when no window is given
+const DefaultExpiringDays = 7
+
+// BatchService handles stock batches: what is left of each lot, what is about
+// to expire and writing expired stock off as waste
+type BatchService struct {
+	repo        repository.StockBatchRepository
+	productRepo repository.ProductRepository
+	outletRepo  repository.OutletRepository
+	transactor  repository.Transactor
+	now         func() time.Time
+}
+
+// NewBatchService creates a new stock batch service
+func NewBatchService(repo repository.StockBatchRepository, productRepo repository.ProductRepository,
+	outletRepo repository.OutletRepository, transactor repository.Transactor) *BatchService {
+	return &BatchService{repo: repo, productRepo: productRepo, outletRepo: outletRepo, transactor: transactor, now: time.Now}
+}
+
+// GetByProduct lists a product's batches with stock remaining at an outlet,
+// the default outlet when outletID is zero, in the order checkout takes them
+func (s *BatchService) GetByProduct(productID, outletID int) ([]domain.StockBatch, error) {
+	outletID, err := resolveOutlet(s.outletRepo, outletID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInStock(productID, outletID)
+}
+
+// GetExpiring lists the batches with stock remaining that expire within the
+// next days, soonest first, including those already expired. Only batches at
+// outletID are listed unless it is zero.
+func (s *BatchService) GetExpiring(days, outletID int) ([]domain.StockBatch, error) {
+	if days < 0 {
+		return nil, invalidInput("days must not be negative")
+	}
+	if err := checkOutlet(s.outletRepo, outletID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetExpiring(s.now().Add(time.Duration(days)*24*time.Hour), outletID)
+}
+
+// WriteOff takes quantity, or everything remaining when it is zero, out of a
+// batch and its outlet's stock and records it in the stock ledger as waste
+func (s *BatchService) WriteOff(id int, input domain.WriteOffInput, meta domain.ChangeMeta) (*domain.StockBatch, error) {
+	if input.Quantity < 0 {
+		return nil, invalidInput("quantity must not be negative")
+	}
+
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		batch, err := repos.StockBatches.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if batch.Remaining == 0 {
+			return invalidInput("batch has no stock remaining")
+		}
+		quantity := input.Quantity
+		if quantity == 0 {
+			quantity = batch.Remaining
+		}
+		if quantity > batch.Remaining {
+			return invalidInput(fmt.Sprintf("batch has only %d remaining", batch.Remaining))
+		}
+
+		if err := repos.StockBatches.Take(batch.ID, quantity); err != nil {
+			return err
+		}
+		if err := repos.Products.DecrementStock(batch.ProductID, batch.OutletID, quantity); err != nil {
+			return err
+		}
+		err = repos.StockMovements.Create(&domain.StockMovement{
+			ProductID: batch.ProductID,
+			OutletID:  batch.OutletID,
+			Quantity:  -quantity,
+			Reason:    domain.StockMovementWaste,
+			Reference: fmt.Sprintf("BATCH-%d", batch.ID),
+			Actor:     meta.Actor,
+			CreatedAt: s.now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		after := *batch
+		after.Remaining -= quantity
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityStockBatch, batch.ID, batch, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// takeBatches takes quantity leaving an outlet's stock of a product out of its
+// batches, first expired first out. Whatever the batches do not cover comes
+// from stock that is not batched.
+func takeBatches(repos repository.Repositories, productID, outletID, quantity int) error {
+	batches, err := repos.StockBatches.GetInStock(productID, outletID)
+	if err != nil {
+		return err
+	}
+	for _, take := range domain.TakeFEFO(batches, quantity) {
+		if err := repos.StockBatches.Take(take.BatchID, take.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trimBatches takes stock out of a product's batches at an outlet, first
+// expired first out, until they hold no more than the outlet's stock
+func trimBatches(repos repository.Repositories, productID, outletID int) error {
+	batches, err := repos.StockBatches.GetInStock(productID, outletID)
+	if err != nil {
+		return err
+	}
+	stock, err := repos.Products.GetStock(productID, outletID)
+	if err != nil {
+		return err
+	}
+	batched := 0
+	for _, b := range batches {
+		batched += b.Remaining
+	}
+	if batched <= stock {
+		return nil
+	}
+	for _, take := range domain.TakeFEFO(batches, batched-stock) {
+		if err := repos.StockBatches.Take(take.BatchID, take.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
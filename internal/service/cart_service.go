@@ -0,0 +1,678 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// CartService handles server-side carts, from parking them to checkout
+type CartService struct {
+	repo            repository.CartRepository
+	productRepo     repository.ProductRepository
+	productUnitRepo repository.ProductUnitRepository
+	customerRepo    repository.CustomerRepository
+	outletRepo      repository.OutletRepository
+	transactor      repository.Transactor
+	reservationTTL  time.Duration
+	loyalty         domain.LoyaltyProgram
+	now             func() time.Time
+}
+
+// NewCartService creates a new cart service. Carts that reserve stock hold
+// it for reservationTTL after their last change, and customers earn and
+// redeem points at checkout under loyalty.
+func NewCartService(repo repository.CartRepository, productRepo repository.ProductRepository,
+	productUnitRepo repository.ProductUnitRepository, customerRepo repository.CustomerRepository,
+	outletRepo repository.OutletRepository, transactor repository.Transactor, reservationTTL time.Duration,
+	loyalty domain.LoyaltyProgram) *CartService {
+	return &CartService{
+		repo:            repo,
+		productRepo:     productRepo,
+		productUnitRepo: productUnitRepo,
+		customerRepo:    customerRepo,
+		outletRepo:      outletRepo,
+		transactor:      transactor,
+		reservationTTL:  reservationTTL,
+		loyalty:         loyalty,
+		now:             time.Now,
+	}
+}
+
+// GetAll lists carts, optionally only those with the given status and only
+// those at outletID unless it is zero
+func (s *CartService) GetAll(status string, outletID int) ([]domain.Cart, error) {
+	switch status {
+	case "", domain.CartStatusOpen, domain.CartStatusHeld, domain.CartStatusCheckedOut, domain.CartStatusVoided:
+	default:
+		return nil, invalidInput("status must be open, held, checked_out or voided")
+	}
+	if err := checkOutlet(s.outletRepo, outletID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(status, outletID)
+}
+
+func (s *CartService) GetByID(id int) (*domain.Cart, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create opens a cart for the cashier named in meta at an outlet, the default
+// outlet when none is given, optionally with items. The cart sells that
+// outlet's stock. A cart created with ReserveStock reserves its items
+// straight away.
+func (s *CartService) Create(input domain.CartInput, meta domain.ChangeMeta) (*domain.Cart, error) {
+	outletID, err := resolveOutlet(s.outletRepo, input.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	cart := &domain.Cart{
+		Cashier:      meta.Actor,
+		OutletID:     outletID,
+		Status:       domain.CartStatusOpen,
+		ReserveStock: input.ReserveStock,
+		CustomerID:   input.CustomerID,
+		Items:        []domain.CartItem{},
+		Payments:     []domain.Payment{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.checkCustomer(input.CustomerID); err != nil {
+		return nil, err
+	}
+	for _, item := range input.Items {
+		if err := s.addItem(cart, item); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Carts.Create(cart); err != nil {
+			return err
+		}
+		return s.reserve(repos, cart, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// AddItem adds quantity of a product to an open cart, merging it into the
+// existing line for that product and unit. Stock is only checked now for
+// carts that reserve it; other carts are checked at checkout.
+func (s *CartService) AddItem(id int, item domain.QuoteItem) (*domain.Cart, error) {
+	return s.modify(id, func(repos repository.Repositories, cart *domain.Cart) error {
+		if cart.Status != domain.CartStatusOpen {
+			return apperrors.ErrConflict
+		}
+		if err := s.addItem(cart, item); err != nil {
+			return err
+		}
+		return s.reserve(repos, cart, s.now())
+	})
+}
+
+// RemoveItem drops a product's line in unitID from an open cart, or every
+// line of the product when unitID is zero
+func (s *CartService) RemoveItem(id, productID, unitID int) (*domain.Cart, error) {
+	return s.modify(id, func(repos repository.Repositories, cart *domain.Cart) error {
+		if cart.Status != domain.CartStatusOpen {
+			return apperrors.ErrConflict
+		}
+		kept := cart.Items[:0]
+		for _, existing := range cart.Items {
+			if existing.ProductID != productID || (unitID != 0 && existing.UnitID != unitID) {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(cart.Items) {
+			return invalidInput("product is not in the cart")
+		}
+		cart.Items = kept
+		return s.reserve(repos, cart, s.now())
+	})
+}
+
+// Hold parks an open cart so the cashier can serve someone else. Its
+// reservations, if any, run on until they expire.
+func (s *CartService) Hold(id int) (*domain.Cart, error) {
+	return s.modify(id, func(_ repository.Repositories, cart *domain.Cart) error {
+		if cart.Status != domain.CartStatusOpen {
+			return apperrors.ErrConflict
+		}
+		cart.Status = domain.CartStatusHeld
+		return nil
+	})
+}
+
+// Resume reopens a held cart
+func (s *CartService) Resume(id int) (*domain.Cart, error) {
+	return s.modify(id, func(_ repository.Repositories, cart *domain.Cart) error {
+		if cart.Status != domain.CartStatusHeld {
+			return apperrors.ErrConflict
+		}
+		cart.Status = domain.CartStatusOpen
+		return nil
+	})
+}
+
+// Checkout turns an open cart into a sale. Within one transaction it
+// re-prices the cart with current prices, promotions and taxes, settles the
+// payments against the total, takes the stock at the cart's outlet that
+// other carts have not reserved, releases the cart's own reservations, and records the sale
+// against the cashier's open shift if there is one. The cashier in meta,
+// when given, takes over the cart so the cash lands in their drawer. A
+// customer on the cart can redeem points off the total and earns points on
+// what is left to pay. The cart is locked first, as every change to it is,
+// so an item added meanwhile is either in the sale or refused.
+func (s *CartService) Checkout(id int, input domain.CheckoutInput, meta domain.ChangeMeta) (*domain.CheckoutResult, error) {
+	var result *domain.CheckoutResult
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		cart, err := repos.Carts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if cart.Status != domain.CartStatusOpen {
+			return apperrors.ErrConflict
+		}
+		if len(cart.Items) == 0 {
+			return invalidInput("cart is empty")
+		}
+		before := cloneCart(*cart)
+
+		now := s.now()
+		items := make([]domain.QuoteItem, len(cart.Items))
+		for i, item := range cart.Items {
+			items[i] = domain.QuoteItem{ProductID: item.ProductID, UnitID: item.UnitID, Quantity: item.Quantity}
+		}
+		quote, err := priceBasket(repos.Products, repos.ProductUnits, repos.Categories, repos.Promotions, repos.TaxRates, items, now)
+		if err != nil {
+			return err
+		}
+		if input.CustomerID != nil {
+			if cart.CustomerID != nil && *cart.CustomerID != *input.CustomerID {
+				return invalidInput("cart already belongs to another customer")
+			}
+			if err := s.checkCustomer(input.CustomerID); err != nil {
+				return err
+			}
+			cart.CustomerID = input.CustomerID
+		}
+		pointsDiscount, err := s.redeemPoints(repos.Customers, cart.CustomerID, input.RedeemPoints, quote.Total)
+		if err != nil {
+			return err
+		}
+		total := quote.Total - pointsDiscount
+		settlement, err := settlePayments(total, input.Payments)
+		if err != nil {
+			return err
+		}
+		if meta.Actor != "" {
+			cart.Cashier = meta.Actor
+		}
+		if err := linkShift(repos.Shifts, cart, settlement.Payments); err != nil {
+			return err
+		}
+		taken, err := takeStock(repos, cart, quote.Lines, now)
+		if err != nil {
+			return err
+		}
+		if err := repos.Reservations.DeleteByCart(cart.ID); err != nil {
+			return err
+		}
+
+		for i, line := range quote.Lines {
+			cost, err := unitCost(repos, line.ProductID, line.UnitID)
+			if err != nil {
+				return err
+			}
+			cart.Items[i] = domain.CartItem{
+				ProductID: line.ProductID,
+				UnitID:    line.UnitID,
+				Quantity:  line.Quantity,
+				Name:      line.Name,
+				UnitPrice: line.UnitPrice,
+				Discounts: line.Discounts,
+				Discount:  line.Discount,
+				Tax:       line.Tax,
+				Total:     line.Total,
+				CostPrice: cost,
+				Stock:     taken[i],
+			}
+		}
+		cart.Status = domain.CartStatusCheckedOut
+		cart.Subtotal = quote.Subtotal
+		cart.CartDiscounts = quote.CartDiscounts
+		cart.Discount = quote.Discount
+		cart.Tax = quote.Tax
+		cart.Taxes = quote.Taxes
+		cart.Total = total
+		cart.Payments = settlement.Payments
+		cart.Paid = settlement.Paid
+		cart.Change = settlement.Change
+		if cart.CustomerID != nil {
+			cart.PointsRedeemed = input.RedeemPoints
+			cart.PointsDiscount = pointsDiscount
+			cart.PointsEarned = s.loyalty.PointsEarned(total)
+			if err := repos.Customers.AddPoints(*cart.CustomerID, cart.PointsEarned-cart.PointsRedeemed); err != nil {
+				return err
+			}
+		}
+		cart.UpdatedAt = now
+		cart.CheckedOutAt = &now
+		if err := repos.Carts.Update(cart); err != nil {
+			return err
+		}
+
+		result = &domain.CheckoutResult{Cart: *cart, Quote: *quote}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityCart, cart.ID, before, cart)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReleaseExpiredReservations deletes reservations whose TTL has passed and
+// returns how many were released
+func (s *CartService) ReleaseExpiredReservations() (int, error) {
+	var released int
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		var err error
+		released, err = repos.Reservations.DeleteExpired(s.now())
+		return err
+	})
+	return released, err
+}
+
+// RunReservationReaper releases expired reservations every interval until
+// ctx is cancelled
+func (s *CartService) RunReservationReaper(ctx context.Context, interval time.Duration) {
+	runReaper(ctx, interval, "reservations", s.ReleaseExpiredReservations)
+}
+
+// modify loads a cart, applies fn and saves it in one transaction
+func (s *CartService) modify(id int, fn func(repos repository.Repositories, cart *domain.Cart) error) (*domain.Cart, error) {
+	var cart *domain.Cart
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		var err error
+		cart, err = repos.Carts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := fn(repos, cart); err != nil {
+			return err
+		}
+		cart.UpdatedAt = s.now()
+		return repos.Carts.Update(cart)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (s *CartService) addItem(cart *domain.Cart, item domain.QuoteItem) error {
+	if item.Quantity < 1 {
+		return invalidInput("quantity must be positive")
+	}
+	product, err := s.productRepo.GetByID(item.ProductID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrProductNotFound
+		}
+		return err
+	}
+	unit, err := unitConversion(s.productUnitRepo, product, item.UnitID)
+	if err != nil {
+		return err
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == item.ProductID && cart.Items[i].UnitID == unit.UnitID {
+			cart.Items[i].Quantity += item.Quantity
+			return nil
+		}
+	}
+	cart.Items = append(cart.Items, domain.CartItem{ProductID: item.ProductID, UnitID: unit.UnitID, Quantity: item.Quantity})
+	return nil
+}
+
+// checkCustomer verifies an optional customer exists
+func (s *CartService) checkCustomer(id *int) error {
+	if id == nil {
+		return nil
+	}
+	if _, err := s.customerRepo.GetByID(*id); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrCustomerNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// redeemPoints returns the discount for spending points of the cart's
+// customer on a sale of total. The discount has to leave something to pay.
+// The customer is locked, before the shift and stock, until checkout
+// commits, so of two checkouts spending the same points the second waits and
+// then finds them spent, before any payment is settled or stock taken.
+func (s *CartService) redeemPoints(repo repository.CustomerRepository, customerID *int, points, total int) (int, error) {
+	if points < 0 {
+		return 0, invalidInput("redeem_points cannot be negative")
+	}
+	if customerID == nil {
+		if points > 0 {
+			return 0, invalidInput("redeeming points needs a customer")
+		}
+		return 0, nil
+	}
+	customer, err := repo.GetByIDForUpdate(*customerID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return 0, apperrors.ErrCustomerNotFound
+		}
+		return 0, err
+	}
+	if points == 0 {
+		return 0, nil
+	}
+	if customer.Points < points {
+		return 0, fmt.Errorf("%w: %s has %d", apperrors.ErrInsufficientPoints, customer.Name, customer.Points)
+	}
+	discount := s.loyalty.RedemptionValue(points)
+	if discount >= total {
+		return 0, invalidInput("points discount must be less than the total")
+	}
+	return discount, nil
+}
+
+// reserve replaces the reservations of a cart that reserves stock with one
+// per product, in its base unit, expiring a TTL from now. Bundles reserve
+// their components. It fails with ErrInsufficientStock when other carts'
+// reservations leave too little of a product at the cart's outlet.
+func (s *CartService) reserve(repos repository.Repositories, cart *domain.Cart, now time.Time) error {
+	if !cart.ReserveStock {
+		return nil
+	}
+
+	sold := make([]stockNeed, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		product, err := repos.Products.GetByID(item.ProductID)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+			return err
+		}
+		unit, err := unitConversion(repos.ProductUnits, product, item.UnitID)
+		if err != nil {
+			return err
+		}
+		sold = append(sold, stockNeed{productID: item.ProductID, quantity: item.Quantity * unit.Factor})
+	}
+	needs, err := stockNeeds(repos, sold)
+	if err != nil {
+		return err
+	}
+	stock, reserved, err := lockStock(repos, cart, needs, now)
+	if err != nil {
+		return err
+	}
+
+	reservations := make([]domain.Reservation, len(needs))
+	for i, need := range needs {
+		if stock[need.productID]-reserved[need.productID] < need.quantity {
+			return fmt.Errorf("%w: %s", apperrors.ErrInsufficientStock, need.name)
+		}
+		reservations[i] = domain.Reservation{
+			CartID:    cart.ID,
+			OutletID:  cart.OutletID,
+			ProductID: need.productID,
+			Quantity:  need.quantity,
+			ExpiresAt: now.Add(s.reservationTTL),
+		}
+	}
+	return repos.Reservations.ReplaceForCart(cart.ID, reservations)
+}
+
+// takeStock checks what the lines need of each product, in its base unit,
+// against the cart outlet's stock not reserved by other carts before
+// decrementing any, so a short product leaves all stock untouched. Bundles
+// take their components. Batched stock is taken first expired first out, and
+// each product taken is written to the stock ledger as a sale of the cart by
+// its cashier. It returns what each line took.
+func takeStock(repos repository.Repositories, cart *domain.Cart, lines []domain.QuoteLine, now time.Time) ([][]domain.StockTake, error) {
+	outletID := cart.OutletID
+	taken := make([][]domain.StockTake, len(lines))
+	needs := make([]stockNeed, 0, len(lines))
+	index := make(map[int]int, len(lines))
+	for i, line := range lines {
+		lineNeeds, err := stockNeeds(repos, []stockNeed{{productID: line.ProductID, quantity: line.BaseQuantity}})
+		if err != nil {
+			return nil, err
+		}
+		for _, need := range lineNeeds {
+			taken[i] = append(taken[i], domain.StockTake{ProductID: need.productID, Quantity: need.quantity})
+			if j, ok := index[need.productID]; ok {
+				needs[j].quantity += need.quantity
+				continue
+			}
+			index[need.productID] = len(needs)
+			needs = append(needs, need)
+		}
+	}
+	stock, reserved, err := lockStock(repos, cart, needs, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, need := range needs {
+		if stock[need.productID]-reserved[need.productID] < need.quantity {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrInsufficientStock, need.name)
+		}
+	}
+	for _, need := range needs {
+		if err := repos.Products.DecrementStock(need.productID, outletID, need.quantity); err != nil {
+			if errors.Is(err, apperrors.ErrInsufficientStock) {
+				return nil, fmt.Errorf("%w: %s", apperrors.ErrInsufficientStock, need.name)
+			}
+			return nil, err
+		}
+		if err := takeBatches(repos, need.productID, outletID, need.quantity); err != nil {
+			return nil, err
+		}
+		err := repos.StockMovements.Create(&domain.StockMovement{
+			ProductID: need.productID,
+			OutletID:  outletID,
+			Quantity:  -need.quantity,
+			Reason:    domain.StockMovementSale,
+			Reference: fmt.Sprintf("CART-%d", cart.ID),
+			Actor:     cart.Cashier,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return taken, nil
+}
+
+// lockStock locks the cart outlet's stock of each needed product, in product
+// order so concurrent carts cannot deadlock, and only then reads what other
+// carts have reserved. Checking needs against the result cannot race with
+// another cart reserving or selling the same stock.
+func lockStock(repos repository.Repositories, cart *domain.Cart, needs []stockNeed, now time.Time) (stock, reserved map[int]int, err error) {
+	ids := make([]int, len(needs))
+	for i, need := range needs {
+		ids[i] = need.productID
+	}
+	slices.Sort(ids)
+
+	stock = make(map[int]int, len(ids))
+	for _, id := range ids {
+		if stock[id], err = repos.Products.LockStock(id, cart.OutletID); err != nil {
+			return nil, nil, err
+		}
+	}
+	reserved, err = repos.Reservations.ReservedTotals(now, cart.OutletID, cart.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stock, reserved, nil
+}
+
+// stockNeed is a quantity of a product, in its base unit, taken from stock
+type stockNeed struct {
+	productID int
+	name      string
+	quantity  int
+}
+
+// stockNeeds totals what is sold of each product into what it takes from
+// stock, in the order products are first seen. A bundle takes its components'
+// quantities for each bundle sold rather than stock of its own, and cannot
+// be sold without components.
+func stockNeeds(repos repository.Repositories, sold []stockNeed) ([]stockNeed, error) {
+	needs := make([]stockNeed, 0, len(sold))
+	index := make(map[int]int, len(sold))
+	add := func(productID, quantity int) error {
+		if i, ok := index[productID]; ok {
+			needs[i].quantity += quantity
+			return nil
+		}
+		product, err := repos.Products.GetByID(productID)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+			return err
+		}
+		index[productID] = len(needs)
+		needs = append(needs, stockNeed{productID: productID, name: product.Name, quantity: quantity})
+		return nil
+	}
+
+	for _, item := range sold {
+		product, err := repos.Products.GetByID(item.productID)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return nil, apperrors.ErrProductNotFound
+			}
+			return nil, err
+		}
+		if product.Type != domain.ProductTypeBundle {
+			if err := add(item.productID, item.quantity); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		components, err := repos.ProductComponents.GetByBundleID(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(components) == 0 {
+			return nil, invalidInput(fmt.Sprintf("%s has no components", product.Name))
+		}
+		for _, c := range components {
+			if err := add(c.ProductID, item.quantity*c.Quantity); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return needs, nil
+}
+
+// unitCost is what one item of a product in one of its units cost: the
+// product's cost price, or the cost of its components for a bundle, times
+// the unit's conversion factor
+func unitCost(repos repository.Repositories, productID, unitID int) (int, error) {
+	product, err := repos.Products.GetByID(productID)
+	if err != nil {
+		return 0, err
+	}
+	cost := product.CostPrice
+	if product.Type == domain.ProductTypeBundle {
+		components, err := repos.ProductComponents.GetByBundleID(product.ID)
+		if err != nil {
+			return 0, err
+		}
+		cost = 0
+		for _, c := range components {
+			component, err := repos.Products.GetByID(c.ProductID)
+			if err != nil {
+				return 0, err
+			}
+			cost += component.CostPrice * c.Quantity
+		}
+	}
+	unit, err := unitConversion(repos.ProductUnits, product, unitID)
+	if err != nil {
+		return 0, err
+	}
+	return cost * unit.Factor, nil
+}
+
+// cloneCart copies a cart's items and payments so the audit snapshot taken
+// before checkout is not altered by it. Items are replaced rather than
+// changed in place, so their discounts need no copy.
+func cloneCart(c domain.Cart) domain.Cart {
+	c.Items = append([]domain.CartItem(nil), c.Items...)
+	c.Payments = append([]domain.Payment(nil), c.Payments...)
+	return c
+}
+
+// linkShift puts the sale in the cashier's open shift, locking it until the
+// sale commits so the shift cannot be closed without it. Cash must land in a
+// shift so the drawer can be reconciled; other tenders may be taken without
+// one.
+func linkShift(repo repository.ShiftRepository, cart *domain.Cart, payments []domain.Payment) error {
+	takesCash := false
+	for _, p := range payments {
+		if p.Method == domain.PaymentCash {
+			takesCash = true
+		}
+	}
+
+	shiftID, err := openShift(repo, cart.Cashier, takesCash)
+	if err != nil {
+		return err
+	}
+	if shiftID != nil {
+		cart.ShiftID = shiftID
+	}
+	return nil
+}
+
+// openShift locks the cashier's open shift until the transaction commits and
+// returns its ID, or nil when there is none. Cash moving without one fails
+// with ErrNoOpenShift.
+func openShift(repo repository.ShiftRepository, cashier string, cash bool) (*int, error) {
+	if cashier == "" {
+		if cash {
+			return nil, apperrors.ErrNoOpenShift
+		}
+		return nil, nil
+	}
+	shift, err := repo.GetOpenForUpdate(cashier)
+	switch {
+	case err == nil:
+		return &shift.ID, nil
+	case errors.Is(err, apperrors.ErrNotFound):
+		if cash {
+			return nil, apperrors.ErrNoOpenShift
+		}
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
@@ -1,36 +1,123 @@
 package service
 
 import (
+	"errors"
+
+	"kasir-api/internal/apperrors"
 	"kasir-api/internal/domain"
 	"kasir-api/internal/repository"
 )
 
 // CategoryService handles category business logic
 type CategoryService struct {
-	repo repository.CategoryRepository
+	repo        repository.CategoryRepository
+	taxRateRepo repository.TaxRateRepository
+	transactor  repository.Transactor
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService(repo repository.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo repository.CategoryRepository, taxRateRepo repository.TaxRateRepository,
+	transactor repository.Transactor) *CategoryService {
+	return &CategoryService{repo: repo, taxRateRepo: taxRateRepo, transactor: transactor}
 }
 
 func (s *CategoryService) GetAll() ([]domain.Category, error) {
 	return s.repo.GetAll()
 }
 
-func (s *CategoryService) Create(category *domain.Category) error {
-	return s.repo.Create(category)
+// GetTree returns the top-level categories with their subcategories nested
+// in Children
+func (s *CategoryService) GetTree() ([]domain.Category, error) {
+	categories, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return domain.CategoryTree(categories), nil
+}
+
+func (s *CategoryService) Create(category *domain.Category, meta domain.ChangeMeta) error {
+	if err := checkTaxRate(s.taxRateRepo, category.TaxRateID); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := checkParent(repos.Categories, category); err != nil {
+			return err
+		}
+		if err := repos.Categories.Create(category); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityCategory, category.ID,
+			nil, category)
+	})
 }
 
 func (s *CategoryService) GetByID(id int) (*domain.Category, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *CategoryService) Update(category *domain.Category) error {
-	return s.repo.Update(category)
+func (s *CategoryService) Update(category *domain.Category, meta domain.ChangeMeta) error {
+	if err := checkTaxRate(s.taxRateRepo, category.TaxRateID); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Categories.GetByIDForUpdate(category.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkParent(repos.Categories, category); err != nil {
+			return err
+		}
+		if err := repos.Categories.Update(category); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityCategory, category.ID,
+			current, category)
+	})
+}
+
+func (s *CategoryService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Categories.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Categories.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityCategory, id,
+			current, nil)
+	})
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+// checkParent verifies that a category's parent exists and that the category
+// is not being placed under itself or one of its own subcategories, which
+// would cut the branch off from the tree. The ancestors stay locked until the
+// transaction ends, so a concurrent move cannot slip a cycle in between the
+// check and the write, and the walk stops at a category it has already seen.
+func checkParent(repo repository.CategoryRepository, category *domain.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if *category.ParentID == category.ID {
+		return invalidInput("a category cannot be its own parent")
+	}
+
+	seen := make(map[int]bool)
+	for id := category.ParentID; id != nil && !seen[*id]; {
+		seen[*id] = true
+		ancestor, err := repo.GetByIDForUpdate(*id)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrCategoryNotFound
+			}
+			return err
+		}
+		if category.ID != 0 && ancestor.ID == category.ID {
+			return invalidInput("a category cannot be moved under its own subcategory")
+		}
+		id = ancestor.ParentID
+	}
+	return nil
 }
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// CustomerService handles customer business logic
+type CustomerService struct {
+	repo       repository.CustomerRepository
+	cartRepo   repository.CartRepository
+	transactor repository.Transactor
+}
+
+// NewCustomerService creates a new customer service
+func NewCustomerService(repo repository.CustomerRepository, cartRepo repository.CartRepository,
+	transactor repository.Transactor) *CustomerService {
+	return &CustomerService{repo: repo, cartRepo: cartRepo, transactor: transactor}
+}
+
+func (s *CustomerService) GetAll() ([]domain.Customer, error) {
+	return s.repo.GetAll()
+}
+
+func (s *CustomerService) GetByID(id int) (*domain.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *CustomerService) Create(customer *domain.Customer, meta domain.ChangeMeta) error {
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Customers.Create(customer); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityCustomer, customer.ID,
+			nil, customer)
+	})
+}
+
+// Update saves the customer's details. Points are left as they are.
+func (s *CustomerService) Update(customer *domain.Customer, meta domain.ChangeMeta) error {
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Customers.GetByID(customer.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Customers.Update(customer); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityCustomer, customer.ID,
+			current, customer)
+	})
+}
+
+// Delete removes a customer. Their past sales are kept without a customer.
+func (s *CustomerService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Customers.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Customers.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityCustomer, id,
+			current, nil)
+	})
+}
+
+// GetTransactions returns the carts a customer checked out, newest first
+func (s *CustomerService) GetTransactions(id int) ([]domain.Cart, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetByCustomer(id)
+}
+
+func validateCustomer(c *domain.Customer) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.MemberCode = strings.TrimSpace(c.MemberCode)
+	if c.Name == "" {
+		return invalidInput("name is required")
+	}
+	if c.Email != "" && !strings.Contains(c.Email, "@") {
+		return invalidInput("email is not valid")
+	}
+	return nil
+}
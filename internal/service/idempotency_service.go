@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// IdempotencyService remembers the first response to requests sent with an
+// idempotency key so that retries get it back instead of repeating the change
+type IdempotencyService struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewIdempotencyService creates a new idempotency service. Responses are
+// kept for ttl after the first request.
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		repo: repo,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Begin claims key for a request to route whose body hashes to requestHash.
+// It returns the stored record when the same request was already handled,
+// and nil when the caller should handle it and then call Complete or Abort.
+// A key reused with a different body fails with ErrIdempotencyKeyReused, and
+// a retry while the first request is still running with
+// ErrIdempotencyInProgress.
+func (s *IdempotencyService) Begin(key, route, requestHash string) (*domain.IdempotencyRecord, error) {
+	now := s.now()
+	record, err := s.repo.Get(key, route, now)
+	if err == nil {
+		if record.RequestHash != requestHash {
+			return nil, apperrors.ErrIdempotencyKeyReused
+		}
+		if !record.Completed() {
+			return nil, apperrors.ErrIdempotencyInProgress
+		}
+		return record, nil
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, err
+	}
+
+	err = s.repo.Reserve(&domain.IdempotencyRecord{
+		Key:         key,
+		Route:       route,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if errors.Is(err, apperrors.ErrConflict) {
+		return nil, apperrors.ErrIdempotencyInProgress
+	}
+	return nil, err
+}
+
+// Complete stores the response to replay for retries of a begun request
+func (s *IdempotencyService) Complete(key, route string, statusCode int, body []byte) error {
+	return s.repo.Complete(key, route, statusCode, body)
+}
+
+// Abort forgets a begun request so that it can be retried from scratch
+func (s *IdempotencyService) Abort(key, route string) error {
+	return s.repo.Delete(key, route)
+}
+
+// ReleaseExpired deletes stored responses whose TTL has passed and returns
+// how many were deleted
+func (s *IdempotencyService) ReleaseExpired() (int, error) {
+	return s.repo.DeleteExpired(s.now())
+}
+
+// RunReaper deletes expired responses every interval until ctx is cancelled
+func (s *IdempotencyService) RunReaper(ctx context.Context, interval time.Duration) {
+	runReaper(ctx, interval, "idempotency keys", s.ReleaseExpired)
+}
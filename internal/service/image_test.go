@@ -0,0 +1,46 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnail_Size(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{name: "landscape", width: 1200, height: 900, wantWidth: 300, wantHeight: 225},
+		{name: "portrait", width: 900, height: 1200, wantWidth: 225, wantHeight: 300},
+		{name: "thin strip keeps a pixel", width: 3000, height: 2, wantWidth: 300, wantHeight: 1},
+		{name: "already small", width: 200, height: 100, wantWidth: 200, wantHeight: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := thumbnail(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height)), 300).Bounds()
+			if got.Dx() != tt.wantWidth || got.Dy() != tt.wantHeight {
+				t.Errorf("thumbnail size = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestThumbnail_AveragesPixels(t *testing.T) {
+	src := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		src.Set(x, 10, color.RGBA{R: 255, A: 255})
+		src.Set(x, 11, color.RGBA{B: 255, A: 255})
+	}
+
+	got := thumbnail(src, 2)
+	if b := got.Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+		t.Fatalf("thumbnail size = %dx%d, want 2x1", b.Dx(), b.Dy())
+	}
+	r, g, b, a := got.At(0, 0).RGBA()
+	if r>>8 != 127 || g != 0 || b>>8 != 127 || a>>8 != 255 {
+		t.Errorf("pixel = %d,%d,%d,%d, want an even mix of red and blue", r>>8, g>>8, b>>8, a>>8)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// OutletService handles outlet business logic
+type OutletService struct {
+	repo       repository.OutletRepository
+	transactor repository.Transactor
+}
+
+// NewOutletService creates a new outlet service
+func NewOutletService(repo repository.OutletRepository, transactor repository.Transactor) *OutletService {
+	return &OutletService{repo: repo, transactor: transactor}
+}
+
+func (s *OutletService) GetAll() ([]domain.Outlet, error) {
+	return s.repo.GetAll()
+}
+
+func (s *OutletService) GetByID(id int) (*domain.Outlet, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *OutletService) Create(outlet *domain.Outlet, meta domain.ChangeMeta) error {
+	if err := validateOutlet(outlet); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Outlets.Create(outlet); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityOutlet, outlet.ID,
+			nil, outlet)
+	})
+}
+
+func (s *OutletService) Update(outlet *domain.Outlet, meta domain.ChangeMeta) error {
+	if err := validateOutlet(outlet); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Outlets.GetByID(outlet.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Outlets.Update(outlet); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityOutlet, outlet.ID,
+			current, outlet)
+	})
+}
+
+// Delete removes an outlet. It fails with ErrConflict for the default outlet
+// and while stock, carts, orders or transfers still refer to the outlet.
+func (s *OutletService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Outlets.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if id == domain.DefaultOutletID {
+			return apperrors.ErrConflict
+		}
+		if err := repos.Outlets.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityOutlet, id,
+			current, nil)
+	})
+}
+
+func validateOutlet(o *domain.Outlet) error {
+	o.Code = strings.ToUpper(strings.TrimSpace(o.Code))
+	o.Name = strings.TrimSpace(o.Name)
+	if o.Code == "" {
+		return invalidInput("code is required")
+	}
+	if o.Name == "" {
+		return invalidInput("name is required")
+	}
+	return nil
+}
+
+// resolveOutlet returns the outlet a stock operation applies to: the default
+// outlet when id is zero, otherwise id once it is known to exist
+func resolveOutlet(repo repository.OutletRepository, id int) (int, error) {
+	if id == 0 {
+		return domain.DefaultOutletID, nil
+	}
+	return id, checkOutlet(repo, id)
+}
+
+// checkOutlet fails with ErrOutletNotFound unless id is zero, meaning every
+// outlet, or an existing outlet
+func checkOutlet(repo repository.OutletRepository, id int) error {
+	if id == 0 {
+		return nil
+	}
+	if _, err := repo.GetByID(id); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrOutletNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import "kasir-api/internal/domain"
+
+// settlePayments checks that payments cover total and works out the cash
+// change. Non-cash tenders are charged exactly, so they may not exceed the
+// total on their own; any overpayment must come from cash.
+func settlePayments(total int, payments []domain.Payment) (*domain.Settlement, error) {
+	if len(payments) == 0 {
+		return nil, invalidInput("at least one payment is required")
+	}
+
+	paid, nonCash := 0, 0
+	for _, p := range payments {
+		switch p.Method {
+		case domain.PaymentCash:
+		case domain.PaymentQRIS, domain.PaymentDebitCard, domain.PaymentEWallet:
+			nonCash += p.Amount
+		default:
+			return nil, invalidInput("payment method must be cash, qris, debit_card or e_wallet")
+		}
+		if p.Amount < 1 {
+			return nil, invalidInput("payment amount must be positive")
+		}
+		paid += p.Amount
+	}
+
+	if paid < total {
+		return nil, invalidInput("payments do not cover the total")
+	}
+	if nonCash > total {
+		return nil, invalidInput("non-cash payments cannot exceed the total")
+	}
+
+	return &domain.Settlement{
+		Payments: payments,
+		Total:    total,
+		Paid:     paid,
+		Change:   paid - total,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+)
+
+func TestSettlePayments(t *testing.T) {
+	cash := func(amount int) domain.Payment { return domain.Payment{Method: domain.PaymentCash, Amount: amount} }
+	qris := func(amount int) domain.Payment { return domain.Payment{Method: domain.PaymentQRIS, Amount: amount} }
+
+	tests := []struct {
+		name       string
+		total      int
+		payments   []domain.Payment
+		wantChange int
+		wantErr    string
+	}{
+		{name: "exact cash", total: 18500, payments: []domain.Payment{cash(18500)}},
+		{name: "cash with change", total: 18500, payments: []domain.Payment{cash(20000)}, wantChange: 1500},
+		{name: "exact qris", total: 18500, payments: []domain.Payment{qris(18500)}},
+		{name: "split qris and cash with change", total: 18500, payments: []domain.Payment{qris(10000), cash(10000)}, wantChange: 1500},
+		{name: "split across all methods", total: 40000, payments: []domain.Payment{
+			cash(10000), qris(10000), {Method: domain.PaymentDebitCard, Amount: 10000}, {Method: domain.PaymentEWallet, Amount: 10000},
+		}},
+		{name: "no payments", total: 18500, wantErr: "invalid input: at least one payment is required"},
+		{name: "unknown method", total: 18500, payments: []domain.Payment{{Method: "cheque", Amount: 18500}}, wantErr: "invalid input: payment method must be cash, qris, debit_card or e_wallet"},
+		{name: "zero amount", total: 18500, payments: []domain.Payment{cash(18500), qris(0)}, wantErr: "invalid input: payment amount must be positive"},
+		{name: "short", total: 18500, payments: []domain.Payment{qris(10000), cash(5000)}, wantErr: "invalid input: payments do not cover the total"},
+		{name: "non-cash overpayment", total: 18500, payments: []domain.Payment{qris(20000)}, wantErr: "invalid input: non-cash payments cannot exceed the total"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement, err := settlePayments(tt.total, tt.payments)
+			if tt.wantErr != "" {
+				if !errors.Is(err, apperrors.ErrInvalidInput) || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("settlePayments: %v", err)
+			}
+			if settlement.Change != tt.wantChange || settlement.Paid != tt.total+tt.wantChange {
+				t.Errorf("settlement = %+v, want change %d", settlement, tt.wantChange)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"net/http"
+
+	// Register the decoders for the accepted image types
+	_ "image/jpeg"
+	_ "image/png"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+	"kasir-api/internal/storage"
+)
+
+// ProductImageService handles product photos: it checks uploads, stores each
+// original with a thumbnail and keeps their records
+type ProductImageService struct {
+	productRepo repository.ProductRepository
+	imageRepo   repository.ProductImageRepository
+	store       storage.Storage
+	transactor  repository.Transactor
+	maxSize     int
+}
+
+// NewProductImageService creates a new product image service accepting
+// uploads of up to maxSize bytes
+func NewProductImageService(productRepo repository.ProductRepository, imageRepo repository.ProductImageRepository,
+	store storage.Storage, transactor repository.Transactor, maxSize int) *ProductImageService {
+	return &ProductImageService{productRepo: productRepo, imageRepo: imageRepo, store: store, transactor: transactor,
+		maxSize: maxSize}
+}
+
+// GetByProductID returns a product's images in upload order
+func (s *ProductImageService) GetByProductID(productID int) ([]domain.ProductImage, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	images, err := s.imageRepo.GetByProductIDs([]int{productID})
+	if err != nil {
+		return nil, err
+	}
+	setImageURLs(s.store, images)
+	return images, nil
+}
+
+// Upload stores a JPEG or PNG photo of a product and its thumbnail. The type
+// is detected from the content rather than trusted from the client. The
+// files are removed again if the record cannot be saved.
+func (s *ProductImageService) Upload(productID int, r io.Reader, meta domain.ChangeMeta) (*domain.ProductImage, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, int64(s.maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > s.maxSize {
+		return nil, apperrors.ErrImageTooLarge
+	}
+	if len(data) == 0 {
+		return nil, invalidInput("image is empty")
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, apperrors.ErrUnsupportedImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, invalidInput("image could not be read")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, invalidInput(fmt.Sprintf("image must not have more than %d pixels", maxImagePixels))
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, invalidInput("image could not be read")
+	}
+	thumb, err := encodeImage(thumbnail(decoded, domain.ThumbnailSize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	name := randomName()
+	img := domain.ProductImage{
+		ProductID:    productID,
+		Key:          fmt.Sprintf("products/%d/%s%s", productID, name, ext),
+		ThumbnailKey: fmt.Sprintf("products/%d/%s_thumb%s", productID, name, ext),
+		ContentType:  contentType,
+		Size:         len(data),
+		Width:        config.Width,
+		Height:       config.Height,
+	}
+	if err := s.store.Save(img.Key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(img.ThumbnailKey, bytes.NewReader(thumb)); err != nil {
+		removeImageFiles(s.store, img)
+		return nil, err
+	}
+
+	err = s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.ProductImages.GetByProductIDs([]int{productID})
+		if err != nil {
+			return err
+		}
+		if err := repos.ProductImages.Create(&img); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityProduct, productID,
+			imagesSnapshot(s.store, current), imagesSnapshot(s.store, append(current, img)))
+	})
+	if err != nil {
+		removeImageFiles(s.store, img)
+		return nil, err
+	}
+
+	img.URL, img.ThumbnailURL = s.store.URL(img.Key), s.store.URL(img.ThumbnailKey)
+	return &img, nil
+}
+
+// Delete removes one of a product's images, and its files once the record
+// is gone
+func (s *ProductImageService) Delete(productID, imageID int, meta domain.ChangeMeta) error {
+	var removed domain.ProductImage
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if _, err := repos.Products.GetByID(productID); err != nil {
+			return err
+		}
+		current, err := repos.ProductImages.GetByProductIDs([]int{productID})
+		if err != nil {
+			return err
+		}
+		remaining := make([]domain.ProductImage, 0, len(current))
+		for _, img := range current {
+			if img.ID == imageID {
+				removed = img
+				continue
+			}
+			remaining = append(remaining, img)
+		}
+		if removed.ID == 0 {
+			return apperrors.ErrImageNotFound
+		}
+
+		if err := repos.ProductImages.Delete(imageID); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityProduct, productID,
+			imagesSnapshot(s.store, current), imagesSnapshot(s.store, remaining))
+	})
+	if err != nil {
+		return err
+	}
+
+	removeImageFiles(s.store, removed)
+	return nil
+}
+
+// removeImageFiles deletes the originals and thumbnails of images from
+// storage. A failure only leaves an orphaned file behind, so it is logged
+// rather than returned.
+func removeImageFiles(store storage.Storage, images ...domain.ProductImage) {
+	for _, img := range images {
+		for _, key := range []string{img.Key, img.ThumbnailKey} {
+			if err := store.Delete(key); err != nil {
+				log.Println("Error removing image file:", key, err)
+			}
+		}
+	}
+}
+
+// setImageURLs fills in where clients fetch each image and its thumbnail
+func setImageURLs(store storage.Storage, images []domain.ProductImage) {
+	for i := range images {
+		images[i].URL = store.URL(images[i].Key)
+		images[i].ThumbnailURL = store.URL(images[i].ThumbnailKey)
+	}
+}
+
+// imagesSnapshot is how a product's images appear in its audit entries
+func imagesSnapshot(store storage.Storage, images []domain.ProductImage) map[string][]domain.ProductImage {
+	snapshot := make([]domain.ProductImage, len(images))
+	copy(snapshot, images)
+	setImageURLs(store, snapshot)
+	return map[string][]domain.ProductImage{"images": snapshot}
+}
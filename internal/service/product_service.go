@@ -2,58 +2,588 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"time"
 
 	"kasir-api/internal/apperrors"
 	"kasir-api/internal/domain"
 	"kasir-api/internal/repository"
+	"kasir-api/internal/storage"
 )
 
 // ProductService handles product business logic
 type ProductService struct {
-	productRepo  repository.ProductRepository
-	categoryRepo repository.CategoryRepository
+	productRepo      repository.ProductRepository
+	categoryRepo     repository.CategoryRepository
+	priceHistoryRepo repository.PriceHistoryRepository
+	taxRateRepo      repository.TaxRateRepository
+	reservationRepo  repository.ReservationRepository
+	movementRepo     repository.StockMovementRepository
+	outletRepo       repository.OutletRepository
+	unitRepo         repository.UnitRepository
+	componentRepo    repository.ProductComponentRepository
+	imageRepo        repository.ProductImageRepository
+	images           storage.Storage
+	transactor       repository.Transactor
+	now              func() time.Time
 }
 
 // NewProductService creates a new product service
-func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) *ProductService {
+func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository,
+	priceHistoryRepo repository.PriceHistoryRepository, taxRateRepo repository.TaxRateRepository,
+	reservationRepo repository.ReservationRepository, movementRepo repository.StockMovementRepository,
+	outletRepo repository.OutletRepository, unitRepo repository.UnitRepository,
+	componentRepo repository.ProductComponentRepository, imageRepo repository.ProductImageRepository,
+	images storage.Storage, transactor repository.Transactor) *ProductService {
 	return &ProductService{
-		productRepo:  productRepo,
-		categoryRepo: categoryRepo,
+		productRepo:      productRepo,
+		categoryRepo:     categoryRepo,
+		priceHistoryRepo: priceHistoryRepo,
+		taxRateRepo:      taxRateRepo,
+		reservationRepo:  reservationRepo,
+		movementRepo:     movementRepo,
+		outletRepo:       outletRepo,
+		unitRepo:         unitRepo,
+		componentRepo:    componentRepo,
+		imageRepo:        imageRepo,
+		images:           images,
+		transactor:       transactor,
+		now:              time.Now,
 	}
 }
 
-func (s *ProductService) GetAll() ([]domain.Product, error) {
-	return s.productRepo.GetAll()
+// GetAll lists products with their stock at an outlet, the default outlet
+// when outletID is zero. A non-zero categoryID keeps only the products in
+// that category or any of its subcategories. With groupVariants, variants are
+// nested under their parent instead of listed alongside it.
+func (s *ProductService) GetAll(outletID, categoryID int, groupVariants bool) ([]domain.Product, error) {
+	outletID, err := resolveOutlet(s.outletRepo, outletID)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.list(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setStock(outletID, products...); err != nil {
+		return nil, err
+	}
+	if err := s.setImages(products); err != nil {
+		return nil, err
+	}
+	if groupVariants {
+		return domain.GroupVariants(products), nil
+	}
+	return products, nil
 }
 
-func (s *ProductService) Create(product *domain.Product) error {
+// list returns every product, or when categoryID is set only those in that
+// category and its subcategories
+func (s *ProductService) list(categoryID int) ([]domain.Product, error) {
+	if categoryID == 0 {
+		return s.productRepo.GetAll()
+	}
+	if _, err := s.categoryRepo.GetByID(categoryID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return s.productRepo.GetByCategory(categoryID)
+}
+
+// GetVariants lists a product's variants with their stock at an outlet, the
+// default outlet when outletID is zero
+func (s *ProductService) GetVariants(id, outletID int) ([]domain.Product, error) {
+	outletID, err := resolveOutlet(s.outletRepo, outletID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	variants, err := s.productRepo.GetVariants(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setStock(outletID, variants...); err != nil {
+		return nil, err
+	}
+	if err := s.setImages(variants); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// Create saves a new product with its stock at an outlet, the default outlet
+// when outletID is zero, writing any opening stock to the stock ledger as an
+// adjustment. Bundles are saved without stock.
+func (s *ProductService) Create(product *domain.Product, outletID int, meta domain.ChangeMeta) error {
+	outletID, err := resolveOutlet(s.outletRepo, outletID)
+	if err != nil {
+		return err
+	}
+	if product.CostPrice < 0 {
+		return invalidInput("cost_price cannot be negative")
+	}
+	if err := s.checkType(product); err != nil {
+		return err
+	}
+	if err := s.checkVariant(product); err != nil {
+		return err
+	}
 	// Validate category exists
-	_, err := s.categoryRepo.GetByID(product.CategoryID)
+	_, err = s.categoryRepo.GetByID(product.CategoryID)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return apperrors.ErrCategoryNotFound
 		}
 		return err
 	}
-	return s.productRepo.Create(product)
+	if err := checkTaxRate(s.taxRateRepo, product.TaxRateID); err != nil {
+		return err
+	}
+	if product.UnitID, err = resolveUnit(s.unitRepo, product.UnitID); err != nil {
+		return err
+	}
+
+	product.CalculateProfit()
+	product.SetReserved(0)
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Products.Create(product); err != nil {
+			return err
+		}
+		if product.Type != domain.ProductTypeBundle {
+			_, err := adjustStock(repos, product.ID, outletID, product.Stock, productReference(product.ID),
+				meta.Actor, s.now())
+			if err != nil {
+				return err
+			}
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityProduct, product.ID,
+			nil, productSnapshot(product))
+	})
 }
 
-func (s *ProductService) GetByID(id int) (*domain.Product, error) {
-	return s.productRepo.GetByID(id)
+// GetByID returns a product with its stock at an outlet, the default outlet
+// when outletID is zero
+func (s *ProductService) GetByID(id, outletID int) (*domain.Product, error) {
+	outletID, err := resolveOutlet(s.outletRepo, outletID)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.productRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	products := []domain.Product{*product}
+	if err := s.setStock(outletID, products...); err != nil {
+		return nil, err
+	}
+	if err := s.setImages(products); err != nil {
+		return nil, err
+	}
+	return &products[0], nil
 }
 
-func (s *ProductService) Update(product *domain.Product) error {
+// Update saves the product and its stock at an outlet, the default outlet
+// when outletID is zero; the stock of bundles is left alone. A change of
+// stock is written to the stock ledger as an adjustment, and lowering it
+// takes it out of the product's batches first expired first out. When the
+// price changed, the old and new price are recorded in the same transaction
+// as the audit entry.
+func (s *ProductService) Update(product *domain.Product, outletID int, meta domain.ChangeMeta) error {
+	outletID, err := resolveOutlet(s.outletRepo, outletID)
+	if err != nil {
+		return err
+	}
+	if product.CostPrice < 0 {
+		return invalidInput("cost_price cannot be negative")
+	}
+	if err := s.checkType(product); err != nil {
+		return err
+	}
+	if err := s.checkVariant(product); err != nil {
+		return err
+	}
 	// Validate category exists
-	_, err := s.categoryRepo.GetByID(product.CategoryID)
+	_, err = s.categoryRepo.GetByID(product.CategoryID)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return apperrors.ErrCategoryNotFound
 		}
 		return err
 	}
-	return s.productRepo.Update(product)
+	if err := checkTaxRate(s.taxRateRepo, product.TaxRateID); err != nil {
+		return err
+	}
+	if product.UnitID, err = resolveUnit(s.unitRepo, product.UnitID); err != nil {
+		return err
+	}
+
+	product.CalculateProfit()
+	err = s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Products.GetByID(product.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Products.Update(product); err != nil {
+			return err
+		}
+		current.Stock = 0
+		switch {
+		case product.Type != domain.ProductTypeBundle:
+			current.Stock, err = adjustStock(repos, product.ID, outletID, product.Stock, productReference(product.ID),
+				meta.Actor, s.now())
+		case current.Type != domain.ProductTypeBundle:
+			current.Stock, err = repos.Products.GetStock(product.ID, outletID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if current.Price != product.Price {
+			err := repos.PriceHistory.Create(&domain.PriceChange{
+				ProductID: product.ID,
+				OldPrice:  current.Price,
+				NewPrice:  product.Price,
+				ChangedBy: meta.Actor,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityProduct, product.ID,
+			productSnapshot(current), productSnapshot(product))
+	})
+	if err != nil {
+		return err
+	}
+
+	products := []domain.Product{*product}
+	if err := s.setStock(outletID, products...); err != nil {
+		return err
+	}
+	if err := s.setImages(products); err != nil {
+		return err
+	}
+	*product = products[0]
+	return nil
+}
+
+// Delete removes a product along with its images. It fails with ErrConflict
+// while the product has variants or is part of a bundle.
+func (s *ProductService) Delete(id int, meta domain.ChangeMeta) error {
+	var images []domain.ProductImage
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Products.GetByID(id)
+		if err != nil {
+			return err
+		}
+		bundles, err := repos.ProductComponents.GetBundleIDs(id)
+		if err != nil {
+			return err
+		}
+		if len(bundles) > 0 {
+			return apperrors.ErrConflict
+		}
+		if images, err = repos.ProductImages.GetByProductIDs([]int{id}); err != nil {
+			return err
+		}
+		for _, img := range images {
+			if err := repos.ProductImages.Delete(img.ID); err != nil {
+				return err
+			}
+		}
+		if err := repos.Products.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityProduct, id,
+			productSnapshot(current), nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	removeImageFiles(s.images, images...)
+	return nil
+}
+
+// GetPriceHistory returns the price changes of a product, newest first. A
+// deleted product is ErrNotFound: its history is kept for auditing with the
+// product id cleared, so it can only be read from the database.
+func (s *ProductService) GetPriceHistory(productID int) ([]domain.PriceChange, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.priceHistoryRepo.GetByProductID(productID)
+}
+
+// GetStockMovements returns a product's stock ledger, newest first, only the
+// movements at outletID unless it is zero
+func (s *ProductService) GetStockMovements(productID, outletID int) ([]domain.StockMovement, error) {
+	if err := checkOutlet(s.outletRepo, outletID); err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.movementRepo.GetByProductID(productID, outletID)
+}
+
+// GetComponents returns what one unit of a bundle consumes of each component
+func (s *ProductService) GetComponents(id int) ([]domain.BundleComponent, error) {
+	if _, err := s.productRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.componentRepo.GetByBundleID(id)
+}
+
+// SetComponents replaces the components of a bundle. Components are standard
+// products other than the bundle, each listed once with a positive quantity
+// in its base unit. The change is audited as an update of the bundle.
+func (s *ProductService) SetComponents(id int, components []domain.BundleComponent, meta domain.ChangeMeta) ([]domain.BundleComponent, error) {
+	if components == nil {
+		components = []domain.BundleComponent{}
+	}
+	seen := make(map[int]bool, len(components))
+	for _, c := range components {
+		if c.Quantity <= 0 {
+			return nil, invalidInput("quantity must be greater than zero")
+		}
+		if c.ProductID == id {
+			return nil, invalidInput("a bundle cannot contain itself")
+		}
+		if seen[c.ProductID] {
+			return nil, invalidInput(fmt.Sprintf("product %d is listed more than once", c.ProductID))
+		}
+		seen[c.ProductID] = true
+	}
+
+	var saved []domain.BundleComponent
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		bundle, err := repos.Products.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if bundle.Type != domain.ProductTypeBundle {
+			return invalidInput("only bundles have components")
+		}
+		for _, c := range components {
+			component, err := repos.Products.GetByID(c.ProductID)
+			if err != nil {
+				if errors.Is(err, apperrors.ErrNotFound) {
+					return apperrors.ErrProductNotFound
+				}
+				return err
+			}
+			if component.Type == domain.ProductTypeBundle {
+				return invalidInput("bundles cannot contain other bundles")
+			}
+		}
+
+		current, err := repos.ProductComponents.GetByBundleID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.ProductComponents.ReplaceForBundle(id, components); err != nil {
+			return err
+		}
+		if saved, err = repos.ProductComponents.GetByBundleID(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityProduct, id,
+			domain.BundleComponentsInput{Components: current}, domain.BundleComponentsInput{Components: saved})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// checkType defaults a product to a standard product and validates a change
+// of type. A bundle holds no stock of its own, cannot be part of another
+// bundle and keeps its type while it has components.
+func (s *ProductService) checkType(product *domain.Product) error {
+	switch product.Type {
+	case "":
+		product.Type = domain.ProductTypeStandard
+	case domain.ProductTypeStandard, domain.ProductTypeBundle:
+	default:
+		return invalidInput("type must be standard or bundle")
+	}
+	if product.Type == domain.ProductTypeBundle {
+		product.Stock = 0
+	}
+	if product.ID == 0 {
+		return nil
+	}
+
+	if product.Type == domain.ProductTypeBundle {
+		bundles, err := s.componentRepo.GetBundleIDs(product.ID)
+		if err != nil {
+			return err
+		}
+		if len(bundles) > 0 {
+			return invalidInput("a product that is part of a bundle cannot become a bundle")
+		}
+		return nil
+	}
+	components, err := s.componentRepo.GetByBundleID(product.ID)
+	if err != nil {
+		return err
+	}
+	if len(components) > 0 {
+		return invalidInput("a bundle with components cannot become a standard product")
+	}
+	return nil
+}
+
+// checkStocked fails for bundles, which are only stocked through their
+// components
+func checkStocked(product *domain.Product) error {
+	if product.Type == domain.ProductTypeBundle {
+		return invalidInput(fmt.Sprintf("%s is a bundle and has no stock of its own", product.Name))
+	}
+	return nil
+}
+
+// checkVariant validates a product's place in the variant tree. A variant
+// must hang off an existing top-level product, be told apart from its
+// siblings by its options, and takes its parent's category unless it names
+// one. Only variants have options.
+func (s *ProductService) checkVariant(product *domain.Product) error {
+	// Variants are only ever filled in when listing
+	product.Variants = nil
+	for name, value := range product.Options {
+		if name == "" || value == "" {
+			return invalidInput("option names and values must not be empty")
+		}
+	}
+	if product.ParentID == nil {
+		if len(product.Options) > 0 {
+			return invalidInput("options are only allowed on variants")
+		}
+		return nil
+	}
+	if len(product.Options) == 0 {
+		return invalidInput("variants need at least one option")
+	}
+	if *product.ParentID == product.ID {
+		return invalidInput("a product cannot be its own variant")
+	}
+
+	parent, err := s.productRepo.GetByID(*product.ParentID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrProductNotFound
+		}
+		return err
+	}
+	if parent.ParentID != nil {
+		return invalidInput("variants cannot have variants")
+	}
+	if product.CategoryID == 0 {
+		product.CategoryID = parent.CategoryID
+	}
+
+	siblings, err := s.productRepo.GetVariants(parent.ID)
+	if err != nil {
+		return err
+	}
+	for _, sibling := range siblings {
+		if sibling.ID != product.ID && maps.Equal(sibling.Options, product.Options) {
+			return invalidInput("another variant has the same options")
+		}
+	}
+
+	if product.ID == 0 {
+		return nil
+	}
+	own, err := s.productRepo.GetVariants(product.ID)
+	if err != nil {
+		return err
+	}
+	if len(own) > 0 {
+		return invalidInput("a product with variants cannot become a variant")
+	}
+	return nil
+}
+
+// setStock fills the products' stock at the outlet, their profit figures,
+// how much of the stock unexpired cart reservations hold and how much is
+// left to sell. A bundle has as much stock as its components make up whole
+// bundles of, and as much left to sell as their unreserved stock makes up.
+func (s *ProductService) setStock(outletID int, products ...domain.Product) error {
+	levels, err := s.productRepo.StockLevels(outletID)
+	if err != nil {
+		return err
+	}
+	reserved, err := s.reservationRepo.ReservedTotals(s.now(), outletID, 0)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		p := &products[i]
+		p.CalculateProfit()
+		if p.Type != domain.ProductTypeBundle {
+			p.Stock = levels[p.ID]
+			p.SetReserved(reserved[p.ID])
+			continue
+		}
+
+		components, err := s.componentRepo.GetByBundleID(p.ID)
+		if err != nil {
+			return err
+		}
+		unreserved := make(map[int]int, len(components))
+		for _, c := range components {
+			unreserved[c.ProductID] = levels[c.ProductID] - reserved[c.ProductID]
+		}
+		p.Stock = domain.BundleStock(components, levels)
+		p.SetReserved(p.Stock - domain.BundleStock(components, unreserved))
+	}
+	return nil
+}
+
+// setImages fills in the products' images
+func (s *ProductService) setImages(products []domain.Product) error {
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	images, err := s.imageRepo.GetByProductIDs(ids)
+	if err != nil {
+		return err
+	}
+	setImageURLs(s.images, images)
+
+	byProduct := make(map[int][]domain.ProductImage)
+	for _, img := range images {
+		byProduct[img.ProductID] = append(byProduct[img.ProductID], img)
+	}
+	for i := range products {
+		products[i].Images = byProduct[products[i].ID]
+	}
+	return nil
+}
+
+// productReference is the stock ledger reference for stock set by editing a
+// product
+func productReference(id int) string {
+	return fmt.Sprintf("PRODUCT-%d", id)
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.productRepo.Delete(id)
+// productSnapshot drops the joined category, images and reservation figures
+// so audit snapshots only hold the product's own columns, plus the derived
+// profit figures
+func productSnapshot(p *domain.Product) *domain.Product {
+	snapshot := *p
+	snapshot.Category = nil
+	snapshot.Images = nil
+	snapshot.Reserved, snapshot.Available = 0, 0
+	snapshot.CalculateProfit()
+	return &snapshot
 }
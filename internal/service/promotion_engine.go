@@ -0,0 +1,153 @@
+package service
+
+import (
+	"kasir-api/internal/domain"
+)
+
+// applyPromotions fills in line and cart discounts on a quote whose lines
+// already carry unit prices and subtotals.
+//
+// Product and category promotions are evaluated per line, then cart
+// promotions against the sum of the discounted lines. At each level either
+// every stackable promotion applies or the single best non-stackable one does,
+// whichever takes more off; a discount never exceeds the amount it applies to.
+// A category promotion also covers the category's subcategories, found by
+// following parents, which maps each subcategory to its parent.
+func applyPromotions(quote *domain.Quote, promotions []domain.Promotion, parents map[int]int) {
+	quote.Subtotal, quote.Discount = 0, 0
+	base := 0
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		var candidates []domain.Promotion
+		for _, p := range promotions {
+			if appliesToLine(p, *line, parents) {
+				candidates = append(candidates, p)
+			}
+		}
+
+		line.Discounts = chooseDiscounts(candidates, line.Subtotal, func(p domain.Promotion) int {
+			return lineDiscount(p, *line)
+		})
+		line.Discount = sumDiscounts(line.Discounts)
+		line.Total = line.Subtotal - line.Discount
+
+		quote.Subtotal += line.Subtotal
+		quote.Discount += line.Discount
+		base += line.Total
+	}
+
+	var candidates []domain.Promotion
+	for _, p := range promotions {
+		if p.Scope == domain.PromotionScopeCart && base >= p.MinSubtotal {
+			candidates = append(candidates, p)
+		}
+	}
+	quote.CartDiscounts = chooseDiscounts(candidates, base, func(p domain.Promotion) int {
+		return cartDiscount(p, base)
+	})
+	quote.Discount += sumDiscounts(quote.CartDiscounts)
+	quote.Total = quote.Subtotal - quote.Discount
+}
+
+func appliesToLine(p domain.Promotion, line domain.QuoteLine, parents map[int]int) bool {
+	switch p.Scope {
+	case domain.PromotionScopeProduct:
+		return p.ProductID != nil && *p.ProductID == line.ProductID
+	case domain.PromotionScopeCategory:
+		return p.CategoryID != nil && inCategory(line.CategoryID, *p.CategoryID, parents)
+	}
+	return false
+}
+
+// inCategory reports whether categoryID is ancestorID or one of its
+// subcategories. The walk is bounded so a corrupt cycle cannot loop forever.
+func inCategory(categoryID, ancestorID int, parents map[int]int) bool {
+	for range len(parents) + 1 {
+		if categoryID == ancestorID {
+			return true
+		}
+		parent, ok := parents[categoryID]
+		if !ok {
+			return false
+		}
+		categoryID = parent
+	}
+	return false
+}
+
+// categoryParents maps each subcategory to its parent
+func categoryParents(categories []domain.Category) map[int]int {
+	parents := make(map[int]int)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			parents[c.ID] = *c.ParentID
+		}
+	}
+	return parents
+}
+
+func lineDiscount(p domain.Promotion, line domain.QuoteLine) int {
+	switch p.Type {
+	case domain.PromotionPercentage:
+		return line.Subtotal * p.Value / 100
+	case domain.PromotionFixedAmount:
+		return p.Value * line.Quantity
+	case domain.PromotionBuyXGetY:
+		group := p.BuyQuantity + p.FreeQuantity
+		if p.BuyQuantity <= 0 || p.FreeQuantity <= 0 {
+			return 0
+		}
+		return line.Quantity / group * p.FreeQuantity * line.UnitPrice
+	}
+	return 0
+}
+
+func cartDiscount(p domain.Promotion, base int) int {
+	switch p.Type {
+	case domain.PromotionPercentage:
+		return base * p.Value / 100
+	case domain.PromotionFixedAmount:
+		return p.Value
+	}
+	return 0
+}
+
+// chooseDiscounts returns either all stackable discounts or the best
+// non-stackable one, whichever is larger, capped at limit. Ties go to the
+// stackable set.
+func chooseDiscounts(candidates []domain.Promotion, limit int, amountOf func(domain.Promotion) int) []domain.AppliedDiscount {
+	stacked := make([]domain.AppliedDiscount, 0)
+	remaining := limit
+	for _, p := range candidates {
+		if !p.Stackable {
+			continue
+		}
+		if amount := min(amountOf(p), remaining); amount > 0 {
+			stacked = append(stacked, domain.AppliedDiscount{PromotionID: p.ID, Name: p.Name, Amount: amount})
+			remaining -= amount
+		}
+	}
+
+	var best *domain.AppliedDiscount
+	for _, p := range candidates {
+		if p.Stackable {
+			continue
+		}
+		if amount := min(amountOf(p), limit); amount > 0 && (best == nil || amount > best.Amount) {
+			best = &domain.AppliedDiscount{PromotionID: p.ID, Name: p.Name, Amount: amount}
+		}
+	}
+
+	if best != nil && best.Amount > sumDiscounts(stacked) {
+		return []domain.AppliedDiscount{*best}
+	}
+	return stacked
+}
+
+func sumDiscounts(discounts []domain.AppliedDiscount) int {
+	total := 0
+	for _, d := range discounts {
+		total += d.Amount
+	}
+	return total
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"kasir-api/internal/domain"
+)
+
+func intPtr(v int) *int { return &v }
+
+func newQuote(lines ...domain.QuoteLine) *domain.Quote {
+	for i := range lines {
+		lines[i].Subtotal = lines[i].UnitPrice * lines[i].Quantity
+	}
+	return &domain.Quote{Lines: lines}
+}
+
+func TestApplyPromotions(t *testing.T) {
+	// Indomie (product 1, category 1) x3 @3500 and Teh Botol (product 2, category 2) x2 @5000
+	lines := func() *domain.Quote {
+		return newQuote(
+			domain.QuoteLine{ProductID: 1, CategoryID: 1, Quantity: 3, UnitPrice: 3500},
+			domain.QuoteLine{ProductID: 2, CategoryID: 2, Quantity: 2, UnitPrice: 5000},
+		)
+	}
+	snacks10 := domain.Promotion{ID: 1, Name: "10% snacks", Type: domain.PromotionPercentage, Scope: domain.PromotionScopeCategory, CategoryID: intPtr(1), Value: 10}
+	b2g1 := domain.Promotion{ID: 2, Name: "buy 2 get 1", Type: domain.PromotionBuyXGetY, Scope: domain.PromotionScopeProduct, ProductID: intPtr(1), BuyQuantity: 2, FreeQuantity: 1}
+	rp500 := domain.Promotion{ID: 3, Name: "Rp500 off", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeProduct, ProductID: intPtr(1), Value: 500, Stackable: true}
+	pct5 := domain.Promotion{ID: 4, Name: "5% stack", Type: domain.PromotionPercentage, Scope: domain.PromotionScopeCategory, CategoryID: intPtr(1), Value: 5, Stackable: true}
+	cart5k := domain.Promotion{ID: 5, Name: "Rp5000 off 15k", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeCart, Value: 5000, MinSubtotal: 15000}
+	cart50k := domain.Promotion{ID: 6, Name: "Rp5000 off 50k", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeCart, Value: 5000, MinSubtotal: 50000}
+	huge := domain.Promotion{ID: 7, Name: "Rp9999 off", Type: domain.PromotionFixedAmount, Scope: domain.PromotionScopeProduct, ProductID: intPtr(2), Value: 9999}
+	drinks10 := domain.Promotion{ID: 8, Name: "10% drinks", Type: domain.PromotionPercentage, Scope: domain.PromotionScopeCategory, CategoryID: intPtr(4), Value: 10}
+
+	tests := []struct {
+		name         string
+		promotions   []domain.Promotion
+		parents      map[int]int
+		wantLineIDs  [][]int
+		wantLineDisc []int
+		wantCartIDs  []int
+		wantDiscount int
+		wantTotal    int
+	}{
+		{
+			name:         "no promotions",
+			wantLineIDs:  [][]int{{}, {}},
+			wantLineDisc: []int{0, 0},
+			wantCartIDs:  []int{},
+			wantTotal:    20500,
+		},
+		{
+			name:         "category percentage",
+			promotions:   []domain.Promotion{snacks10},
+			wantLineIDs:  [][]int{{1}, {}},
+			wantLineDisc: []int{1050, 0},
+			wantCartIDs:  []int{},
+			wantDiscount: 1050,
+			wantTotal:    19450,
+		},
+		{
+			name:         "best exclusive wins",
+			promotions:   []domain.Promotion{snacks10, b2g1},
+			wantLineIDs:  [][]int{{2}, {}},
+			wantLineDisc: []int{3500, 0},
+			wantCartIDs:  []int{},
+			wantDiscount: 3500,
+			wantTotal:    17000,
+		},
+		{
+			name:         "stackables combine",
+			promotions:   []domain.Promotion{rp500, pct5},
+			wantLineIDs:  [][]int{{3, 4}, {}},
+			wantLineDisc: []int{1500 + 525, 0},
+			wantCartIDs:  []int{},
+			wantDiscount: 2025,
+			wantTotal:    18475,
+		},
+		{
+			name:         "stacked set beats smaller exclusive",
+			promotions:   []domain.Promotion{snacks10, rp500, pct5},
+			wantLineIDs:  [][]int{{3, 4}, {}},
+			wantLineDisc: []int{2025, 0},
+			wantCartIDs:  []int{},
+			wantDiscount: 2025,
+			wantTotal:    18475,
+		},
+		{
+			name:         "cart promotion on discounted base",
+			promotions:   []domain.Promotion{snacks10, cart5k, cart50k},
+			wantLineIDs:  [][]int{{1}, {}},
+			wantLineDisc: []int{1050, 0},
+			wantCartIDs:  []int{5},
+			wantDiscount: 6050,
+			wantTotal:    14450,
+		},
+		{
+			name:         "discount capped at line subtotal",
+			promotions:   []domain.Promotion{huge},
+			wantLineIDs:  [][]int{{}, {7}},
+			wantLineDisc: []int{0, 10000},
+			wantCartIDs:  []int{},
+			wantDiscount: 10000,
+			wantTotal:    10500,
+		},
+		{
+			// Category 2 is a subcategory of 3, itself a subcategory of 4
+			name:         "category promotion covers nested subcategories",
+			promotions:   []domain.Promotion{drinks10},
+			parents:      map[int]int{2: 3, 3: 4},
+			wantLineIDs:  [][]int{{}, {8}},
+			wantLineDisc: []int{0, 1000},
+			wantCartIDs:  []int{},
+			wantDiscount: 1000,
+			wantTotal:    19500,
+		},
+		{
+			name:         "category promotion skips parent categories",
+			promotions:   []domain.Promotion{snacks10},
+			parents:      map[int]int{1: 2},
+			wantLineIDs:  [][]int{{1}, {}},
+			wantLineDisc: []int{1050, 0},
+			wantCartIDs:  []int{},
+			wantDiscount: 1050,
+			wantTotal:    19450,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := lines()
+			applyPromotions(quote, tt.promotions, tt.parents)
+
+			for i, line := range quote.Lines {
+				var ids []int
+				for _, d := range line.Discounts {
+					ids = append(ids, d.PromotionID)
+				}
+				if i < len(tt.wantLineIDs) && !slices.Equal(ids, tt.wantLineIDs[i]) {
+					t.Errorf("line %d promotions = %v, want %v", i, ids, tt.wantLineIDs[i])
+				}
+				if line.Discount != tt.wantLineDisc[i] || line.Total != line.Subtotal-line.Discount {
+					t.Errorf("line %d discount, total = %d, %d, want %d", i, line.Discount, line.Total, tt.wantLineDisc[i])
+				}
+				if line.Discounts == nil {
+					t.Errorf("line %d discounts is nil, want empty slice", i)
+				}
+			}
+			var cartIDs []int
+			for _, d := range quote.CartDiscounts {
+				cartIDs = append(cartIDs, d.PromotionID)
+			}
+			if !slices.Equal(cartIDs, tt.wantCartIDs) {
+				t.Errorf("cart promotions = %v, want %v", cartIDs, tt.wantCartIDs)
+			}
+			if quote.Subtotal != 20500 || quote.Discount != tt.wantDiscount || quote.Total != tt.wantTotal {
+				t.Errorf("subtotal, discount, total = %d, %d, %d, want 20500, %d, %d",
+					quote.Subtotal, quote.Discount, quote.Total, tt.wantDiscount, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestLineDiscount_BuyXGetY(t *testing.T) {
+	b2g1 := domain.Promotion{Type: domain.PromotionBuyXGetY, BuyQuantity: 2, FreeQuantity: 1}
+	for qty, want := range map[int]int{1: 0, 2: 0, 3: 1000, 5: 1000, 6: 2000, 7: 2000} {
+		line := domain.QuoteLine{Quantity: qty, UnitPrice: 1000, Subtotal: qty * 1000}
+		if got := lineDiscount(b2g1, line); got != want {
+			t.Errorf("quantity %d: discount = %d, want %d", qty, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// PromotionService handles promotion business logic
+type PromotionService struct {
+	promotionRepo repository.PromotionRepository
+	productRepo   repository.ProductRepository
+	categoryRepo  repository.CategoryRepository
+	transactor    repository.Transactor
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(promotionRepo repository.PromotionRepository, productRepo repository.ProductRepository,
+	categoryRepo repository.CategoryRepository, transactor repository.Transactor) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		transactor:    transactor,
+	}
+}
+
+func (s *PromotionService) GetAll() ([]domain.Promotion, error) {
+	return s.promotionRepo.GetAll()
+}
+
+func (s *PromotionService) GetByID(id int) (*domain.Promotion, error) {
+	return s.promotionRepo.GetByID(id)
+}
+
+func (s *PromotionService) Create(promotion *domain.Promotion, meta domain.ChangeMeta) error {
+	if err := s.validate(promotion); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Promotions.Create(promotion); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityPromotion, promotion.ID,
+			nil, promotion)
+	})
+}
+
+func (s *PromotionService) Update(promotion *domain.Promotion, meta domain.ChangeMeta) error {
+	if err := s.validate(promotion); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Promotions.GetByID(promotion.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Promotions.Update(promotion); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityPromotion, promotion.ID,
+			current, promotion)
+	})
+}
+
+func (s *PromotionService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Promotions.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Promotions.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityPromotion, id,
+			current, nil)
+	})
+}
+
+// validate checks the rule is well-formed for its type and scope and that the
+// product or category it targets exists
+func (s *PromotionService) validate(p *domain.Promotion) error {
+	if p.Name == "" {
+		return invalidInput("name is required")
+	}
+
+	switch p.Type {
+	case domain.PromotionPercentage:
+		if p.Value < 1 || p.Value > 100 {
+			return invalidInput("percentage value must be between 1 and 100")
+		}
+	case domain.PromotionFixedAmount:
+		if p.Value < 1 {
+			return invalidInput("fixed amount value must be positive")
+		}
+	case domain.PromotionBuyXGetY:
+		if p.BuyQuantity < 1 || p.FreeQuantity < 1 {
+			return invalidInput("buy_quantity and free_quantity must be positive")
+		}
+		if p.Scope == domain.PromotionScopeCart {
+			return invalidInput("buy_x_get_y cannot be scoped to the whole cart")
+		}
+	default:
+		return invalidInput("type must be percentage, fixed_amount or buy_x_get_y")
+	}
+
+	if p.MinSubtotal < 0 {
+		return invalidInput("min_subtotal cannot be negative")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return invalidInput("ends_at must be after starts_at")
+	}
+
+	switch p.Scope {
+	case domain.PromotionScopeProduct:
+		if p.ProductID == nil {
+			return invalidInput("product_id is required for product scope")
+		}
+		p.CategoryID = nil
+		if _, err := s.productRepo.GetByID(*p.ProductID); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+			return err
+		}
+	case domain.PromotionScopeCategory:
+		if p.CategoryID == nil {
+			return invalidInput("category_id is required for category scope")
+		}
+		p.ProductID = nil
+		if _, err := s.categoryRepo.GetByID(*p.CategoryID); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrCategoryNotFound
+			}
+			return err
+		}
+	case domain.PromotionScopeCart:
+		p.ProductID, p.CategoryID = nil, nil
+	default:
+		return invalidInput("scope must be product, category or cart")
+	}
+
+	return nil
+}
+
+// invalidInput wraps ErrInvalidInput with a message safe to show to clients
+func invalidInput(msg string) error {
+	return fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, msg)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// PurchaseOrderService handles ordering stock from suppliers and receiving it
+type PurchaseOrderService struct {
+	repo       repository.PurchaseOrderRepository
+	outletRepo repository.OutletRepository
+	transactor repository.Transactor
+	now        func() time.Time
+}
+
+// NewPurchaseOrderService creates a new purchase order service
+func NewPurchaseOrderService(repo repository.PurchaseOrderRepository, outletRepo repository.OutletRepository,
+	transactor repository.Transactor) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo, outletRepo: outletRepo, transactor: transactor, now: time.Now}
+}
+
+// GetAll lists purchase orders, only those with status unless it is empty
+// and only those for outletID unless it is zero
+func (s *PurchaseOrderService) GetAll(status string, outletID int) ([]domain.PurchaseOrder, error) {
+	switch status {
+	case "", domain.PurchaseOrderStatusOrdered, domain.PurchaseOrderStatusPartiallyReceived,
+		domain.PurchaseOrderStatusReceived, domain.PurchaseOrderStatusCancelled:
+	default:
+		return nil, invalidInput("status must be ordered, partially_received, received or cancelled")
+	}
+	if err := checkOutlet(s.outletRepo, outletID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(status, outletID)
+}
+
+func (s *PurchaseOrderService) GetByID(id int) (*domain.PurchaseOrder, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create places an order with a supplier for an outlet, the default outlet
+// when none is given. Lines can be ordered in any unit the product converts
+// from, such as cartons; the conversion is fixed on the line. Nothing is
+// added to stock until the order is received.
+func (s *PurchaseOrderService) Create(input domain.PurchaseOrderInput, meta domain.ChangeMeta) (*domain.PurchaseOrder, error) {
+	if len(input.Lines) == 0 {
+		return nil, invalidInput("lines must not be empty")
+	}
+	seen := make(map[int]bool, len(input.Lines))
+	lines := make([]domain.PurchaseOrderLine, len(input.Lines))
+	for i, line := range input.Lines {
+		if line.Quantity <= 0 {
+			return nil, invalidInput("quantity must be greater than zero")
+		}
+		if line.UnitCost < 0 {
+			return nil, invalidInput("unit_cost must not be negative")
+		}
+		if seen[line.ProductID] {
+			return nil, invalidInput(fmt.Sprintf("product %d is listed more than once", line.ProductID))
+		}
+		seen[line.ProductID] = true
+		lines[i] = domain.PurchaseOrderLine{ProductID: line.ProductID, UnitID: line.UnitID, Quantity: line.Quantity,
+			UnitCost: line.UnitCost}
+	}
+
+	now := s.now()
+	order := &domain.PurchaseOrder{
+		SupplierID: input.SupplierID,
+		OutletID:   input.OutletID,
+		Status:     domain.PurchaseOrderStatusOrdered,
+		Notes:      strings.TrimSpace(input.Notes),
+		Lines:      lines,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		outletID, err := resolveOutlet(repos.Outlets, order.OutletID)
+		if err != nil {
+			return err
+		}
+		order.OutletID = outletID
+		if _, err := repos.Suppliers.GetByID(order.SupplierID); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrSupplierNotFound
+			}
+			return err
+		}
+		for i := range order.Lines {
+			line := &order.Lines[i]
+			product, err := repos.Products.GetByID(line.ProductID)
+			if err != nil {
+				if errors.Is(err, apperrors.ErrNotFound) {
+					return apperrors.ErrProductNotFound
+				}
+				return err
+			}
+			if err := checkStocked(product); err != nil {
+				return err
+			}
+			unit, err := unitConversion(repos.ProductUnits, product, line.UnitID)
+			if err != nil {
+				return err
+			}
+			line.UnitID, line.Factor = unit.UnitID, unit.Factor
+		}
+		if err := repos.PurchaseOrders.Create(order); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityPurchaseOrder, order.ID,
+			nil, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Receive books a delivery against an order. Each line adds its quantity,
+// converted to the product's base unit, to the product's stock at the order's
+// outlet through a stock movement and moves the product's cost price to the
+// weighted average of the stock on hand and the delivery. An
+// order can be received in several deliveries; its status follows how much
+// has arrived. Received and cancelled orders fail with ErrConflict.
+func (s *PurchaseOrderService) Receive(id int, input domain.ReceiveInput, meta domain.ChangeMeta) (*domain.PurchaseOrder, error) {
+	if len(input.Lines) == 0 {
+		return nil, invalidInput("lines must not be empty")
+	}
+
+	var order *domain.PurchaseOrder
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.PurchaseOrders.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if current.Status == domain.PurchaseOrderStatusReceived || current.Status == domain.PurchaseOrderStatusCancelled {
+			return apperrors.ErrConflict
+		}
+
+		now := s.now()
+		order = current
+		before := *current
+		before.Lines = append([]domain.PurchaseOrderLine(nil), current.Lines...)
+		seen := make(map[int]bool, len(input.Lines))
+		// Lines are booked in product order, the order checkout locks stock
+		// in, so a delivery and a sale cannot deadlock
+		lines := slices.SortedFunc(slices.Values(input.Lines), func(a, b domain.ReceiveLine) int {
+			return a.ProductID - b.ProductID
+		})
+		for _, received := range lines {
+			if received.Quantity <= 0 {
+				return invalidInput("quantity must be greater than zero")
+			}
+			if seen[received.ProductID] {
+				return invalidInput(fmt.Sprintf("product %d is listed more than once", received.ProductID))
+			}
+			seen[received.ProductID] = true
+
+			i := orderLineIndex(order.Lines, received.ProductID)
+			if i < 0 {
+				return invalidInput(fmt.Sprintf("product %d is not on this purchase order", received.ProductID))
+			}
+			line := &order.Lines[i]
+			if received.Quantity > line.Outstanding() {
+				return invalidInput(fmt.Sprintf("product %d has only %d left to receive", line.ProductID, line.Outstanding()))
+			}
+			if err := receiveLine(repos, order, *line, received, meta, now); err != nil {
+				return err
+			}
+			line.ReceivedQuantity += received.Quantity
+		}
+
+		order.UpdateStatus()
+		order.UpdatedAt = now
+		if err := repos.PurchaseOrders.Update(order); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityPurchaseOrder, order.ID,
+			&before, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Cancel stops an order from receiving anything more. What already arrived
+// stays in stock. Received and cancelled orders fail with ErrConflict.
+func (s *PurchaseOrderService) Cancel(id int, meta domain.ChangeMeta) (*domain.PurchaseOrder, error) {
+	var order *domain.PurchaseOrder
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.PurchaseOrders.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if current.Status == domain.PurchaseOrderStatusReceived || current.Status == domain.PurchaseOrderStatusCancelled {
+			return apperrors.ErrConflict
+		}
+
+		before := *current
+		order = current
+		order.Status = domain.PurchaseOrderStatusCancelled
+		order.UpdatedAt = s.now()
+		if err := repos.PurchaseOrders.Update(order); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityPurchaseOrder, order.ID,
+			&before, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// receiveLine adds the received quantity of the line's unit, in the product's
+// base unit, to the order outlet's stock at the line's cost per base unit and
+// records the movement in the stock ledger. The cost price averages over the
+// product's stock at every outlet, locked so a concurrent receipt or sale
+// cannot change it meanwhile. A delivery with a lot number or expiry date is
+// kept as a batch.
+func receiveLine(repos repository.Repositories, order *domain.PurchaseOrder, line domain.PurchaseOrderLine,
+	received domain.ReceiveLine, meta domain.ChangeMeta, now time.Time) error {
+	product, err := repos.Products.GetByIDForUpdate(line.ProductID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrProductNotFound
+		}
+		return err
+	}
+	base := line.BaseQuantity(received.Quantity)
+	cost := product.ReceivedCost(base, line.BaseCost())
+	if err := repos.Products.ReceiveStock(product.ID, order.OutletID, base, cost); err != nil {
+		return err
+	}
+	lot := strings.TrimSpace(received.LotNumber)
+	if lot != "" || received.ExpiresAt != nil {
+		batch := &domain.StockBatch{
+			ProductID: product.ID,
+			OutletID:  order.OutletID,
+			LotNumber: lot,
+			ExpiresAt: received.ExpiresAt,
+			Quantity:  base,
+			Remaining: base,
+			CreatedAt: now,
+		}
+		if err := repos.StockBatches.Create(batch); err != nil {
+			return err
+		}
+	}
+	return repos.StockMovements.Create(&domain.StockMovement{
+		ProductID: product.ID,
+		OutletID:  order.OutletID,
+		Quantity:  base,
+		Reason:    domain.StockMovementPurchase,
+		Reference: fmt.Sprintf("PO-%d", order.ID),
+		Actor:     meta.Actor,
+		CreatedAt: now,
+	})
+}
+
+func orderLineIndex(lines []domain.PurchaseOrderLine, productID int) int {
+	for i, line := range lines {
+		if line.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
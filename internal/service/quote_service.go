@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// QuoteService prices baskets with current prices, active promotions and taxes
+type QuoteService struct {
+	productRepo     repository.ProductRepository
+	productUnitRepo repository.ProductUnitRepository
+	categoryRepo    repository.CategoryRepository
+	promotionRepo   repository.PromotionRepository
+	taxRateRepo     repository.TaxRateRepository
+	now             func() time.Time
+}
+
+// NewQuoteService creates a new quote service
+func NewQuoteService(productRepo repository.ProductRepository, productUnitRepo repository.ProductUnitRepository,
+	categoryRepo repository.CategoryRepository, promotionRepo repository.PromotionRepository,
+	taxRateRepo repository.TaxRateRepository) *QuoteService {
+	return &QuoteService{
+		productRepo:     productRepo,
+		productUnitRepo: productUnitRepo,
+		categoryRepo:    categoryRepo,
+		promotionRepo:   promotionRepo,
+		taxRateRepo:     taxRateRepo,
+		now:             time.Now,
+	}
+}
+
+// Quote prices the requested items without touching stock. Repeated products
+// in the same unit are merged into one line so quantity-based promotions see
+// the full count.
+func (s *QuoteService) Quote(req domain.QuoteRequest) (*domain.Quote, error) {
+	items, err := mergeQuoteItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	return priceBasket(s.productRepo, s.productUnitRepo, s.categoryRepo, s.promotionRepo, s.taxRateRepo, items, s.now())
+}
+
+// priceBasket prices merged items with the prices, promotions and taxes in
+// effect at the given time. Items in another unit than the product's base
+// unit are priced per that unit.
+func priceBasket(productRepo repository.ProductRepository, productUnitRepo repository.ProductUnitRepository,
+	categoryRepo repository.CategoryRepository, promotionRepo repository.PromotionRepository,
+	taxRateRepo repository.TaxRateRepository, items []domain.QuoteItem, at time.Time) (*domain.Quote, error) {
+	categories, err := categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	categoriesByID := make(map[int]domain.Category, len(categories))
+	for _, c := range categories {
+		categoriesByID[c.ID] = c
+	}
+
+	quote := &domain.Quote{Lines: make([]domain.QuoteLine, 0, len(items))}
+	for _, item := range items {
+		product, err := productRepo.GetByID(item.ProductID)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return nil, apperrors.ErrProductNotFound
+			}
+			return nil, err
+		}
+		unit, err := unitConversion(productUnitRepo, product, item.UnitID)
+		if err != nil {
+			return nil, err
+		}
+		quote.Lines = append(quote.Lines, domain.QuoteLine{
+			ProductID:    product.ID,
+			Name:         product.Name,
+			CategoryID:   product.CategoryID,
+			UnitID:       unit.UnitID,
+			Quantity:     item.Quantity,
+			BaseQuantity: item.Quantity * unit.Factor,
+			UnitPrice:    unit.Price,
+			Subtotal:     unit.Price * item.Quantity,
+			TaxRateID:    product.EffectiveTaxRateID(categoriesByID),
+		})
+	}
+
+	promotions, err := promotionRepo.GetActive(at)
+	if err != nil {
+		return nil, err
+	}
+	applyPromotions(quote, promotions, categoryParents(categories))
+
+	taxRates, err := taxRateRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[int]domain.TaxRate, len(taxRates))
+	for _, rate := range taxRates {
+		rates[rate.ID] = rate
+	}
+	applyTaxes(quote, rates)
+
+	return quote, nil
+}
+
+func mergeQuoteItems(items []domain.QuoteItem) ([]domain.QuoteItem, error) {
+	if len(items) == 0 {
+		return nil, invalidInput("items must not be empty")
+	}
+
+	type key struct{ productID, unitID int }
+	merged := make([]domain.QuoteItem, 0, len(items))
+	index := make(map[key]int)
+	for _, item := range items {
+		if item.Quantity < 1 {
+			return nil, invalidInput("quantity must be positive")
+		}
+		k := key{item.ProductID, item.UnitID}
+		if i, ok := index[k]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[k] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runReaper calls release every interval until ctx is cancelled, logging how
+// many expired rows of what it released
+func runReaper(ctx context.Context, interval time.Duration, what string, release func() (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := release()
+			if err != nil {
+				log.Println("Error releasing expired "+what+":", err)
+				continue
+			}
+			if released > 0 {
+				log.Println("Released expired "+what+":", released)
+			}
+		}
+	}
+}
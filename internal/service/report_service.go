@@ -0,0 +1,49 @@
+package service
+
+import (
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// ReportService builds reports over completed sales
+type ReportService struct {
+	cartRepo   repository.CartRepository
+	refundRepo repository.RefundRepository
+	outletRepo repository.OutletRepository
+}
+
+// NewReportService creates a new report service
+func NewReportService(cartRepo repository.CartRepository, refundRepo repository.RefundRepository,
+	outletRepo repository.OutletRepository) *ReportService {
+	return &ReportService{cartRepo: cartRepo, refundRepo: refundRepo, outletRepo: outletRepo}
+}
+
+// Sales totals the revenue, tax, cost and gross profit of the carts checked
+// out in a period, at one outlet unless filter.OutletID is zero, less the
+// refunds made in the period. Costs are the snapshots taken at checkout, so
+// later cost changes do not alter past profit.
+func (s *ReportService) Sales(filter domain.SalesFilter) (*domain.SalesReport, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, invalidInput("from must be before to")
+	}
+	if err := checkOutlet(s.outletRepo, filter.OutletID); err != nil {
+		return nil, err
+	}
+	carts, err := s.cartRepo.GetSales(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var report domain.SalesReport
+	for _, cart := range carts {
+		report.AddSale(cart)
+	}
+	refunds, err := s.refundRepo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, refund := range refunds {
+		report.AddRefund(refund)
+	}
+	return &report, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// ShiftService handles cashier shifts and their cash reconciliation
+type ShiftService struct {
+	repo       repository.ShiftRepository
+	transactor repository.Transactor
+	now        func() time.Time
+}
+
+// NewShiftService creates a new shift service
+func NewShiftService(repo repository.ShiftRepository, transactor repository.Transactor) *ShiftService {
+	return &ShiftService{repo: repo, transactor: transactor, now: time.Now}
+}
+
+func (s *ShiftService) GetByID(id int) (*domain.Shift, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetCurrent returns the cashier's open shift
+func (s *ShiftService) GetCurrent(cashier string) (*domain.Shift, error) {
+	return s.repo.GetOpen(cashier)
+}
+
+// Open starts a shift for the cashier named in meta. A cashier can only have
+// one open shift at a time.
+func (s *ShiftService) Open(input domain.OpenShiftInput, meta domain.ChangeMeta) (*domain.Shift, error) {
+	if meta.Actor == "" {
+		return nil, invalidInput("cashier is required")
+	}
+	if input.OpeningCash < 0 {
+		return nil, invalidInput("opening_cash cannot be negative")
+	}
+
+	shift := &domain.Shift{Cashier: meta.Actor, OpeningCash: input.OpeningCash, OpenedAt: s.now()}
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Shifts.Create(shift); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityShift, shift.ID,
+			nil, shift)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+// Close counts the drawer and reconciles it against the cash the shift should
+// hold. Only the shift's cashier can close it, otherwise it fails with
+// ErrForbidden; it fails with ErrConflict if the shift is already closed.
+// The shift stays locked from reading its sales to closing it, so a
+// checkout cannot add cash the reconciliation misses.
+func (s *ShiftService) Close(id int, input domain.CloseShiftInput, meta domain.ChangeMeta) (*domain.ShiftReport, error) {
+	if input.CountedCash < 0 {
+		return nil, invalidInput("counted_cash cannot be negative")
+	}
+
+	var report *domain.ShiftReport
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Shifts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if meta.Actor != current.Cashier {
+			return apperrors.ErrForbidden
+		}
+		if !current.IsOpen() {
+			return apperrors.ErrConflict
+		}
+
+		totals, err := repos.Carts.PaymentTotals(id)
+		if err != nil {
+			return err
+		}
+		refunds, err := repos.Refunds.ShiftTotals(id)
+		if err != nil {
+			return err
+		}
+		report = &domain.ShiftReport{
+			CashSales:     totals[domain.PaymentCash],
+			CashRefunds:   refunds[domain.PaymentCash],
+			PaymentTotals: totals,
+			RefundTotals:  refunds,
+		}
+		for method, amount := range refunds {
+			totals[method] -= amount
+		}
+		report.ExpectedCash = current.OpeningCash + report.CashSales - report.CashRefunds
+		report.CountedCash = input.CountedCash
+		report.Variance = report.CountedCash - report.ExpectedCash
+
+		shift := *current
+		closedAt := s.now()
+		shift.ClosedAt = &closedAt
+		shift.ExpectedCash = &report.ExpectedCash
+		shift.CountedCash = &report.CountedCash
+		shift.Variance = &report.Variance
+		if err := repos.Shifts.Close(&shift); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return apperrors.ErrConflict
+			}
+			return err
+		}
+		report.Shift = shift
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityShift, id,
+			current, &shift)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// StockCountService handles stock opname: counting the stock on the shelves
+// and correcting the system stock to what was found
+type StockCountService struct {
+	repo       repository.StockCountRepository
+	outletRepo repository.OutletRepository
+	transactor repository.Transactor
+	now        func() time.Time
+}
+
+// NewStockCountService creates a new stock count service
+func NewStockCountService(repo repository.StockCountRepository, outletRepo repository.OutletRepository,
+	transactor repository.Transactor) *StockCountService {
+	return &StockCountService{repo: repo, outletRepo: outletRepo, transactor: transactor, now: time.Now}
+}
+
+// GetAll lists stock counts, only those at outletID unless it is zero and
+// only those with status unless it is empty
+func (s *StockCountService) GetAll(outletID int, status string) ([]domain.StockCount, error) {
+	switch status {
+	case "", domain.StockCountStatusCounting, domain.StockCountStatusApproved, domain.StockCountStatusCancelled:
+	default:
+		return nil, invalidInput("status must be counting, approved or cancelled")
+	}
+	if err := checkOutlet(s.outletRepo, outletID); err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.GetAll(outletID, status)
+	if err != nil {
+		return nil, err
+	}
+	for i := range counts {
+		counts[i].CalculateVariance()
+	}
+	return counts, nil
+}
+
+// GetByID returns a stock count with the variance of each counted line
+func (s *StockCountService) GetByID(id int) (*domain.StockCount, error) {
+	count, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	count.CalculateVariance()
+	return count, nil
+}
+
+// Create starts counting the stocked products at an outlet, the default
+// outlet when none is given, or only those in a category and its
+// subcategories. Each line keeps the product's stock at the outlet as the
+// count starts. Bundles hold no stock of their own and are not counted.
+func (s *StockCountService) Create(input domain.StockCountInput, meta domain.ChangeMeta) (*domain.StockCount, error) {
+	now := s.now()
+	count := &domain.StockCount{
+		CategoryID: input.CategoryID,
+		Status:     domain.StockCountStatusCounting,
+		Notes:      strings.TrimSpace(input.Notes),
+		Entries:    []domain.StockCountEntry{},
+		CreatedBy:  meta.Actor,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		outletID, err := resolveOutlet(repos.Outlets, input.OutletID)
+		if err != nil {
+			return err
+		}
+		count.OutletID = outletID
+
+		products, err := countedProducts(repos, input.CategoryID)
+		if err != nil {
+			return err
+		}
+		levels, err := repos.Products.StockLevels(outletID)
+		if err != nil {
+			return err
+		}
+		for _, p := range products {
+			if p.Type == domain.ProductTypeBundle {
+				continue
+			}
+			count.Lines = append(count.Lines, domain.StockCountLine{
+				ProductID:      p.ID,
+				ProductName:    p.Name,
+				SystemQuantity: levels[p.ID],
+			})
+		}
+		if len(count.Lines) == 0 {
+			return invalidInput("there are no products to count")
+		}
+
+		if err := repos.StockCounts.Create(count); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityStockCount, count.ID,
+			nil, count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	count.CalculateVariance()
+	return count, nil
+}
+
+// Count records what the requesting counter found of each product. Entries
+// add to what other counters found, so the same product can be counted in
+// several places; a negative quantity corrects an earlier entry as long as
+// the product's total does not drop below zero. Counts that are no longer
+// counting fail with ErrConflict.
+func (s *StockCountService) Count(id int, input domain.StockCountEntryInput, meta domain.ChangeMeta) (*domain.StockCount, error) {
+	if len(input.Lines) == 0 {
+		return nil, invalidInput("lines must not be empty")
+	}
+
+	var count *domain.StockCount
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.StockCounts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.StockCountStatusCounting {
+			return apperrors.ErrConflict
+		}
+
+		now := s.now()
+		count = current
+		before := cloneStockCount(*current)
+		seen := make(map[int]bool, len(input.Lines))
+		for _, counted := range input.Lines {
+			if seen[counted.ProductID] {
+				return invalidInput(fmt.Sprintf("product %d is listed more than once", counted.ProductID))
+			}
+			seen[counted.ProductID] = true
+
+			i := count.LineIndex(counted.ProductID)
+			if i < 0 {
+				return invalidInput(fmt.Sprintf("product %d is not in this stock count", counted.ProductID))
+			}
+			line := &count.Lines[i]
+			total := counted.Quantity
+			if line.CountedQuantity != nil {
+				total += *line.CountedQuantity
+			}
+			if total < 0 {
+				return invalidInput(fmt.Sprintf("counted quantity of product %d must not drop below zero", counted.ProductID))
+			}
+			line.CountedQuantity = &total
+
+			entry := domain.StockCountEntry{
+				ProductID: counted.ProductID,
+				Quantity:  counted.Quantity,
+				CountedBy: meta.Actor,
+				CountedAt: now,
+			}
+			if err := repos.StockCounts.AddEntry(count.ID, &entry); err != nil {
+				return err
+			}
+			count.Entries = append(count.Entries, entry)
+		}
+
+		count.UpdatedAt = now
+		if err := repos.StockCounts.Update(count); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityStockCount, count.ID,
+			&before, count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	count.CalculateVariance()
+	return count, nil
+}
+
+// Approve applies the variance of every counted product, counted less the
+// system quantity snapshotted when the count started, to the outlet's stock
+// as it stands now, so sales and receipts made while counting are kept. Each
+// variance is posted to the stock ledger as an adjustment by the approver.
+// Stock taken away comes out of the product's batches first expired first
+// out. Products that were not counted, or have since been deleted, are left
+// alone. Counts that are no longer counting fail with ErrConflict, and a
+// shortage larger than the stock left fails with ErrInsufficientStock.
+func (s *StockCountService) Approve(id int, meta domain.ChangeMeta) (*domain.StockCount, error) {
+	var count *domain.StockCount
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.StockCounts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.StockCountStatusCounting {
+			return apperrors.ErrConflict
+		}
+
+		now := s.now()
+		count = current
+		before := cloneStockCount(*current)
+		counted := false
+		for _, i := range linesByProduct(count.Lines) {
+			line := &count.Lines[i]
+			if line.CountedQuantity == nil {
+				continue
+			}
+			counted = true
+			if err := adjustCountedStock(repos, count, line, meta, now); err != nil {
+				return err
+			}
+		}
+		if !counted {
+			return invalidInput("nothing has been counted")
+		}
+
+		count.Status = domain.StockCountStatusApproved
+		count.ApprovedBy = meta.Actor
+		count.UpdatedAt = now
+		if err := repos.StockCounts.Update(count); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityStockCount, count.ID,
+			&before, count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	count.CalculateVariance()
+	return count, nil
+}
+
+// Cancel abandons a count without changing any stock. Counts that are no
+// longer counting fail with ErrConflict.
+func (s *StockCountService) Cancel(id int, meta domain.ChangeMeta) (*domain.StockCount, error) {
+	var count *domain.StockCount
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.StockCounts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.StockCountStatusCounting {
+			return apperrors.ErrConflict
+		}
+
+		before := cloneStockCount(*current)
+		count = current
+		count.Status = domain.StockCountStatusCancelled
+		count.UpdatedAt = s.now()
+		if err := repos.StockCounts.Update(count); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityStockCount, count.ID,
+			&before, count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	count.CalculateVariance()
+	return count, nil
+}
+
+// countedProducts lists the products a count covers: those in the category
+// and its subcategories, or every product when categoryID is nil
+func countedProducts(repos repository.Repositories, categoryID *int) ([]domain.Product, error) {
+	if categoryID == nil {
+		return repos.Products.GetAll()
+	}
+	if _, err := repos.Categories.GetByID(*categoryID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, apperrors.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return repos.Products.GetByCategory(*categoryID)
+}
+
+// linesByProduct returns the indexes of a count's lines in product order, the
+// order checkout locks stock in, so approving cannot deadlock with a sale
+func linesByProduct(lines []domain.StockCountLine) []int {
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return lines[a].ProductID - lines[b].ProductID })
+	return order
+}
+
+// adjustCountedStock adds a counted line's variance to the count outlet's
+// current stock of its product and records it in the stock ledger. The
+// line's system quantity is left as counted against, so the audited variance
+// is the one the counters saw.
+func adjustCountedStock(repos repository.Repositories, count *domain.StockCount, line *domain.StockCountLine,
+	meta domain.ChangeMeta, now time.Time) error {
+	variance := *line.CountedQuantity - line.SystemQuantity
+	if variance == 0 {
+		return nil
+	}
+	if _, err := repos.Products.GetByID(line.ProductID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	stock, err := repos.Products.LockStock(line.ProductID, count.OutletID)
+	if err != nil {
+		return err
+	}
+	if stock+variance < 0 {
+		return fmt.Errorf("%w: %s", apperrors.ErrInsufficientStock, line.ProductName)
+	}
+	return setLockedStock(repos, line.ProductID, count.OutletID, stock, stock+variance,
+		fmt.Sprintf("SO-%d", count.ID), meta.Actor, now)
+}
+
+// adjustStock sets a product's stock at an outlet to quantity and writes the
+// difference to the stock ledger as an adjustment, returning the stock it
+// replaced. The stock stays locked from reading it to setting it, so a sale
+// cannot slip in between and be lost from stock or the ledger. Stock taken
+// away comes out of the product's batches first expired first out.
+func adjustStock(repos repository.Repositories, productID, outletID, quantity int, reference, actor string,
+	now time.Time) (int, error) {
+	stock, err := repos.Products.LockStock(productID, outletID)
+	if err != nil {
+		return 0, err
+	}
+	if err := setLockedStock(repos, productID, outletID, stock, quantity, reference, actor, now); err != nil {
+		return 0, err
+	}
+	return stock, nil
+}
+
+// setLockedStock sets a product's stock at an outlet, already locked at
+// stock, to quantity and writes the difference to the stock ledger as an
+// adjustment
+func setLockedStock(repos repository.Repositories, productID, outletID, stock, quantity int, reference,
+	actor string, now time.Time) error {
+	change := quantity - stock
+	if change == 0 {
+		return nil
+	}
+
+	if err := repos.Products.SetStock(productID, outletID, quantity); err != nil {
+		return err
+	}
+	if change < 0 {
+		if err := trimBatches(repos, productID, outletID); err != nil {
+			return err
+		}
+	}
+	return repos.StockMovements.Create(&domain.StockMovement{
+		ProductID: productID,
+		OutletID:  outletID,
+		Quantity:  change,
+		Reason:    domain.StockMovementAdjustment,
+		Reference: reference,
+		Actor:     actor,
+		CreatedAt: now,
+	})
+}
+
+// cloneStockCount copies a count's lines and entries so the audit snapshot
+// taken before a change is not altered by it
+func cloneStockCount(c domain.StockCount) domain.StockCount {
+	c.Lines = append([]domain.StockCountLine(nil), c.Lines...)
+	c.Entries = append([]domain.StockCountEntry(nil), c.Entries...)
+	return c
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// StockTransferService handles moving stock between outlets
+type StockTransferService struct {
+	repo       repository.StockTransferRepository
+	outletRepo repository.OutletRepository
+	transactor repository.Transactor
+	now        func() time.Time
+}
+
+// NewStockTransferService creates a new stock transfer service
+func NewStockTransferService(repo repository.StockTransferRepository, outletRepo repository.OutletRepository,
+	transactor repository.Transactor) *StockTransferService {
+	return &StockTransferService{repo: repo, outletRepo: outletRepo, transactor: transactor, now: time.Now}
+}
+
+// GetAll lists transfers, only those from or to outletID unless it is zero
+func (s *StockTransferService) GetAll(outletID int) ([]domain.StockTransfer, error) {
+	if err := checkOutlet(s.outletRepo, outletID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(outletID)
+}
+
+func (s *StockTransferService) GetByID(id int) (*domain.StockTransfer, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create dispatches stock from one outlet to another. The lines leave the
+// source outlet's stock straight away, each through a transfer_out stock
+// movement, and the transfer stays in transit until it is received. Batched
+// stock leaves first expired first out and arrives unbatched. It fails
+// with ErrInsufficientStock when the source outlet is short of a product.
+func (s *StockTransferService) Create(input domain.StockTransferInput, meta domain.ChangeMeta) (*domain.StockTransfer, error) {
+	if input.FromOutletID == input.ToOutletID {
+		return nil, invalidInput("from_outlet_id and to_outlet_id must differ")
+	}
+	if len(input.Lines) == 0 {
+		return nil, invalidInput("lines must not be empty")
+	}
+	seen := make(map[int]bool, len(input.Lines))
+	for _, line := range input.Lines {
+		if line.Quantity <= 0 {
+			return nil, invalidInput("quantity must be greater than zero")
+		}
+		if seen[line.ProductID] {
+			return nil, invalidInput(fmt.Sprintf("product %d is listed more than once", line.ProductID))
+		}
+		seen[line.ProductID] = true
+	}
+
+	now := s.now()
+	transfer := &domain.StockTransfer{
+		FromOutletID: input.FromOutletID,
+		ToOutletID:   input.ToOutletID,
+		Status:       domain.StockTransferStatusInTransit,
+		Notes:        strings.TrimSpace(input.Notes),
+		Lines:        append([]domain.StockTransferLine(nil), input.Lines...),
+		CreatedBy:    meta.Actor,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		for _, id := range []int{transfer.FromOutletID, transfer.ToOutletID} {
+			if id == 0 {
+				return invalidInput("from_outlet_id and to_outlet_id are required")
+			}
+			if err := checkOutlet(repos.Outlets, id); err != nil {
+				return err
+			}
+		}
+		if err := repos.StockTransfers.Create(transfer); err != nil {
+			return err
+		}
+		for _, line := range transferLinesByProduct(transfer.Lines) {
+			product, err := repos.Products.GetByID(line.ProductID)
+			if err != nil {
+				if errors.Is(err, apperrors.ErrNotFound) {
+					return apperrors.ErrProductNotFound
+				}
+				return err
+			}
+			if err := checkStocked(product); err != nil {
+				return err
+			}
+			if err := repos.Products.DecrementStock(product.ID, transfer.FromOutletID, line.Quantity); err != nil {
+				if errors.Is(err, apperrors.ErrInsufficientStock) {
+					return fmt.Errorf("%w: %s", apperrors.ErrInsufficientStock, product.Name)
+				}
+				return err
+			}
+			if err := takeBatches(repos, product.ID, transfer.FromOutletID, line.Quantity); err != nil {
+				return err
+			}
+			err = moveTransferLine(repos, transfer, transfer.FromOutletID, line.ProductID, -line.Quantity,
+				domain.StockMovementTransferOut, meta, now)
+			if err != nil {
+				return err
+			}
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityStockTransfer, transfer.ID,
+			nil, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// Receive books a transfer's lines into the destination outlet's stock.
+// Transfers no longer in transit fail with ErrConflict.
+func (s *StockTransferService) Receive(id int, meta domain.ChangeMeta) (*domain.StockTransfer, error) {
+	return s.settle(id, domain.StockTransferStatusReceived, meta)
+}
+
+// Cancel returns a transfer's lines to the source outlet's stock. Transfers
+// no longer in transit fail with ErrConflict.
+func (s *StockTransferService) Cancel(id int, meta domain.ChangeMeta) (*domain.StockTransfer, error) {
+	return s.settle(id, domain.StockTransferStatusCancelled, meta)
+}
+
+// settle ends an in-transit transfer with status, adding its lines back to
+// the stock of the destination when received or the source when cancelled
+func (s *StockTransferService) settle(id int, status string, meta domain.ChangeMeta) (*domain.StockTransfer, error) {
+	var transfer *domain.StockTransfer
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.StockTransfers.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if current.Status != domain.StockTransferStatusInTransit {
+			return apperrors.ErrConflict
+		}
+
+		outletID := current.ToOutletID
+		if status == domain.StockTransferStatusCancelled {
+			outletID = current.FromOutletID
+		}
+		now := s.now()
+		for _, line := range transferLinesByProduct(current.Lines) {
+			if err := repos.Products.AddStock(line.ProductID, outletID, line.Quantity); err != nil {
+				return err
+			}
+			err := moveTransferLine(repos, current, outletID, line.ProductID, line.Quantity,
+				domain.StockMovementTransferIn, meta, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		before := *current
+		transfer = current
+		transfer.Status = status
+		transfer.UpdatedAt = now
+		if err := repos.StockTransfers.Update(transfer); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityStockTransfer, transfer.ID,
+			&before, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// transferLinesByProduct returns the lines in product order, the order checkout locks
+// stock in, so a transfer and a sale or an opposite transfer cannot deadlock
+func transferLinesByProduct(lines []domain.StockTransferLine) []domain.StockTransferLine {
+	return slices.SortedFunc(slices.Values(lines), func(a, b domain.StockTransferLine) int {
+		return a.ProductID - b.ProductID
+	})
+}
+
+// moveTransferLine records a transfer line leaving or arriving at an outlet
+// in the stock ledger
+func moveTransferLine(repos repository.Repositories, transfer *domain.StockTransfer, outletID, productID, quantity int,
+	reason string, meta domain.ChangeMeta, now time.Time) error {
+	return repos.StockMovements.Create(&domain.StockMovement{
+		ProductID: productID,
+		OutletID:  outletID,
+		Quantity:  quantity,
+		Reason:    reason,
+		Reference: fmt.Sprintf("TR-%d", transfer.ID),
+		Actor:     meta.Actor,
+		CreatedAt: now,
+	})
+}
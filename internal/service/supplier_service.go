@@ -0,0 +1,86 @@
+package service
+
+import (
+	"strings"
+
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// SupplierService handles supplier business logic
+type SupplierService struct {
+	repo       repository.SupplierRepository
+	transactor repository.Transactor
+}
+
+// NewSupplierService creates a new supplier service
+func NewSupplierService(repo repository.SupplierRepository, transactor repository.Transactor) *SupplierService {
+	return &SupplierService{repo: repo, transactor: transactor}
+}
+
+func (s *SupplierService) GetAll() ([]domain.Supplier, error) {
+	return s.repo.GetAll()
+}
+
+func (s *SupplierService) GetByID(id int) (*domain.Supplier, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *SupplierService) Create(supplier *domain.Supplier, meta domain.ChangeMeta) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Suppliers.Create(supplier); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntitySupplier, supplier.ID,
+			nil, supplier)
+	})
+}
+
+func (s *SupplierService) Update(supplier *domain.Supplier, meta domain.ChangeMeta) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Suppliers.GetByID(supplier.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Suppliers.Update(supplier); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntitySupplier, supplier.ID,
+			current, supplier)
+	})
+}
+
+// Delete removes a supplier. It fails with ErrConflict while purchase orders
+// still refer to the supplier.
+func (s *SupplierService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Suppliers.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Suppliers.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntitySupplier, id,
+			current, nil)
+	})
+}
+
+func validateSupplier(s *domain.Supplier) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return invalidInput("name is required")
+	}
+	if s.Email != "" && !strings.Contains(s.Email, "@") {
+		return invalidInput("email is not valid")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"math"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// applyTaxes computes tax for every quote line that has a tax rate, after
+// promotions have been applied. Cart discounts are shared across lines in
+// proportion to their discounted totals so they reduce the taxable amount.
+// Each line's tax is rounded half up to a whole Rupiah.
+func applyTaxes(quote *domain.Quote, rates map[int]domain.TaxRate) {
+	shares := allocateCartDiscount(quote)
+
+	summaries := make(map[int]*domain.TaxSummary)
+	quote.Taxes = make([]domain.TaxSummary, 0)
+	quote.Tax = 0
+	exclusiveTax := 0
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		line.Tax = 0
+		if line.TaxRateID == nil {
+			continue
+		}
+		rate, ok := rates[*line.TaxRateID]
+		if !ok {
+			continue
+		}
+
+		amount := line.Total - shares[i]
+		line.Tax = calculateTax(amount, rate)
+		taxable := amount
+		if rate.Inclusive {
+			taxable -= line.Tax
+		} else {
+			exclusiveTax += line.Tax
+		}
+
+		summary, ok := summaries[rate.ID]
+		if !ok {
+			quote.Taxes = append(quote.Taxes, domain.TaxSummary{
+				TaxRateID: rate.ID, Name: rate.Name, Rate: rate.Rate, Inclusive: rate.Inclusive,
+			})
+			summary = &quote.Taxes[len(quote.Taxes)-1]
+			summaries[rate.ID] = summary
+		}
+		summary.Taxable += taxable
+		summary.Tax += line.Tax
+		quote.Tax += line.Tax
+	}
+
+	quote.Total = quote.Subtotal - quote.Discount + exclusiveTax
+}
+
+// calculateTax returns the tax contained in amount for inclusive rates, or
+// the tax to add on top of amount for exclusive ones
+func calculateTax(amount int, rate domain.TaxRate) int {
+	basisPoints := int(math.Round(rate.Rate * 100))
+	if rate.Inclusive {
+		return divRoundHalfUp(amount*basisPoints, 10000+basisPoints)
+	}
+	return divRoundHalfUp(amount*basisPoints, 10000)
+}
+
+// allocateCartDiscount splits the cart-level discount across lines pro rata
+// to their totals, giving any rounding remainder to the last line
+func allocateCartDiscount(quote *domain.Quote) []int {
+	shares := make([]int, len(quote.Lines))
+	cartDiscount := sumDiscounts(quote.CartDiscounts)
+	base := 0
+	for _, line := range quote.Lines {
+		base += line.Total
+	}
+	if cartDiscount == 0 || base == 0 {
+		return shares
+	}
+
+	allocated, last := 0, -1
+	for i, line := range quote.Lines {
+		if line.Total == 0 {
+			continue
+		}
+		shares[i] = cartDiscount * line.Total / base
+		allocated += shares[i]
+		last = i
+	}
+	shares[last] += cartDiscount - allocated
+	return shares
+}
+
+func divRoundHalfUp(numerator, denominator int) int {
+	if numerator < 0 {
+		return -divRoundHalfUp(-numerator, denominator)
+	}
+	return (numerator + denominator/2) / denominator
+}
+
+// checkTaxRate verifies an optional tax rate reference
+func checkTaxRate(repo repository.TaxRateRepository, id *int) error {
+	if id == nil {
+		return nil
+	}
+	if _, err := repo.GetByID(*id); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrTaxRateNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// TaxRateService handles tax rate business logic
+type TaxRateService struct {
+	repo       repository.TaxRateRepository
+	transactor repository.Transactor
+}
+
+// NewTaxRateService creates a new tax rate service
+func NewTaxRateService(repo repository.TaxRateRepository, transactor repository.Transactor) *TaxRateService {
+	return &TaxRateService{repo: repo, transactor: transactor}
+}
+
+func (s *TaxRateService) GetAll() ([]domain.TaxRate, error) {
+	return s.repo.GetAll()
+}
+
+func (s *TaxRateService) GetByID(id int) (*domain.TaxRate, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TaxRateService) Create(rate *domain.TaxRate, meta domain.ChangeMeta) error {
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.TaxRates.Create(rate); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityTaxRate, rate.ID,
+			nil, rate)
+	})
+}
+
+func (s *TaxRateService) Update(rate *domain.TaxRate, meta domain.ChangeMeta) error {
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.TaxRates.GetByID(rate.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.TaxRates.Update(rate); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityTaxRate, rate.ID,
+			current, rate)
+	})
+}
+
+// Delete removes a tax rate. It fails with ErrConflict while products or
+// categories still use the rate.
+func (s *TaxRateService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.TaxRates.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.TaxRates.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityTaxRate, id,
+			current, nil)
+	})
+}
+
+func validateTaxRate(rate *domain.TaxRate) error {
+	if rate.Name == "" {
+		return invalidInput("name is required")
+	}
+	if rate.Rate <= 0 || rate.Rate > 100 {
+		return invalidInput("rate must be greater than 0 and at most 100")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+
+	"kasir-api/internal/domain"
+)
+
+func TestApplyTaxes(t *testing.T) {
+	ppn := domain.TaxRate{ID: 1, Name: "PPN", Rate: 11, Inclusive: true}
+	pb1 := domain.TaxRate{ID: 2, Name: "PB1", Rate: 10}
+	rates := map[int]domain.TaxRate{ppn.ID: ppn, pb1.ID: pb1}
+
+	line := func(total int, taxRateID *int) domain.QuoteLine {
+		return domain.QuoteLine{Subtotal: total, Total: total, TaxRateID: taxRateID}
+	}
+	quote := func(cartDiscount int, lines ...domain.QuoteLine) *domain.Quote {
+		q := &domain.Quote{Lines: lines, CartDiscounts: []domain.AppliedDiscount{}}
+		for _, l := range lines {
+			q.Subtotal += l.Subtotal
+		}
+		if cartDiscount > 0 {
+			q.CartDiscounts = append(q.CartDiscounts, domain.AppliedDiscount{Amount: cartDiscount})
+		}
+		q.Discount = cartDiscount
+		return q
+	}
+
+	tests := []struct {
+		name        string
+		quote       *domain.Quote
+		wantLineTax []int
+		wantTaxes   []domain.TaxSummary
+		wantTax     int
+		wantTotal   int
+	}{
+		{
+			name:        "untaxed and unknown rates",
+			quote:       quote(0, line(10000, nil), line(5000, intPtr(99))),
+			wantLineTax: []int{0, 0},
+			wantTaxes:   []domain.TaxSummary{},
+			wantTotal:   15000,
+		},
+		{
+			name:        "inclusive tax is extracted from the price",
+			quote:       quote(0, line(9450, intPtr(1))),
+			wantLineTax: []int{936},
+			wantTaxes:   []domain.TaxSummary{{TaxRateID: 1, Name: "PPN", Rate: 11, Inclusive: true, Taxable: 8514, Tax: 936}},
+			wantTax:     936,
+			wantTotal:   9450,
+		},
+		{
+			name:        "exclusive tax is added to the total",
+			quote:       quote(0, line(10500, intPtr(2)), line(2000, intPtr(2))),
+			wantLineTax: []int{1050, 200},
+			wantTaxes:   []domain.TaxSummary{{TaxRateID: 2, Name: "PB1", Rate: 10, Taxable: 12500, Tax: 1250}},
+			wantTax:     1250,
+			wantTotal:   13750,
+		},
+		{
+			name:        "rounds half up",
+			quote:       quote(0, line(55, intPtr(2)), line(54, intPtr(2))),
+			wantLineTax: []int{6, 5},
+			wantTaxes:   []domain.TaxSummary{{TaxRateID: 2, Name: "PB1", Rate: 10, Taxable: 109, Tax: 11}},
+			wantTax:     11,
+			wantTotal:   120,
+		},
+		{
+			name:        "cart discount reduces the taxable amount pro rata",
+			quote:       quote(2050, line(10500, intPtr(2)), line(10000, nil)),
+			wantLineTax: []int{945, 0},
+			wantTaxes:   []domain.TaxSummary{{TaxRateID: 2, Name: "PB1", Rate: 10, Taxable: 9450, Tax: 945}},
+			wantTax:     945,
+			wantTotal:   19395,
+		},
+		{
+			name:        "mixed rates",
+			quote:       quote(0, line(11100, intPtr(1)), line(10000, intPtr(2))),
+			wantLineTax: []int{1100, 1000},
+			wantTaxes: []domain.TaxSummary{
+				{TaxRateID: 1, Name: "PPN", Rate: 11, Inclusive: true, Taxable: 10000, Tax: 1100},
+				{TaxRateID: 2, Name: "PB1", Rate: 10, Taxable: 10000, Tax: 1000},
+			},
+			wantTax:   2100,
+			wantTotal: 22100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyTaxes(tt.quote, rates)
+
+			for i, want := range tt.wantLineTax {
+				if got := tt.quote.Lines[i].Tax; got != want {
+					t.Errorf("line %d tax = %d, want %d", i, got, want)
+				}
+			}
+			if len(tt.quote.Taxes) != len(tt.wantTaxes) {
+				t.Fatalf("taxes = %+v, want %+v", tt.quote.Taxes, tt.wantTaxes)
+			}
+			for i := range tt.wantTaxes {
+				if tt.quote.Taxes[i] != tt.wantTaxes[i] {
+					t.Errorf("taxes[%d] = %+v, want %+v", i, tt.quote.Taxes[i], tt.wantTaxes[i])
+				}
+			}
+			if tt.quote.Tax != tt.wantTax || tt.quote.Total != tt.wantTotal {
+				t.Errorf("tax, total = %d, %d, want %d, %d", tt.quote.Tax, tt.quote.Total, tt.wantTax, tt.wantTotal)
+			}
+		})
+	}
+}
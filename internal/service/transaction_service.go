@@ -0,0 +1,445 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/receipt"
+	"kasir-api/internal/repository"
+)
+
+// TransactionService handles completed sales, the checked out carts, after
+// checkout: printing their receipts, refunding and voiding them
+type TransactionService struct {
+	cartRepo     repository.CartRepository
+	refundRepo   repository.RefundRepository
+	outletRepo   repository.OutletRepository
+	transactor   repository.Transactor
+	voidWindow   time.Duration
+	receipts     *receipt.Renderer
+	receiptPaper int
+	now          func() time.Time
+}
+
+// NewTransactionService creates a new transaction service. A sale can be
+// voided for voidWindow after checkout, and after that until its shift
+// closes. Receipts are rendered by receipts, for receiptPaper unless another
+// paper width is asked for.
+func NewTransactionService(cartRepo repository.CartRepository, refundRepo repository.RefundRepository,
+	outletRepo repository.OutletRepository, transactor repository.Transactor, voidWindow time.Duration,
+	receipts *receipt.Renderer, receiptPaper int) *TransactionService {
+	return &TransactionService{
+		cartRepo:     cartRepo,
+		refundRepo:   refundRepo,
+		outletRepo:   outletRepo,
+		transactor:   transactor,
+		voidWindow:   voidWindow,
+		receipts:     receipts,
+		receiptPaper: receiptPaper,
+		now:          time.Now,
+	}
+}
+
+// Receipt renders a sale's receipt in format, text unless given, for a paper
+// width in millimetres, the configured one unless given. A voided sale's
+// receipt is marked void.
+func (s *TransactionService) Receipt(id int, format string, paper int) ([]byte, error) {
+	switch format {
+	case "":
+		format = receipt.FormatText
+	case receipt.FormatText, receipt.FormatESCPOS, receipt.FormatPDF:
+	default:
+		return nil, invalidInput("format must be text, escpos or pdf")
+	}
+	if paper == 0 {
+		paper = s.receiptPaper
+	}
+	if _, ok := receipt.Columns(paper); !ok {
+		return nil, invalidInput("paper must be 58 or 80")
+	}
+
+	cart, err := s.cartRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSale(cart); err != nil {
+		return nil, err
+	}
+	outlet, err := s.outletRepo.GetByID(cart.OutletID)
+	if err != nil {
+		return nil, err
+	}
+	return s.receipts.Render(format, *outlet, *cart, paper)
+}
+
+// GetRefunds lists the refunds of a sale, oldest first
+func (s *TransactionService) GetRefunds(id int) ([]domain.Refund, error) {
+	cart, err := s.cartRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSale(cart); err != nil {
+		return nil, err
+	}
+	return s.refundRepo.GetByCart(id)
+}
+
+// Refund gives back the requested quantities of a sale's lines, or all that
+// is left to refund when no lines are given. The sale is locked while what
+// was already refunded is read, so no line is refunded more than was sold.
+// The refund is paid back in the refunding cashier's open shift, locked
+// before the sale as voids lock them, and a cash refund needs one so the
+// drawer can be reconciled.
+// Each line is refunded its share of what the sale took, tax and any cart
+// discount or points included, in proportion to the quantity. With Restock
+// the same share of the stock the line took at checkout goes back into the
+// sale outlet's stock through the stock ledger.
+func (s *TransactionService) Refund(id int, input domain.RefundInput, meta domain.ChangeMeta) (*domain.Refund, error) {
+	for _, line := range input.Lines {
+		if line.Quantity <= 0 {
+			return nil, invalidInput("quantity must be positive")
+		}
+	}
+	switch input.Method {
+	case "", domain.PaymentCash, domain.PaymentQRIS, domain.PaymentDebitCard, domain.PaymentEWallet:
+	default:
+		return nil, invalidInput("method must be cash, qris, debit_card or e_wallet")
+	}
+
+	var refund *domain.Refund
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		sale, err := repos.Carts.GetByID(id)
+		if err != nil {
+			return err
+		}
+		// A cart still being checked out holds its lock while it waits for
+		// the shift, so only sales go on to lock one
+		if err := checkSale(sale); err != nil {
+			return err
+		}
+		method := input.Method
+		if method == "" {
+			method = refundMethod(sale)
+		}
+		shiftID, err := openShift(repos.Shifts, meta.Actor, method == domain.PaymentCash)
+		if err != nil {
+			return err
+		}
+		cart, err := repos.Carts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := checkSale(cart); err != nil {
+			return err
+		}
+		if cart.Status == domain.CartStatusVoided {
+			return apperrors.ErrConflict
+		}
+		previous, err := repos.Refunds.GetByCart(cart.ID)
+		if err != nil {
+			return err
+		}
+		refunded := refundedQuantities(cart, previous)
+		quantities, err := refundQuantities(cart, input.Lines, refunded)
+		if err != nil {
+			return err
+		}
+
+		refund = &domain.Refund{
+			CartID:     cart.ID,
+			OutletID:   cart.OutletID,
+			ShiftID:    shiftID,
+			Lines:      make([]domain.RefundLine, 0, len(quantities)),
+			Restock:    input.Restock,
+			Reason:     input.Reason,
+			Method:     method,
+			RefundedBy: meta.Actor,
+			CreatedAt:  s.now(),
+		}
+		shares := lineShares(cart)
+		var returned []domain.StockTake
+		for i, quantity := range quantities {
+			if quantity == 0 {
+				continue
+			}
+			item := cart.Items[i]
+			line := domain.RefundLine{
+				ProductID: item.ProductID,
+				UnitID:    item.UnitID,
+				Quantity:  quantity,
+				Amount:    quantityShare(shares[i], item.Quantity, refunded[i], quantity),
+				Tax:       quantityShare(item.Tax, item.Quantity, refunded[i], quantity),
+				CostPrice: item.CostPrice,
+			}
+			refund.Lines = append(refund.Lines, line)
+			refund.Amount += line.Amount
+			refund.Tax += line.Tax
+			for _, taken := range item.Stock {
+				returned = append(returned, domain.StockTake{
+					ProductID: taken.ProductID,
+					Quantity:  quantityShare(taken.Quantity, item.Quantity, refunded[i], quantity),
+				})
+			}
+		}
+		if len(refund.Lines) == 0 {
+			return invalidInput("nothing left to refund")
+		}
+		if err := repos.Refunds.Create(refund); err != nil {
+			return err
+		}
+
+		if refund.Restock {
+			err := restock(repos, cart.OutletID, returned, domain.StockMovementRefund,
+				fmt.Sprintf("REFUND-%d", refund.ID), meta.Actor, refund.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityRefund, refund.ID, nil, refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// Void undoes a sale a cashier rang up by mistake: the stock its lines took
+// at checkout goes back to the outlet through the stock ledger, the customer's points earned and redeemed
+// are reversed, and the cart is marked voided, no longer counting as a sale.
+// A sale can be voided within the void window after checkout or while its
+// shift is still open, and not once it has refunds. The customer and the
+// shift are locked before the sale, so a shift closing concurrently counts
+// the sale either fully or not at all; a cart not yet checked out is turned
+// away first, as checkout holds its lock while taking the same two.
+func (s *TransactionService) Void(id int, input domain.VoidInput, meta domain.ChangeMeta) (*domain.Cart, error) {
+	var cart *domain.Cart
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		sale, err := repos.Carts.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := checkSale(sale); err != nil {
+			return err
+		}
+		if sale.CustomerID != nil {
+			// A deleted customer has no points left to correct
+			_, err := repos.Customers.GetByIDForUpdate(*sale.CustomerID)
+			if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+				return err
+			}
+		}
+		var shift *domain.Shift
+		if sale.ShiftID != nil {
+			if shift, err = repos.Shifts.GetByIDForUpdate(*sale.ShiftID); err != nil {
+				return err
+			}
+		}
+		cart, err = repos.Carts.GetByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := checkSale(cart); err != nil {
+			return err
+		}
+		if cart.Status == domain.CartStatusVoided {
+			return apperrors.ErrConflict
+		}
+		refunds, err := repos.Refunds.GetByCart(cart.ID)
+		if err != nil {
+			return err
+		}
+		if len(refunds) > 0 {
+			return fmt.Errorf("%w: it has refunds", apperrors.ErrVoidNotAllowed)
+		}
+		now := s.now()
+		inWindow := s.voidWindow > 0 && cart.CheckedOutAt != nil && now.Sub(*cart.CheckedOutAt) <= s.voidWindow
+		if !inWindow && (shift == nil || !shift.IsOpen()) {
+			return fmt.Errorf("%w: the void window has passed and its shift is closed", apperrors.ErrVoidNotAllowed)
+		}
+		before := cloneCart(*cart)
+
+		var taken []domain.StockTake
+		for _, item := range cart.Items {
+			taken = append(taken, item.Stock...)
+		}
+		err = restock(repos, cart.OutletID, taken, domain.StockMovementVoid, fmt.Sprintf("CART-%d", cart.ID), meta.Actor, now)
+		if err != nil {
+			return err
+		}
+		if cart.CustomerID != nil && cart.PointsRedeemed != cart.PointsEarned {
+			// A deleted customer has no points left to correct
+			err := repos.Customers.AddPoints(*cart.CustomerID, cart.PointsRedeemed-cart.PointsEarned)
+			if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+				return err
+			}
+		}
+
+		cart.VoidedAt = &now
+		cart.VoidedBy = meta.Actor
+		cart.VoidReason = input.Reason
+		cart.UpdatedAt = now
+		if err := repos.Carts.Void(cart); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityCart, cart.ID, before, cart)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// checkSale fails with ErrNotFound for carts that are not completed sales,
+// voided or not
+func checkSale(cart *domain.Cart) error {
+	switch cart.Status {
+	case domain.CartStatusCheckedOut, domain.CartStatusVoided:
+		return nil
+	}
+	return apperrors.ErrNotFound
+}
+
+// refundMethod is how a sale is paid back unless another method is asked
+// for: in cash when it took any cash, otherwise in its first tender
+func refundMethod(cart *domain.Cart) string {
+	for _, p := range cart.Payments {
+		if p.Method == domain.PaymentCash {
+			return domain.PaymentCash
+		}
+	}
+	if len(cart.Payments) > 0 {
+		return cart.Payments[0].Method
+	}
+	return domain.PaymentCash
+}
+
+// refundedQuantities sums what earlier refunds gave back of each of the
+// cart's lines, by line index
+func refundedQuantities(cart *domain.Cart, refunds []domain.Refund) []int {
+	refunded := make([]int, len(cart.Items))
+	for _, refund := range refunds {
+		for _, line := range refund.Lines {
+			for i, item := range cart.Items {
+				if item.ProductID == line.ProductID && item.UnitID == line.UnitID {
+					refunded[i] += line.Quantity
+				}
+			}
+		}
+	}
+	return refunded
+}
+
+// refundQuantities works out how much of each of the cart's lines, by line
+// index, a refund gives back, failing when a line would be refunded more
+// than was sold. Without lines everything not yet refunded is given back.
+func refundQuantities(cart *domain.Cart, lines []domain.RefundLineInput, refunded []int) ([]int, error) {
+	quantities := make([]int, len(cart.Items))
+	if len(lines) == 0 {
+		for i, item := range cart.Items {
+			quantities[i] = item.Quantity - refunded[i]
+		}
+		return quantities, nil
+	}
+
+	for _, line := range lines {
+		match := -1
+		for i, item := range cart.Items {
+			if item.ProductID != line.ProductID || (line.UnitID != 0 && item.UnitID != line.UnitID) {
+				continue
+			}
+			if match >= 0 {
+				return nil, invalidInput(fmt.Sprintf("%s was sold in several units, unit_id is needed", item.Name))
+			}
+			match = i
+		}
+		if match < 0 {
+			return nil, invalidInput(fmt.Sprintf("product %d was not sold in this unit", line.ProductID))
+		}
+		quantities[match] += line.Quantity
+	}
+	for i, item := range cart.Items {
+		if left := item.Quantity - refunded[i]; quantities[i] > left {
+			return nil, invalidInput(fmt.Sprintf("only %d of %s left to refund", left, item.Name))
+		}
+	}
+	return quantities, nil
+}
+
+// lineShares splits what a sale took, its total after cart discounts, tax
+// and points, across its lines in proportion to their totals, giving any
+// rounding remainder to the last line
+func lineShares(cart *domain.Cart) []int {
+	shares := make([]int, len(cart.Items))
+	base := 0
+	for _, item := range cart.Items {
+		base += item.Total
+	}
+	if base == 0 {
+		return shares
+	}
+
+	allocated, last := 0, -1
+	for i, item := range cart.Items {
+		if item.Total == 0 {
+			continue
+		}
+		shares[i] = cart.Total * item.Total / base
+		allocated += shares[i]
+		last = i
+	}
+	shares[last] += cart.Total - allocated
+	return shares
+}
+
+// quantityShare is the part of amount, spread over sold items, that quantity
+// more items are worth after refunded have been. Refunding every item in any
+// number of steps adds up to amount exactly.
+func quantityShare(amount, sold, refunded, quantity int) int {
+	return amount*(refunded+quantity)/sold - amount*refunded/sold
+}
+
+// restock puts stock taken by sold lines back into an outlet's stock and
+// writes each product returned to the stock ledger with reason and reference.
+// Stock is added in product order, the order checkout locks it in. Products
+// deleted since have no stock to return to and are skipped.
+func restock(repos repository.Repositories, outletID int, taken []domain.StockTake, reason, reference, actor string,
+	now time.Time) error {
+	totals := make(map[int]int, len(taken))
+	for _, t := range taken {
+		totals[t.ProductID] += t.Quantity
+	}
+
+	for _, productID := range slices.Sorted(maps.Keys(totals)) {
+		quantity := totals[productID]
+		if quantity == 0 {
+			continue
+		}
+		if _, err := repos.Products.GetByID(productID); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if err := repos.Products.AddStock(productID, outletID, quantity); err != nil {
+			return err
+		}
+		err := repos.StockMovements.Create(&domain.StockMovement{
+			ProductID: productID,
+			OutletID:  outletID,
+			Quantity:  quantity,
+			Reason:    reason,
+			Reference: reference,
+			Actor:     actor,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"kasir-api/internal/domain"
+)
+
+func TestLineShares(t *testing.T) {
+	cart := &domain.Cart{
+		Total: 10000,
+		Items: []domain.CartItem{{Total: 3000}, {Total: 0}, {Total: 4000}, {Total: 3500}},
+	}
+	// 10000 split 3000:4000:3500 rounds down to 2857 and 3809, the last line
+	// taking the remainder
+	if got, want := lineShares(cart), []int{2857, 0, 3809, 3334}; !slices.Equal(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+	if got := lineShares(&domain.Cart{Items: []domain.CartItem{{}}}); !slices.Equal(got, []int{0}) {
+		t.Errorf("shares of a free cart = %v, want [0]", got)
+	}
+}
+
+func TestQuantityShare(t *testing.T) {
+	// Refunding 1, then 1, then 1 of 3 items adds up to the whole amount
+	total := 0
+	for refunded := range 3 {
+		total += quantityShare(1000, 3, refunded, 1)
+	}
+	if total != 1000 {
+		t.Errorf("refunded one at a time = %d, want 1000", total)
+	}
+	if got := quantityShare(1000, 3, 1, 2); got != 667 {
+		t.Errorf("last two of three = %d, want 667", got)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kasir-api/internal/apperrors"
+	"kasir-api/internal/domain"
+	"kasir-api/internal/repository"
+)
+
+// UnitService handles units of measure and the unit conversions of products
+type UnitService struct {
+	repo            repository.UnitRepository
+	productRepo     repository.ProductRepository
+	productUnitRepo repository.ProductUnitRepository
+	transactor      repository.Transactor
+}
+
+// NewUnitService creates a new unit service
+func NewUnitService(repo repository.UnitRepository, productRepo repository.ProductRepository,
+	productUnitRepo repository.ProductUnitRepository, transactor repository.Transactor) *UnitService {
+	return &UnitService{repo: repo, productRepo: productRepo, productUnitRepo: productUnitRepo, transactor: transactor}
+}
+
+func (s *UnitService) GetAll() ([]domain.Unit, error) {
+	return s.repo.GetAll()
+}
+
+func (s *UnitService) GetByID(id int) (*domain.Unit, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *UnitService) Create(unit *domain.Unit, meta domain.ChangeMeta) error {
+	if err := validateUnit(unit); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		if err := repos.Units.Create(unit); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionCreate, domain.AuditEntityUnit, unit.ID, nil, unit)
+	})
+}
+
+func (s *UnitService) Update(unit *domain.Unit, meta domain.ChangeMeta) error {
+	if err := validateUnit(unit); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Units.GetByID(unit.ID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Units.Update(unit); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityUnit, unit.ID,
+			current, unit)
+	})
+}
+
+// Delete removes a unit. It fails with ErrConflict for the piece unit and
+// while products are counted or converted in the unit.
+func (s *UnitService) Delete(id int, meta domain.ChangeMeta) error {
+	return s.transactor.WithinTx(func(repos repository.Repositories) error {
+		current, err := repos.Units.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if id == domain.DefaultUnitID {
+			return apperrors.ErrConflict
+		}
+		if err := repos.Units.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionDelete, domain.AuditEntityUnit, id, current, nil)
+	})
+}
+
+// GetProductUnits returns the units a product converts into its base unit
+func (s *UnitService) GetProductUnits(productID int) ([]domain.ProductUnit, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.productUnitRepo.GetByProductID(productID)
+}
+
+// SetProductUnits replaces a product's unit conversions. The product's base
+// unit converts into itself and cannot be listed, and each unit can only be
+// listed once. The change is audited as an update of the product's units.
+func (s *UnitService) SetProductUnits(productID int, units []domain.ProductUnit, meta domain.ChangeMeta) ([]domain.ProductUnit, error) {
+	if units == nil {
+		units = []domain.ProductUnit{}
+	}
+	seen := make(map[int]bool, len(units))
+	for _, u := range units {
+		if u.Factor <= 0 {
+			return nil, invalidInput("factor must be greater than zero")
+		}
+		if u.Price < 0 {
+			return nil, invalidInput("price must not be negative")
+		}
+		if seen[u.UnitID] {
+			return nil, invalidInput(fmt.Sprintf("unit %d is listed more than once", u.UnitID))
+		}
+		seen[u.UnitID] = true
+	}
+
+	var saved []domain.ProductUnit
+	err := s.transactor.WithinTx(func(repos repository.Repositories) error {
+		product, err := repos.Products.GetByID(productID)
+		if err != nil {
+			return err
+		}
+		for _, u := range units {
+			if u.UnitID == product.UnitID {
+				return invalidInput(fmt.Sprintf("unit %d is the product's base unit", u.UnitID))
+			}
+			if err := checkUnit(repos.Units, u.UnitID); err != nil {
+				return err
+			}
+		}
+
+		current, err := repos.ProductUnits.GetByProductID(productID)
+		if err != nil {
+			return err
+		}
+		if err := repos.ProductUnits.ReplaceForProduct(productID, units); err != nil {
+			return err
+		}
+		if saved, err = repos.ProductUnits.GetByProductID(productID); err != nil {
+			return err
+		}
+		return recordAudit(repos.Audit, meta, domain.AuditActionUpdate, domain.AuditEntityProduct, productID,
+			domain.ProductUnitsInput{Units: current}, domain.ProductUnitsInput{Units: saved})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func validateUnit(u *domain.Unit) error {
+	u.Code = strings.ToLower(strings.TrimSpace(u.Code))
+	u.Name = strings.TrimSpace(u.Name)
+	if u.Code == "" {
+		return invalidInput("code is required")
+	}
+	if u.Name == "" {
+		return invalidInput("name is required")
+	}
+	return nil
+}
+
+// resolveUnit returns the base unit a product is counted in: the piece unit
+// when id is zero, otherwise id once it is known to exist
+func resolveUnit(repo repository.UnitRepository, id int) (int, error) {
+	if id == 0 {
+		return domain.DefaultUnitID, nil
+	}
+	return id, checkUnit(repo, id)
+}
+
+// checkUnit fails with ErrUnitNotFound unless the unit exists
+func checkUnit(repo repository.UnitRepository, id int) error {
+	if _, err := repo.GetByID(id); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.ErrUnitNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// unitConversion returns how one unitID of a product converts into its base
+// unit and what it sells for. Zero and the product's own unit are the base
+// unit itself; any other unit must be one of the product's conversions.
+func unitConversion(repo repository.ProductUnitRepository, product *domain.Product, unitID int) (domain.ProductUnit, error) {
+	if unitID == 0 || unitID == product.UnitID {
+		return domain.ProductUnit{UnitID: product.UnitID, Factor: 1, Price: product.Price}, nil
+	}
+	units, err := repo.GetByProductID(product.ID)
+	if err != nil {
+		return domain.ProductUnit{}, err
+	}
+	for _, u := range units {
+		if u.UnitID == unitID {
+			u.Price = u.SellingPrice(product.Price)
+			return u, nil
+		}
+	}
+	return domain.ProductUnit{}, invalidInput(fmt.Sprintf("%s has no conversion for unit %d", product.Name, unitID))
+}
@@ -0,0 +1,61 @@
+package storage_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kasir-api/internal/storage"
+)
+
+func TestLocal_SaveServeDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewLocal(dir, "/uploads/")
+
+	if err := store.Save("products/1/photo.jpg", strings.NewReader("jpeg bytes")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "products", "1", "photo.jpg")); err != nil || string(got) != "jpeg bytes" {
+		t.Fatalf("stored file = %q, %v, want the saved content", got, err)
+	}
+	if got := store.URL("products/1/photo.jpg"); got != "/uploads/products/1/photo.jpg" {
+		t.Errorf("URL = %q, want /uploads/products/1/photo.jpg", got)
+	}
+
+	rec := httptest.NewRecorder()
+	store.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products/1/photo.jpg", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "jpeg bytes" {
+		t.Errorf("serve = %d %q, want 200 with the saved content", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	store.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products/1/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("serve directory = %d, want 404", rec.Code)
+	}
+
+	if err := store.Delete("products/1/photo.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "products", "1", "photo.jpg")); !os.IsNotExist(err) {
+		t.Errorf("after Delete: stat err = %v, want not exist", err)
+	}
+	if err := store.Delete("products/1/photo.jpg"); err != nil {
+		t.Errorf("Delete missing: %v, want nil", err)
+	}
+}
+
+func TestLocal_RejectsKeysOutsideDir(t *testing.T) {
+	store := storage.NewLocal(t.TempDir(), "/uploads")
+	for _, key := range []string{"", "../escape.jpg", "/etc/passwd", "products/../../escape.jpg"} {
+		if err := store.Save(key, strings.NewReader("x")); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Errorf("Save(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(key); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Errorf("Delete(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Package storage keeps uploaded files, such as product images, outside the
+// database
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrInvalidKey is returned for keys that are empty or would escape the
+// storage root
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage saves files under keys and tells clients where to fetch them.
+// Keys are slash-separated relative paths such as products/1/3f2a9c1e.jpg,
+// so an S3-compatible implementation can use them as object keys unchanged.
+type Storage interface {
+	// Save stores what r yields under key, replacing any file already there
+	Save(key string, r io.Reader) error
+	// Delete removes the file under key. Deleting a missing file is not an
+	// error.
+	Delete(key string) error
+	// URL returns where clients fetch the file under key
+	URL(key string) string
+}